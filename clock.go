@@ -0,0 +1,49 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import "time"
+
+// Clock abstracts the passing of time, for interval based behaviour
+// (like [BufferedWriter]'s auto-flush), so that it can be
+// driven deterministically, in tests, for example.
+// [SystemClock] is the real implementation, used by default.
+// Note: the current time used by providers is configured with [SetNowFunc].
+type Clock interface {
+	// NewTicker returns a new Ticker, ticking with given period.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker abstracts a [time.Ticker], see [Clock].
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+}
+
+// SystemClock is the [Clock] based on the system's time.
+type SystemClock struct{}
+
+// NewTicker returns a Ticker based on [time.NewTicker].
+func (SystemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{ticker: time.NewTicker(d)}
+}
+
+// systemTicker is the [Ticker] returned by [SystemClock].
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+// C returns the channel on which the ticks are delivered.
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Stop turns off the ticker.
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
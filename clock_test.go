@@ -0,0 +1,73 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestSystemClock(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ticker", testSystemClockTicker)
+}
+
+func testSystemClockTicker(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.SystemClock{}
+
+	// act
+	ticker := subject.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	// assert
+	select {
+	case <-ticker.C():
+	case <-time.After(5 * time.Second):
+		t.Error("ticker did not tick")
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		start   = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+		subject = NewFakeClock(start)
+		ticker  = subject.NewTicker(time.Second)
+	)
+
+	// act & assert
+	subject.Advance(999 * time.Millisecond)
+	assertEqual(t, start.Add(999*time.Millisecond), subject.Now())
+	select {
+	case <-ticker.C():
+		t.Error("unexpected tick")
+	default:
+	}
+
+	subject.Advance(time.Millisecond)
+	select {
+	case tick := <-ticker.C():
+		assertEqual(t, start.Add(time.Second), tick)
+	default:
+		t.Error("expected a tick")
+	}
+
+	ticker.Stop()
+	subject.Advance(time.Second)
+	select {
+	case <-ticker.C():
+		t.Error("unexpected tick after stop")
+	default:
+	}
+}
@@ -6,10 +6,18 @@
 package xlog
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path"
+	"reflect"
 	"runtime"
+	"runtime/debug"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,6 +30,11 @@ const MessageKey = "msg"
 // You are not obliged to use this key.
 const ErrorKey = "err"
 
+// StackKey represents the key under which the stack trace resides,
+// when loggers are configured to capture it automatically
+// (see [SyncLoggerWithStackTrace] / [AsyncLoggerWithStackTrace]).
+const StackKey = "stack"
+
 const (
 	defaultOptTimeKey   = "date"
 	defaultOptLevelKey  = "lvl"
@@ -32,6 +45,14 @@ const (
 // slice in case provided slice is odd.
 const noValue = "*NoValue*"
 
+// ErrOddKeyValues is the error reported to [CommonOpts.ErrHandler], in strict
+// mode (see [CommonOpts.StrictKeyValues]), for a log with odd key-values.
+var ErrOddKeyValues = errors.New("xlog: odd number of key-values")
+
+// ErrNonStringKey is the error reported to [CommonOpts.ErrHandler], in strict
+// mode (see [CommonOpts.StrictKeyValues]), for a log with a non-string key.
+var ErrNonStringKey = errors.New("xlog: non-string key")
+
 // CommonOpts is a struct holding common configurations for a logger.
 type CommonOpts struct {
 	// MinLevel is a function that returns the minimum level
@@ -49,6 +70,14 @@ type CommonOpts struct {
 	// By default, "CRITICAL", "ERROR", "WARN", "INFO", "DEBUG" labels are used.
 	LevelLabels map[Level]string
 
+	// LevelFormatter is a function that returns the value logged for a level,
+	// overriding LevelLabels. It is useful for backends preferring numeric
+	// severities, see [NumericLevelFormatter].
+	// Formatters mapping levels (like syslog, sentry, RFC5424, text with colors)
+	// recognize its values, too.
+	// By default, is nil, meaning LevelLabels are used.
+	LevelFormatter func(lvl Level) any
+
 	// LevelKey is the key under which level is found.
 	// By default, is set to "lvl".
 	LevelKey string
@@ -61,6 +90,13 @@ type CommonOpts struct {
 	// By default, is set to UTC time formatted as RFC3339Nano.
 	Time Provider
 
+	// TimeLayout is the layout [time.Time] values passed in key-values are
+	// formatted with, so that all formats log them consistently.
+	// It can be set to an empty string if you want [time.Time] values to be
+	// serialized by the formatter as they are.
+	// By default, is set to RFC3339Nano.
+	TimeLayout string
+
 	// SourceKey is the key under which caller filename and line are found.
 	// It can be set to an empty string if you want to disable this information
 	// from logs.
@@ -69,8 +105,30 @@ type CommonOpts struct {
 
 	// Source is a provider that returns the source where the log occurred
 	// in the call stack.
+	// By default, is set to a provider equivalent to SourceProvider(4, 0).
 	Source Provider
 
+	// SourceSkipExtra is the no. of extra frames to be skipped by the
+	// default Source provider. It is useful when you wrap xlog in your own
+	// helper, which introduces extra frame(s) in the call stack, so that
+	// the source points to the helper's caller instead of the helper.
+	// Note: it is not applied to a Source provider you set explicitly,
+	// like SourceProvider(skipFrames, skipPath); add the extra frames to
+	// its skipFrames instead.
+	// By default, is 0.
+	SourceSkipExtra int
+
+	// UseRelativeSource flag, if true, the default Source provider logs
+	// the file path relative to the main module (auto-detected from build info,
+	// see [SourceProviderFromBuildInfo]), like "internal/svc/handler.go:42",
+	// instead of the absolute, machine dependent, one.
+	// It works whether the binary is built with -trimpath flag, or not.
+	// Files outside the main module (dependencies) get only their base
+	// name logged (like "handler.go:42").
+	// Note: it is not applied to a Source provider you set explicitly.
+	// By default, is false.
+	UseRelativeSource bool
+
 	// AdditionalKeyValues holds additional key-values that will be stored
 	// with each log.
 	// Example: you may want to log your application version or name or
@@ -78,6 +136,43 @@ type CommonOpts struct {
 	// The value can be a Provider for dynamically retrieve a value at runtime.
 	AdditionalKeyValues []any
 
+	// NoValue is the placeholder value added to a log's key-values
+	// in case they are odd (the last key has no value).
+	// By default, is set to "*NoValue*".
+	NoValue string
+
+	// BareMessageAsKey flag, if true, odd key-values starting with a string
+	// have that string treated as the log's message, logged under [MessageKey]
+	// (example: logger.Info("user logged in", "userId", 123) logs
+	// "msg":"user logged in","userId":123), instead of the last key getting
+	// the NoValue placeholder.
+	// Note: this is ambiguous, as a value forgotten anywhere in a log also makes
+	// it odd (example: logger.Info("userId", 123, "ip") logs "msg":"userId","123":"ip").
+	// By default, is false.
+	BareMessageAsKey bool
+
+	// StrictKeyValues flag, if true, a log with odd key-values is reported
+	// to ErrHandler with an [ErrOddKeyValues] error (the log is still logged,
+	// with the NoValue placeholder), and a log with a non-string key is
+	// reported with an [ErrNonStringKey] error. It is useful during development,
+	// to surface misuse of the logging API.
+	// By default, is false.
+	StrictKeyValues bool
+
+	// NormalizeKeys flag, if true, non-string keys are converted to strings
+	// before formatting, so that every formatter behaves consistently
+	// (example: key 10 becomes "10"; a logfmt formatter would otherwise
+	// fail to encode it).
+	// By default, is false.
+	NormalizeKeys bool
+
+	// SequenceKey is the key under which a sequence number is logged.
+	// The sequence number is incremented with each log, across the logger's
+	// lifetime (starting with 1), useful for detecting dropped / reordered
+	// logs downstream. Each logger has its own sequence.
+	// By default, is set to an empty string, meaning the feature is disabled.
+	SequenceKey string
+
 	// ErrHandler callback to process errors that occurred during logging.
 	// By design, the logger contract does not return errors from its methods
 	// as you most probably use it for this purpose, to log an error, and
@@ -96,6 +191,21 @@ type LevelProvider func() Level
 // Provider is a function that returns at runtime a value.
 type Provider func() any
 
+// LazyValue is a value computed only if the log it is passed to
+// is actually going to be logged (it passes the level filter).
+// See [Lazy].
+type LazyValue func() any
+
+// Lazy wraps an expensive to compute value, in order to be computed only
+// if the log it is passed to is actually going to be logged.
+//
+// Example of usage:
+//
+//	logger.Debug("payload", xlog.Lazy(func() any { return expensive() }))
+func Lazy(fn func() any) LazyValue {
+	return fn
+}
+
 // ErrorHandler is a callback to handle internal logging errors.
 // It accepts as 1st param the internal logging error.
 // It accepts as 2nd param the key-values log entry on which error occurred.
@@ -109,24 +219,76 @@ var NopErrorHandler ErrorHandler = func(_ error, _ []any) {}
 // You can start customization of fields from this object.
 func NewCommonOpts() *CommonOpts {
 	return &CommonOpts{
-		MinLevel: FixedLevelProvider(LevelWarning),
-		MaxLevel: FixedLevelProvider(LevelCritical),
-		LevelLabels: map[Level]string{
-			LevelCritical: "CRITICAL",
-			LevelError:    "ERROR",
-			LevelWarning:  "WARN",
-			LevelInfo:     "INFO",
-			LevelDebug:    "DEBUG",
-		},
-		LevelKey:   defaultOptLevelKey,
-		TimeKey:    defaultOptTimeKey,
-		Time:       UTCTimeProvider(time.RFC3339Nano),
-		SourceKey:  defaultOptSourceKey,
-		Source:     SourceProvider(4, 0),
-		ErrHandler: NopErrorHandler,
+		MinLevel:    FixedLevelProvider(LevelWarning),
+		MaxLevel:    FixedLevelProvider(LevelCritical),
+		LevelLabels: DefaultLevelLabels(),
+		LevelKey:    defaultOptLevelKey,
+		TimeKey:     defaultOptTimeKey,
+		Time:        UTCTimeProvider(time.RFC3339Nano),
+		TimeLayout:  time.RFC3339Nano,
+		SourceKey:   defaultOptSourceKey,
+		Source:      defaultSourceProvider,
+		NoValue:     noValue,
+		ErrHandler:  NopErrorHandler,
 	}
 }
 
+// DefaultLevelLabels returns the default level labels
+// ("CRITICAL", "ERROR", "WARN", "INFO", "DEBUG"), the ones [NewCommonOpts] sets.
+// A new map is returned on each call, so it can be used as a starting point
+// for tweaking individual labels, without affecting other options.
+//
+// Example of usage:
+//
+//	labels := xlog.DefaultLevelLabels()
+//	labels[xlog.LevelWarning] = "WARNING"
+//	xOpts.LevelLabels = labels
+func DefaultLevelLabels() map[Level]string {
+	return map[Level]string{
+		LevelCritical: "CRITICAL",
+		LevelError:    "ERROR",
+		LevelWarning:  "WARN",
+		LevelInfo:     "INFO",
+		LevelDebug:    "DEBUG",
+	}
+}
+
+// Clone returns a copy of the options, with its own LevelLabels map and
+// AdditionalKeyValues slice, so that they can be tweaked without affecting
+// the original (example: when deriving a component specific logger from
+// the application's one).
+// Note: functions (providers, error handler) are shared.
+func (opts *CommonOpts) Clone() *CommonOpts {
+	clone := *opts
+	if opts.LevelLabels != nil {
+		clone.LevelLabels = make(map[Level]string, len(opts.LevelLabels))
+		for lvl, label := range opts.LevelLabels {
+			clone.LevelLabels[lvl] = label
+		}
+	}
+	if opts.AdditionalKeyValues != nil {
+		clone.AdditionalKeyValues = make([]any, len(opts.AdditionalKeyValues))
+		copy(clone.AdditionalKeyValues, opts.AdditionalKeyValues)
+	}
+
+	return &clone
+}
+
+// NumericLevelFormatter is a [CommonOpts.LevelFormatter] which logs a level
+// as its numeric value (example: 40 for [LevelError]).
+func NumericLevelFormatter(lvl Level) any {
+	return int(lvl)
+}
+
+// levelValue returns the value to be logged for given level.
+func (opts *CommonOpts) levelValue(lvl Level) any {
+	if opts.LevelFormatter != nil {
+		return opts.LevelFormatter(lvl)
+	}
+
+	return opts.LevelLabels[lvl]
+}
+
 // BetweenMinMax returns true if passed level is found in
 // [MinLevel, MaxLevel] interval, false otherwise.
 func (opts *CommonOpts) BetweenMinMax(lvl Level) bool {
@@ -134,35 +296,179 @@ func (opts *CommonOpts) BetweenMinMax(lvl Level) bool {
 }
 
 // WithDefaultKeyValues returns keyValues enriched with default ones.
+// [LazyValue] values are resolved.
 func (opts *CommonOpts) WithDefaultKeyValues(lvl Level, keyValues ...any) []any {
+	keyValues = opts.evenKeyValues(keyValues)
 	keyVals := make([]any, 0, 6+len(opts.AdditionalKeyValues)+len(keyValues))
-	keyValues = AppendNoValue(keyValues)
-	keyVals = append(keyVals, opts.TimeKey, opts.Time())
-	if lvl != LevelNone {
-		keyVals = append(keyVals, opts.LevelKey, opts.LevelLabels[lvl])
-	}
+
+	// Note: providers are called from here, and not from a helper, as some of them
+	// (like SourceProvider) depend on the call stack depth. Built-in loggers call
+	// appendDefaultKeyValues instead, at the same depth.
+	keyVals = opts.appendTimeAndLevel(keyVals, lvl)
 	if opts.SourceKey != "" {
-		source := opts.Source()
-		if source != "" {
-			keyVals = append(keyVals, opts.SourceKey, source)
+		if isDefaultSourceProvider(opts.Source) {
+			// -1 = this call replaces the provider's frame.
+			keyVals = opts.appendSource(keyVals, callerSource(defaultSourceSkipFrames-1+opts.SourceSkipExtra, opts.UseRelativeSource))
+		} else {
+			keyVals = opts.appendSource(keyVals, opts.Source())
 		}
 	}
+	for i := 0; i < len(opts.AdditionalKeyValues); i += 2 {
+		value := opts.AdditionalKeyValues[i+1]
+		if valueProvider, isProvider := value.(Provider); isProvider {
+			value = valueProvider()
+		}
+		keyVals = append(keyVals, opts.AdditionalKeyValues[i], value)
+	}
+
+	return opts.appendKeyValues(keyVals, keyValues)
+}
 
+// appendDefaultKeyValues is like [CommonOpts.WithDefaultKeyValues], but appends
+// to given slice (which can be a pooled one, or nil, case in which a new one
+// is allocated). It is meant to be called directly from loggers' log method,
+// so that providers are called at the same call stack depth as from WithDefaultKeyValues.
+func (opts *CommonOpts) appendDefaultKeyValues(keyVals []any, lvl Level, keyValues []any) []any {
+	keyValues = opts.evenKeyValues(keyValues)
+	if keyVals == nil {
+		keyVals = make([]any, 0, 6+len(opts.AdditionalKeyValues)+len(keyValues))
+	}
+
+	keyVals = opts.appendTimeAndLevel(keyVals, lvl)
+	if opts.SourceKey != "" {
+		if isDefaultSourceProvider(opts.Source) {
+			// -1 = this call replaces the provider's frame.
+			keyVals = opts.appendSource(keyVals, callerSource(defaultSourceSkipFrames-1+opts.SourceSkipExtra, opts.UseRelativeSource))
+		} else {
+			keyVals = opts.appendSource(keyVals, opts.Source())
+		}
+	}
 	for i := 0; i < len(opts.AdditionalKeyValues); i += 2 {
-		key := opts.AdditionalKeyValues[i]
 		value := opts.AdditionalKeyValues[i+1]
-		valueProvider, isProvider := value.(Provider)
-		if isProvider {
+		if valueProvider, isProvider := value.(Provider); isProvider {
 			value = valueProvider()
 		}
-		keyVals = append(keyVals, key, value)
+		keyVals = append(keyVals, opts.AdditionalKeyValues[i], value)
+	}
+
+	return opts.appendKeyValues(keyVals, keyValues)
+}
+
+// appendTimeAndLevel appends the time and the level (if not [LevelNone]) key-values.
+func (opts *CommonOpts) appendTimeAndLevel(keyVals []any, lvl Level) []any {
+	keyVals = append(keyVals, opts.TimeKey, opts.Time())
+	if lvl != LevelNone {
+		keyVals = append(keyVals, opts.LevelKey, opts.levelValue(lvl))
+	}
+
+	return keyVals
+}
+
+// appendSource appends the source key-value, if source is not empty.
+func (opts *CommonOpts) appendSource(keyVals []any, source any) []any {
+	if source == "" {
+		return keyVals
 	}
 
+	return append(keyVals, opts.SourceKey, source)
+}
+
+// appendKeyValues appends given (user passed) key-values, resolving them.
+func (opts *CommonOpts) appendKeyValues(keyVals, keyValues []any) []any {
 	keyVals = append(keyVals, keyValues...)
+	opts.resolveKeyValues(keyVals, keyValues)
 
 	return keyVals
 }
 
+// evenKeyValues returns given key-values, with the NoValue placeholder
+// appended, if they are odd (or with the message key prepended, see
+// [CommonOpts.BareMessageAsKey]). In strict mode, odd key-values are reported
+// to ErrHandler.
+func (opts *CommonOpts) evenKeyValues(keyValues []any) []any {
+	if len(keyValues)%2 == 0 {
+		return keyValues
+	}
+	if opts.BareMessageAsKey {
+		if _, isString := keyValues[0].(string); isString {
+			keyVals := make([]any, 0, len(keyValues)+1)
+			keyVals = append(keyVals, MessageKey)
+
+			return append(keyVals, keyValues...)
+		}
+	}
+	if opts.StrictKeyValues {
+		opts.ErrHandler(
+			fmt.Errorf("%w, key %v has no value", ErrOddKeyValues, keyValues[len(keyValues)-1]),
+			keyValues,
+		)
+	}
+	placeholder := opts.NoValue
+	if placeholder == "" {
+		placeholder = noValue
+	}
+
+	return append(keyValues, placeholder)
+}
+
+// resolveKeyValues normalizes keys (if configured), resolves [LazyValue] values
+// and formats [time.Time] values, in place.
+// keyValues are the ones passed by the user, to be reported to ErrHandler, if needed.
+func (opts *CommonOpts) resolveKeyValues(keyVals, keyValues []any) {
+	for idx := 0; idx < len(keyVals); idx += 2 {
+		if _, isString := keyVals[idx].(string); !isString {
+			if opts.StrictKeyValues {
+				opts.ErrHandler(
+					fmt.Errorf("%w, key %v is of type %T", ErrNonStringKey, keyVals[idx], keyVals[idx]),
+					keyValues,
+				)
+			}
+			if opts.NormalizeKeys {
+				keyVals[idx] = stringify(keyVals[idx])
+			}
+		}
+
+		if lazyValue, isLazy := keyVals[idx+1].(LazyValue); isLazy {
+			keyVals[idx+1] = lazyValue()
+		}
+		if opts.TimeLayout != "" {
+			if t, ok := keyVals[idx+1].(time.Time); ok {
+				keyVals[idx+1] = t.Format(opts.TimeLayout)
+			}
+		}
+	}
+}
+
+// maxPooledKeyValsCap is the max capacity of a key-values slice to be put
+// back into the pool, so that an unusually large log does not keep memory retained.
+const maxPooledKeyValsCap = 256
+
+// keyValsPool is a pool of key-values slices, used by loggers with pooling enabled
+// (see [SyncLoggerWithPooling] / [AsyncLoggerWithPooling]).
+var keyValsPool = sync.Pool{
+	New: func() any {
+		keyVals := make([]any, 0, 32)
+
+		return &keyVals
+	},
+}
+
+// getKeyVals returns an empty key-values slice from the pool.
+func getKeyVals() *[]any {
+	return keyValsPool.Get().(*[]any)
+}
+
+// putKeyVals puts back given key-values slice into the pool.
+// The slice must not be used anymore after this call.
+func putKeyVals(keyVals *[]any) {
+	if cap(*keyVals) > maxPooledKeyValsCap {
+		return
+	}
+	clear(*keyVals) // do not retain logged values.
+	*keyVals = (*keyVals)[:0]
+	keyValsPool.Put(keyVals)
+}
+
 // FixedLevelProvider provides a fixed Level returned at each call.
 func FixedLevelProvider(lvl Level) LevelProvider {
 	return func() Level { return lvl }
@@ -188,18 +494,249 @@ func EnvLevelProvider(envLvlKey string, defaultLvl Level, levelLabels map[Level]
 	}
 }
 
+// nowFunc holds the function used by time providers to get current time.
+var nowFunc atomic.Pointer[func() time.Time]
+
+// SetNowFunc installs globally the function used by the time providers
+// (and other time based features, like [CachedProvider]) from this package
+// to get the current time.
+// By default, [time.Now] is used. Passing nil restores the default.
+// It is concurrent safe, and it's meant to be used mainly in tests,
+// to freeze the clock for deterministic output, or on platforms where
+// you may want to inject your own clock.
+func SetNowFunc(fn func() time.Time) {
+	if fn == nil {
+		nowFunc.Store(nil)
+
+		return
+	}
+	nowFunc.Store(&fn)
+}
+
+// now returns current time, using the installed clock, if any.
+func now() time.Time {
+	if fn := nowFunc.Load(); fn != nil {
+		return (*fn)()
+	}
+
+	return time.Now()
+}
+
+// StaticProvider is a provider which returns the same, given value, at each call.
+func StaticProvider(value any) Provider {
+	return func() any {
+		return value
+	}
+}
+
+// CachedProvider decorates a provider, memoizing its result.
+// The wrapped provider is evaluated lazily, at first call, and
+// re-evaluated after given ttl expires. A ttl <= 0 means the value
+// never expires, the wrapped provider is called only once.
+// It is useful for expensive / effectively constant values stored in
+// [CommonOpts.AdditionalKeyValues], which otherwise would be recomputed
+// for every log.
+func CachedProvider(provider Provider, ttl time.Duration) Provider {
+	var (
+		value     any
+		evaluated bool
+		expiresAt time.Time
+		mu        sync.RWMutex
+	)
+	isValid := func() bool {
+		return evaluated && (ttl <= 0 || now().Before(expiresAt))
+	}
+
+	return func() any {
+		mu.RLock()
+		if isValid() {
+			defer mu.RUnlock()
+
+			return value
+		}
+		mu.RUnlock()
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !isValid() { // double check, another goroutine may have refreshed it.
+			value = provider()
+			evaluated = true
+			if ttl > 0 {
+				expiresAt = now().Add(ttl)
+			}
+		}
+
+		return value
+	}
+}
+
+// HostnameProvider is a provider which returns host name, as reported by
+// [os.Hostname]. The host name is retrieved only once, and cached.
+// In case of error, "unknown" is returned.
+func HostnameProvider() Provider {
+	return CachedProvider(func() any {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return "unknown"
+		}
+
+		return hostname
+	}, 0)
+}
+
+// PIDProvider is a provider which returns the process id, as int.
+func PIDProvider() Provider {
+	return StaticProvider(os.Getpid())
+}
+
+// GoroutineIDProvider is a provider which returns the id of the goroutine
+// it is called from, as uint64 (0, if it cannot be determined).
+// It is meant for debugging concurrency issues only, as it is expensive:
+// the id is parsed from the goroutine's stack trace ([runtime.Stack]), at each call
+// (Go deliberately does not expose goroutine ids).
+// Providers are called from the goroutine making the log, for both sync and async loggers.
+//
+// Example of usage:
+//
+//	xOpts.AdditionalKeyValues = []any{"goid", xlog.GoroutineIDProvider()}
+func GoroutineIDProvider() Provider {
+	return func() any {
+		return goroutineID()
+	}
+}
+
+// goroutineStackPrefix is the prefix of a goroutine's stack trace.
+var goroutineStackPrefix = []byte("goroutine ")
+
+// goroutineID returns current goroutine's id, parsed from
+// its stack trace ("goroutine 123 [running]:...").
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	idField := bytes.TrimPrefix(buf[:n], goroutineStackPrefix)
+	if idx := bytes.IndexByte(idField, ' '); idx > 0 {
+		idField = idField[:idx]
+	}
+	id, err := strconv.ParseUint(string(idField), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
+
 // UTCTimeProvider is a formatted current UTC time provider.
 func UTCTimeProvider(format string) Provider {
 	return func() any {
-		return time.Now().UTC().Format(format)
+		return now().UTC().Format(format)
 	}
 }
 
 // LocalTimeProvider is a formatted current local time provider.
 func LocalTimeProvider(format string) Provider {
 	return func() any {
-		return time.Now().Format(format)
+		return now().Local().Format(format)
+	}
+}
+
+// TimeProviderInLocation is a formatted current time provider,
+// in the given location.
+// If location is nil, UTC is used.
+func TimeProviderInLocation(format string, loc *time.Location) Provider {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return func() any {
+		return now().In(loc).Format(format)
+	}
+}
+
+// LoadTimeProvider is a formatted current time provider, in the location
+// with the given name (example: "Europe/Bucharest").
+// An error is returned if location cannot be loaded.
+// See also [time.LoadLocation].
+func LoadTimeProvider(format, tzName string) (Provider, error) {
+	loc, err := time.LoadLocation(tzName)
+	if err != nil {
+		return nil, err
+	}
+
+	return TimeProviderInLocation(format, loc), nil
+}
+
+// TimeUnit is the unit in which a Unix timestamp is expressed.
+type TimeUnit byte
+
+const (
+	// TimeUnitSecond expresses a Unix timestamp in seconds.
+	TimeUnitSecond TimeUnit = iota
+	// TimeUnitMillisecond expresses a Unix timestamp in milliseconds.
+	TimeUnitMillisecond
+	// TimeUnitMicrosecond expresses a Unix timestamp in microseconds.
+	TimeUnitMicrosecond
+	// TimeUnitNanosecond expresses a Unix timestamp in nanoseconds.
+	TimeUnitNanosecond
+)
+
+// UnixTimeProvider is a current Unix timestamp provider.
+// The returned value is an int64 expressed in the given unit,
+// so that it gets serialized as a number.
+func UnixTimeProvider(unit TimeUnit) Provider {
+	return func() any {
+		t := now()
+		switch unit {
+		case TimeUnitMillisecond:
+			return t.UnixMilli()
+		case TimeUnitMicrosecond:
+			return t.UnixMicro()
+		case TimeUnitNanosecond:
+			return t.UnixNano()
+		default:
+			return t.Unix()
+		}
+	}
+}
+
+// UnixFloatTimeProvider is a current Unix timestamp provider.
+// The returned value is a float64 representing seconds with
+// fractional part (as GELF expects, for example).
+func UnixFloatTimeProvider() Provider {
+	return func() any {
+		return float64(now().UnixNano()) / float64(time.Second)
+	}
+}
+
+// defaultSourceSkipFrames is the no. of frames the default source provider skips.
+const defaultSourceSkipFrames = 4
+
+// defaultSourceProvider is the default [CommonOpts.Source] provider.
+// It is equivalent to SourceProvider(4, 0).
+func defaultSourceProvider() any {
+	return callerSource(defaultSourceSkipFrames, false)
+}
+
+// isDefaultSourceProvider returns true if given provider is [defaultSourceProvider].
+func isDefaultSourceProvider(provider Provider) bool {
+	return reflect.ValueOf(provider).Pointer() == reflect.ValueOf(defaultSourceProvider).Pointer()
+}
+
+// callerSource returns file and line from call stack.
+// skipFrames semantic is the same as for [runtime.Caller], relative to
+// callerSource's caller.
+// relative flag, if true, makes the file path relative to the main module.
+func callerSource(skipFrames int, relative bool) any {
+	// +1 = skip callerSource itself.
+	pc, file, line, ok := runtime.Caller(skipFrames + 1)
+	if ok {
+		if relative {
+			file = relativeFile(pc, file)
+		}
+
+		return file + ":" + strconv.FormatInt(int64(line), 10)
 	}
+
+	return ""
 }
 
 // SourceProvider is a file and line from call stack
@@ -210,25 +747,196 @@ func SourceProvider(skipFrames, skipPath int) Provider {
 	return func() any {
 		_, file, line, ok := runtime.Caller(skipFrames)
 		if ok {
-			idx := 0
-			if skipPath > 0 {
-				for skipPath, i := skipPath, len(file)-1; i >= 0 && skipPath > 0; i-- {
-					if file[i] == '/' {
-						skipPath--
-						idx = i
-					}
-				}
+			return trimPath(file, skipPath) + ":" + strconv.FormatInt(int64(line), 10)
+		}
+
+		return ""
+	}
+}
+
+// SourceProviderTrimPrefix is a file and line from call stack,
+// with given prefix stripped from file path.
+// First param is the number of frames to skip in the call stack.
+// Second param is the path prefix to strip (usually your project's root,
+// or module path if you build with -trimpath flag).
+// This way you get stable, machine independent paths, like
+// "internal/svc/handler.go:42".
+// If prefix is not found in the file path, only the base file name is returned.
+func SourceProviderTrimPrefix(skipFrames int, prefix string) Provider {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	return func() any {
+		_, file, line, ok := runtime.Caller(skipFrames)
+		if ok {
+			return trimPrefix(file, prefix) + ":" + strconv.FormatInt(int64(line), 10)
+		}
+
+		return ""
+	}
+}
+
+// SourceProviderFromBuildInfo is a [SourceProviderTrimPrefix] with the prefix
+// being the main module path, auto-detected with [debug.ReadBuildInfo].
+// Note: file paths contain the module path if the binary is built
+// with -trimpath flag (or inside GOPATH), otherwise only the base file
+// name gets logged.
+func SourceProviderFromBuildInfo(skipFrames int) Provider {
+	return SourceProviderTrimPrefix(skipFrames, mainModulePath())
+}
+
+// relativeFile returns given file path (of the function with given pc)
+// relative to the main module.
+// The main module's root directory is the function's file directory,
+// without the function's package path relative to the main module
+// (example: "/home/john/app/internal/svc/handler.go", of package
+// "example.com/app/internal/svc", from "example.com/app" module, is
+// "internal/svc/handler.go"), so that it works regardless of -trimpath flag.
+// If the function does not belong to the main module, see [trimPrefix].
+func relativeFile(pc uintptr, file string) string {
+	modPath := cachedMainModulePath()
+	if modPath == "" {
+		return path.Base(file)
+	}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		pkgPath := funcPackagePath(fn.Name())
+		if pkgPath == "main" {
+			pkgPath = cachedMainPackagePath()
+		}
+		if pkgDir, found := strings.CutPrefix(pkgPath, modPath); found && (pkgDir == "" || pkgDir[0] == '/') {
+			if root, found := strings.CutSuffix(path.Dir(file), pkgDir); found {
+				return strings.TrimPrefix(file[len(root):], "/")
 			}
+		}
+	}
+
+	return trimPrefix(file, modPath)
+}
+
+// funcPackagePath returns the package path of the function with given
+// (fully qualified) name, like "example.com/app/internal/svc.(*Handler).Save".
+// The "_test" suffix of external test packages is dropped.
+func funcPackagePath(funcName string) string {
+	lastSlashIdx := strings.LastIndexByte(funcName, '/')
+	if dotIdx := strings.IndexByte(funcName[lastSlashIdx+1:], '.'); dotIdx >= 0 {
+		funcName = funcName[:lastSlashIdx+1+dotIdx]
+	}
+
+	return strings.TrimSuffix(funcName, "_test")
+}
+
+// cachedMainModulePath returns main module path, read only once from build info.
+var cachedMainModulePath = sync.OnceValue(mainModulePath)
+
+// cachedMainPackagePath returns main package path, read only once from build info.
+var cachedMainPackagePath = sync.OnceValue(func() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi.Path
+	}
+
+	return ""
+})
+
+// mainModulePath returns main module path, read from build info.
+func mainModulePath() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi.Main.Path
+	}
+
+	return ""
+}
 
-			return file[idx:] + ":" + strconv.FormatInt(int64(line), 10)
+// trimPrefix returns file path after given prefix.
+// If prefix is not found, the base file name is returned.
+func trimPrefix(file, prefix string) string {
+	if prefix != "" {
+		if idx := strings.LastIndex(file, prefix+"/"); idx >= 0 {
+			return file[idx+len(prefix)+1:]
+		}
+	}
+
+	return path.Base(file)
+}
+
+// SourceProviderWithFunc is a function name, file and line from call stack.
+// Output looks like "pkg.Func (/file.go:42)".
+// First param is the number of frames to skip in the call stack.
+// Second param is number of directories to skip from file name
+// backwards to root dir (0 means full path is returned).
+// See also [FuncProvider] if you want to log the function name under
+// a separate key.
+func SourceProviderWithFunc(skipFrames, skipPath int) Provider {
+	return func() any {
+		frame, ok := callerFrame(skipFrames)
+		if ok {
+			return trimFuncName(frame.Function) + " (" +
+				trimPath(frame.File, skipPath) + ":" + strconv.FormatInt(int64(frame.Line), 10) + ")"
+		}
+
+		return ""
+	}
+}
+
+// FuncProvider is a function name from call stack.
+// Output looks like "pkg.Func".
+// The param is the number of frames to skip in the call stack.
+// It can be used as a value in [CommonOpts.AdditionalKeyValues] (with the
+// same skipFrames as [CommonOpts.Source]) to log the function name under
+// its own key.
+func FuncProvider(skipFrames int) Provider {
+	return func() any {
+		frame, ok := callerFrame(skipFrames)
+		if ok {
+			return trimFuncName(frame.Function)
 		}
 
 		return ""
 	}
 }
 
+// callerFrame returns the frame from call stack, skipping given no. of frames.
+// skipFrames semantic is the same as for [runtime.Caller], relative to
+// callerFrame's caller.
+func callerFrame(skipFrames int) (runtime.Frame, bool) {
+	var pcs [1]uintptr
+	// +2 = skip runtime.Callers and callerFrame itself.
+	if runtime.Callers(skipFrames+2, pcs[:]) < 1 {
+		return runtime.Frame{}, false
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+
+	return frame, frame.File != ""
+}
+
+// trimPath returns file path skipping given no. of directories
+// backwards to root dir (0 means full path is returned).
+func trimPath(file string, skipPath int) string {
+	idx := 0
+	if skipPath > 0 {
+		for skipPath, i := skipPath, len(file)-1; i >= 0 && skipPath > 0; i-- {
+			if file[i] == '/' {
+				skipPath--
+				idx = i
+			}
+		}
+	}
+
+	return file[idx:]
+}
+
+// trimFuncName removes package path from a fully qualified function name.
+// Example: "github.com/actforgood/xlog.Foo" => "xlog.Foo".
+func trimFuncName(function string) string {
+	if idx := strings.LastIndexByte(function, '/'); idx >= 0 {
+		return function[idx+1:]
+	}
+
+	return function
+}
+
 // AppendNoValue is a safety function which adds a "*NoValue*"
 // at the end of keyValues slice in case it is odd.
+// Note: loggers add the [CommonOpts.NoValue] placeholder before
+// formatting, so a customized placeholder takes precedence.
 func AppendNoValue(keyValues []any) []any {
 	if len(keyValues)%2 == 1 {
 		keyValues = append(keyValues, noValue)
@@ -248,6 +956,97 @@ func StackErr(err error) string {
 	return fmt.Sprintf("%+v", err)
 }
 
+// default max no. of frames a stack trace captured by a logger contains.
+const defaultStackTraceMaxDepth = 32
+
+// xlogFuncPrefix is the prefix of this package's functions' names
+// (like "github.com/actforgood/xlog.").
+var xlogFuncPrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	lastSlashIdx := strings.LastIndexByte(name, '/')
+
+	return name[:lastSlashIdx+strings.IndexByte(name[lastSlashIdx:], '.')+1]
+}()
+
+// stackTrace returns current goroutine's stack trace, with at most
+// maxDepth frames, one per line, in the "pkg.Func (/path/to/file.go:42)" format.
+// Leading frames belonging to this package are skipped.
+func stackTrace(maxDepth int) string {
+	if maxDepth <= 0 {
+		maxDepth = defaultStackTraceMaxDepth
+	}
+	// a few more frames are captured, for the ones of this package to be skipped.
+	const xlogFramesNo = 16
+	pcs := make([]uintptr, maxDepth+xlogFramesNo)
+	n := runtime.Callers(2, pcs) // skip runtime.Callers and stackTrace itself.
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var (
+		sb      strings.Builder
+		depth   int
+		inXlog  = true
+		frame   runtime.Frame
+		hasMore = n > 0
+	)
+	for hasMore && depth < maxDepth {
+		frame, hasMore = frames.Next()
+		if inXlog && strings.HasPrefix(frame.Function, xlogFuncPrefix) {
+			continue
+		}
+		inXlog = false
+		if depth > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(frame.Function)
+		sb.WriteString(" (")
+		sb.WriteString(frame.File)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(frame.Line))
+		sb.WriteByte(')')
+		depth++
+	}
+
+	return sb.String()
+}
+
+// appendStackTrace appends the stack trace to given key-values,
+// if given level is at or above the configured min level.
+// A nil min level means the feature is disabled.
+func appendStackTrace(keyVals []any, minLvl *Level, maxDepth int, lvl Level) []any {
+	if minLvl == nil || lvl < *minLvl {
+		return keyVals
+	}
+
+	return append(keyVals, StackKey, stackTrace(maxDepth))
+}
+
+// appendSequence appends, under given key, the next value of given
+// sequence to key-values. An empty key means the feature is disabled.
+func appendSequence(keyVals []any, key string, seq *atomic.Uint64) []any {
+	if key == "" {
+		return keyVals
+	}
+
+	return append(keyVals, key, seq.Add(1))
+}
+
+// levelsByValue returns a map of level labels, and of the values logged for levels
+// (see [CommonOpts.LevelFormatter]), stringified, to their levels.
+// This way formatters can map a logged level value back to its level,
+// whether it is a label or, for example, a numeric level.
+func levelsByValue(opts *CommonOpts) map[string]Level {
+	levels := make(map[string]Level, 2*len(opts.LevelLabels))
+	for lvl, label := range opts.LevelLabels {
+		levels[label] = lvl
+		if opts.LevelFormatter != nil {
+			levels[stringify(opts.LevelFormatter(lvl))] = lvl
+		}
+	}
+
+	return levels
+}
+
 // flipLevelLabels flips level labels map.
 func flipLevelLabels(levelLabels map[Level]string) map[string]Level {
 	flippedLevelLabels := make(map[string]Level, len(levelLabels))
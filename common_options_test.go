@@ -6,17 +6,27 @@
 package xlog_test
 
 import (
+	"bytes"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/actforgood/xlog"
+	"github.com/actforgood/xlog/internal/sourcetest"
 )
 
 // timeBuffer is a buffer to take around a Time call checks.
@@ -47,6 +57,7 @@ func TestNewCommonOpts(t *testing.T) {
 		}
 
 		assertEqual(t, "lvl", subject.LevelKey)
+		assertNil(t, subject.LevelFormatter)
 	})
 
 	t.Run("default time options", func(t *testing.T) {
@@ -59,6 +70,7 @@ func TestNewCommonOpts(t *testing.T) {
 			after := time.Now().UTC().Add(timeBuffer)
 			checkTime(t, result, before, after, time.RFC3339Nano)
 		}
+		assertEqual(t, time.RFC3339Nano, subject.TimeLayout)
 	})
 
 	t.Run("default source options", func(t *testing.T) {
@@ -81,6 +93,77 @@ func TestNewCommonOpts(t *testing.T) {
 		t.Parallel()
 		assertNotNil(t, subject.ErrHandler)
 	})
+
+	t.Run("default key-values validation options", func(t *testing.T) {
+		t.Parallel()
+		assertEqual(t, "*NoValue*", subject.NoValue)
+		assertFalse(t, subject.StrictKeyValues)
+		assertFalse(t, subject.BareMessageAsKey)
+		assertFalse(t, subject.NormalizeKeys)
+	})
+}
+
+func TestCommonOpts_Clone(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.NewCommonOpts()
+	subject.AdditionalKeyValues = []any{"app", "demo"}
+
+	// act
+	result := subject.Clone()
+	result.AdditionalKeyValues[1] = "component"
+	result.AdditionalKeyValues = append(result.AdditionalKeyValues, "component", "db")
+	result.LevelLabels[xlog.LevelWarning] = "WARNING"
+	result.LevelKey = "level"
+
+	// assert
+	assertEqual(t, []any{"app", "demo"}, subject.AdditionalKeyValues)
+	assertEqual(t, "WARN", subject.LevelLabels[xlog.LevelWarning])
+	assertEqual(t, "lvl", subject.LevelKey)
+	assertEqual(t, []any{"app", "component", "component", "db"}, result.AdditionalKeyValues)
+	assertEqual(t, "WARNING", result.LevelLabels[xlog.LevelWarning])
+	assertEqual(t, "CRITICAL", result.LevelLabels[xlog.LevelCritical])
+	assertEqual(t, subject.TimeKey, result.TimeKey)
+	assertEqual(t, subject.MinLevel(), result.MinLevel())
+}
+
+func TestCommonOpts_Clone_nilFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := &xlog.CommonOpts{LevelKey: "lvl"}
+
+	// act
+	result := subject.Clone()
+
+	// assert
+	assertTrue(t, result.LevelLabels == nil)
+	assertTrue(t, result.AdditionalKeyValues == nil)
+	assertEqual(t, "lvl", result.LevelKey)
+	assertTrue(t, subject != result)
+}
+
+func TestDefaultLevelLabels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	expected := map[xlog.Level]string{
+		xlog.LevelCritical: "CRITICAL",
+		xlog.LevelError:    "ERROR",
+		xlog.LevelWarning:  "WARN",
+		xlog.LevelInfo:     "INFO",
+		xlog.LevelDebug:    "DEBUG",
+	}
+
+	// act
+	result := xlog.DefaultLevelLabels()
+	result[xlog.LevelWarning] = "WARNING"
+	delete(result, xlog.LevelDebug)
+
+	// assert
+	assertEqual(t, expected, xlog.DefaultLevelLabels())
+	assertEqual(t, expected, xlog.NewCommonOpts().LevelLabels)
 }
 
 func TestCommonOpts_BetweenMinMax(t *testing.T) {
@@ -427,6 +510,59 @@ func TestCommonOpts_WithDefaultKeyValues(t *testing.T) {
 	t.Run("no source (value)", testCommonOptsDefaultKeyValuesNoSourceValue)
 	t.Run("level", testCommonOptsDefaultKeyValuesLevel)
 	t.Run("default with custom", testCommonOptsDefaultKeyValuesWithCustom)
+	t.Run("source from custom logger", testCommonOptsDefaultKeyValuesSourceFromCustomLogger)
+	t.Run("explicit source provider from custom logger", testCommonOptsDefaultKeyValuesExplicitSourceFromCustomLogger)
+}
+
+// customLogger mimics a logger built upon [xlog.CommonOpts.WithDefaultKeyValues],
+// having the same call stack depth as the built-in ones.
+type customLogger struct {
+	opts *xlog.CommonOpts
+}
+
+func (logger customLogger) Error(keyValues ...any) []any {
+	return logger.log(xlog.LevelError, keyValues...)
+}
+
+func (logger customLogger) log(lvl xlog.Level, keyValues ...any) []any {
+	return logger.opts.WithDefaultKeyValues(lvl, keyValues...)
+}
+
+func testCommonOptsDefaultKeyValuesSourceFromCustomLogger(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := customLogger{opts: xlog.NewCommonOpts()}
+
+	// act
+	_, _, line, _ := runtime.Caller(0)
+	result := subject.Error()
+
+	// assert
+	if !assertEqual(t, 6, len(result)) {
+		t.FailNow()
+	}
+	assertEqual(t, "src", result[4])
+	assertTrue(t, strings.HasSuffix(result[5].(string), "common_options_test.go:"+strconv.Itoa(line+1)))
+}
+
+func testCommonOptsDefaultKeyValuesExplicitSourceFromCustomLogger(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := customLogger{opts: xlog.NewCommonOpts()}
+	subject.opts.Source = xlog.SourceProvider(4, 1) // the documented default equivalent.
+
+	// act
+	_, _, line, _ := runtime.Caller(0)
+	result := subject.Error()
+
+	// assert
+	if !assertEqual(t, 6, len(result)) {
+		t.FailNow()
+	}
+	assertEqual(t, "src", result[4])
+	assertEqual(t, "/common_options_test.go:"+strconv.Itoa(line+1), result[5])
 }
 
 func testCommonOptsDefaultKeyValuesTimeLevelSource(t *testing.T) {
@@ -656,6 +792,370 @@ func testCommonOptsDefaultKeyValuesWithCustom(t *testing.T) {
 	assertEqual(t, 1, result[9])
 }
 
+func TestCommonOpts_WithDefaultKeyValues_formatsTimeValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject   = xlog.NewCommonOpts()
+		someTime  = time.Date(2022, 3, 14, 16, 1, 20, 0, time.UTC)
+		keyValues = []any{"t", someTime, "d", 1500 * time.Millisecond}
+	)
+	subject.Time = staticTimeProvider
+	subject.SourceKey = ""
+
+	// act
+	result := subject.WithDefaultKeyValues(xlog.LevelNone, keyValues...)
+
+	// assert
+	assertEqual(t, []any{"date", staticTime, "t", "2022-03-14T16:01:20Z", "d", 1500 * time.Millisecond}, result)
+	assertEqual(t, someTime, keyValues[1]) // passed key-values are not modified.
+
+	// arrange - custom layout.
+	subject.TimeLayout = time.Kitchen
+
+	// act
+	result = subject.WithDefaultKeyValues(xlog.LevelNone, keyValues...)
+
+	// assert
+	assertEqual(t, "4:01PM", result[3])
+
+	// arrange - disable time layout.
+	subject.TimeLayout = ""
+
+	// act
+	result = subject.WithDefaultKeyValues(xlog.LevelNone, keyValues...)
+
+	// assert
+	assertEqual(t, someTime, result[3])
+}
+
+func TestCommonOpts_WithDefaultKeyValues_resolvesLazyValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject   = xlog.NewCommonOpts()
+		lazyCalls = 0
+		lazyValue = xlog.Lazy(func() any {
+			lazyCalls++
+
+			return "expensive"
+		})
+		lazyTime = xlog.Lazy(func() any {
+			return time.Date(2022, 3, 14, 16, 1, 20, 0, time.UTC)
+		})
+		keyValues = []any{"payload", lazyValue, "t", lazyTime}
+	)
+	subject.Time = staticTimeProvider
+	subject.SourceKey = ""
+	subject.AdditionalKeyValues = []any{"app", xlog.Lazy(func() any { return "demo" })}
+
+	// act
+	result := subject.WithDefaultKeyValues(xlog.LevelNone, keyValues...)
+
+	// assert
+	assertEqual(
+		t,
+		[]any{"date", staticTime, "app", "demo", "payload", "expensive", "t", "2022-03-14T16:01:20Z"},
+		result,
+	)
+	assertEqual(t, 1, lazyCalls)
+	_, isLazy := keyValues[1].(xlog.LazyValue)
+	assertTrue(t, isLazy) // passed key-values are not modified.
+}
+
+func TestCommonOpts_WithDefaultKeyValues_levelFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("numeric levels", testCommonOptsWithDefaultKeyValuesNumericLevels)
+	t.Run("custom level formatter", testCommonOptsWithDefaultKeyValuesCustomLevelFormatter)
+}
+
+func testCommonOptsWithDefaultKeyValuesNumericLevels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.NewCommonOpts()
+	subject.LevelFormatter = xlog.NumericLevelFormatter
+	subject.Time = staticTimeProvider
+	subject.SourceKey = ""
+	tests := [...]struct {
+		name     string
+		input    xlog.Level
+		expected string
+	}{
+		{
+			name:     "LevelKey is not present for None",
+			input:    xlog.LevelNone,
+			expected: `{"date":"` + staticTime + `","msg":"foo"}`,
+		},
+		{
+			name:     "numeric Debug",
+			input:    xlog.LevelDebug,
+			expected: `{"date":"` + staticTime + `","lvl":10,"msg":"foo"}`,
+		},
+		{
+			name:     "numeric Info",
+			input:    xlog.LevelInfo,
+			expected: `{"date":"` + staticTime + `","lvl":20,"msg":"foo"}`,
+		},
+		{
+			name:     "numeric Warning",
+			input:    xlog.LevelWarning,
+			expected: `{"date":"` + staticTime + `","lvl":30,"msg":"foo"}`,
+		},
+		{
+			name:     "numeric Error",
+			input:    xlog.LevelError,
+			expected: `{"date":"` + staticTime + `","lvl":40,"msg":"foo"}`,
+		},
+		{
+			name:     "numeric Critical",
+			input:    xlog.LevelCritical,
+			expected: `{"date":"` + staticTime + `","lvl":50,"msg":"foo"}`,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var buf bytes.Buffer
+
+			// act
+			keyVals := subject.WithDefaultKeyValues(test.input, xlog.MessageKey, "foo")
+			err := xlog.JSONFormatter(&buf, keyVals)
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, test.expected+"\n", buf.String())
+		})
+	}
+}
+
+func testCommonOptsWithDefaultKeyValuesCustomLevelFormatter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.NewCommonOpts()
+	subject.Time = staticTimeProvider
+	subject.SourceKey = ""
+	subject.LevelFormatter = func(lvl xlog.Level) any {
+		if lvl >= xlog.LevelError {
+			return 3
+		}
+
+		return 6
+	}
+
+	// act
+	resultErr := subject.WithDefaultKeyValues(xlog.LevelError, "foo", "bar")
+	resultInfo := subject.WithDefaultKeyValues(xlog.LevelInfo, "foo", "bar")
+
+	// assert
+	assertEqual(t, []any{"date", staticTime, "lvl", 3, "foo", "bar"}, resultErr)
+	assertEqual(t, []any{"date", staticTime, "lvl", 6, "foo", "bar"}, resultInfo)
+}
+
+func TestCommonOpts_WithDefaultKeyValues_oddKeyValues(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default placeholder", testCommonOptsWithDefaultKeyValuesOddDefaultPlaceholder)
+	t.Run("custom placeholder", testCommonOptsWithDefaultKeyValuesOddCustomPlaceholder)
+	t.Run("strict mode", testCommonOptsWithDefaultKeyValuesOddStrict)
+	t.Run("bare message", testCommonOptsWithDefaultKeyValuesOddBareMessage)
+}
+
+func testCommonOptsWithDefaultKeyValuesOddDefaultPlaceholder(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		errHandler = new(MockErrorHandler)
+		subject    = xlog.NewCommonOpts()
+	)
+	subject.Time = staticTimeProvider
+	subject.SourceKey = ""
+	subject.ErrHandler = errHandler.Handle
+
+	// act
+	result := subject.WithDefaultKeyValues(xlog.LevelNone, "foo", "bar", "odd")
+
+	// assert
+	assertEqual(t, []any{"date", staticTime, "foo", "bar", "odd", "*NoValue*"}, result)
+	assertEqual(t, 0, errHandler.HandleCallsCount())
+}
+
+func testCommonOptsWithDefaultKeyValuesOddCustomPlaceholder(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.NewCommonOpts()
+	subject.Time = staticTimeProvider
+	subject.SourceKey = ""
+	subject.NoValue = "<missing>"
+
+	// act
+	result := subject.WithDefaultKeyValues(xlog.LevelNone, "foo", "bar", "odd")
+
+	// assert
+	assertEqual(t, []any{"date", staticTime, "foo", "bar", "odd", "<missing>"}, result)
+}
+
+func testCommonOptsWithDefaultKeyValuesOddStrict(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		errHandler = new(MockErrorHandler)
+		subject    = xlog.NewCommonOpts()
+	)
+	subject.Time = staticTimeProvider
+	subject.SourceKey = ""
+	subject.StrictKeyValues = true
+	subject.ErrHandler = errHandler.Handle
+	errHandler.SetHandleCallback(func(err error, keyValues []any) {
+		assertTrue(t, errors.Is(err, xlog.ErrOddKeyValues))
+		assertTrue(t, strings.Contains(err.Error(), "key odd has no value"))
+		assertEqual(t, []any{"foo", "bar", "odd"}, keyValues)
+	})
+
+	// act
+	result1 := subject.WithDefaultKeyValues(xlog.LevelNone, "foo", "bar", "odd")
+	result2 := subject.WithDefaultKeyValues(xlog.LevelNone, "foo", "bar")
+
+	// assert
+	assertEqual(t, []any{"date", staticTime, "foo", "bar", "odd", "*NoValue*"}, result1)
+	assertEqual(t, []any{"date", staticTime, "foo", "bar"}, result2)
+	assertEqual(t, 1, errHandler.HandleCallsCount())
+}
+
+func testCommonOptsWithDefaultKeyValuesOddBareMessage(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf        bytes.Buffer
+		errHandler = new(MockErrorHandler)
+		subject    = xlog.NewCommonOpts()
+		logger     = xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(subject))
+	)
+	subject.Time = staticTimeProvider
+	subject.SourceKey = ""
+	subject.MinLevel = xlog.FixedLevelProvider(xlog.LevelDebug)
+	subject.StrictKeyValues = true
+	subject.ErrHandler = errHandler.Handle
+
+	// act & assert
+	subject.BareMessageAsKey = false
+	logger.Info("hi")
+	assertEqual(t, `{"date":"`+staticTime+`","hi":"*NoValue*","lvl":"INFO"}`+"\n", buf.String())
+	assertEqual(t, 1, errHandler.HandleCallsCount())
+
+	buf.Reset()
+	subject.BareMessageAsKey = true
+	logger.Info("hi")
+	assertEqual(t, `{"date":"`+staticTime+`","lvl":"INFO","msg":"hi"}`+"\n", buf.String())
+
+	result := subject.WithDefaultKeyValues(xlog.LevelNone, "user logged in", "userId", 123)
+	assertEqual(t, []any{"date", staticTime, xlog.MessageKey, "user logged in", "userId", 123}, result)
+
+	result = subject.WithDefaultKeyValues(xlog.LevelNone, 10, "ten", "odd")
+	assertEqual(t, []any{"date", staticTime, 10, "ten", "odd", "*NoValue*"}, result) // not a leading string.
+	assertEqual(t, 3, errHandler.HandleCallsCount())                                 // odd key-values, non-string key.
+}
+
+func TestCommonOpts_WithDefaultKeyValues_normalizesKeys(t *testing.T) {
+	t.Parallel()
+
+	commOpts := xlog.NewCommonOpts()
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	commOpts.NormalizeKeys = true
+	tests := [...]struct {
+		name           string
+		formatter      xlog.Formatter
+		expectedOutput string
+	}{
+		{
+			name:           "json",
+			formatter:      xlog.JSONFormatter,
+			expectedOutput: `{"10":"ten","date":"` + staticTime + `","lvl":"ERROR"}` + "\n",
+		},
+		{
+			name:           "logfmt",
+			formatter:      xlog.LogfmtFormatter,
+			expectedOutput: "date=" + staticTime + " lvl=ERROR 10=ten\n",
+		},
+		{
+			name:           "text",
+			formatter:      xlog.TextFormatter(commOpts),
+			expectedOutput: staticTime + " ERROR 10=ten\n",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				buf     bytes.Buffer
+				subject = xlog.NewSyncLogger(
+					&buf,
+					xlog.SyncLoggerWithOptions(commOpts),
+					xlog.SyncLoggerWithFormatter(test.formatter),
+				)
+			)
+
+			// act
+			subject.Error(10, "ten")
+
+			// assert
+			assertEqual(t, test.expectedOutput, buf.String())
+		})
+	}
+}
+
+func TestCommonOpts_WithDefaultKeyValues_nonStringKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		errHandler = new(MockErrorHandler)
+		subject    = xlog.NewCommonOpts()
+	)
+	subject.Time = staticTimeProvider
+	subject.SourceKey = ""
+	subject.ErrHandler = errHandler.Handle
+
+	// act
+	result := subject.WithDefaultKeyValues(xlog.LevelNone, 10, "ten")
+
+	// assert
+	assertEqual(t, []any{"date", staticTime, 10, "ten"}, result) // not normalized by default.
+	assertEqual(t, 0, errHandler.HandleCallsCount())
+
+	// arrange - strict mode.
+	subject.StrictKeyValues = true
+	errHandler.SetHandleCallback(func(err error, keyValues []any) {
+		assertTrue(t, errors.Is(err, xlog.ErrNonStringKey))
+		assertTrue(t, strings.Contains(err.Error(), "key 10 is of type int"))
+		assertEqual(t, []any{10, "ten"}, keyValues)
+	})
+
+	// act
+	result = subject.WithDefaultKeyValues(xlog.LevelNone, 10, "ten")
+
+	// assert
+	assertEqual(t, []any{"date", staticTime, 10, "ten"}, result)
+	assertEqual(t, 1, errHandler.HandleCallsCount())
+}
+
 func TestFixedLevelProvider(t *testing.T) {
 	t.Parallel()
 
@@ -784,11 +1284,177 @@ func testEnvLevelProviderWithNotFoundEnv(t *testing.T) {
 	assertEqual(t, defaultLvl, result)
 }
 
-func TestUTCTimeProvider(t *testing.T) {
+func TestStaticProvider(t *testing.T) {
 	t.Parallel()
 
 	// arrange
-	var (
+	subject := xlog.StaticProvider("some-value")
+
+	// act
+	result1 := subject()
+	result2 := subject()
+
+	// assert
+	assertEqual(t, "some-value", result1)
+	assertEqual(t, "some-value", result2)
+}
+
+func TestCachedProvider(t *testing.T) {
+	// Note: do not run in parallel as it changes the global clock.
+
+	// arrange
+	var (
+		callsCnt int
+		provider = xlog.Provider(func() any {
+			callsCnt++
+
+			return callsCnt
+		})
+		currentTime = time.Date(2022, time.March, 14, 16, 1, 20, 0, time.UTC)
+		ttl         = time.Minute
+		subject     = xlog.CachedProvider(provider, ttl)
+	)
+	xlog.SetNowFunc(func() time.Time {
+		return currentTime
+	})
+	defer xlog.SetNowFunc(nil)
+
+	// act & assert
+	assertEqual(t, 0, callsCnt) // lazily evaluated.
+	assertEqual(t, 1, subject())
+	assertEqual(t, 1, subject())
+
+	currentTime = currentTime.Add(ttl - time.Nanosecond)
+	assertEqual(t, 1, subject())
+
+	currentTime = currentTime.Add(time.Nanosecond) // ttl expired.
+	assertEqual(t, 2, subject())
+	assertEqual(t, 2, subject())
+	assertEqual(t, 2, callsCnt)
+}
+
+func TestCachedProvider_neverExpires(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		callsCnt uint32
+		provider = xlog.Provider(func() any {
+			return atomic.AddUint32(&callsCnt, 1)
+		})
+		subject = xlog.CachedProvider(provider, 0)
+		wg      sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assertEqual(t, uint32(1), subject())
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, uint32(1), atomic.LoadUint32(&callsCnt))
+}
+
+func TestHostnameProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject           = xlog.HostnameProvider()
+		expected, hostErr = os.Hostname()
+	)
+	if hostErr != nil {
+		expected = "unknown"
+	}
+
+	// act
+	result1 := subject()
+	result2 := subject()
+
+	// assert
+	assertEqual(t, expected, result1)
+	assertEqual(t, expected, result2)
+}
+
+func TestPIDProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.PIDProvider()
+
+	// act
+	result := subject()
+
+	// assert
+	assertEqual(t, os.Getpid(), result)
+}
+
+func TestGoroutineIDProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		logger   = xlog.NewSyncLogger(xlog.NewSyncWriter(&buf), xlog.SyncLoggerWithOptions(commOpts))
+		wg       sync.WaitGroup
+		subject  = xlog.GoroutineIDProvider()
+	)
+	commOpts.SourceKey = ""
+	commOpts.AdditionalKeyValues = []any{"goid", subject}
+
+	// act
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Error(xlog.MessageKey, "from goroutine")
+		}()
+	}
+	wg.Wait()
+	result1 := subject()
+	result2 := subject()
+
+	// assert
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if assertEqual(t, 2, len(lines)) {
+		var doc1, doc2 map[string]any
+		if err := json.Unmarshal([]byte(lines[0]), &doc1); err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := json.Unmarshal([]byte(lines[1]), &doc2); err != nil {
+			t.Fatal(err.Error())
+		}
+		assertTrue(t, doc1["goid"].(float64) > 0)
+		assertTrue(t, doc2["goid"].(float64) > 0)
+		assertTrue(t, doc1["goid"] != doc2["goid"])
+		assertTrue(t, float64(result1.(uint64)) != doc1["goid"])
+	}
+	assertTrue(t, result1.(uint64) > 0)
+	assertEqual(t, result1, result2) // same goroutine.
+}
+
+func BenchmarkGoroutineIDProvider(b *testing.B) {
+	subject := xlog.GoroutineIDProvider()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_ = subject()
+	}
+}
+
+func TestUTCTimeProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
 		subject = xlog.LocalTimeProvider
 		format  = "2006-01-02"
 	)
@@ -824,6 +1490,185 @@ func TestLocalTimeProvider(t *testing.T) {
 	checkTime(t, result, before, after, format)
 }
 
+func TestSetNowFunc(t *testing.T) {
+	// Note: do not run in parallel as it changes the global clock.
+
+	// arrange
+	var (
+		format     = time.RFC3339Nano
+		frozenTime = time.Date(2022, time.March, 14, 16, 1, 20, 123, time.UTC)
+		utcTime    = xlog.UTCTimeProvider(format)
+		localTime  = xlog.LocalTimeProvider(format)
+	)
+	xlog.SetNowFunc(func() time.Time {
+		return frozenTime
+	})
+	defer xlog.SetNowFunc(nil)
+
+	// act
+	utcResult1, utcResult2 := utcTime(), utcTime()
+	localResult := localTime()
+
+	// assert
+	assertEqual(t, "2022-03-14T16:01:20.000000123Z", utcResult1)
+	assertEqual(t, utcResult1, utcResult2)
+	assertEqual(t, frozenTime.Local().Format(format), localResult)
+
+	// restore default clock and see current time is returned.
+	xlog.SetNowFunc(nil)
+	before := time.Now().UTC().Add(-1 * timeBuffer)
+	utcResult3 := utcTime()
+	after := time.Now().UTC().Add(timeBuffer)
+	checkTime(t, utcResult3, before, after, format)
+}
+
+func TestTimeProviderInLocation(t *testing.T) {
+	// Note: do not run in parallel as it changes the global clock.
+
+	// arrange
+	var (
+		format      = time.RFC3339
+		knownTime   = time.Date(2022, time.July, 1, 12, 0, 0, 0, time.UTC)
+		bucharest   = time.FixedZone("EEST", 3*60*60)
+		newYork     = time.FixedZone("EDT", -4*60*60)
+		subjectBuc  = xlog.TimeProviderInLocation(format, bucharest)
+		subjectNY   = xlog.TimeProviderInLocation(format, newYork)
+		subjectNil  = xlog.TimeProviderInLocation(format, nil)
+		expectedBuc = "2022-07-01T15:00:00+03:00"
+		expectedNY  = "2022-07-01T08:00:00-04:00"
+		expectedNil = "2022-07-01T12:00:00Z"
+	)
+	xlog.SetNowFunc(func() time.Time {
+		return knownTime
+	})
+	defer xlog.SetNowFunc(nil)
+
+	// act
+	resultBuc := subjectBuc()
+	resultNY := subjectNY()
+	resultNil := subjectNil()
+
+	// assert
+	assertEqual(t, expectedBuc, resultBuc)
+	assertEqual(t, expectedNY, resultNY)
+	assertEqual(t, expectedNil, resultNil)
+}
+
+func TestLoadTimeProvider(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid location", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		subject, err := xlog.LoadTimeProvider(time.RFC3339, "UTC")
+
+		// assert
+		if assertNil(t, err) && assertNotNil(t, subject) {
+			before := time.Now().UTC().Add(-1 * time.Second)
+			result := subject()
+			after := time.Now().UTC().Add(time.Second)
+			checkTime(t, result, before, after, time.RFC3339)
+		}
+	})
+
+	t.Run("invalid location", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		subject, err := xlog.LoadTimeProvider(time.RFC3339, "Invalid/Location")
+
+		// assert
+		assertNotNil(t, err)
+		assertNil(t, subject)
+	})
+}
+
+func TestUnixTimeProvider(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name      string
+		unit      xlog.TimeUnit
+		toUnit    func(time.Time) int64
+		tolerance int64
+	}{
+		{
+			name:      "seconds",
+			unit:      xlog.TimeUnitSecond,
+			toUnit:    time.Time.Unix,
+			tolerance: 1,
+		},
+		{
+			name:      "milliseconds",
+			unit:      xlog.TimeUnitMillisecond,
+			toUnit:    time.Time.UnixMilli,
+			tolerance: timeBuffer.Milliseconds(),
+		},
+		{
+			name:      "microseconds",
+			unit:      xlog.TimeUnitMicrosecond,
+			toUnit:    time.Time.UnixMicro,
+			tolerance: timeBuffer.Microseconds(),
+		},
+		{
+			name:      "nanoseconds",
+			unit:      xlog.TimeUnitNanosecond,
+			toUnit:    time.Time.UnixNano,
+			tolerance: timeBuffer.Nanoseconds(),
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := xlog.UnixTimeProvider(test.unit)
+
+			// act
+			before := test.toUnit(time.Now()) - test.tolerance
+			result := subject()
+			after := test.toUnit(time.Now()) + test.tolerance
+
+			// assert
+			resultInt, isInt64 := result.(int64)
+			if assertTrue(t, isInt64) {
+				assertTrue(t, resultInt >= before)
+				assertTrue(t, resultInt <= after)
+			}
+			jsonResult, err := json.Marshal(result)
+			if assertNil(t, err) {
+				assertEqual(t, strconv.FormatInt(resultInt, 10), string(jsonResult))
+			}
+		})
+	}
+}
+
+func TestUnixFloatTimeProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.UnixFloatTimeProvider()
+
+	// act
+	before := float64(time.Now().Add(-1*timeBuffer).UnixNano()) / float64(time.Second)
+	result := subject()
+	after := float64(time.Now().Add(timeBuffer).UnixNano()) / float64(time.Second)
+
+	// assert
+	resultFloat, isFloat64 := result.(float64)
+	if assertTrue(t, isFloat64) {
+		assertTrue(t, resultFloat >= before)
+		assertTrue(t, resultFloat <= after)
+	}
+	jsonResult, err := json.Marshal(result)
+	if assertNil(t, err) {
+		assertFalse(t, strings.Contains(string(jsonResult), `"`))
+	}
+}
+
 func TestSourceProvider(t *testing.T) {
 	t.Parallel()
 
@@ -862,6 +1707,258 @@ func TestSourceProvider(t *testing.T) {
 	}
 }
 
+func TestCommonOpts_SourceSkipExtra(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name               string
+		sourceSkipExtra    int
+		expectedSourceFile string
+	}{
+		{
+			name:               "without extra skip, source is the wrapper",
+			sourceSkipExtra:    0,
+			expectedSourceFile: "/common_test.go:",
+		},
+		{
+			name:               "with extra skip, source is the wrapper's caller",
+			sourceSkipExtra:    1,
+			expectedSourceFile: "/common_options_test.go:",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				logger   = xlog.NewCaptureLogger()
+				commOpts = xlog.NewCommonOpts()
+				subject  = xlog.NewSyncLogger(
+					io.Discard,
+					xlog.SyncLoggerWithOptions(commOpts),
+					xlog.SyncLoggerWithFormatter(func(_ io.Writer, keyValues []any) error {
+						logger.Error(keyValues...)
+
+						return nil
+					}),
+				)
+			)
+			commOpts.SourceSkipExtra = test.sourceSkipExtra
+
+			// act
+			logThroughWrapper(subject, "msg", "foo")
+
+			// assert
+			records := logger.Records()
+			if assertEqual(t, 1, len(records)) {
+				src, _ := records[0].KeyValues[5].(string)
+				assertEqual(t, "src", records[0].KeyValues[4])
+				assertTrue(t, strings.Contains(src, test.expectedSourceFile))
+			}
+		})
+	}
+}
+
+func TestCommonOpts_SourceSkipExtra_notAppliedToExplicitProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.NewSyncLogger(
+			&buf,
+			xlog.SyncLoggerWithOptions(commOpts),
+			xlog.SyncLoggerWithFormatter(xlog.LogfmtFormatter),
+		)
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.Source = xlog.SourceProvider(4, 1)
+	commOpts.SourceSkipExtra = 1
+
+	// act
+	logThroughWrapper(subject, "msg", "foo")
+
+	// assert
+	assertTrue(t, strings.Contains(buf.String(), " src=/common_test.go:"))
+}
+
+func TestSourceProviderTrimPrefix(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject       = xlog.SourceProviderTrimPrefix
+		_, file, _, _ = runtime.Caller(0)
+		reg           = regexp.MustCompile(`^common_options_test\.go:\d+$`)
+	)
+
+	t.Run("prefix is stripped", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		result := subject(1, filepath.ToSlash(filepath.Dir(file)))()
+		resultWithSlash := subject(1, filepath.ToSlash(filepath.Dir(file))+"/")()
+
+		// assert
+		assertTrue(t, reg.MatchString(result.(string)))
+		assertTrue(t, reg.MatchString(resultWithSlash.(string)))
+	})
+
+	t.Run("only part of dir is stripped", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		dir := filepath.ToSlash(filepath.Dir(file))
+		parentDir := path.Dir(dir)
+
+		// act
+		result := subject(1, parentDir)()
+
+		// assert
+		assertTrue(t, regexp.MustCompile(`^`+path.Base(dir)+`/common_options_test\.go:\d+$`).MatchString(result.(string)))
+	})
+
+	t.Run("base name is returned if prefix is not found", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		result := subject(1, "/this/prefix/does/not/exist")()
+		resultEmptyPrefix := subject(1, "")()
+
+		// assert
+		assertTrue(t, reg.MatchString(result.(string)))
+		assertTrue(t, reg.MatchString(resultEmptyPrefix.(string)))
+	})
+
+	t.Run("too many frames skipped", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		result := subject(1000, "")()
+
+		// assert
+		assertEqual(t, "", result)
+	})
+}
+
+func TestSourceProviderFromBuildInfo(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xlog.SourceProviderFromBuildInfo
+		reg     = regexp.MustCompile(`^common_options_test\.go:\d+$`)
+	)
+
+	// act
+	result := subject(1)()
+
+	// assert
+	assertTrue(t, reg.MatchString(result.(string)))
+}
+
+func TestCommonOpts_UseRelativeSource(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		logger   = xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(commOpts))
+		srcs     = make([]string, 0, 2)
+	)
+	commOpts.UseRelativeSource = true
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelNone)
+
+	// act
+	for i := 0; i < 2; i++ {
+		buf.Reset()
+		logger.Info(xlog.MessageKey, "relative source")
+		_, _, line, _ := runtime.Caller(0)
+		var doc map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err.Error())
+		}
+		srcs = append(srcs, doc["src"].(string))
+
+		// assert
+		assertEqual(t, "common_options_test.go:"+strconv.Itoa(line-1), doc["src"])
+	}
+	assertEqual(t, srcs[0], srcs[1]) // stable.
+
+	// act - file from a nested package is relative to the module root,
+	// without -trimpath, too.
+	buf.Reset()
+	sourcetest.Log(logger)
+
+	// assert
+	assertTrue(t, strings.Contains(
+		buf.String(),
+		`"src":"internal/sourcetest/sourcetest.go:`+strconv.Itoa(sourcetest.LogLine)+`"`,
+	))
+
+	// act - option is not applied to an explicitly set provider.
+	buf.Reset()
+	commOpts.Source = xlog.SourceProvider(4, 0)
+	logger.Info(xlog.MessageKey, "explicit provider")
+
+	// assert
+	assertTrue(t, strings.Contains(buf.String(), `"src":"/`))
+}
+
+func TestSourceProviderWithFunc(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xlog.SourceProviderWithFunc
+		reg     = regexp.MustCompile(`^xlog_test\.TestSourceProviderWithFunc \(.*common_options_test\.go:\d+\)$`)
+	)
+
+	// act
+	result := subject(1, 0)()
+	resultStr, ok := result.(string)
+
+	// assert
+	if assertTrue(t, ok) {
+		assertTrue(t, reg.MatchString(resultStr))
+	}
+
+	// act
+	result2 := subject(1, 1)() // with 1 path skipped.
+	result2Str, ok := result2.(string)
+
+	// assert
+	if assertTrue(t, ok) {
+		assertEqual(t, 0, strings.Index(result2Str, "xlog_test.TestSourceProviderWithFunc (/common_options_test.go:"))
+	}
+
+	// act
+	result3 := subject(1000, 0)() // with too many frames skipped.
+
+	// assert
+	assertEqual(t, "", result3)
+}
+
+func TestFuncProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.FuncProvider
+
+	// act
+	result := subject(1)()
+	result2 := subject(1000)() // with too many frames skipped.
+
+	// assert
+	assertEqual(t, "xlog_test.TestFuncProvider", result)
+	assertEqual(t, "", result2)
+}
+
 func TestAppendNoValue(t *testing.T) {
 	t.Parallel()
 
@@ -961,3 +2058,35 @@ func BenchmarkCommonOpts_WithDefaultKeyValues(b *testing.B) {
 		_ = subject.WithDefaultKeyValues(xlog.LevelInfo)
 	}
 }
+
+func BenchmarkCommonOpts_WithDefaultKeyValues_withProvider(b *testing.B) {
+	subject := xlog.NewCommonOpts()
+	subject.AdditionalKeyValues = []any{
+		"provider-key", xlog.Provider(func() any {
+			return fmt.Sprintf("%s-%d", "provider-value", os.Getpid())
+		}),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_ = subject.WithDefaultKeyValues(xlog.LevelInfo)
+	}
+}
+
+func BenchmarkCommonOpts_WithDefaultKeyValues_withCachedProvider(b *testing.B) {
+	subject := xlog.NewCommonOpts()
+	subject.AdditionalKeyValues = []any{
+		"provider-key", xlog.CachedProvider(func() any {
+			return fmt.Sprintf("%s-%d", "provider-value", os.Getpid())
+		}, 0),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_ = subject.WithDefaultKeyValues(xlog.LevelInfo)
+	}
+}
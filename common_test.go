@@ -102,3 +102,9 @@ type dummyStringer struct {
 func (ds dummyStringer) String() string {
 	return "dummyStringer: " + ds.Name
 }
+
+// logThroughWrapper logs given key-values with error level, simulating
+// a helper which wraps xlog, introducing an extra frame in the call stack.
+func logThroughWrapper(logger xlog.Logger, keyValues ...any) {
+	logger.Error(keyValues...)
+}
@@ -0,0 +1,165 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+const (
+	// DiffChangedKey represents the key under which [LogDiff] logs the changed keys.
+	DiffChangedKey = "changed"
+	// DiffAddedKey represents the key under which [LogDiff] logs the added keys.
+	DiffAddedKey = "added"
+	// DiffRemovedKey represents the key under which [LogDiff] logs the removed keys.
+	DiffRemovedKey = "removed"
+
+	// default max nesting level of maps compared key by key.
+	defaultDiffMaxDepth = 5
+)
+
+// DiffChange holds a changed key's values, see [LogDiff].
+type DiffChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// DiffFields holds a section of [LogDiff]'s differences, keyed by
+// (nested) keys. Its string representation is JSON, so that it can be
+// logged by non-JSON formatters, too (logfmt does not support maps).
+type DiffFields map[string]any
+
+// String returns the JSON representation of the fields.
+func (fields DiffFields) String() string {
+	encoded, err := json.Marshal(map[string]any(fields))
+	if err != nil {
+		return fmt.Sprint(map[string]any(fields))
+	}
+
+	return string(encoded)
+}
+
+// diffConfig holds configurations for [LogDiff].
+type diffConfig struct {
+	sep      string
+	maxDepth int
+	msg      string
+}
+
+// LogDiffOption defines optional function for configuring [LogDiff].
+type LogDiffOption func(*diffConfig)
+
+// LogDiffWithMaxDepth sets the max nesting level of maps compared key by key.
+// Deeper maps are compared as a whole.
+// By default, is 5. A value <= 0 is treated as 1 (only top level keys are compared).
+func LogDiffWithMaxDepth(maxDepth int) LogDiffOption {
+	return func(cfg *diffConfig) {
+		cfg.maxDepth = max(maxDepth, 1)
+	}
+}
+
+// LogDiffWithSeparator sets the separator nested keys are joined with.
+// By default, is ".".
+func LogDiffWithSeparator(sep string) LogDiffOption {
+	return func(cfg *diffConfig) {
+		cfg.sep = sep
+	}
+}
+
+// LogDiffWithMessage sets the logged message.
+// By default, is "config changed".
+func LogDiffWithMessage(msg string) LogDiffOption {
+	return func(cfg *diffConfig) {
+		cfg.msg = msg
+	}
+}
+
+// LogDiff computes the differences between before and after maps (a configuration,
+// before and after a reload, for example) and logs them, with given level, as structured fields:
+//   - under [DiffChangedKey], a [DiffFields] of the changed keys to their [DiffChange];
+//   - under [DiffAddedKey], a [DiffFields] of the added keys to their values;
+//   - under [DiffRemovedKey], a [DiffFields] of the removed keys to their values.
+//
+// Nested maps (of type map[string]any) are compared key by key, up to a depth (see
+// [LogDiffWithMaxDepth]), their keys being joined with a separator (example: "db.host").
+// Values are compared with [reflect.DeepEqual]. Empty sections are not logged,
+// and nothing is logged if there are no differences, or the level is not enabled.
+// Note: this function adds 2 frames to the call stack, so you may
+// want to set [CommonOpts.SourceSkipExtra] to 2, for the source to point to its caller.
+//
+// Example of usage:
+//
+//	xlog.LogDiff(logger, xlog.LevelInfo, oldConfig, newConfig)
+//	// {"changed":{"db.host":{"before":"db1","after":"db2"}},"added":{"cache":{"ttl":60}},"lvl":"INFO","msg":"config changed"}
+func LogDiff(logger Logger, lvl Level, before, after map[string]any, opts ...LogDiffOption) {
+	if !IsLevelEnabled(logger, lvl) {
+		return
+	}
+
+	cfg := diffConfig{
+		sep:      ".",
+		maxDepth: defaultDiffMaxDepth,
+		msg:      "config changed",
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var (
+		changed = make(DiffFields)
+		added   = make(DiffFields)
+		removed = make(DiffFields)
+	)
+	diffMaps(before, after, "", cfg.maxDepth, cfg.sep, changed, added, removed)
+	if len(changed)+len(added)+len(removed) == 0 {
+		return
+	}
+
+	keyValues := make([]any, 0, 8)
+	keyValues = append(keyValues, MessageKey, cfg.msg)
+	if len(changed) > 0 {
+		keyValues = append(keyValues, DiffChangedKey, changed)
+	}
+	if len(added) > 0 {
+		keyValues = append(keyValues, DiffAddedKey, added)
+	}
+	if len(removed) > 0 {
+		keyValues = append(keyValues, DiffRemovedKey, removed)
+	}
+	logByLevel(logger, lvl, keyValues...)
+}
+
+// diffMaps stores into changed / added / removed maps the differences
+// between before and after maps, with keys prefixed by given prefix.
+func diffMaps(before, after map[string]any, prefix string, depth int, sep string, changed, added, removed DiffFields) {
+	for key, beforeValue := range before {
+		afterValue, found := after[key]
+		if !found {
+			removed[prefix+key] = beforeValue
+
+			continue
+		}
+		if depth > 1 {
+			beforeMap, isBeforeMap := beforeValue.(map[string]any)
+			afterMap, isAfterMap := afterValue.(map[string]any)
+			if isBeforeMap && isAfterMap {
+				diffMaps(beforeMap, afterMap, prefix+key+sep, depth-1, sep, changed, added, removed)
+
+				continue
+			}
+		}
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			changed[prefix+key] = DiffChange{Before: beforeValue, After: afterValue}
+		}
+	}
+	for key, afterValue := range after {
+		if _, found := before[key]; !found {
+			added[prefix+key] = afterValue
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestLogDiff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("added, removed, changed keys are logged", testLogDiffFields)
+	t.Run("nested maps deeper than max depth are compared as a whole", testLogDiffMaxDepth)
+	t.Run("no differences, nothing is logged", testLogDiffNoChanges)
+	t.Run("custom message and separator", testLogDiffCustomMessageAndSeparator)
+	t.Run("disabled level, nothing is logged", testLogDiffDisabledLevel)
+	t.Run("logfmt formatter is supported", testLogDiffLogfmt)
+}
+
+func testLogDiffFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger = xlog.NewCaptureLogger()
+		before = map[string]any{
+			"port":  8080,
+			"debug": false,
+			"db": map[string]any{
+				"host": "db1",
+				"pool": 10,
+				"user": "admin",
+			},
+			"tags": []string{"a", "b"},
+		}
+		after = map[string]any{
+			"port":  8080,
+			"debug": true,
+			"db": map[string]any{
+				"host": "db2",
+				"pool": 10,
+			},
+			"tags":  []string{"a", "b"},
+			"cache": map[string]any{"ttl": 60},
+		}
+	)
+
+	// act
+	xlog.LogDiff(logger, xlog.LevelInfo, before, after)
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{
+				Level: xlog.LevelInfo,
+				KeyValues: []any{
+					xlog.MessageKey, "config changed",
+					xlog.DiffChangedKey, xlog.DiffFields{
+						"debug":   xlog.DiffChange{Before: false, After: true},
+						"db.host": xlog.DiffChange{Before: "db1", After: "db2"},
+					},
+					xlog.DiffAddedKey, xlog.DiffFields{
+						"cache": map[string]any{"ttl": 60},
+					},
+					xlog.DiffRemovedKey, xlog.DiffFields{
+						"db.user": "admin",
+					},
+				},
+			},
+		},
+		logger.Records(),
+	)
+}
+
+func testLogDiffMaxDepth(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger = xlog.NewCaptureLogger()
+		before = map[string]any{
+			"db": map[string]any{
+				"primary": map[string]any{"host": "db1", "port": 5432},
+			},
+		}
+		after = map[string]any{
+			"db": map[string]any{
+				"primary": map[string]any{"host": "db2", "port": 5432},
+			},
+		}
+	)
+
+	// act
+	xlog.LogDiff(logger, xlog.LevelWarning, before, after, xlog.LogDiffWithMaxDepth(2))
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{
+				Level: xlog.LevelWarning,
+				KeyValues: []any{
+					xlog.MessageKey, "config changed",
+					xlog.DiffChangedKey, xlog.DiffFields{
+						"db.primary": xlog.DiffChange{
+							Before: map[string]any{"host": "db1", "port": 5432},
+							After:  map[string]any{"host": "db2", "port": 5432},
+						},
+					},
+				},
+			},
+		},
+		logger.Records(),
+	)
+}
+
+func testLogDiffNoChanges(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger = xlog.NewCaptureLogger()
+		before = map[string]any{"db": map[string]any{"host": "db1"}, "tags": []string{"a"}}
+		after  = map[string]any{"db": map[string]any{"host": "db1"}, "tags": []string{"a"}}
+	)
+
+	// act
+	xlog.LogDiff(logger, xlog.LevelInfo, before, after)
+	xlog.LogDiff(logger, xlog.LevelInfo, nil, nil)
+
+	// assert
+	assertEqual(t, 0, len(logger.Records()))
+}
+
+func testLogDiffCustomMessageAndSeparator(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger = xlog.NewCaptureLogger()
+		before = map[string]any{"db": map[string]any{"host": "db1"}}
+		after  = map[string]any{"db": map[string]any{"host": "db2"}}
+	)
+
+	// act
+	xlog.LogDiff(
+		logger,
+		xlog.LevelDebug,
+		before,
+		after,
+		xlog.LogDiffWithMessage("settings reloaded"),
+		xlog.LogDiffWithSeparator("/"),
+	)
+
+	// assert
+	assertTrue(t, logger.HasEntry(xlog.LevelDebug, xlog.MessageKey, "settings reloaded"))
+	assertTrue(t, logger.HasEntry(
+		xlog.LevelDebug,
+		xlog.DiffChangedKey,
+		xlog.DiffFields{"db/host": xlog.DiffChange{Before: "db1", After: "db2"}},
+	))
+}
+
+func testLogDiffDisabledLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf    bytes.Buffer
+		opts   = xlog.NewCommonOpts()
+		before = map[string]any{"db": map[string]any{"host": "db1"}}
+		after  = map[string]any{"db": map[string]any{"host": "db2"}}
+	)
+	opts.MinLevel = xlog.FixedLevelProvider(xlog.LevelWarning)
+	logger := xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(opts))
+
+	// act
+	xlog.LogDiff(logger, xlog.LevelInfo, before, after)
+
+	// assert
+	assertEqual(t, 0, buf.Len())
+}
+
+func testLogDiffLogfmt(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf    bytes.Buffer
+		opts   = xlog.NewCommonOpts()
+		before = map[string]any{"db": map[string]any{"host": "db1"}}
+		after  = map[string]any{"db": map[string]any{"host": "db2"}, "cache": map[string]any{"ttl": 60}}
+	)
+	opts.Time = xlog.StaticProvider(staticTime)
+	opts.Source = xlog.StaticProvider("")
+	opts.AdditionalKeyValues = nil
+	logger := xlog.NewSyncLogger(
+		&buf,
+		xlog.SyncLoggerWithOptions(opts),
+		xlog.SyncLoggerWithFormatter(xlog.LogfmtFormatter),
+	)
+
+	// act
+	xlog.LogDiff(logger, xlog.LevelWarning, before, after)
+
+	// assert
+	assertEqual(
+		t,
+		`date=`+staticTime+` lvl=WARN msg="config changed" `+
+			`changed="{\"db.host\":{\"before\":\"db1\",\"after\":\"db2\"}}" `+
+			`added="{\"cache\":{\"ttl\":60}}"`+"\n",
+		buf.String(),
+	)
+}
@@ -0,0 +1,217 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ThresholdErrorHandlerKeyValuesKey represents the key under which
+// a [NewThresholdErrorHandler]'s fallback logger logs the key-values
+// of the log the error occurred on.
+const ThresholdErrorHandlerKeyValuesKey = "failed_log"
+
+const (
+	// default max no. of errors a stderr error handler writes within an interval.
+	defaultStderrErrHandlerMaxErrors = 10
+	// default interval a stderr error handler's max no. of errors applies to.
+	defaultStderrErrHandlerInterval = time.Second
+	// max length of a value dumped by a stderr error handler.
+	stderrErrHandlerMaxValueLen = 128
+)
+
+// NewThresholdErrorHandler returns an ErrorHandler which forwards each internal
+// logging error to given fallback Logger (a logger writing to os.Stderr, for example;
+// it can be nil, if you are not interested in that), and, if more than maxErrors
+// occur within given window, calls given escalate callback (with the error
+// which tripped the threshold), so that you can react (disable the failing sink,
+// for example). This way, a broken logger does not fail silently.
+// The escalate callback is called only once.
+// It is concurrent safe.
+//
+// Example of usage:
+//
+//	xOpts.ErrHandler = xlog.NewThresholdErrorHandler(stderrLogger, 10, time.Minute, func(err error, _ []any) {
+//		failing.Store(true)
+//	})
+func NewThresholdErrorHandler(
+	fallback Logger,
+	maxErrors int,
+	window time.Duration,
+	escalate ErrorHandler,
+) ErrorHandler {
+	var (
+		maxErrs = max(maxErrors, 0)
+		// occurrences holds, as a ring buffer, the times of the last maxErrs+1 errors.
+		occurrences = make([]time.Time, maxErrs+1)
+		next        int
+		count       int
+		escalated   bool
+		mu          sync.Mutex
+	)
+
+	return func(err error, keyValues []any) {
+		if fallback != nil {
+			fallback.Error(
+				MessageKey, "logging failed",
+				ErrorKey, err,
+				ThresholdErrorHandlerKeyValuesKey, keyValues,
+			)
+		}
+
+		mu.Lock()
+		if escalated {
+			mu.Unlock()
+
+			return
+		}
+		currentTime := time.Now()
+		occurrences[next] = currentTime
+		next = (next + 1) % len(occurrences)
+		count = min(count+1, len(occurrences))
+		// the oldest of the last maxErrs+1 errors is the next one to be overwritten.
+		tripped := count == len(occurrences) && currentTime.Sub(occurrences[next]) <= window
+		if tripped {
+			escalated = true
+		}
+		mu.Unlock()
+
+		if tripped && escalate != nil {
+			escalate(err, keyValues)
+		}
+	}
+}
+
+// stderrErrorHandler writes internal logging errors to a writer, rate limited.
+type stderrErrorHandler struct {
+	// w is the writer errors are written to.
+	w io.Writer
+	// prefix is written in front of each error.
+	prefix string
+	// maxErrors is the max no. of errors written within an interval.
+	maxErrors int
+	// interval is the time window maxErrors applies to.
+	interval time.Duration
+	// windowStart is the start time of current window.
+	windowStart time.Time
+	// windowCount is the no. of errors written within current window.
+	windowCount int
+	// suppressed is the no. of errors dropped since last written one.
+	suppressed int
+	// concurrency semaphore.
+	mu sync.Mutex
+}
+
+// StderrErrorHandlerOption defines optional function for configuring
+// a stderr error handler.
+type StderrErrorHandlerOption func(*stderrErrorHandler)
+
+// StderrErrorHandlerWithWriter sets the writer errors are written to.
+// By default, is set to os.Stderr.
+func StderrErrorHandlerWithWriter(w io.Writer) StderrErrorHandlerOption {
+	return func(h *stderrErrorHandler) {
+		h.w = w
+	}
+}
+
+// StderrErrorHandlerWithRateLimit sets the max no. of errors written within
+// given interval; the rest of them are dropped, and their no. is reported
+// with the next written error.
+// By default, max 10 errors / second are written.
+// Pass a maxErrors value <=0 to disable rate limiting.
+func StderrErrorHandlerWithRateLimit(maxErrors int, interval time.Duration) StderrErrorHandlerOption {
+	return func(h *stderrErrorHandler) {
+		h.maxErrors = maxErrors
+		h.interval = interval
+	}
+}
+
+// NewStderrErrorHandler returns an ErrorHandler which writes each internal logging
+// error, prefixed with given prefix, followed by a compact dump of the key-values
+// of the log it occurred on (long values are truncated), on a line, to os.Stderr.
+// When the main sink fails, writing the error to it would be pointless, so this
+// is a more practical choice than [NopErrorHandler], for many applications.
+// Errors are rate limited, in order to avoid a storm of them,
+// see [StderrErrorHandlerWithRateLimit].
+// It is concurrent safe.
+//
+// Example of output:
+//
+//	xlog: write /var/log/app.log: no space left on device (3 errors suppressed) msg="user logged in" userId=123
+func NewStderrErrorHandler(prefix string, opts ...StderrErrorHandlerOption) ErrorHandler {
+	h := &stderrErrorHandler{
+		w:         os.Stderr,
+		prefix:    prefix,
+		maxErrors: defaultStderrErrHandlerMaxErrors,
+		interval:  defaultStderrErrHandlerInterval,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h.handle
+}
+
+// handle writes given error, if rate limit allows it.
+func (h *stderrErrorHandler) handle(err error, keyValues []any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.maxErrors > 0 {
+		if currentTime := time.Now(); currentTime.Sub(h.windowStart) >= h.interval {
+			h.windowStart = currentTime
+			h.windowCount = 0
+		}
+		if h.windowCount >= h.maxErrors {
+			h.suppressed++
+
+			return
+		}
+		h.windowCount++
+	}
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufPool.Put(buf)
+
+	_, _ = buf.WriteString(h.prefix)
+	if err != nil {
+		_, _ = buf.WriteString(err.Error())
+	}
+	if h.suppressed > 0 {
+		_, _ = buf.WriteString(" (")
+		_, _ = buf.WriteString(strconv.Itoa(h.suppressed))
+		_, _ = buf.WriteString(" errors suppressed)")
+		h.suppressed = 0
+	}
+	for idx := 0; idx < len(keyValues); idx += 2 {
+		_ = buf.WriteByte(' ')
+		_, _ = buf.WriteString(stringify(keyValues[idx]))
+		_ = buf.WriteByte('=')
+		if idx+1 >= len(keyValues) {
+			_, _ = buf.WriteString(noValue)
+
+			break
+		}
+		value, truncated := truncateValue(keyValues[idx+1], stderrErrHandlerMaxValueLen, "…")
+		if !truncated {
+			value = stringify(keyValues[idx+1])
+		}
+		if value == "" || strings.ContainsAny(value, " =\"\\\r\n\t") {
+			value = strconv.Quote(value)
+		}
+		_, _ = buf.WriteString(value)
+	}
+	_ = buf.WriteByte('\n')
+
+	_, _ = h.w.Write(buf.Bytes())
+}
@@ -0,0 +1,254 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestNewThresholdErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("errors are forwarded to fallback logger", testThresholdErrorHandlerForwardsToFallback)
+	t.Run("escalates once when threshold is tripped", testThresholdErrorHandlerEscalatesOnce)
+	t.Run("does not escalate for errors spread over window", testThresholdErrorHandlerSpreadErrors)
+	t.Run("nil fallback and escalate", testThresholdErrorHandlerNilFallbackAndEscalate)
+	t.Run("concurrency", testThresholdErrorHandlerConcurrency)
+}
+
+func testThresholdErrorHandlerForwardsToFallback(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		fallback  = xlog.NewCaptureLogger()
+		subject   = xlog.NewThresholdErrorHandler(fallback, 10, time.Minute, nil)
+		keyValues = []any{xlog.MessageKey, "foo"}
+	)
+
+	// act
+	subject(ErrWrite, keyValues)
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{{
+			Level: xlog.LevelError,
+			KeyValues: []any{
+				xlog.MessageKey, "logging failed",
+				xlog.ErrorKey, ErrWrite,
+				xlog.ThresholdErrorHandlerKeyValuesKey, keyValues,
+			},
+		}},
+		fallback.Records(),
+	)
+}
+
+func testThresholdErrorHandlerEscalatesOnce(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		fallback     = xlog.NewMockLogger()
+		escalation   = new(MockErrorHandler)
+		maxErrors    = 3
+		subject      = xlog.NewThresholdErrorHandler(fallback, maxErrors, time.Minute, escalation.Handle)
+		errTripping  = errors.New("tripping error")
+		errsNo       = 10
+		keyValues    = []any{xlog.MessageKey, "foo"}
+		trippingKeys = []any{xlog.MessageKey, "bar"}
+	)
+	escalation.SetHandleCallback(func(err error, kv []any) {
+		assertEqual(t, errTripping, err)
+		assertEqual(t, trippingKeys, kv)
+	})
+
+	// act & assert
+	for i := 0; i < maxErrors; i++ {
+		subject(ErrWrite, keyValues)
+	}
+	assertEqual(t, 0, escalation.HandleCallsCount())
+
+	subject(errTripping, trippingKeys)
+	assertEqual(t, 1, escalation.HandleCallsCount())
+
+	for i := maxErrors + 1; i < errsNo; i++ {
+		subject(ErrWrite, keyValues)
+	}
+	assertEqual(t, 1, escalation.HandleCallsCount())
+	assertEqual(t, errsNo, fallback.LogCallsCount(xlog.LevelError))
+}
+
+func testThresholdErrorHandlerSpreadErrors(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		escalation = new(MockErrorHandler)
+		window     = 20 * time.Millisecond
+		subject    = xlog.NewThresholdErrorHandler(nil, 1, window, escalation.Handle)
+	)
+
+	// act
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			time.Sleep(window + 10*time.Millisecond)
+		}
+		subject(ErrWrite, nil)
+	}
+
+	// assert
+	assertEqual(t, 0, escalation.HandleCallsCount())
+
+	// act - 2 errors within window.
+	subject(ErrWrite, nil)
+
+	// assert
+	assertEqual(t, 1, escalation.HandleCallsCount())
+}
+
+func testThresholdErrorHandlerNilFallbackAndEscalate(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.NewThresholdErrorHandler(nil, 0, time.Minute, nil)
+
+	// act & assert - should not panic.
+	subject(ErrWrite, nil)
+	subject(ErrWrite, nil)
+}
+
+func testThresholdErrorHandlerConcurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		fallback     = xlog.NewMockLogger()
+		escalation   = new(MockErrorHandler)
+		subject      = xlog.NewThresholdErrorHandler(fallback, 50, time.Minute, escalation.Handle)
+		goroutinesNo = 10
+		errsNo       = 20
+		wg           sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < errsNo; j++ {
+				subject(ErrWrite, nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, 1, escalation.HandleCallsCount())
+	assertEqual(t, goroutinesNo*errsNo, fallback.LogCallsCount(xlog.LevelError))
+}
+
+func TestNewStderrErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error and key-values are written", testStderrErrorHandlerWrites)
+	t.Run("errors are rate limited", testStderrErrorHandlerRateLimits)
+	t.Run("rate limiting can be disabled", testStderrErrorHandlerNoRateLimit)
+}
+
+func testStderrErrorHandlerWrites(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		subject = xlog.NewStderrErrorHandler(
+			"xlog: ",
+			xlog.StderrErrorHandlerWithWriter(&buf),
+		)
+		keyValues = []any{
+			xlog.MessageKey, "user logged in",
+			"userId", 123,
+			"payload", strings.Repeat("a", 200),
+			"odd",
+		}
+	)
+
+	// act
+	subject(ErrWrite, keyValues)
+
+	// assert
+	assertEqual(
+		t,
+		"xlog: "+ErrWrite.Error()+` msg="user logged in" userId=123 payload=`+
+			strings.Repeat("a", 128)+"… odd=*NoValue*\n",
+		buf.String(),
+	)
+}
+
+func testStderrErrorHandlerRateLimits(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		interval = 200 * time.Millisecond
+		subject  = xlog.NewStderrErrorHandler(
+			"xlog: ",
+			xlog.StderrErrorHandlerWithWriter(&buf),
+			xlog.StderrErrorHandlerWithRateLimit(2, interval),
+		)
+	)
+
+	// act
+	for i := 0; i < 5; i++ {
+		subject(ErrWrite, []any{"attempt", i})
+	}
+
+	// assert
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assertEqual(t, []string{"xlog: " + ErrWrite.Error() + " attempt=0", "xlog: " + ErrWrite.Error() + " attempt=1"}, lines)
+
+	// act - after interval elapses, errors are written again.
+	time.Sleep(interval + 50*time.Millisecond)
+	subject(ErrWrite, []any{"attempt", 5})
+
+	// assert
+	lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if assertEqual(t, 3, len(lines)) {
+		assertEqual(t, "xlog: "+ErrWrite.Error()+" (3 errors suppressed) attempt=5", lines[2])
+	}
+}
+
+func testStderrErrorHandlerNoRateLimit(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		subject = xlog.NewStderrErrorHandler(
+			"",
+			xlog.StderrErrorHandlerWithWriter(&buf),
+			xlog.StderrErrorHandlerWithRateLimit(0, 0),
+		)
+		errorsNo = 50
+	)
+
+	// act
+	for i := 0; i < errorsNo; i++ {
+		subject(ErrWrite, nil)
+	}
+
+	// assert
+	assertEqual(t, errorsNo, strings.Count(buf.String(), ErrWrite.Error()+"\n"))
+}
@@ -6,15 +6,49 @@
 package xlog
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"strconv"
+	"strings"
 )
 
 // Formatter writes the provided key-values in a given format.
 // Returns error in case something goes wrong.
 type Formatter func(w io.Writer, keyValues []any) error
 
+// ErrFormatterPanicked is the error passed to [CommonOpts.ErrHandler]
+// when a formatter panics. The logger recovers from the panic, so that
+// a buggy formatter does not take down the application.
+var ErrFormatterPanicked = errors.New("xlog: formatter panicked")
+
+// safeFormat calls the formatter, converting a panic into an
+// [ErrFormatterPanicked] error.
+func safeFormat(formatter Formatter, w io.Writer, keyValues []any) (err error) {
+	defer recoverFormatterPanic(&err)
+
+	return formatter(w, keyValues)
+}
+
+// safeFormatBatch calls the batch formatter, converting a panic into an
+// [ErrFormatterPanicked] error.
+func safeFormatBatch(formatter BatchFormatter, w io.Writer, entries [][]any) (err error) {
+	defer recoverFormatterPanic(&err)
+
+	return formatter.FormatBatch(w, entries)
+}
+
+// recoverFormatterPanic recovers from a formatter panic, if any,
+// setting the error to an [ErrFormatterPanicked] one.
+// It must be called deferred.
+func recoverFormatterPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%w: %v", ErrFormatterPanicked, r)
+	}
+}
+
 // stringify returns string representation of an interface.
 func stringify(i any) string {
 	switch data := i.(type) {
@@ -28,3 +62,57 @@ func stringify(i any) string {
 
 	return fmt.Sprint(i)
 }
+
+// SliceEncoding defines how slices / arrays values are rendered
+// by non-JSON formatters (logfmt, text).
+type SliceEncoding byte
+
+const (
+	// SliceEncodingGoDefault leaves slices to the formatter's default rendering:
+	// the text formatter renders them as [fmt.Sprint] does (example: [a b]), which
+	// is lossy and not re-parseable, the logfmt one does not support them.
+	SliceEncodingGoDefault SliceEncoding = iota
+
+	// SliceEncodingCommaJoined renders slices' elements joined by comma (example: a,b).
+	SliceEncodingCommaJoined
+
+	// SliceEncodingJSON renders slices JSON encoded (example: ["a","b"]).
+	SliceEncodingJSON
+)
+
+// encodeSlice returns given value encoded as configured, if it is a slice / an array,
+// or the value itself, otherwise.
+// Byte slices, and slices having their own string representation
+// ([fmt.Stringer], error) are not encoded.
+func encodeSlice(value any, sliceEnc SliceEncoding) any {
+	if sliceEnc == SliceEncodingGoDefault {
+		return value
+	}
+	switch value.(type) {
+	case string, []byte, fmt.Stringer, error:
+		return value
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return value
+	}
+
+	switch sliceEnc {
+	case SliceEncodingCommaJoined:
+		var sb strings.Builder
+		for idx := 0; idx < rv.Len(); idx++ {
+			if idx > 0 {
+				_ = sb.WriteByte(',')
+			}
+			_, _ = sb.WriteString(stringify(rv.Index(idx).Interface()))
+		}
+
+		return sb.String()
+	case SliceEncodingJSON:
+		if encoded, err := json.Marshal(value); err == nil {
+			return string(encoded)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,112 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Suffixes appended to the error key for the extracted error fields.
+const (
+	// ErrorTypeKeySuffix is the suffix of the key under which the error's type is logged.
+	ErrorTypeKeySuffix = "_type"
+	// ErrorCauseKeySuffix is the suffix of the key under which the error's cause chain is logged.
+	ErrorCauseKeySuffix = "_cause"
+)
+
+// errorCauseSep is the separator of errors in the cause chain.
+const errorCauseSep = " <- "
+
+// ErrorFields returns key-values describing given error, under [ErrorKey]:
+// "err" - the error message, "err_type" - the error's type,
+// "err_cause" - the messages of the wrapped errors chain, joined (the key is
+// present only if error wraps other errors). Errors wrapping multiple errors,
+// like the ones created with [errors.Join], have all of them (and their chains)
+// in the cause, depth first.
+//
+// Example of usage:
+//
+//	logger.Error(append([]any{xlog.MessageKey, "could not save user"}, xlog.ErrorFields(err)...)...)
+func ErrorFields(err error) []any {
+	return errorFields(ErrorKey, err)
+}
+
+// errorFields returns key-values describing given error, under given key.
+func errorFields(key string, err error) []any {
+	if err == nil {
+		return []any{key, nil}
+	}
+
+	fields := []any{
+		key, err.Error(),
+		key + ErrorTypeKeySuffix, fmt.Sprintf("%T", err),
+	}
+
+	if causes := appendErrorCauses(nil, err); len(causes) > 0 {
+		fields = append(fields, key+ErrorCauseKeySuffix, strings.Join(causes, errorCauseSep))
+	}
+
+	return fields
+}
+
+// appendErrorCauses appends to causes the messages of the errors wrapped
+// by given error, depth first. Both errors wrapping a single error and
+// errors wrapping multiple errors (like the ones created with [errors.Join])
+// are unwrapped.
+func appendErrorCauses(causes []string, err error) []string {
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := wrapper.Unwrap(); cause != nil {
+			causes = append(causes, cause.Error())
+			causes = appendErrorCauses(causes, cause)
+		}
+	case interface{ Unwrap() []error }:
+		for _, cause := range wrapper.Unwrap() {
+			if cause != nil {
+				causes = append(causes, cause.Error())
+				causes = appendErrorCauses(causes, cause)
+			}
+		}
+	}
+
+	return causes
+}
+
+// ExpandErrorsFormatter is a decorator which expands an error found under
+// given key (usually [ErrorKey]) into its message, type and cause chain,
+// before passing the key-values to the decorated formatter.
+// See [ErrorFields] for the resulted fields.
+// Values under errorKey which are not errors are left untouched.
+var ExpandErrorsFormatter = func(formatter Formatter, errorKey string) Formatter {
+	return func(w io.Writer, keyValues []any) error {
+		keyValues = AppendNoValue(keyValues)
+
+		var expanded []any // lazily copied, if something needs to be expanded.
+		for idx := 0; idx < len(keyValues); idx += 2 {
+			key, value := keyValues[idx], keyValues[idx+1]
+			err, isErr := value.(error)
+			if key != errorKey || !isErr || err == nil {
+				if expanded != nil {
+					expanded = append(expanded, key, value)
+				}
+
+				continue
+			}
+			if expanded == nil {
+				expanded = make([]any, idx, len(keyValues)+4)
+				copy(expanded, keyValues[:idx])
+			}
+			expanded = append(expanded, errorFields(errorKey, err)...)
+		}
+		if expanded != nil {
+			keyValues = expanded
+		}
+
+		return formatter(w, keyValues)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func ExampleExpandErrorsFormatter() {
+	// In this example we create a SyncLogger that expands
+	// logged errors into message, type and cause chain.
+
+	opts := xlog.NewCommonOpts()
+	opts.Time = func() any { // mock time for output check
+		return "2022-04-12T16:01:20Z"
+	}
+	opts.SourceKey = "" // disable source for output check
+	logger := xlog.NewSyncLogger(
+		os.Stdout,
+		xlog.SyncLoggerWithOptions(opts),
+		xlog.SyncLoggerWithFormatter(xlog.ExpandErrorsFormatter(
+			xlog.LogfmtFormatter,
+			xlog.ErrorKey,
+		)),
+	)
+	defer logger.Close()
+
+	err := fmt.Errorf("could not read config: %w", io.ErrUnexpectedEOF)
+	logger.Error(xlog.MessageKey, "init failed", xlog.ErrorKey, err)
+
+	// Output:
+	// date=2022-04-12T16:01:20Z lvl=ERROR msg="init failed" err="could not read config: unexpected EOF" err_type=*fmt.wrapError err_cause="unexpected EOF"
+}
+
+func TestErrorFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		rootErr    = errors.New("root error")
+		wrappedErr = fmt.Errorf("middle: %w", rootErr)
+		topErr     = fmt.Errorf("top: %w", wrappedErr)
+		otherErr   = errors.New("other error")
+		joinedErr  = fmt.Errorf("top: %w", errors.Join(wrappedErr, otherErr))
+		tests      = [...]struct {
+			name     string
+			input    error
+			expected []any
+		}{
+			{
+				name:     "nil error",
+				input:    nil,
+				expected: []any{xlog.ErrorKey, nil},
+			},
+			{
+				name:  "error without cause",
+				input: rootErr,
+				expected: []any{
+					"err", "root error",
+					"err_type", "*errors.errorString",
+				},
+			},
+			{
+				name:  "error with causes chain",
+				input: topErr,
+				expected: []any{
+					"err", "top: middle: root error",
+					"err_type", "*fmt.wrapError",
+					"err_cause", "middle: root error <- root error",
+				},
+			},
+			{
+				name:  "error with joined causes",
+				input: joinedErr,
+				expected: []any{
+					"err", "top: middle: root error\nother error",
+					"err_type", "*fmt.wrapError",
+					"err_cause", "middle: root error\nother error <- middle: root error <- root error <- other error",
+				},
+			},
+		}
+	)
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			result := xlog.ErrorFields(test.input)
+
+			// assert
+			assertEqual(t, test.expected, result)
+		})
+	}
+}
+
+func TestExpandErrorsFormatter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.ExpandErrorsFormatter(formatter.Format, "error")
+		rootErr   = errors.New("root error")
+		input     = []any{
+			"msg", "something failed",
+			"error", fmt.Errorf("top: %w", fmt.Errorf("middle: %w", rootErr)),
+			"err", rootErr, // different key, not expanded.
+			"foo", "bar",
+		}
+		inputCopy = append([]any(nil), input...)
+		expected  = []any{
+			"msg", "something failed",
+			"error", "top: middle: root error",
+			"error_type", "*fmt.wrapError",
+			"error_cause", "middle: root error <- root error",
+			"err", rootErr,
+			"foo", "bar",
+		}
+	)
+	formatter.SetFormatCallback(func(_ io.Writer, keyValues []any) error {
+		assertEqual(t, expected, keyValues)
+
+		return nil
+	})
+
+	// act
+	err := subject(io.Discard, input)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, formatter.FormatCallsCount())
+	assertEqual(t, inputCopy, input) // original key-values are not modified.
+}
+
+func TestExpandErrorsFormatter_noErrorIsLeftUntouched(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.ExpandErrorsFormatter(formatter.Format, xlog.ErrorKey)
+		input     = []any{"msg", "foo", xlog.ErrorKey, "not an error"}
+	)
+	formatter.SetFormatCallback(func(_ io.Writer, keyValues []any) error {
+		assertEqual(t, input, keyValues)
+
+		return ErrFormat
+	})
+
+	// act
+	err := subject(io.Discard, input)
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrFormat))
+	assertEqual(t, 1, formatter.FormatCallsCount())
+}
@@ -0,0 +1,132 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+const (
+	defaultFlattenMaxDepth = 5
+	defaultFlattenMaxKeys  = 64
+)
+
+// FlattenFormatter is a decorator which expands map and struct values into
+// multiple key-values, with keys joined by given separator
+// (example: "user", User{ID: 1, Name: "John"} => "user.ID", 1, "user.Name", "John"),
+// before passing them to the decorated formatter.
+// It is useful for formatters which do not know how to render nested values,
+// like logfmt / text ones.
+// Pointers are dereferenced, structs' exported fields are expanded, and map
+// keys are sorted for a predictable output. Values implementing [fmt.Stringer],
+// [error], [json.Marshaler] or [encoding.TextMarshaler] are not expanded.
+// maxDepth is the maximum nesting level expanded, deeper values are passed as they are
+// (a value <= 0 means a default of 5).
+// maxKeys is the maximum no. of keys a value can be expanded into, if exceeded,
+// the value is passed as it is (a value <= 0 means a default of 64).
+var FlattenFormatter = func(formatter Formatter, sep string, maxDepth, maxKeys int) Formatter {
+	if maxDepth <= 0 {
+		maxDepth = defaultFlattenMaxDepth
+	}
+	if maxKeys <= 0 {
+		maxKeys = defaultFlattenMaxKeys
+	}
+
+	return func(w io.Writer, keyValues []any) error {
+		keyValues = AppendNoValue(keyValues)
+
+		flattened := make([]any, 0, len(keyValues))
+		for idx := 0; idx < len(keyValues); idx += 2 {
+			startIdx := len(flattened)
+			flattened = flatten(flattened, keyValues[idx], keyValues[idx+1], sep, maxDepth)
+			if (len(flattened)-startIdx)/2 > maxKeys { // keep value as it is.
+				clear(flattened[startIdx:])
+				flattened = append(flattened[:startIdx], keyValues[idx], keyValues[idx+1])
+			}
+		}
+
+		return formatter(w, flattened)
+	}
+}
+
+var (
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// flatten appends to dst given key-value, expanded if it's a map / struct.
+func flatten(dst []any, key, value any, sep string, depth int) []any {
+	if depth <= 0 || value == nil {
+		return append(dst, key, value)
+	}
+
+	rv := reflect.ValueOf(value)
+	if isFlattenLeaf(rv.Type()) {
+		return append(dst, key, value)
+	}
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return append(dst, key, value)
+		}
+		rv = rv.Elem()
+		if isFlattenLeaf(rv.Type()) {
+			return append(dst, key, rv.Interface())
+		}
+	}
+
+	switch rv.Kind() { // nolint
+	case reflect.Map:
+		if rv.Len() == 0 {
+			return append(dst, key, value)
+		}
+		mapKeys := rv.MapKeys()
+		sort.Slice(mapKeys, func(i, j int) bool {
+			return stringify(mapKeys[i].Interface()) < stringify(mapKeys[j].Interface())
+		})
+		for _, mapKey := range mapKeys {
+			dst = flatten(
+				dst,
+				stringify(key)+sep+stringify(mapKey.Interface()),
+				rv.MapIndex(mapKey).Interface(),
+				sep,
+				depth-1,
+			)
+		}
+
+		return dst
+	case reflect.Struct:
+		rt := rv.Type()
+		startLen := len(dst)
+		for i := 0; i < rt.NumField(); i++ {
+			if !rt.Field(i).IsExported() {
+				continue
+			}
+			dst = flatten(dst, stringify(key)+sep+rt.Field(i).Name, rv.Field(i).Interface(), sep, depth-1)
+		}
+		if len(dst) == startLen { // no exported fields.
+			return append(dst, key, value)
+		}
+
+		return dst
+	}
+
+	return append(dst, key, value)
+}
+
+// isFlattenLeaf returns true if a value of given type should not be expanded.
+func isFlattenLeaf(rt reflect.Type) bool {
+	return rt.Implements(stringerType) ||
+		rt.Implements(errorType) ||
+		rt.Implements(jsonMarshalerType) ||
+		rt.Implements(textMarshalerType)
+}
@@ -0,0 +1,227 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+type flattenAddress struct {
+	City   string
+	Street string
+}
+
+type flattenUser struct {
+	ID       int
+	Name     string
+	Address  *flattenAddress
+	Tags     map[string]string
+	password string
+}
+
+func ExampleFlattenFormatter() {
+	// In this example we create a SyncLogger that expands nested
+	// values into dotted keys, before writing them in logfmt format.
+
+	opts := xlog.NewCommonOpts()
+	opts.MinLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+	opts.Time = func() any { // mock time for output check
+		return "2022-04-12T16:01:20Z"
+	}
+	opts.SourceKey = ""
+	logger := xlog.NewSyncLogger(
+		os.Stdout,
+		xlog.SyncLoggerWithOptions(opts),
+		xlog.SyncLoggerWithFormatter(xlog.FlattenFormatter(xlog.LogfmtFormatter, ".", 0, 0)),
+	)
+	defer logger.Close()
+
+	logger.Info(
+		xlog.MessageKey, "User logged in",
+		"user", map[string]any{"id": 123, "name": "John"},
+	)
+
+	// Output:
+	// date=2022-04-12T16:01:20Z lvl=INFO msg="User logged in" user.id=123 user.name=John
+}
+
+func TestFlattenFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nested values are flattened", testFlattenFormatterFlattensNestedValues)
+	t.Run("max depth is respected", testFlattenFormatterRespectsMaxDepth)
+	t.Run("max keys is respected", testFlattenFormatterRespectsMaxKeys)
+	t.Run("inner formatter error is returned", testFlattenFormatterReturnsErr)
+}
+
+func testFlattenFormatterFlattensNestedValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.FlattenFormatter(formatter.Format, ".", 0, 0)
+		someErr   = errors.New("some error")
+		someTime  = time.Date(2022, time.April, 12, 16, 1, 20, 0, time.UTC)
+		keyValues = []any{
+			"user", flattenUser{
+				ID:       1,
+				Name:     "John",
+				Address:  &flattenAddress{City: "Bucharest", Street: "Main"},
+				Tags:     map[string]string{"b": "2", "a": "1"},
+				password: "unexported fields are skipped",
+			},
+			"req", map[string]any{
+				"id":   "abc",
+				"nil":  nil,
+				"meta": map[int]bool{1: true},
+			},
+			10, "ten",
+			"err", someErr,
+			"time", someTime,
+			"nilUser", (*flattenUser)(nil),
+			"emptyMap", map[string]any{},
+			"ints", []int{1, 2},
+		}
+		expectedKeyValues = []any{
+			"user.ID", 1,
+			"user.Name", "John",
+			"user.Address.City", "Bucharest",
+			"user.Address.Street", "Main",
+			"user.Tags.a", "1",
+			"user.Tags.b", "2",
+			"req.id", "abc",
+			"req.meta.1", true,
+			"req.nil", nil,
+			10, "ten",
+			"err", someErr,
+			"time", someTime,
+			"nilUser", (*flattenUser)(nil),
+			"emptyMap", map[string]any{},
+			"ints", []int{1, 2},
+		}
+	)
+	formatter.SetFormatCallback(func(_ io.Writer, keyValues []any) error {
+		assertEqual(t, expectedKeyValues, keyValues)
+
+		return nil
+	})
+
+	// act
+	resultErr := subject(io.Discard, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, 1, formatter.FormatCallsCount())
+}
+
+func testFlattenFormatterRespectsMaxDepth(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.FlattenFormatter(formatter.Format, "_", 2, 0)
+		address   = &flattenAddress{City: "Bucharest"}
+		keyValues = []any{
+			"user", flattenUser{ID: 1, Address: address},
+			"odd",
+		}
+		expectedKeyValues = []any{
+			"user_ID", 1,
+			"user_Name", "",
+			"user_Address_City", "Bucharest",
+			"user_Address_Street", "",
+			"user_Tags", map[string]string(nil),
+			"odd", "*NoValue*",
+		}
+	)
+	formatter.SetFormatCallback(func(_ io.Writer, keyValues []any) error {
+		assertEqual(t, expectedKeyValues, keyValues)
+
+		return nil
+	})
+	subjectLowerDepth := xlog.FlattenFormatter(func(_ io.Writer, keyValues []any) error {
+		assertEqual(t, []any{"user_ID", 1, "user_Name", "", "user_Address", address, "user_Tags", map[string]string(nil)}, keyValues)
+
+		return nil
+	}, "_", 1, 0)
+
+	// act
+	resultErr := subject(io.Discard, keyValues)
+	resultErrLowerDepth := subjectLowerDepth(io.Discard, keyValues[:2])
+
+	// assert
+	assertNil(t, resultErr)
+	assertNil(t, resultErrLowerDepth)
+	assertEqual(t, 1, formatter.FormatCallsCount())
+}
+
+func testFlattenFormatterRespectsMaxKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer    bytes.Buffer
+		subject   = xlog.FlattenFormatter(xlog.TextFormatter(xlog.NewCommonOpts()), ".", 0, 2)
+		keyValues = []any{
+			"small", map[string]int{"a": 1, "b": 2},
+			"big", map[string]int{"a": 1, "b": 2, "c": 3},
+		}
+	)
+
+	// act
+	resultErr := subject(&writer, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, "small.a=1 small.b=2 big=map[a:1 b:2 c:3]\n", writer.String())
+}
+
+func testFlattenFormatterReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.FlattenFormatter(formatter.Format, ".", 0, 0)
+	)
+	formatter.SetFormatCallback(FormatCallbackErr)
+
+	// act
+	resultErr := subject(io.Discard, []any{"foo", "bar"})
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, ErrFormat))
+}
+
+func BenchmarkFlattenFormatter(b *testing.B) {
+	var (
+		subject = xlog.FlattenFormatter(xlog.LogfmtFormatter, ".", 0, 0)
+		input   = []any{
+			"foo", "bar",
+			"user", flattenUser{
+				ID:      1,
+				Name:    "John",
+				Address: &flattenAddress{City: "Bucharest", Street: "Main"},
+			},
+		}
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_ = subject(io.Discard, input)
+	}
+}
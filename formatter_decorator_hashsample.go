@@ -0,0 +1,41 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"hash/fnv"
+	"io"
+)
+
+// hashSampleBuckets is the no. of buckets sampled values are hashed into.
+const hashSampleBuckets = 10000
+
+// HashSampleFormatter is a decorator which keeps only a fraction, given by rate
+// (from 0 to 1), of the logs, deciding by the hash of the value found under given key
+// (example: "trace_id"). This way sampling is consistent: logs with the same value
+// are always kept or always dropped, across services using the same key and rate.
+// A kept log is passed to the decorated formatter, a dropped one is not written at all.
+// Logs lacking the key are always kept.
+var HashSampleFormatter = func(formatter Formatter, key string, rate float64) Formatter {
+	threshold := uint64(min(max(rate, 0), 1) * hashSampleBuckets)
+
+	return func(w io.Writer, keyValues []any) error {
+		for idx := 0; idx < len(keyValues)-1; idx += 2 {
+			if keyValues[idx] != key {
+				continue
+			}
+			hasher := fnv.New64a()
+			_, _ = hasher.Write([]byte(stringify(keyValues[idx+1])))
+			if hasher.Sum64()%hashSampleBuckets >= threshold {
+				return nil // log is dropped.
+			}
+
+			break
+		}
+
+		return formatter(w, keyValues)
+	}
+}
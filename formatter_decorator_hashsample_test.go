@@ -0,0 +1,146 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestHashSampleFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("kept fraction approximates rate", testHashSampleFormatterKeptFraction)
+	t.Run("sampling is deterministic", testHashSampleFormatterDeterministic)
+	t.Run("logs without key are kept", testHashSampleFormatterKeepsLogsWithoutKey)
+	t.Run("rate bounds", testHashSampleFormatterRateBounds)
+	t.Run("inner formatter error is returned", testHashSampleFormatterReturnsErr)
+}
+
+func testHashSampleFormatterKeptFraction(t *testing.T) {
+	t.Parallel()
+
+	rates := [...]float64{0.1, 0.25, 0.5, 0.9}
+	for _, rateData := range rates {
+		rate := rateData // capture range variable
+		t.Run(strconv.FormatFloat(rate, 'f', -1, 64), func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				formatter = new(MockFormatter)
+				subject   = xlog.HashSampleFormatter(formatter.Format, "trace_id", rate)
+				idsNo     = 20000
+			)
+
+			// act
+			for i := 0; i < idsNo; i++ {
+				_ = subject(io.Discard, []any{xlog.MessageKey, "foo", "trace_id", "trace-" + strconv.Itoa(i)})
+			}
+
+			// assert
+			keptFraction := float64(formatter.FormatCallsCount()) / float64(idsNo)
+			assertTrue(t, math.Abs(keptFraction-rate) < 0.02)
+		})
+	}
+}
+
+func testHashSampleFormatterDeterministic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter1 = new(MockFormatter)
+		formatter2 = new(MockFormatter)
+		subject1   = xlog.HashSampleFormatter(formatter1.Format, "trace_id", 0.5)
+		subject2   = xlog.HashSampleFormatter(formatter2.Format, "trace_id", 0.5) // another "service".
+		idsNo      = 100
+	)
+
+	for i := 0; i < idsNo; i++ {
+		traceID := "trace-" + strconv.Itoa(i)
+		keptCount1 := formatter1.FormatCallsCount()
+		keptCount2 := formatter2.FormatCallsCount()
+
+		// act
+		for j := 0; j < 3; j++ {
+			_ = subject1(io.Discard, []any{xlog.MessageKey, "foo", "trace_id", traceID})
+		}
+		_ = subject2(io.Discard, []any{"trace_id", traceID, xlog.MessageKey, "bar"})
+
+		// assert
+		kept1 := formatter1.FormatCallsCount() - keptCount1
+		kept2 := formatter2.FormatCallsCount() - keptCount2
+		assertTrue(t, kept1 == 0 || kept1 == 3)
+		assertEqual(t, kept1 == 3, kept2 == 1)
+	}
+}
+
+func testHashSampleFormatterKeepsLogsWithoutKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.HashSampleFormatter(formatter.Format, "trace_id", 0)
+	)
+
+	// act
+	_ = subject(io.Discard, []any{xlog.MessageKey, "foo"})
+	_ = subject(io.Discard, []any{xlog.MessageKey, "foo", "trace_id"})
+	_ = subject(io.Discard, []any{xlog.MessageKey, "foo", "trace_id", "abc"})
+
+	// assert
+	assertEqual(t, 2, formatter.FormatCallsCount())
+}
+
+func testHashSampleFormatterRateBounds(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatterAll  = new(MockFormatter)
+		formatterNone = new(MockFormatter)
+		subjectAll    = xlog.HashSampleFormatter(formatterAll.Format, "trace_id", 1.5)
+		subjectNone   = xlog.HashSampleFormatter(formatterNone.Format, "trace_id", -1)
+		idsNo         = 1000
+	)
+
+	// act
+	for i := 0; i < idsNo; i++ {
+		keyValues := []any{"trace_id", i}
+		_ = subjectAll(io.Discard, keyValues)
+		_ = subjectNone(io.Discard, keyValues)
+	}
+
+	// assert
+	assertEqual(t, idsNo, formatterAll.FormatCallsCount())
+	assertEqual(t, 0, formatterNone.FormatCallsCount())
+}
+
+func testHashSampleFormatterReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.HashSampleFormatter(formatter.Format, "trace_id", 1)
+	)
+	formatter.SetFormatCallback(func(io.Writer, []any) error {
+		return ErrFormat
+	})
+
+	// act
+	err := subject(io.Discard, []any{"trace_id", "abc"})
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrFormat))
+}
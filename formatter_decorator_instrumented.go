@@ -0,0 +1,29 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+	"time"
+)
+
+// InstrumentedFormatter is a decorator which measures the cost of the decorated formatter,
+// for capacity planning. For each log, given observe callback is called with the duration
+// of the decorated formatter's call, the no. of bytes it wrote and the error it returned
+// (if any), so that they can be reported as metrics, for example.
+// Note: the duration includes the writing time, as formatters write directly to the writer;
+// decorate a formatter writing to a buffered writer to measure mostly the formatting cost.
+// The observe callback is called synchronously, it should be fast.
+var InstrumentedFormatter = func(inner Formatter, observe func(d time.Duration, n int, err error)) Formatter {
+	return func(w io.Writer, keyValues []any) error {
+		cw := NewCountingWriter(w)
+		start := time.Now()
+		err := inner(cw, keyValues)
+		observe(time.Since(start), int(cw.BytesWritten()), err)
+
+		return err
+	}
+}
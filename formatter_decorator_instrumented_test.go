@@ -0,0 +1,85 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestInstrumentedFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("duration and written bytes are observed", testInstrumentedFormatterObserves)
+	t.Run("errors are observed and returned", testInstrumentedFormatterObservesErr)
+}
+
+func testInstrumentedFormatterObserves(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf           bytes.Buffer
+		observedCalls int
+		observedDur   time.Duration
+		observedBytes int
+		observedErr   error
+		formatter     = new(MockFormatter)
+		sleep         = 5 * time.Millisecond
+		observe       = func(d time.Duration, n int, err error) {
+			observedCalls++
+			observedDur, observedBytes, observedErr = d, n, err
+		}
+		subject = xlog.InstrumentedFormatter(formatter.Format, observe)
+	)
+	formatter.SetFormatCallback(func(w io.Writer, keyValues []any) error {
+		time.Sleep(sleep)
+
+		return xlog.JSONFormatter(w, keyValues)
+	})
+
+	// act
+	err := subject(&buf, []any{xlog.MessageKey, "Hello World", "year", 2022})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, formatter.FormatCallsCount())
+	assertEqual(t, "{\"msg\":\"Hello World\",\"year\":2022}\n", buf.String())
+	assertEqual(t, 1, observedCalls)
+	assertTrue(t, observedDur >= sleep)
+	assertEqual(t, buf.Len(), observedBytes)
+	assertNil(t, observedErr)
+}
+
+func testInstrumentedFormatterObservesErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer        = new(MockWriter)
+		observedDur   time.Duration
+		observedBytes = -1
+		observedErr   error
+		observe       = func(d time.Duration, n int, err error) {
+			observedDur, observedBytes, observedErr = d, n, err
+		}
+		subject = xlog.InstrumentedFormatter(xlog.JSONFormatter, observe)
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	err := subject(writer, []any{xlog.MessageKey, "Hello World"})
+
+	// assert
+	assertEqual(t, ErrWrite, err)
+	assertEqual(t, ErrWrite, observedErr)
+	assertEqual(t, 0, observedBytes)
+	assertTrue(t, observedDur > 0)
+}
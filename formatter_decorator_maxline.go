@@ -0,0 +1,84 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"bytes"
+	"io"
+	"sort"
+)
+
+// TruncatedFieldsKey is the key under which [MaxLineFormatter] reports
+// the no. of dropped fields.
+const TruncatedFieldsKey = "_truncated_fields"
+
+// MaxLineFormatter is a decorator which caps the size of a formatted log line
+// to maxBytes.
+// If the decorated formatter's output exceeds the limit, user fields are dropped,
+// starting with the last logged ones, until the line fits, and the no. of dropped
+// fields is reported under [TruncatedFieldsKey].
+// Time, level, source (keys are taken from given options) and message ([MessageKey])
+// fields are always preserved, so if they alone exceed the limit, the line
+// is written anyway, with all user fields dropped.
+// It can be useful for backends which reject log lines over a certain size.
+var MaxLineFormatter = func(formatter Formatter, maxBytes int, opts *CommonOpts) Formatter {
+	return func(w io.Writer, keyValues []any) error {
+		keyValues = AppendNoValue(keyValues)
+
+		buf := bufPool.Get().(*bytes.Buffer)
+		buf.Reset()
+		defer bufPool.Put(buf)
+
+		if err := formatter(buf, keyValues); err != nil {
+			return err
+		}
+		if buf.Len() <= maxBytes {
+			_, err := w.Write(buf.Bytes())
+
+			return err
+		}
+
+		// separate essential fields from user ones.
+		essentialKeyVals := make([]any, 0, 10)
+		userKeyVals := make([]any, 0, len(keyValues))
+		for idx := 0; idx < len(keyValues); idx += 2 {
+			switch keyValues[idx] {
+			case opts.TimeKey, opts.LevelKey, opts.SourceKey, MessageKey:
+				essentialKeyVals = append(essentialKeyVals, keyValues[idx], keyValues[idx+1])
+			default:
+				userKeyVals = append(userKeyVals, keyValues[idx], keyValues[idx+1])
+			}
+		}
+
+		// binary search the max no. of user fields that fit.
+		var (
+			userFieldsNo = len(userKeyVals) / 2
+			keyVals      = make([]any, 0, len(essentialKeyVals)+len(userKeyVals)+2)
+			formatErr    error
+		)
+		format := func(keptFieldsNo int) {
+			keyVals = append(keyVals[:0], essentialKeyVals...)
+			keyVals = append(keyVals, userKeyVals[:2*keptFieldsNo]...)
+			keyVals = append(keyVals, TruncatedFieldsKey, userFieldsNo-keptFieldsNo)
+			buf.Reset()
+			formatErr = formatter(buf, keyVals) // reset on each probe, a smaller no. of fields may format fine.
+		}
+		keptFieldsNo := sort.Search(userFieldsNo, func(n int) bool {
+			// find the first no. of fields which does not fit.
+			format(n + 1)
+
+			return formatErr != nil || buf.Len() > maxBytes
+		})
+		format(keptFieldsNo)
+		if formatErr != nil {
+			return formatErr
+		}
+
+		_, err := w.Write(buf.Bytes())
+
+		return err
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestMaxLineFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("line under limit is untouched", testMaxLineFormatterUnderLimit)
+	t.Run("overflowing fields are dropped", testMaxLineFormatterDropsFields)
+	t.Run("essential fields are always kept", testMaxLineFormatterKeepsEssentialFields)
+	t.Run("inner formatter error is returned", testMaxLineFormatterReturnsFormatErr)
+	t.Run("inner formatter error for more fields is not sticky", testMaxLineFormatterRecoversFromProbeErr)
+	t.Run("write error is returned", testMaxLineFormatterReturnsWriteErr)
+}
+
+func testMaxLineFormatterUnderLimit(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject   = xlog.MaxLineFormatter(xlog.LogfmtFormatter, 100, xlog.NewCommonOpts())
+		keyValues = []any{"date", staticTime, "lvl", "INFO", "foo", "bar"}
+		writer    bytes.Buffer
+	)
+
+	// act
+	resultErr := subject(&writer, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, "date="+staticTime+" lvl=INFO foo=bar\n", writer.String())
+}
+
+func testMaxLineFormatterDropsFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject   = xlog.MaxLineFormatter(xlog.LogfmtFormatter, 110, xlog.NewCommonOpts())
+		keyValues = []any{
+			"date", staticTime,
+			"lvl", "INFO",
+			"first", strings.Repeat("a", 10),
+			"second", strings.Repeat("b", 10),
+			"third", strings.Repeat("c", 50),
+			"fourth", "d",
+			xlog.MessageKey, "Hello World",
+			"src", "/file.go:10",
+		}
+		expected = "date=" + staticTime + " lvl=INFO msg=\"Hello World\" src=/file.go:10 " +
+			"first=aaaaaaaaaa _truncated_fields=3\n"
+		writer bytes.Buffer
+	)
+
+	// act
+	resultErr := subject(&writer, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, expected, writer.String())
+	assertTrue(t, writer.Len() <= 110)
+}
+
+func testMaxLineFormatterKeepsEssentialFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		opts      = xlog.NewCommonOpts()
+		subject   = xlog.MaxLineFormatter(xlog.JSONFormatter, 50, opts)
+		keyValues = []any{
+			"date", staticTime,
+			"lvl", "ERROR",
+			"src", "/file.go:10",
+			xlog.MessageKey, strings.Repeat("m", 50),
+			"foo", "bar",
+			"baz", 1,
+		}
+		writer bytes.Buffer
+	)
+
+	// act
+	resultErr := subject(&writer, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	var kvMap map[string]any
+	if err := json.Unmarshal(writer.Bytes(), &kvMap); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, 5, len(kvMap))
+	assertEqual(t, staticTime, kvMap["date"])
+	assertEqual(t, "ERROR", kvMap["lvl"])
+	assertEqual(t, "/file.go:10", kvMap["src"])
+	assertEqual(t, strings.Repeat("m", 50), kvMap[xlog.MessageKey])
+	assertEqual(t, float64(2), kvMap[xlog.TruncatedFieldsKey])
+}
+
+func testMaxLineFormatterReturnsFormatErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.MaxLineFormatter(formatter.Format, 10, xlog.NewCommonOpts())
+	)
+	formatter.SetFormatCallback(FormatCallbackErr)
+
+	// act
+	resultErr := subject(io.Discard, []any{"foo", "bar"})
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, ErrFormat))
+	assertEqual(t, 1, formatter.FormatCallsCount())
+
+	// arrange - error occurs only when dropping fields.
+	formatter.SetFormatCallback(func(w io.Writer, keyValues []any) error {
+		if formatter.FormatCallsCount() > 2 {
+			return ErrFormat
+		}
+
+		return xlog.LogfmtFormatter(w, keyValues)
+	})
+
+	// act
+	resultErr = subject(io.Discard, []any{"foo", "bar", "baz", "qux"})
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, ErrFormat))
+}
+
+func testMaxLineFormatterRecoversFromProbeErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = func(w io.Writer, keyValues []any) error {
+			// fails for truncated logs still having "third" field.
+			var hasThird, isTruncated bool
+			for idx := 0; idx < len(keyValues); idx += 2 {
+				hasThird = hasThird || keyValues[idx] == "third"
+				isTruncated = isTruncated || keyValues[idx] == xlog.TruncatedFieldsKey
+			}
+			if hasThird && isTruncated {
+				return errors.New("intentionally triggered format error")
+			}
+
+			return xlog.LogfmtFormatter(w, keyValues)
+		}
+		subject   = xlog.MaxLineFormatter(formatter, 100, xlog.NewCommonOpts())
+		keyValues = []any{
+			"first", "a",
+			"second", "b",
+			"third", strings.Repeat("c", 100),
+		}
+		writer bytes.Buffer
+	)
+
+	// act
+	resultErr := subject(&writer, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, "first=a second=b _truncated_fields=1\n", writer.String())
+}
+
+func testMaxLineFormatterReturnsWriteErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xlog.MaxLineFormatter(xlog.LogfmtFormatter, 10, xlog.NewCommonOpts())
+		writer  = new(MockWriter)
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	resultErr := subject(writer, []any{"foo", "bar"})
+	resultErr2 := subject(writer, []any{"foo", "bar", "baz", "qux"})
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, ErrWrite))
+	assertTrue(t, errors.Is(resultErr2, ErrWrite))
+	assertEqual(t, 2, writer.WriteCallsCount())
+}
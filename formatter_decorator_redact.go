@@ -0,0 +1,197 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue is the mask a string field tagged with `log:"redact"`
+// is replaced with, by [RedactStructFormatter].
+const RedactedValue = "***"
+
+const (
+	// the struct tag key a field is marked for redaction with.
+	redactTagKey = "log"
+	// the struct tag value a field is marked for redaction with.
+	redactTagValue = "redact"
+	// max nesting level of structs inspected for redaction.
+	defaultRedactMaxDepth = 5
+)
+
+// RedactStructFormatter is a decorator which masks the struct fields tagged with
+// `log:"redact"` before passing key-values to the decorated formatter.
+// It is useful when logging a whole request / config struct as a single value.
+// String fields are replaced with [RedactedValue], fields of other types with their
+// zero value. Logged values are not altered, a copy of them is redacted.
+// Struct values and pointers to structs are inspected, including nested ones
+// (found in exported fields, slices, arrays, maps' values), up to 5 levels deep.
+// Only exported fields can be redacted.
+//
+// Example:
+//
+//	type Credentials struct {
+//		User     string
+//		Password string `log:"redact"`
+//	}
+//	logger.Info(xlog.MessageKey, "login", "credentials", Credentials{User: "john", Password: "s3cr3t"})
+//	// logs {"credentials":{"User":"john","Password":"***"},"msg":"login"} with a JSON formatter.
+var RedactStructFormatter = func(formatter Formatter) Formatter {
+	return func(w io.Writer, keyValues []any) error {
+		var redacted []any
+		for idx := 1; idx < len(keyValues); idx += 2 {
+			if keyValues[idx] == nil {
+				continue
+			}
+			value, ok := redactValue(reflect.ValueOf(keyValues[idx]), defaultRedactMaxDepth)
+			if !ok {
+				continue
+			}
+			if redacted == nil { // do not alter original key-values.
+				redacted = make([]any, len(keyValues))
+				copy(redacted, keyValues)
+			}
+			redacted[idx] = value.Interface()
+		}
+		if redacted == nil {
+			return formatter(w, keyValues)
+		}
+
+		return formatter(w, redacted)
+	}
+}
+
+// redactValue returns a copy of given struct / pointer to struct value,
+// with tagged fields masked, and true, or false, if there is nothing to redact.
+func redactValue(rv reflect.Value, depth int) (reflect.Value, bool) {
+	if depth <= 0 {
+		return rv, false
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return rv, false
+		}
+		elem, ok := redactValue(rv.Elem(), depth)
+		if !ok {
+			return rv, false
+		}
+		if rv.Kind() == reflect.Interface {
+			return elem, true
+		}
+		ptr := reflect.New(elem.Type())
+		ptr.Elem().Set(elem)
+
+		return ptr, true
+	case reflect.Struct:
+		var (
+			rt      = rv.Type()
+			copyVal reflect.Value
+		)
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			var newFieldVal reflect.Value
+			if isRedactTag(field.Tag.Get(redactTagKey)) {
+				newFieldVal = maskedValue(field.Type)
+			} else if fieldVal, ok := redactValue(rv.Field(i), depth-1); ok {
+				newFieldVal = fieldVal
+			} else {
+				continue
+			}
+			if !copyVal.IsValid() {
+				copyVal = reflect.New(rt).Elem()
+				copyVal.Set(rv)
+			}
+			copyVal.Field(i).Set(newFieldVal)
+		}
+		if !copyVal.IsValid() {
+			return rv, false
+		}
+
+		return copyVal, true
+	case reflect.Slice, reflect.Array:
+		return redactElems(rv, depth)
+	case reflect.Map:
+		return redactMap(rv, depth)
+	}
+
+	return rv, false
+}
+
+// redactElems returns a copy of given slice / array value,
+// with elements' tagged fields masked, and true, or false, if there is nothing to redact.
+func redactElems(rv reflect.Value, depth int) (reflect.Value, bool) {
+	var copyVal reflect.Value
+	for i := 0; i < rv.Len(); i++ {
+		elem, ok := redactValue(rv.Index(i), depth-1)
+		if !ok {
+			continue
+		}
+		if !copyVal.IsValid() {
+			if rv.Kind() == reflect.Slice {
+				copyVal = reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+				reflect.Copy(copyVal, rv)
+			} else {
+				copyVal = reflect.New(rv.Type()).Elem()
+				copyVal.Set(rv)
+			}
+		}
+		copyVal.Index(i).Set(elem)
+	}
+	if !copyVal.IsValid() {
+		return rv, false
+	}
+
+	return copyVal, true
+}
+
+// redactMap returns a copy of given map value,
+// with values' tagged fields masked, and true, or false, if there is nothing to redact.
+func redactMap(rv reflect.Value, depth int) (reflect.Value, bool) {
+	var copyVal reflect.Value
+	iter := rv.MapRange()
+	for iter.Next() {
+		elem, ok := redactValue(iter.Value(), depth-1)
+		if !ok {
+			continue
+		}
+		if !copyVal.IsValid() {
+			copyVal = reflect.MakeMapWithSize(rv.Type(), rv.Len())
+			copyIter := rv.MapRange()
+			for copyIter.Next() {
+				copyVal.SetMapIndex(copyIter.Key(), copyIter.Value())
+			}
+		}
+		copyVal.SetMapIndex(iter.Key(), elem)
+	}
+	if !copyVal.IsValid() {
+		return rv, false
+	}
+
+	return copyVal, true
+}
+
+// isRedactTag returns true if given struct tag value marks a field for redaction.
+func isRedactTag(tagValue string) bool {
+	name, _, _ := strings.Cut(tagValue, ",")
+
+	return name == redactTagValue
+}
+
+// maskedValue returns the value a redacted field of given type is replaced with.
+func maskedValue(rt reflect.Type) reflect.Value {
+	if rt.Kind() == reflect.String {
+		return reflect.ValueOf(RedactedValue).Convert(rt)
+	}
+
+	return reflect.Zero(rt)
+}
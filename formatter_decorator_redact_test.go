@@ -0,0 +1,217 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+type redactCredentials struct {
+	User     string
+	Password string `log:"redact"`
+	PIN      int    `json:"pin" log:"redact,omitempty"`
+	secret   string `log:"redact"` // unexported, not serialized by JSON.
+}
+
+type redactRequest struct {
+	ID          int
+	Credentials redactCredentials
+	Backup      *redactCredentials
+	Extra       any
+	Token       secretToken `log:"redact"`
+}
+
+type secretToken string
+
+type redactNode struct {
+	Name   string `log:"redact"`
+	Parent *redactNode
+}
+
+func TestRedactStructFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tagged fields are masked", testRedactStructFormatterMasks)
+	t.Run("nested structs are masked", testRedactStructFormatterMasksNested)
+	t.Run("structs in collections are masked", testRedactStructFormatterMasksCollections)
+	t.Run("depth is limited", testRedactStructFormatterDepthLimit)
+	t.Run("other values are passed as they are", testRedactStructFormatterPassesOtherValues)
+	t.Run("inner formatter error is returned", testRedactStructFormatterReturnsErr)
+}
+
+func testRedactStructFormatterMasks(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		subject = xlog.RedactStructFormatter(xlog.JSONFormatter)
+		creds   = redactCredentials{User: "john", Password: "s3cr3t", PIN: 1234, secret: "x"}
+	)
+
+	// act
+	err := subject(&buf, []any{xlog.MessageKey, "login", "credentials", creds, "credentialsPtr", &creds})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		`{"credentials":{"User":"john","Password":"***","pin":0},`+
+			`"credentialsPtr":{"User":"john","Password":"***","pin":0},"msg":"login"}`+"\n",
+		buf.String(),
+	)
+	// original value is not altered.
+	assertEqual(t, redactCredentials{User: "john", Password: "s3cr3t", PIN: 1234, secret: "x"}, creds)
+}
+
+func testRedactStructFormatterMasksNested(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		subject = xlog.RedactStructFormatter(xlog.JSONFormatter)
+		req     = redactRequest{
+			ID:          7,
+			Credentials: redactCredentials{User: "john", Password: "s3cr3t"},
+			Backup:      &redactCredentials{User: "jane", Password: "p4ss"},
+			Extra:       redactCredentials{User: "jim", Password: "pwd"},
+			Token:       "tok",
+		}
+	)
+
+	// act
+	err := subject(&buf, []any{"req", req})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		`{"req":{"ID":7,"Credentials":{"User":"john","Password":"***","pin":0},`+
+			`"Backup":{"User":"jane","Password":"***","pin":0},`+
+			`"Extra":{"User":"jim","Password":"***","pin":0},"Token":"***"}}`+"\n",
+		buf.String(),
+	)
+	assertEqual(t, "p4ss", req.Backup.Password)
+}
+
+func testRedactStructFormatterMasksCollections(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		subject = xlog.RedactStructFormatter(xlog.JSONFormatter)
+		slice   = []redactCredentials{{User: "john", Password: "s3cr3t"}, {User: "jane", Password: "p4ss"}}
+		array   = [1]*redactCredentials{{User: "jim", Password: "pwd"}}
+		dict    = map[string]any{"primary": redactCredentials{User: "joe", Password: "pwd"}, "id": 7}
+		nested  = struct{ Users []redactCredentials }{Users: slice}
+	)
+
+	// act
+	err := subject(&buf, []any{"slice", slice, "array", array, "map", dict, "nested", nested})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		`{"array":[{"User":"jim","Password":"***","pin":0}],`+
+			`"map":{"id":7,"primary":{"User":"joe","Password":"***","pin":0}},`+
+			`"nested":{"Users":[{"User":"john","Password":"***","pin":0},{"User":"jane","Password":"***","pin":0}]},`+
+			`"slice":[{"User":"john","Password":"***","pin":0},{"User":"jane","Password":"***","pin":0}]}`+"\n",
+		buf.String(),
+	)
+	// original values are not altered.
+	assertEqual(t, "s3cr3t", slice[0].Password)
+	assertEqual(t, "pwd", array[0].Password)
+	assertEqual(t, redactCredentials{User: "joe", Password: "pwd"}, dict["primary"])
+}
+
+func testRedactStructFormatterDepthLimit(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.RedactStructFormatter(formatter.Format)
+		node      = &redactNode{Name: "level1"}
+	)
+	for i := 2; i <= 7; i++ {
+		node = &redactNode{Name: "level", Parent: node}
+	}
+	formatter.SetFormatCallback(func(_ io.Writer, keyValues []any) error {
+		current := keyValues[1].(*redactNode)
+		for level := 1; current != nil; level++ {
+			if level <= 5 {
+				assertEqual(t, xlog.RedactedValue, current.Name)
+			} else {
+				assertTrue(t, current.Name != xlog.RedactedValue)
+			}
+			current = current.Parent
+		}
+
+		return nil
+	})
+
+	// act
+	err := subject(io.Discard, []any{"node", node})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, formatter.FormatCallsCount())
+}
+
+func testRedactStructFormatterPassesOtherValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.RedactStructFormatter(formatter.Format)
+		nilCreds  *redactCredentials
+		keyValues = []any{
+			"str", "foo",
+			"int", 1,
+			"nil", nil,
+			"nilPtr", nilCreds,
+			"noTags", struct{ A string }{A: "a"},
+		}
+	)
+	formatter.SetFormatCallback(func(_ io.Writer, kv []any) error {
+		assertEqual(t, keyValues, kv)
+
+		return nil
+	})
+
+	// act
+	err := subject(io.Discard, keyValues)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, formatter.FormatCallsCount())
+}
+
+func testRedactStructFormatterReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.RedactStructFormatter(xlog.JSONFormatter)
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	err := subject(writer, []any{"credentials", redactCredentials{Password: "s3cr3t"}})
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+}
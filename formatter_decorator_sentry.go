@@ -19,9 +19,10 @@ var bufPool = sync.Pool{
 	},
 }
 
-// extractLevel searches for level label and returns its byte representation.
-func extractLevel(labeledLevels map[string]Level, levelKey string, keyValues []any) Level {
-	if lvl, found := labeledLevels[extractKeyValue(levelKey, keyValues).(string)]; found {
+// extractLevel searches for level value and returns the level it stands for
+// (see [levelsByValue]).
+func extractLevel(levels map[string]Level, levelKey string, keyValues []any) Level {
+	if lvl, found := levels[stringify(extractKeyValue(levelKey, keyValues))]; found {
 		return lvl
 	}
 
@@ -39,9 +40,22 @@ func extractKeyValue(key string, keyValues []any) any {
 	return ""
 }
 
+// sentryLogContextKey is the key of the Sentry context holding
+// the formatted log, for captured exceptions.
+const sentryLogContextKey = "log"
+
 // SentryFormatter is a decorator which sends another formatter 's output to Sentry.
 // The writer from the Logger should be io.Discard, as it uses internally a bytes.Buffer.
-var SentryFormatter = func(formatter Formatter, hub *sentry.Hub, opts *CommonOpts) Formatter {
+// For error and critical logs having an error under [ErrorKey], an exception
+// (with stacktrace, if available) is captured, with formatted log attached
+// as "log" context. Otherwise, formatted log is captured as a message.
+// Check for SentryFormatterWith* options to further customize it.
+var SentryFormatter = func(
+	formatter Formatter,
+	hub *sentry.Hub,
+	opts *CommonOpts,
+	sentryOpts ...SentryFormatterOption,
+) Formatter {
 	var (
 		mu             sync.Mutex
 		sentryLevelMap = map[Level]sentry.Level{
@@ -52,8 +66,14 @@ var SentryFormatter = func(formatter Formatter, hub *sentry.Hub, opts *CommonOpt
 			LevelCritical: sentry.LevelFatal,
 			LevelNone:     sentry.Level(""),
 		}
-		labeledLevels = flipLevelLabels(opts.LevelLabels)
+		levels = levelsByValue(opts)
+		cfg    sentryFormatterConfig
+		// breadcrumbs of this formatter, pending to be sent with next event.
+		breadcrumbs = newRing[*sentry.Breadcrumb](sentryMaxBreadcrumbs(hub))
 	)
+	for _, opt := range sentryOpts {
+		opt(&cfg)
+	}
 
 	return func(_ io.Writer, keyValues []any) error {
 		keyValues = AppendNoValue(keyValues)
@@ -66,14 +86,169 @@ var SentryFormatter = func(formatter Formatter, hub *sentry.Hub, opts *CommonOpt
 			return err
 		}
 
-		sentryLevel := sentryLevelMap[extractLevel(labeledLevels, opts.LevelKey, keyValues)]
+		lvl := extractLevel(levels, opts.LevelKey, keyValues)
+		sentryLevel := sentryLevelMap[lvl]
 
 		mu.Lock()
 		defer mu.Unlock()
 
-		hub.Scope().SetLevel(sentryLevel)
-		_ = hub.CaptureMessage(buf.String())
+		if cfg.isBreadcrumb(lvl) {
+			breadcrumbs.push(&sentry.Breadcrumb{
+				Type:      "default",
+				Category:  "log",
+				Message:   buf.String(),
+				Level:     sentryLevel,
+				Timestamp: now(),
+			})
+
+			return nil
+		}
+
+		// capture on a scope of its own, hub's scope is not altered.
+		hub.WithScope(func(scope *sentry.Scope) {
+			scope.SetLevel(sentryLevel)
+			pendingBreadcrumbs, _ := breadcrumbs.drain()
+			for _, breadcrumb := range pendingBreadcrumbs {
+				hub.AddBreadcrumb(breadcrumb, nil)
+			}
+
+			if cfg.structured() {
+				_ = hub.CaptureEvent(cfg.event(hub, sentryLevel, buf.String(), keyValues))
+			} else if err, isErr := extractKeyValue(ErrorKey, keyValues).(error); isErr && lvl >= LevelError {
+				scope.SetContext(sentryLogContextKey, sentry.Context{"message": buf.String()})
+				_ = hub.CaptureException(err)
+			} else {
+				_ = hub.CaptureMessage(buf.String())
+			}
+		})
 
 		return nil
 	}
 }
+
+// sentryFormatterConfig holds optional configurations for [SentryFormatter].
+type sentryFormatterConfig struct {
+	// max level logs are sent as breadcrumbs instead of events.
+	// can be set with [SentryFormatterWithBreadcrumbLevel] functional option.
+	breadcrumbMaxLevel *Level
+	// keys whose values become event's tags.
+	// can be set with [SentryFormatterWithOptions] functional option.
+	tags map[string]struct{}
+	// key whose error value becomes event's exception (defaults to [ErrorKey]
+	// if structured mapping is enabled).
+	// can be set with [SentryFormatterWithOptions] functional option.
+	exceptionKey string
+}
+
+// SentryFormatterOptions holds the configuration for mapping log's
+// key-values to structured Sentry event data.
+type SentryFormatterOptions struct {
+	// Tags are the keys whose values become event's tags (indexed, searchable).
+	Tags []string
+	// Exception is the key whose value, if it's an error, becomes event's
+	// exception (with stacktrace, if available).
+	// Defaults to [ErrorKey].
+	Exception string
+}
+
+// structured returns true if logs' key-values should be mapped
+// to structured event data.
+func (cfg sentryFormatterConfig) structured() bool {
+	return cfg.tags != nil
+}
+
+// event creates a Sentry event from a log.
+// Tag keys become event's tags, exception key's error becomes event's
+// exception and the rest of key-values become event's extra.
+func (cfg sentryFormatterConfig) event(
+	hub *sentry.Hub,
+	sentryLevel sentry.Level,
+	message string,
+	keyValues []any,
+) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Level = sentryLevel
+	event.Message = message
+	for idx := 0; idx < len(keyValues); idx += 2 {
+		key := stringify(keyValues[idx])
+		value := keyValues[idx+1]
+		if _, isTag := cfg.tags[key]; isTag {
+			event.Tags[key] = stringify(value)
+
+			continue
+		}
+		if key == cfg.exceptionKey {
+			if err, isErr := value.(error); isErr {
+				event.SetException(err, sentryMaxErrorDepth(hub))
+
+				continue
+			}
+		}
+		event.Extra[key] = valueForJSON(value) // errors, for example, would be serialized as {}.
+	}
+
+	return event
+}
+
+// sentryMaxErrorDepth returns the max depth errors chain is unwrapped
+// to, configured on hub's client.
+func sentryMaxErrorDepth(hub *sentry.Hub) int {
+	const defaultMaxErrorDepth = 10 // Sentry's default.
+	if client := hub.Client(); client != nil && client.Options().MaxErrorDepth > 0 {
+		return client.Options().MaxErrorDepth
+	}
+
+	return defaultMaxErrorDepth
+}
+
+// sentryMaxBreadcrumbs returns the max no. of breadcrumbs kept,
+// configured on hub's client.
+func sentryMaxBreadcrumbs(hub *sentry.Hub) int {
+	const defaultMaxBreadcrumbs = 30 // Sentry's default.
+	if client := hub.Client(); client != nil && client.Options().MaxBreadcrumbs > 0 {
+		return client.Options().MaxBreadcrumbs
+	}
+
+	return defaultMaxBreadcrumbs
+}
+
+// isBreadcrumb returns true if a log with given level should be sent as a breadcrumb.
+func (cfg sentryFormatterConfig) isBreadcrumb(lvl Level) bool {
+	return cfg.breadcrumbMaxLevel != nil && lvl != LevelNone && lvl <= *cfg.breadcrumbMaxLevel
+}
+
+// SentryFormatterOption defines optional function for configuring
+// a Sentry formatter.
+type SentryFormatterOption func(*sentryFormatterConfig)
+
+// SentryFormatterWithBreadcrumbLevel sets the max level logs are sent
+// to Sentry as breadcrumbs, instead of events.
+// Accumulated breadcrumbs are sent along with the next event
+// (a log with a greater level), reducing Sentry events volume, while
+// preserving context. They are kept by the formatter itself, hub's
+// breadcrumbs (added by other integrations) are not cleared.
+// Example: if set to [LevelInfo], debug and info logs become breadcrumbs,
+// an error log is captured as an event, carrying them.
+// By default, all logs are sent as events.
+func SentryFormatterWithBreadcrumbLevel(maxLvl Level) SentryFormatterOption {
+	return func(cfg *sentryFormatterConfig) {
+		cfg.breadcrumbMaxLevel = &maxLvl
+	}
+}
+
+// SentryFormatterWithOptions sets the mapping of log's key-values
+// to structured Sentry event data: tags, exception.
+// Key-values which are not mapped to tags / exception become event's extra.
+// By default, only the formatted log is sent, as event's message.
+func SentryFormatterWithOptions(sentryOpts SentryFormatterOptions) SentryFormatterOption {
+	return func(cfg *sentryFormatterConfig) {
+		cfg.tags = make(map[string]struct{}, len(sentryOpts.Tags))
+		for _, tag := range sentryOpts.Tags {
+			cfg.tags[tag] = struct{}{}
+		}
+		cfg.exceptionKey = sentryOpts.Exception
+		if cfg.exceptionKey == "" {
+			cfg.exceptionKey = ErrorKey
+		}
+	}
+}
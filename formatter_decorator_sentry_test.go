@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -127,6 +128,33 @@ func TestSentryFormatter_successfullySendsDataToSentry(t *testing.T) {
 	}
 }
 
+func TestSentryFormatter_numericLevels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		sentryHub  = setUpSentryHub()
+		commOpts   = xlog.NewCommonOpts()
+		sentLevels []sentry.Level
+	)
+	commOpts.LevelFormatter = xlog.NumericLevelFormatter
+	sentryHub.Scope().AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		sentLevels = append(sentLevels, event.Level)
+
+		return event
+	})
+	subject := xlog.SentryFormatter(xlog.JSONFormatter, sentryHub, commOpts)
+
+	// act
+	errWarn := subject(io.Discard, commOpts.WithDefaultKeyValues(xlog.LevelWarning, xlog.MessageKey, "foo"))
+	errCrit := subject(io.Discard, commOpts.WithDefaultKeyValues(xlog.LevelCritical, xlog.MessageKey, "bar"))
+
+	// assert
+	assertNil(t, errWarn)
+	assertNil(t, errCrit)
+	assertEqual(t, []sentry.Level{sentry.LevelWarning, sentry.LevelFatal}, sentLevels)
+}
+
 func TestSentryFormatter_returnsErrFromFormatter(t *testing.T) {
 	t.Parallel()
 
@@ -159,6 +187,336 @@ func TestSentryFormatter_returnsErrFromFormatter(t *testing.T) {
 	assertEqual(t, 0, scopeMessageProcessedCallsCnt)
 }
 
+func TestSentryFormatter_capturesException(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		sentryHub = setUpSentryHub()
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.SentryFormatter(
+			xlog.LogfmtFormatter,
+			sentryHub,
+			commOpts,
+		)
+		someErr = errors.New("could not connect to db")
+		events  []*sentry.Event
+	)
+	sentryHub.Scope().AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		events = append(events, event)
+
+		return event
+	})
+
+	// act
+	errError := subject(io.Discard, []any{commOpts.LevelKey, "ERROR", xlog.ErrorKey, someErr})
+	errCritical := subject(io.Discard, []any{commOpts.LevelKey, "CRITICAL", xlog.ErrorKey, someErr})
+	errWarn := subject(io.Discard, []any{commOpts.LevelKey, "WARN", xlog.ErrorKey, someErr})
+	errNotAnErr := subject(io.Discard, []any{commOpts.LevelKey, "ERROR", xlog.ErrorKey, "not an error"})
+
+	// assert
+	assertNil(t, errError)
+	assertNil(t, errCritical)
+	assertNil(t, errWarn)
+	assertNil(t, errNotAnErr)
+	if assertEqual(t, 4, len(events)) {
+		for idx, expectedLevel := range []sentry.Level{sentry.LevelError, sentry.LevelFatal} {
+			event := events[idx]
+			assertEqual(t, expectedLevel, event.Level)
+			assertEqual(t, "", event.Message)
+			if assertEqual(t, 1, len(event.Exception)) {
+				assertEqual(t, someErr.Error(), event.Exception[0].Value)
+			}
+			assertTrue(t, strings.Contains(event.Contexts["log"]["message"].(string), `err="could not connect to db"`))
+		}
+		// fallback to message.
+		assertEqual(t, sentry.LevelWarning, events[2].Level)
+		assertEqual(t, 0, len(events[2].Exception))
+		assertEqual(t, `lvl=WARN err="could not connect to db"`+"\n", events[2].Message)
+		_, found := events[2].Contexts["log"]
+		assertFalse(t, found) // hub's scope is not altered.
+		assertEqual(t, sentry.LevelError, events[3].Level)
+		assertEqual(t, 0, len(events[3].Exception))
+		assertEqual(t, `lvl=ERROR err="not an error"`+"\n", events[3].Message)
+	}
+}
+
+func TestSentryFormatter_withBreadcrumbLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		sentryHub = setUpSentryHub()
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.SentryFormatter(
+			xlog.LogfmtFormatter,
+			sentryHub,
+			commOpts,
+			xlog.SentryFormatterWithBreadcrumbLevel(xlog.LevelInfo),
+		)
+		events []*sentry.Event
+	)
+	sentryHub.Scope().AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		events = append(events, event)
+
+		return event
+	})
+
+	// act
+	errDebug := subject(io.Discard, []any{commOpts.LevelKey, "DEBUG", xlog.MessageKey, "debug log"})
+	errInfo := subject(io.Discard, []any{commOpts.LevelKey, "INFO", xlog.MessageKey, "info log"})
+
+	// assert
+	assertNil(t, errDebug)
+	assertNil(t, errInfo)
+	assertEqual(t, 0, len(events))
+
+	// act
+	errError := subject(io.Discard, []any{commOpts.LevelKey, "ERROR", xlog.MessageKey, "error log"})
+
+	// assert
+	assertNil(t, errError)
+	if assertEqual(t, 1, len(events)) {
+		assertEqual(t, "lvl=ERROR msg=\"error log\"\n", events[0].Message)
+		assertEqual(t, sentry.LevelError, events[0].Level)
+		if assertEqual(t, 2, len(events[0].Breadcrumbs)) {
+			assertEqual(t, "lvl=DEBUG msg=\"debug log\"\n", events[0].Breadcrumbs[0].Message)
+			assertEqual(t, sentry.LevelDebug, events[0].Breadcrumbs[0].Level)
+			assertEqual(t, "log", events[0].Breadcrumbs[0].Category)
+			assertEqual(t, "lvl=INFO msg=\"info log\"\n", events[0].Breadcrumbs[1].Message)
+			assertEqual(t, sentry.LevelInfo, events[0].Breadcrumbs[1].Level)
+		}
+	}
+
+	// act
+	errWarn := subject(io.Discard, []any{commOpts.LevelKey, "WARN", xlog.MessageKey, "warn log"})
+
+	// assert
+	assertNil(t, errWarn)
+	if assertEqual(t, 2, len(events)) {
+		assertEqual(t, sentry.LevelWarning, events[1].Level)
+		assertEqual(t, 0, len(events[1].Breadcrumbs)) // breadcrumbs were cleared after previous event.
+	}
+}
+
+func TestSentryFormatter_withBreadcrumbLevel_keepsHubBreadcrumbs(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		sentryHub = setUpSentryHub()
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.SentryFormatter(
+			xlog.LogfmtFormatter,
+			sentryHub,
+			commOpts,
+			xlog.SentryFormatterWithBreadcrumbLevel(xlog.LevelInfo),
+		)
+		events []*sentry.Event
+	)
+	sentryHub.Scope().AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		events = append(events, event)
+
+		return event
+	})
+	sentryHub.AddBreadcrumb(&sentry.Breadcrumb{Category: "http", Message: "GET /users"}, nil)
+
+	// act
+	errInfo := subject(io.Discard, []any{commOpts.LevelKey, "INFO", xlog.MessageKey, "info log"})
+	errError1 := subject(io.Discard, []any{commOpts.LevelKey, "ERROR", xlog.MessageKey, "error log 1"})
+	errError2 := subject(io.Discard, []any{commOpts.LevelKey, "ERROR", xlog.MessageKey, "error log 2"})
+
+	// assert
+	assertNil(t, errInfo)
+	assertNil(t, errError1)
+	assertNil(t, errError2)
+	if assertEqual(t, 2, len(events)) {
+		if assertEqual(t, 2, len(events[0].Breadcrumbs)) {
+			assertEqual(t, "GET /users", events[0].Breadcrumbs[0].Message)
+			assertEqual(t, "lvl=INFO msg=\"info log\"\n", events[0].Breadcrumbs[1].Message)
+		}
+		if assertEqual(t, 1, len(events[1].Breadcrumbs)) { // only formatter's breadcrumbs were sent once.
+			assertEqual(t, "GET /users", events[1].Breadcrumbs[0].Message)
+		}
+	}
+}
+
+func TestSentryFormatter_withOptions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		sentryHub = setUpSentryHub()
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.SentryFormatter(
+			xlog.LogfmtFormatter,
+			sentryHub,
+			commOpts,
+			xlog.SentryFormatterWithOptions(xlog.SentryFormatterOptions{
+				Tags:      []string{"requestID", "userID"},
+				Exception: xlog.ErrorKey,
+			}),
+		)
+		someErr = errors.New("could not connect to db")
+		events  []*sentry.Event
+	)
+	sentryHub.Scope().AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		events = append(events, event)
+
+		return event
+	})
+
+	// act
+	err := subject(io.Discard, []any{
+		commOpts.LevelKey, "ERROR",
+		xlog.MessageKey, "could not save user",
+		"requestID", "abc-123",
+		"userID", 7,
+		"attempt", 3,
+		xlog.ErrorKey, someErr,
+	})
+
+	// assert
+	assertNil(t, err)
+	if assertEqual(t, 1, len(events)) {
+		event := events[0]
+		assertEqual(t, sentry.LevelError, event.Level)
+		assertEqual(
+			t,
+			`lvl=ERROR msg="could not save user" requestID=abc-123 userID=7 attempt=3 err="could not connect to db"`+"\n",
+			event.Message,
+		)
+		assertEqual(t, "abc-123", event.Tags["requestID"])
+		assertEqual(t, "7", event.Tags["userID"])
+		assertEqual(t, "could not save user", event.Extra[xlog.MessageKey])
+		assertEqual(t, 3, event.Extra["attempt"])
+		assertEqual(t, "ERROR", event.Extra[commOpts.LevelKey])
+		_, found := event.Extra["requestID"]
+		assertFalse(t, found)
+		_, found = event.Extra[xlog.ErrorKey]
+		assertFalse(t, found)
+		if assertEqual(t, 1, len(event.Exception)) {
+			assertEqual(t, someErr.Error(), event.Exception[0].Value)
+			assertEqual(t, "*errors.errorString", event.Exception[0].Type)
+		}
+	}
+}
+
+func TestSentryFormatter_withOptions_nonErrorExceptionValueGoesToExtra(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		sentryHub = setUpSentryHub()
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.SentryFormatter(
+			xlog.JSONFormatter,
+			sentryHub,
+			commOpts,
+			xlog.SentryFormatterWithOptions(xlog.SentryFormatterOptions{Exception: xlog.ErrorKey}),
+		)
+		events []*sentry.Event
+	)
+	sentryHub.Scope().AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		events = append(events, event)
+
+		return event
+	})
+
+	// act
+	err := subject(io.Discard, []any{commOpts.LevelKey, "WARN", xlog.ErrorKey, "not an error"})
+
+	// assert
+	assertNil(t, err)
+	if assertEqual(t, 1, len(events)) {
+		assertEqual(t, 0, len(events[0].Exception))
+		assertEqual(t, "not an error", events[0].Extra[xlog.ErrorKey])
+		assertEqual(t, 0, len(events[0].Tags))
+	}
+}
+
+func TestSentryFormatter_withOptions_exceptionDefaultsToErrorKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		sentryHub = setUpSentryHub()
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.SentryFormatter(
+			xlog.LogfmtFormatter,
+			sentryHub,
+			commOpts,
+			xlog.SentryFormatterWithOptions(xlog.SentryFormatterOptions{Tags: []string{"requestID"}}),
+		)
+		someErr = errors.New("could not connect to db")
+		events  []*sentry.Event
+	)
+	sentryHub.Scope().AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		events = append(events, event)
+
+		return event
+	})
+
+	// act
+	err := subject(io.Discard, []any{
+		commOpts.LevelKey, "ERROR",
+		xlog.MessageKey, "could not save user",
+		"requestID", "abc-123",
+		xlog.ErrorKey, someErr,
+	})
+
+	// assert
+	assertNil(t, err)
+	if assertEqual(t, 1, len(events)) {
+		assertEqual(t, "abc-123", events[0].Tags["requestID"])
+		_, found := events[0].Extra[xlog.ErrorKey]
+		assertFalse(t, found)
+		if assertEqual(t, 1, len(events[0].Exception)) {
+			assertEqual(t, someErr.Error(), events[0].Exception[0].Value)
+		}
+	}
+}
+
+func TestSentryFormatter_withOptions_extraValuesAreJSONFriendly(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		sentryHub = setUpSentryHub()
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.SentryFormatter(
+			xlog.JSONFormatter,
+			sentryHub,
+			commOpts,
+			xlog.SentryFormatterWithOptions(xlog.SentryFormatterOptions{Tags: []string{"requestID"}}),
+		)
+		events []*sentry.Event
+	)
+	sentryHub.Scope().AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		events = append(events, event)
+
+		return event
+	})
+
+	// act
+	err := subject(io.Discard, []any{
+		commOpts.LevelKey, "ERROR",
+		"requestID", "abc-123",
+		"cause", errors.New("could not connect to db"), // not the exception key.
+		"timeout", 2 * time.Second,
+	})
+
+	// assert
+	assertNil(t, err)
+	if assertEqual(t, 1, len(events)) {
+		assertEqual(t, "could not connect to db", events[0].Extra["cause"])
+		assertEqual(t, "2s", events[0].Extra["timeout"])
+		extra, err := json.Marshal(events[0].Extra)
+		assertNil(t, err)
+		assertTrue(t, strings.Contains(string(extra), `"cause":"could not connect to db"`))
+	}
+}
+
 func TestSentryFormatter_concurrency(t *testing.T) {
 	t.Parallel()
 
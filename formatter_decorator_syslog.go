@@ -13,6 +13,7 @@ import (
 	"errors"
 	"io"
 	"log/syslog"
+	"sync"
 )
 
 // syslogWriter is an interface wrapping stdlib syslog Writer.
@@ -57,24 +58,33 @@ var ErrNotSyslogWriter = errors.New("the writer should be a *syslog.Writer")
 // SyslogLevelProvider is a function that extracts the syslog level.
 type SyslogLevelProvider func(keyValues []any) syslog.Priority
 
+// SyslogPrefixProvider is a function that returns the prefix to be written
+// with the current log.
+type SyslogPrefixProvider func(keyValues []any) string
+
 const noLevel = syslog.Priority(-100000)
 
 // NewDefaultSyslogLevelProvider returns a SyslogLevelProvider that maps xlog default Levels
 // to their appropriate syslog Levels.
 func NewDefaultSyslogLevelProvider(opts *CommonOpts) SyslogLevelProvider {
-	levelsMap := make(map[any]syslog.Priority, 5)
+	var (
+		levelsMap  = make(map[any]syslog.Priority, 10)
+		priorities = map[Level]syslog.Priority{
+			LevelDebug:    syslog.LOG_DEBUG,
+			LevelInfo:     syslog.LOG_INFO,
+			LevelWarning:  syslog.LOG_WARNING,
+			LevelError:    syslog.LOG_ERR,
+			LevelCritical: syslog.LOG_CRIT,
+		}
+	)
 	for lvl, label := range opts.LevelLabels {
-		switch lvl {
-		case LevelDebug:
-			levelsMap[label] = syslog.LOG_DEBUG
-		case LevelInfo:
-			levelsMap[label] = syslog.LOG_INFO
-		case LevelWarning:
-			levelsMap[label] = syslog.LOG_WARNING
-		case LevelError:
-			levelsMap[label] = syslog.LOG_ERR
-		case LevelCritical:
-			levelsMap[label] = syslog.LOG_CRIT
+		priority, found := priorities[lvl]
+		if !found {
+			continue
+		}
+		levelsMap[label] = priority
+		if opts.LevelFormatter != nil { // numeric levels, for example.
+			levelsMap[opts.LevelFormatter(lvl)] = priority
 		}
 	}
 
@@ -103,11 +113,23 @@ func NewExtractFromKeySyslogLevelProvider(
 // (maybe you want to support other syslog levels - for example nothing stops you from doing this:
 // logger.Log("lvl","NOTICE", ...) and map also "NOTICE" to [syslog.LOG_NOTICE]).
 // The third param is a prefix to be written with each log. You'll pass here empty string or [SyslogPrefixCee].
+// If the prefix should be decided per log, use [SyslogFormatterWithPrefixProvider] option.
+// Check for SyslogFormatterWith* options to further customize it.
 var SyslogFormatter = func(
 	formatter Formatter,
 	syslogLevelProvider SyslogLevelProvider,
 	prefix string,
+	syslogOpts ...SyslogFormatterOption,
 ) Formatter {
+	cfg := syslogFormatterConfig{pool: &bufPool}
+	for _, opt := range syslogOpts {
+		opt(&cfg)
+	}
+	prefixProvider := cfg.prefixProvider
+	if prefixProvider == nil {
+		prefixProvider = func([]any) string { return prefix }
+	}
+
 	return func(w io.Writer, keyValues []any) error {
 		sw, ok := w.(syslogWriter)
 		if !ok {
@@ -115,12 +137,12 @@ var SyslogFormatter = func(
 		}
 		keyValues = AppendNoValue(keyValues)
 
-		buf := bufPool.Get().(*bytes.Buffer)
-		buf.Reset()
-		defer bufPool.Put(buf)
+		buf := cfg.pool.Get().(*bytes.Buffer)
+		buf.Reset() // buffer is reset before each use, whatever path previous use ended on.
+		defer cfg.pool.Put(buf)
 
-		if prefix != "" {
-			_, _ = buf.WriteString(prefix)
+		if logPrefix := prefixProvider(keyValues); logPrefix != "" {
+			_, _ = buf.WriteString(logPrefix)
 		}
 
 		if err := formatter(buf, keyValues); err != nil {
@@ -152,3 +174,52 @@ var SyslogFormatter = func(
 		}
 	}
 }
+
+// syslogFormatterConfig holds optional configurations for [SyslogFormatter].
+type syslogFormatterConfig struct {
+	// pool of buffers logs are formatted into.
+	// can be set with [SyslogFormatterWithBufferSize] functional option.
+	pool *sync.Pool
+	// prefixProvider returns the prefix to be written with a log.
+	// can be set with [SyslogFormatterWithPrefixProvider] functional option.
+	prefixProvider SyslogPrefixProvider
+}
+
+// SyslogFormatterOption defines optional function for configuring
+// a syslog formatter.
+type SyslogFormatterOption func(*syslogFormatterConfig)
+
+// SyslogFormatterWithBufferSize sets a dedicated pool of buffers,
+// with given initial capacity, logs are formatted into.
+// Sizing buffers to your usual log length avoids buffers' growth
+// (allocations) in high throughput scenarios.
+// By default, a pool shared with other formatters (like [SentryFormatter])
+// is used.
+func SyslogFormatterWithBufferSize(size int) SyslogFormatterOption {
+	return func(cfg *syslogFormatterConfig) {
+		cfg.pool = &sync.Pool{
+			New: func() any {
+				return bytes.NewBuffer(make([]byte, 0, size))
+			},
+		}
+	}
+}
+
+// SyslogFormatterWithPrefixProvider sets a provider which decides the prefix
+// to be written with each log, overriding the fixed prefix passed to [SyslogFormatter].
+// It is useful in mixed pipelines, where only some logs (structured ones, for example)
+// should carry the [SyslogPrefixCee] marker.
+// Example:
+//
+//	xlog.SyslogFormatterWithPrefixProvider(func(keyValues []any) string {
+//		if len(keyValues) > 2 {
+//			return xlog.SyslogPrefixCee
+//		}
+//
+//		return ""
+//	})
+func SyslogFormatterWithPrefixProvider(provider SyslogPrefixProvider) SyslogFormatterOption {
+	return func(cfg *syslogFormatterConfig) {
+		cfg.prefixProvider = provider
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"github.com/actforgood/xlog"
+
+	"github.com/getsentry/sentry-go"
 )
 
 func ExampleSyncLogger_withSyslog() {
@@ -199,6 +201,72 @@ func TestSyslogFormatter_successfullyWritesKeyValuesNoLevel(t *testing.T) {
 	assertEqual(t, 1, writer.WriteCallsCount())
 }
 
+func TestNewDefaultSyslogLevelProvider_numericLevels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	commOpts := xlog.NewCommonOpts()
+	commOpts.LevelFormatter = xlog.NumericLevelFormatter
+	subject := xlog.NewDefaultSyslogLevelProvider(commOpts)
+
+	// act
+	resultErr := subject(commOpts.WithDefaultKeyValues(xlog.LevelError, xlog.MessageKey, "foo"))
+	resultDebug := subject(commOpts.WithDefaultKeyValues(xlog.LevelDebug, xlog.MessageKey, "foo"))
+	resultLabel := subject([]any{commOpts.LevelKey, "WARN"}) // labels are still recognized.
+
+	// assert
+	assertEqual(t, syslog.LOG_ERR, resultErr)
+	assertEqual(t, syslog.LOG_DEBUG, resultDebug)
+	assertEqual(t, syslog.LOG_WARNING, resultLabel)
+}
+
+func TestSyslogFormatter_withPrefixProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		commOpts = xlog.NewCommonOpts()
+		writer   = NewMockSyslogWriter()
+		subject  = xlog.SyslogFormatter(
+			xlog.JSONFormatter,
+			xlog.NewDefaultSyslogLevelProvider(commOpts),
+			"ignored prefix",
+			xlog.SyslogFormatterWithPrefixProvider(func(keyValues []any) string {
+				for idx := 0; idx < len(keyValues); idx += 2 {
+					if keyValues[idx] == "structured" && keyValues[idx+1] == true {
+						return xlog.SyslogPrefixCee
+					}
+				}
+
+				return ""
+			}),
+		)
+		msgs []string
+	)
+	writer.SetLogCallback(syslog.LOG_INFO, func(msg string) error {
+		msgs = append(msgs, msg)
+
+		return nil
+	})
+
+	// act
+	err1 := subject(writer, []any{commOpts.LevelKey, "INFO", "structured", true})
+	err2 := subject(writer, []any{commOpts.LevelKey, "INFO", "structured", false})
+
+	// assert
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertEqual(t, 2, writer.LogCallsCount(syslog.LOG_INFO))
+	assertEqual(
+		t,
+		[]string{
+			`@cee:{"lvl":"INFO","structured":true}` + "\n",
+			`{"lvl":"INFO","structured":false}` + "\n",
+		},
+		msgs,
+	)
+}
+
 func TestSyslogFormatter_returnsErrFromFormatter(t *testing.T) {
 	t.Parallel()
 
@@ -225,6 +293,57 @@ func TestSyslogFormatter_returnsErrFromFormatter(t *testing.T) {
 	assertEqual(t, 0, writer.WriteCallsCount())
 }
 
+func TestSyslogFormatter_withBufferSize_resetsBufferAfterError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		commOpts  = xlog.NewCommonOpts()
+		formatter = new(MockFormatter)
+		writer    = NewMockSyslogWriter()
+		subject   = xlog.SyslogFormatter(
+			formatter.Format,
+			xlog.NewDefaultSyslogLevelProvider(commOpts),
+			"",
+			xlog.SyslogFormatterWithBufferSize(64),
+		)
+		keyValues = []any{"foo", "bar"}
+	)
+	formatter.SetFormatCallback(func(w io.Writer, _ []any) error {
+		_, _ = w.Write([]byte("partially_formatted_"))
+
+		return ErrFormat
+	})
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		assertEqual(t, "some_kv_formatted", string(p))
+
+		return len(p), nil
+	})
+
+	// act
+	resultErr := subject(writer, keyValues)
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, ErrFormat))
+	assertEqual(t, 0, writer.WriteCallsCount())
+
+	// arrange
+	formatter.SetFormatCallback(func(w io.Writer, _ []any) error {
+		_, _ = w.Write([]byte("some_kv_formatted"))
+
+		return nil
+	})
+
+	// act
+	for i := 0; i < 10; i++ {
+		resultErr = subject(writer, keyValues)
+
+		// assert
+		assertNil(t, resultErr)
+	}
+	assertEqual(t, 10, writer.WriteCallsCount())
+}
+
 func TestSyslogFormatter_returnsErrNotSyslogWriter(t *testing.T) {
 	t.Parallel()
 
@@ -302,6 +421,92 @@ func TestSyslogFormatter_concurrency(t *testing.T) {
 	assertEqual(t, expectedSum, int(sum))
 }
 
+func TestSyslogFormatter_interleavedWithSentryFormatter_concurrency(t *testing.T) {
+	t.Parallel()
+
+	for _, testData := range [...]struct {
+		name       string
+		syslogOpts []xlog.SyslogFormatterOption
+	}{
+		{name: "shared buffers pool"},
+		{name: "dedicated buffers pool", syslogOpts: []xlog.SyslogFormatterOption{xlog.SyslogFormatterWithBufferSize(256)}},
+	} {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				commOpts      = xlog.NewCommonOpts()
+				writer        = NewMockSyslogWriter()
+				syslogSubject = xlog.SyslogFormatter(
+					xlog.JSONFormatter,
+					xlog.NewDefaultSyslogLevelProvider(commOpts),
+					"",
+					test.syslogOpts...,
+				)
+				sentryHub     = setUpSentryHub()
+				sentrySubject = xlog.SentryFormatter(
+					xlog.JSONFormatter,
+					sentryHub,
+					commOpts,
+				)
+				goroutinesNo       = 100
+				logsNo             = 10
+				wg                 sync.WaitGroup
+				syslogLogsCnt      int64
+				sentryLogsCnt      int64
+				assertNotCorrupted = func(source string, p []byte) {
+					var logData map[string]any
+					if err := json.Unmarshal(p, &logData); err != nil {
+						t.Errorf("corrupted log %q: %s", p, err.Error())
+
+						return
+					}
+					assertEqual(t, 3, len(logData))
+					assertEqual(t, source, logData["source"])
+				}
+			)
+			writer.SetWriteCallback(func(p []byte) (int, error) {
+				atomic.AddInt64(&syslogLogsCnt, 1)
+				assertNotCorrupted("syslog", p)
+
+				return len(p), nil
+			})
+			sentryHub.Scope().AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+				atomic.AddInt64(&sentryLogsCnt, 1)
+				assertNotCorrupted("sentry", []byte(event.Message))
+
+				return event
+			})
+
+			// act
+			for i := 0; i < goroutinesNo; i++ {
+				wg.Add(2)
+				go func(threadNo int) {
+					defer wg.Done()
+					for j := 0; j < logsNo; j++ {
+						resultErr := syslogSubject(writer, []any{"source", "syslog", "threadNo", threadNo, "logNo", j})
+						assertNil(t, resultErr)
+					}
+				}(i)
+				go func(threadNo int) {
+					defer wg.Done()
+					for j := 0; j < logsNo; j++ {
+						resultErr := sentrySubject(io.Discard, []any{"source", "sentry", "threadNo", threadNo, "logNo", j})
+						assertNil(t, resultErr)
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			// assert
+			assertEqual(t, int64(goroutinesNo*logsNo), atomic.LoadInt64(&syslogLogsCnt))
+			assertEqual(t, int64(goroutinesNo*logsNo), atomic.LoadInt64(&sentryLogsCnt))
+		})
+	}
+}
+
 func BenchmarkSyslogFormatter_json_syncLogger(b *testing.B) {
 	commonOpts := xlog.NewCommonOpts()
 	commonOpts.Source = xlog.SourceProvider(4, 1)
@@ -329,6 +534,34 @@ func BenchmarkSyslogFormatter_json_syncLogger(b *testing.B) {
 	}
 }
 
+func BenchmarkSyslogFormatter_json_syncLogger_withBufferSize(b *testing.B) {
+	commonOpts := xlog.NewCommonOpts()
+	commonOpts.Source = xlog.SourceProvider(4, 1)
+	writer := NopSyslogWriter{}
+	logger := xlog.NewSyncLogger(
+		writer,
+		xlog.SyncLoggerWithOptions(commonOpts),
+		xlog.SyncLoggerWithFormatter(xlog.SyslogFormatter(
+			xlog.JSONFormatter,
+			xlog.NewDefaultSyslogLevelProvider(commonOpts),
+			"",
+			xlog.SyslogFormatterWithBufferSize(1024),
+		)),
+	)
+	defer func() {
+		_ = logger.Close()
+		_ = writer.Close()
+	}()
+	kv := getBenchmarkKeyVals()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		logger.Error(kv...)
+	}
+}
+
 func BenchmarkSyslogFormatter_json_asyncLogger(b *testing.B) {
 	commonOpts := xlog.NewCommonOpts()
 	commonOpts.Source = xlog.SourceProvider(4, 1)
@@ -0,0 +1,108 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// TruncateOption defines optional function for configuring
+// a truncate formatter.
+type TruncateOption func(*truncateConfig)
+
+// truncateConfig holds the configuration of a truncate formatter.
+type truncateConfig struct {
+	maxValueLen int
+	maxMsgLen   int
+	suffix      string
+}
+
+// TruncateWithMessageLen sets a separate maximum length for the
+// value found under [MessageKey].
+// By default, the same maximum length as for the rest of values is used.
+func TruncateWithMessageLen(maxMsgLen int) TruncateOption {
+	return func(cfg *truncateConfig) {
+		cfg.maxMsgLen = maxMsgLen
+	}
+}
+
+// TruncateFormatter is a decorator which truncates values longer than
+// maxValueLen bytes, appending given suffix to them (example: "…(truncated)"),
+// before passing them to the decorated formatter.
+// It protects against oversized log lines, caused by a large payload
+// (stack dump, base64 blob, etc.).
+// Non-string values (numbers, Stringers, errors, etc.) whose string
+// representation exceeds the limit are replaced with their truncated
+// string representation.
+// Truncation is done at a valid UTF-8 boundary. Keys are never truncated.
+var TruncateFormatter = func(
+	formatter Formatter,
+	maxValueLen int,
+	suffix string,
+	opts ...TruncateOption,
+) Formatter {
+	cfg := truncateConfig{
+		maxValueLen: maxValueLen,
+		maxMsgLen:   maxValueLen,
+		suffix:      suffix,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cfg.maxValueLen = max(cfg.maxValueLen, 0)
+	cfg.maxMsgLen = max(cfg.maxMsgLen, 0)
+
+	return func(w io.Writer, keyValues []any) error {
+		keyValues = AppendNoValue(keyValues)
+
+		var truncated []any // lazily copied, if something needs to be truncated.
+		for idx := 1; idx < len(keyValues); idx += 2 {
+			maxLen := cfg.maxValueLen
+			if keyValues[idx-1] == MessageKey {
+				maxLen = cfg.maxMsgLen
+			}
+			value, isTruncated := truncateValue(keyValues[idx], maxLen, cfg.suffix)
+			if !isTruncated {
+				continue
+			}
+			if truncated == nil {
+				truncated = make([]any, len(keyValues))
+				copy(truncated, keyValues)
+			}
+			truncated[idx] = value
+		}
+		if truncated != nil {
+			keyValues = truncated
+		}
+
+		return formatter(w, keyValues)
+	}
+}
+
+// truncateValue returns the truncated string representation of the value,
+// if it exceeds given max length.
+func truncateValue(value any, maxLen int, suffix string) (string, bool) {
+	var str string
+	switch val := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		str = val
+	default:
+		str = stringify(val)
+	}
+	if len(str) <= maxLen {
+		return "", false
+	}
+
+	cutIdx := maxLen
+	for cutIdx > 0 && !utf8.RuneStart(str[cutIdx]) {
+		cutIdx--
+	}
+
+	return str[:cutIdx] + suffix, true
+}
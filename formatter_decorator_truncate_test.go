@@ -0,0 +1,182 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestTruncateFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("long values are truncated", testTruncateFormatterTruncatesLongValues)
+	t.Run("short values are untouched", testTruncateFormatterKeepsShortValues)
+	t.Run("message has its own limit", testTruncateFormatterWithMessageLen)
+	t.Run("multi-byte chars are not split", testTruncateFormatterKeepsValidUTF8)
+	t.Run("inner formatter error is returned", testTruncateFormatterReturnsErr)
+}
+
+func testTruncateFormatterTruncatesLongValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.TruncateFormatter(formatter.Format, 5, "…")
+		keyValues = []any{
+			"exact", "12345",
+			"over", "123456",
+			"stringer", dummyStringer{Name: "John"},
+			"err", errors.New("some long error"),
+			"slice", []int{1, 2, 3},
+			"int", 1234567890,
+			"float", 3.14,
+			"duration", 123456789 * time.Nanosecond,
+			"odd",
+		}
+		expectedKeyValues = []any{
+			"exact", "12345",
+			"over", "12345…",
+			"stringer", "dummy…",
+			"err", "some …",
+			"slice", "[1 2 …",
+			"int", "12345…",
+			"float", 3.14,
+			"duration", "123.4…",
+			"odd", "*NoVa…",
+		}
+	)
+	formatter.SetFormatCallback(func(_ io.Writer, keyValues []any) error {
+		assertEqual(t, expectedKeyValues, keyValues)
+
+		return nil
+	})
+
+	// act
+	resultErr := subject(io.Discard, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, 1, formatter.FormatCallsCount())
+	assertEqual(t, "123456", keyValues[3]) // input is not altered.
+}
+
+func testTruncateFormatterKeepsShortValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.TruncateFormatter(formatter.Format, 100, "…(truncated)")
+		keyValues = []any{
+			"foo", "bar",
+			"stringer", dummyStringer{Name: "John"},
+			"int", 10,
+			"nil", nil,
+		}
+	)
+	formatter.SetFormatCallback(func(_ io.Writer, kv []any) error {
+		assertEqual(t, keyValues, kv)
+		assertTrue(t, &keyValues[0] == &kv[0]) // no copy was made.
+
+		return nil
+	})
+
+	// act
+	resultErr := subject(io.Discard, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, 1, formatter.FormatCallsCount())
+}
+
+func testTruncateFormatterWithMessageLen(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.TruncateFormatter(
+			formatter.Format,
+			3,
+			"...",
+			xlog.TruncateWithMessageLen(10),
+		)
+		keyValues = []any{
+			xlog.MessageKey, "Hello World",
+			"foo", "foobar",
+		}
+		expectedKeyValues = []any{
+			xlog.MessageKey, "Hello Worl...",
+			"foo", "foo...",
+		}
+	)
+	formatter.SetFormatCallback(func(_ io.Writer, keyValues []any) error {
+		assertEqual(t, expectedKeyValues, keyValues)
+
+		return nil
+	})
+
+	// act
+	resultErr := subject(io.Discard, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, 1, formatter.FormatCallsCount())
+}
+
+func testTruncateFormatterKeepsValidUTF8(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.TruncateFormatter(formatter.Format, 4, "~")
+		keyValues = []any{"foo", "ăîâșț"} // each char has 2 bytes.
+	)
+	formatter.SetFormatCallback(func(_ io.Writer, keyValues []any) error {
+		assertEqual(t, []any{"foo", "ăî~"}, keyValues)
+
+		return nil
+	})
+	subjectOdd := xlog.TruncateFormatter(func(_ io.Writer, keyValues []any) error {
+		assertEqual(t, []any{"foo", "ăî~"}, keyValues)
+
+		return nil
+	}, 5, "~")
+
+	// act
+	resultErr := subject(io.Discard, keyValues)
+	resultErrOdd := subjectOdd(io.Discard, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertNil(t, resultErrOdd)
+	assertEqual(t, 1, formatter.FormatCallsCount())
+}
+
+func testTruncateFormatterReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.TruncateFormatter(formatter.Format, 1, "")
+	)
+	formatter.SetFormatCallback(FormatCallbackErr)
+
+	// act
+	resultErr := subject(io.Discard, []any{"foo", strings.Repeat("bar", 10)})
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, ErrFormat))
+}
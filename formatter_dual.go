@@ -0,0 +1,46 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"bytes"
+	"io"
+)
+
+// DualFormatter writes, on the same line, a log formatted with a human friendly formatter
+// (like [TextFormatter]) and with a structured one (like [JSONFormatter]), separated by sep:
+// human<sep>structured. It can be useful during a migration from human readable logs to
+// structured ones, for example, so that both people and tooling can consume them.
+// Trailing newlines of both formatters' outputs are trimmed, and a single newline
+// ends the line. The line is written with a single Write call.
+// It returns error if a formatting/writing problem is encountered.
+var DualFormatter = func(human, structured Formatter, sep string) Formatter {
+	return func(w io.Writer, keyValues []any) error {
+		buf := bufPool.Get().(*bytes.Buffer)
+		buf.Reset()
+		defer bufPool.Put(buf)
+
+		if err := human(buf, keyValues); err != nil {
+			return err
+		}
+		trimTrailingNewlines(buf)
+		_, _ = buf.WriteString(sep)
+		if err := structured(buf, keyValues); err != nil {
+			return err
+		}
+		trimTrailingNewlines(buf)
+		_ = buf.WriteByte('\n')
+
+		_, err := w.Write(buf.Bytes())
+
+		return err
+	}
+}
+
+// trimTrailingNewlines removes trailing "\n" / "\r\n" from given buffer.
+func trimTrailingNewlines(buf *bytes.Buffer) {
+	buf.Truncate(len(bytes.TrimRight(buf.Bytes(), "\r\n")))
+}
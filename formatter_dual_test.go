@@ -0,0 +1,138 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestDualFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("human and structured on the same line", testDualFormatterWritesBoth)
+	t.Run("single trailing newline", testDualFormatterSingleNewline)
+	t.Run("formatter errors are returned", testDualFormatterReturnsFormatErr)
+	t.Run("write error", testDualFormatterReturnsWriteErr)
+}
+
+func testDualFormatterWritesBoth(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.DualFormatter(xlog.TextFormatter(commOpts), xlog.JSONFormatter, " | ")
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	keyValues := commOpts.WithDefaultKeyValues(xlog.LevelError, xlog.MessageKey, "could not save user", "userId", 123)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	line := buf.String()
+	assertEqual(t, 1, strings.Count(line, "\n"))
+	assertTrue(t, strings.HasSuffix(line, "\n"))
+	parts := strings.SplitN(strings.TrimSuffix(line, "\n"), " | ", 2)
+	if assertEqual(t, 2, len(parts)) {
+		assertEqual(t, staticTime+" ERROR could not save user userId=123", parts[0])
+		var doc map[string]any
+		if err := json.Unmarshal([]byte(parts[1]), &doc); err != nil {
+			t.Fatal(err.Error())
+		}
+		assertEqual(
+			t,
+			map[string]any{
+				"date":   staticTime,
+				"lvl":    "ERROR",
+				"msg":    "could not save user",
+				"userId": float64(123),
+			},
+			doc,
+		)
+	}
+}
+
+func testDualFormatterSingleNewline(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf        bytes.Buffer
+		human      = new(MockFormatter)
+		structured = new(MockFormatter)
+		subject    = xlog.DualFormatter(human.Format, structured.Format, "\t")
+	)
+	human.SetFormatCallback(func(w io.Writer, _ []any) error {
+		_, err := w.Write([]byte("human\r\n"))
+
+		return err
+	})
+	structured.SetFormatCallback(func(w io.Writer, _ []any) error {
+		_, err := w.Write([]byte(`{"a":1}`)) // no trailing newline.
+
+		return err
+	})
+
+	// act
+	err := subject(&buf, []any{"a", 1})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, "human\t{\"a\":1}\n", buf.String())
+}
+
+func testDualFormatterReturnsFormatErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer   = new(MockWriter)
+		failing  = new(MockFormatter)
+		subjects = []xlog.Formatter{
+			xlog.DualFormatter(failing.Format, xlog.JSONFormatter, " "),
+			xlog.DualFormatter(xlog.LogfmtFormatter, failing.Format, " "),
+		}
+	)
+	failing.SetFormatCallback(FormatCallbackErr)
+
+	for _, subject := range subjects {
+		// act
+		err := subject(writer, []any{"foo", "bar"})
+
+		// assert
+		assertTrue(t, errors.Is(err, ErrFormat))
+	}
+	assertEqual(t, 0, writer.WriteCallsCount())
+}
+
+func testDualFormatterReturnsWriteErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.DualFormatter(xlog.LogfmtFormatter, xlog.JSONFormatter, " ")
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	err := subject(writer, []any{"foo", "bar"})
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
@@ -0,0 +1,128 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ECSVersion is the Elastic Common Schema version [ECSFormatter] complies with.
+const ECSVersion = "8.11.0"
+
+// ECSFormatter serializes key-values in JSON format, as an
+// Elastic Common Schema (ECS) compliant document, and writes it to the writer.
+// Fields mapping:
+//   - time key => "@timestamp" (ISO8601 UTC time with millisecond precision,
+//     re-parsed as [LogstashFormatter] does)
+//   - level key => "log.level"
+//   - [MessageKey] => "message"
+//   - source key => "log.origin.file.name", "log.origin.file.line"
+//     (and "log.origin.function", if source contains the function name)
+//   - [ErrorKey] => "error.message"
+//
+// The rest of the keys are nested under "labels" object, so that they
+// do not conflict with ECS fields. As ECS labels are keywords, their values
+// are written as strings (maps, slices, structs are JSON encoded).
+// Keys of above fields are taken from given options.
+// It returns error if a serialization/writing problem is encountered.
+var ECSFormatter = func(opts *CommonOpts) Formatter {
+	return func(w io.Writer, keyValues []any) error {
+		keyValues = AppendNoValue(keyValues)
+
+		var (
+			doc = map[string]any{
+				"ecs": map[string]any{"version": ECSVersion},
+			}
+			log    = make(map[string]any, 2)
+			labels = make(map[string]any, len(keyValues)/2)
+		)
+		for idx := 0; idx < len(keyValues); idx += 2 {
+			key := stringify(keyValues[idx])
+			value := keyValues[idx+1]
+			switch key {
+			case opts.TimeKey:
+				timestamp := logstashTime(value, opts.TimeLayout)
+				if timestamp.IsZero() {
+					timestamp = now()
+				}
+				doc["@timestamp"] = timestamp.UTC().Format(logstashTimeLayout)
+			case opts.LevelKey:
+				log["level"] = valueForJSON(value)
+			case MessageKey:
+				doc["message"] = stringify(value)
+			case opts.SourceKey:
+				if origin := ecsLogOrigin(stringify(value)); origin != nil {
+					log["origin"] = origin
+				}
+			case ErrorKey:
+				if err, isErr := value.(error); isErr && err != nil {
+					doc["error"] = map[string]any{"message": err.Error()}
+				} else if value != nil {
+					doc["error"] = map[string]any{"message": stringify(value)}
+				}
+			default:
+				labels[key] = ecsLabel(value)
+			}
+		}
+		if len(log) > 0 {
+			doc["log"] = log
+		}
+		if len(labels) > 0 {
+			doc["labels"] = labels
+		}
+
+		// encode ECS document into JSON.
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+
+		return encoder.Encode(doc)
+	}
+}
+
+// ecsLabel returns the string (keyword) value of a label.
+func ecsLabel(value any) string {
+	value = valueForJSON(value)
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer:
+		if _, isStringer := value.(fmt.Stringer); isStringer {
+			break
+		}
+		if encoded, err := json.Marshal(value); err == nil {
+			return string(encoded)
+		}
+	}
+
+	return stringify(value)
+}
+
+// ecsLogOrigin returns the "log.origin" object, parsed from a source value
+// looking like "/file.go:42", or "pkg.Func (/file.go:42)".
+func ecsLogOrigin(source string) map[string]any {
+	if source == "" {
+		return nil
+	}
+
+	origin := make(map[string]any, 2)
+	if idx := strings.Index(source, " ("); idx > 0 && strings.HasSuffix(source, ")") {
+		origin["function"] = source[:idx]
+		source = source[idx+2 : len(source)-1]
+	}
+	file := map[string]any{"name": source}
+	if idx := strings.LastIndexByte(source, ':'); idx > 0 {
+		if line, err := strconv.Atoi(source[idx+1:]); err == nil {
+			file["name"] = source[:idx]
+			file["line"] = line
+		}
+	}
+	origin["file"] = file
+
+	return origin
+}
@@ -0,0 +1,234 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestECSFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ECS fields by level", testECSFormatterFieldsByLevel)
+	t.Run("timestamp is normalized", testECSFormatterNormalizesTimestamp)
+	t.Run("source with function", testECSFormatterSourceWithFunction)
+	t.Run("no optional fields", testECSFormatterNoOptionalFields)
+	t.Run("write error", testECSFormatterReturnsWriteErr)
+}
+
+func testECSFormatterFieldsByLevel(t *testing.T) {
+	t.Parallel()
+
+	levels := [...]xlog.Level{
+		xlog.LevelDebug,
+		xlog.LevelInfo,
+		xlog.LevelWarning,
+		xlog.LevelError,
+		xlog.LevelCritical,
+	}
+	for _, lvl := range levels {
+		// arrange
+		var (
+			buf      bytes.Buffer
+			commOpts = xlog.NewCommonOpts()
+			someErr  = errors.New("connection refused")
+		)
+		commOpts.Time = staticTimeProvider
+		commOpts.Source = func() any { return "/app/repo/user.go:42" }
+		subject := xlog.ECSFormatter(commOpts)
+		keyValues := commOpts.WithDefaultKeyValues(
+			lvl,
+			xlog.MessageKey, "could not save user",
+			xlog.ErrorKey, someErr,
+			"userId", 123,
+			"message", "not the ECS message",
+			"roles", []string{"admin", "dev"},
+			"active", true,
+		)
+
+		// act
+		err := subject(&buf, keyValues)
+
+		// assert
+		assertNil(t, err)
+		var doc map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err.Error())
+		}
+		assertEqual(t, "2021-11-30T16:01:20.000Z", doc["@timestamp"])
+		assertEqual(t, "could not save user", doc["message"])
+		assertEqual(t, map[string]any{"version": xlog.ECSVersion}, doc["ecs"])
+		assertEqual(t, map[string]any{"message": someErr.Error()}, doc["error"])
+		assertEqual(
+			t,
+			map[string]any{
+				"level": commOpts.LevelLabels[lvl],
+				"origin": map[string]any{
+					"file": map[string]any{
+						"name": "/app/repo/user.go",
+						"line": float64(42),
+					},
+				},
+			},
+			doc["log"],
+		)
+		assertEqual(
+			t,
+			map[string]any{
+				"userId":  "123",
+				"message": "not the ECS message",
+				"roles":   `["admin","dev"]`,
+				"active":  "true",
+			},
+			doc["labels"],
+		)
+		assertEqual(t, 6, len(doc))
+	}
+}
+
+func testECSFormatterNormalizesTimestamp(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name      string
+		layout    string
+		timeValue any
+		expected  string
+	}{
+		{
+			name:      "time.Time",
+			timeValue: time.Date(2022, 3, 14, 18, 1, 20, 123456789, time.FixedZone("EET", 2*3600)),
+			expected:  "2022-03-14T16:01:20.123Z",
+		},
+		{
+			name:      "custom layout",
+			layout:    time.RFC1123Z,
+			timeValue: "Mon, 14 Mar 2022 18:01:20 +0200",
+			expected:  "2022-03-14T16:01:20.000Z",
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				buf      bytes.Buffer
+				commOpts = xlog.NewCommonOpts()
+			)
+			commOpts.TimeLayout = test.layout
+			subject := xlog.ECSFormatter(commOpts)
+
+			// act
+			err := subject(&buf, []any{commOpts.TimeKey, test.timeValue})
+
+			// assert
+			assertNil(t, err)
+			var doc map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatal(err.Error())
+			}
+			assertEqual(t, test.expected, doc["@timestamp"])
+		})
+	}
+}
+
+func testECSFormatterSourceWithFunction(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.ECSFormatter(commOpts)
+	)
+
+	// act
+	err := subject(&buf, []any{commOpts.SourceKey, "svc.(*Handler).Save (/app/svc/handler.go:7)"})
+
+	// assert
+	assertNil(t, err)
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err.Error())
+	}
+	assertEqual(
+		t,
+		map[string]any{
+			"origin": map[string]any{
+				"function": "svc.(*Handler).Save",
+				"file": map[string]any{
+					"name": "/app/svc/handler.go",
+					"line": float64(7),
+				},
+			},
+		},
+		doc["log"],
+	)
+}
+
+func testECSFormatterNoOptionalFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.ECSFormatter(commOpts)
+	)
+
+	// act
+	err := subject(&buf, []any{xlog.MessageKey, "foo", xlog.ErrorKey, nil, "odd"})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		`{"ecs":{"version":"`+xlog.ECSVersion+`"},"labels":{"odd":"*NoValue*"},"message":"foo"}`+"\n",
+		buf.String(),
+	)
+}
+
+func testECSFormatterReturnsWriteErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.ECSFormatter(xlog.NewCommonOpts())
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	err := subject(writer, []any{xlog.MessageKey, "foo"})
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func BenchmarkECSFormatter(b *testing.B) {
+	var (
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.ECSFormatter(commOpts)
+		kv       = commOpts.WithDefaultKeyValues(xlog.LevelError, getBenchmarkKeyVals()...)
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_ = subject(io.Discard, kv)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// emfEnvelopeKey is the key of the AWS CloudWatch Embedded Metric Format metadata.
+const emfEnvelopeKey = "_aws"
+
+// EMFFormatter serializes key-values in JSON format, as an
+// AWS CloudWatch Embedded Metric Format (EMF) document, and writes it to the writer.
+// This way, metrics can be emitted through logs (on Lambda / ECS, for example).
+// The first param is the CloudWatch namespace metrics are published under.
+// The second param holds the keys to be declared as metrics. Only the ones
+// present in a log, with a numeric value, are declared; their values are
+// preserved as numbers.
+// Example of output:
+// {"_aws":{"CloudWatchMetrics":[{"Dimensions":[["service"]],"Metrics":[{"Name":"latency","Unit":"Milliseconds"}],"Namespace":"my-app"}],"Timestamp":1647273680000},"latency":42,"lvl":"INFO","msg":"request served","service":"users"}.
+// "_aws.Timestamp" is taken from the time key (see [LogstashFormatter] for
+// how it is parsed; if it cannot be parsed, current time is used).
+// All the key-values, metrics or not, are written at the top level.
+// If no metric is found in a log, it is written without the "_aws" envelope.
+// Check for EMFFormatterWith* options to further customize it.
+// It returns error if a serialization/writing problem is encountered.
+var EMFFormatter = func(
+	namespace string,
+	metricKeys []string,
+	opts *CommonOpts,
+	emfOpts ...EMFFormatterOption,
+) Formatter {
+	var cfg emfFormatterConfig
+	for _, opt := range emfOpts {
+		opt(&cfg)
+	}
+	dimensions := cfg.dimensions
+	if dimensions == nil {
+		dimensions = []string{}
+	}
+
+	return func(w io.Writer, keyValues []any) error {
+		keyValues = AppendNoValue(keyValues)
+
+		doc := make(map[string]any, len(keyValues)/2+1)
+		for idx := 0; idx < len(keyValues); idx += 2 {
+			key := stringify(keyValues[idx])
+			doc[key] = valueForJSON(keyValues[idx+1])
+		}
+
+		metrics := make([]map[string]any, 0, len(metricKeys))
+		for _, key := range metricKeys {
+			if !isNumeric(doc[key]) {
+				continue
+			}
+			metric := map[string]any{"Name": key}
+			if unit, found := cfg.units[key]; found {
+				metric["Unit"] = unit
+			}
+			metrics = append(metrics, metric)
+		}
+		if len(metrics) > 0 {
+			timestamp := logstashTime(doc[opts.TimeKey], opts.TimeLayout)
+			if timestamp.IsZero() {
+				timestamp = now()
+			}
+			doc[emfEnvelopeKey] = map[string]any{
+				"Timestamp": timestamp.UnixMilli(),
+				"CloudWatchMetrics": []map[string]any{{
+					"Namespace":  namespace,
+					"Dimensions": [][]string{dimensions},
+					"Metrics":    metrics,
+				}},
+			}
+		}
+
+		// encode EMF document into JSON.
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+
+		return encoder.Encode(doc)
+	}
+}
+
+// emfFormatterConfig holds optional configurations for [EMFFormatter].
+type emfFormatterConfig struct {
+	// keys metrics are dimensioned by.
+	// can be set with [EMFFormatterWithDimensions] functional option.
+	dimensions []string
+	// units of metrics, by metric key.
+	// can be set with [EMFFormatterWithUnits] functional option.
+	units map[string]string
+}
+
+// EMFFormatterOption defines optional function for configuring
+// an EMF formatter.
+type EMFFormatterOption func(*emfFormatterConfig)
+
+// EMFFormatterWithDimensions sets the keys metrics are dimensioned by
+// (example: "service", "env"). Their values should be strings, of low cardinality.
+// By default, metrics have no dimension.
+func EMFFormatterWithDimensions(keys ...string) EMFFormatterOption {
+	return func(cfg *emfFormatterConfig) {
+		cfg.dimensions = keys
+	}
+}
+
+// EMFFormatterWithUnits sets the CloudWatch units of metrics, by metric key
+// (example: "latency" => "Milliseconds").
+// By default, no unit is declared (CloudWatch treats it as "None").
+func EMFFormatterWithUnits(units map[string]string) EMFFormatterOption {
+	return func(cfg *emfFormatterConfig) {
+		cfg.units = units
+	}
+}
+
+// isNumeric returns true if given value is a number.
+func isNumeric(value any) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64, json.Number:
+		return true
+	}
+
+	return false
+}
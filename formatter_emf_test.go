@@ -0,0 +1,167 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestEMFFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("metrics are declared in _aws envelope", testEMFFormatterMetrics)
+	t.Run("non numeric / missing metrics are not declared", testEMFFormatterNonNumericMetrics)
+	t.Run("write error", testEMFFormatterReturnsWriteErr)
+}
+
+func testEMFFormatterMetrics(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		someTime = time.Date(2022, 3, 14, 16, 1, 20, 123000000, time.UTC)
+		subject  = xlog.EMFFormatter(
+			"my-app",
+			[]string{"latency", "bytes", "missing"},
+			commOpts,
+			xlog.EMFFormatterWithDimensions("service"),
+			xlog.EMFFormatterWithUnits(map[string]string{"latency": "Milliseconds"}),
+		)
+	)
+	commOpts.Time = func() any { return someTime.Format(time.RFC3339Nano) }
+	commOpts.SourceKey = ""
+	keyValues := commOpts.WithDefaultKeyValues(
+		xlog.LevelInfo,
+		xlog.MessageKey, "request served",
+		"service", "users",
+		"latency", 42.5,
+		"bytes", uint32(1024),
+	)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	var doc map[string]any
+	decoder := json.NewDecoder(&buf)
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatal(err.Error())
+	}
+	assertEqual(
+		t,
+		map[string]any{
+			"Timestamp": json.Number("1647273680123"),
+			"CloudWatchMetrics": []any{
+				map[string]any{
+					"Namespace":  "my-app",
+					"Dimensions": []any{[]any{"service"}},
+					"Metrics": []any{
+						map[string]any{"Name": "latency", "Unit": "Milliseconds"},
+						map[string]any{"Name": "bytes"},
+					},
+				},
+			},
+		},
+		doc["_aws"],
+	)
+	assertEqual(t, json.Number("42.5"), doc["latency"])
+	assertEqual(t, json.Number("1024"), doc["bytes"])
+	assertEqual(t, "users", doc["service"])
+	assertEqual(t, "INFO", doc["lvl"])
+	assertEqual(t, "request served", doc["msg"])
+	assertEqual(t, "2022-03-14T16:01:20.123Z", doc["date"])
+	assertEqual(t, 7, len(doc))
+}
+
+func testEMFFormatterNonNumericMetrics(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.EMFFormatter("my-app", []string{"latency", "count"}, commOpts)
+	)
+
+	// act
+	err := subject(&buf, []any{xlog.MessageKey, "foo", "latency", "fast"})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, `{"latency":"fast","msg":"foo"}`+"\n", buf.String())
+
+	// act - a single numeric metric, no dimensions.
+	buf.Reset()
+	err = subject(&buf, []any{"latency", "fast", "count", 3})
+
+	// assert
+	assertNil(t, err)
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err.Error())
+	}
+	envelope, _ := doc["_aws"].(map[string]any)
+	if assertNotNil(t, envelope) {
+		assertTrue(t, envelope["Timestamp"].(float64) > 0)
+		assertEqual(
+			t,
+			[]any{
+				map[string]any{
+					"Namespace":  "my-app",
+					"Dimensions": []any{[]any{}},
+					"Metrics":    []any{map[string]any{"Name": "count"}},
+				},
+			},
+			envelope["CloudWatchMetrics"],
+		)
+	}
+	assertEqual(t, float64(3), doc["count"])
+	assertEqual(t, "fast", doc["latency"])
+}
+
+func testEMFFormatterReturnsWriteErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.EMFFormatter("my-app", []string{"count"}, xlog.NewCommonOpts())
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	err := subject(writer, []any{"count", 1})
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func BenchmarkEMFFormatter(b *testing.B) {
+	var (
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.EMFFormatter("my-app", []string{"latency"}, commOpts)
+		kv       = commOpts.WithDefaultKeyValues(xlog.LevelError, append(getBenchmarkKeyVals(), "latency", 42)...)
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_ = subject(io.Discard, kv)
+	}
+}
@@ -6,8 +6,10 @@
 package xlog
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
+	"time"
 )
 
 // JSONFormatter serializes key-values in JSON format and writes the
@@ -29,10 +31,40 @@ var JSONFormatter Formatter = func(w io.Writer, keyValues []any) error {
 	return encoder.Encode(keyValueMap)
 }
 
+// NDJSONBatchFormatter serializes a batch of logs in newline-delimited JSON format
+// (each log is a JSON object, as serialized by [JSONFormatter], followed by a "\n"),
+// format which many log shippers / bulk ingestion APIs expect.
+// The whole batch is written to the writer with a single Write call.
+// It returns error if a serialization/writing problem is encountered.
+// It is meant to be used with [AsyncLoggerWithBatchFormatter].
+var NDJSONBatchFormatter BatchFormatter = BatchFormatterFunc(func(w io.Writer, entries [][]any) error {
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufPool.Put(buf)
+
+	for _, keyValues := range entries {
+		if err := JSONFormatter(buf, keyValues); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+
+	return err
+})
+
 // valueForJSON applies some customization upon a value.
-// Currently an error.Error() is taken instead of error itself.
+// Currently an error.Error() is taken instead of error itself,
+// unless the error implements [json.Marshaler].
+// A [time.Duration] is represented by its String() (example: "1.5s"),
+// instead of its no. of nanoseconds.
+// A [json.Marshaler] (like a [json.RawMessage]) is passed as it is
+// to the encoder, so that its own JSON representation is embedded.
 func valueForJSON(v any) any {
-	switch val := v.(type) { // nolint
+	switch val := v.(type) {
+	case json.Marshaler:
+		return val
+	case time.Duration:
+		return val.String()
 	case error:
 		if val != nil {
 			return val.Error()
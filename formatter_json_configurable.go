@@ -0,0 +1,127 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONFormatterOpts is a struct holding configurations for
+// [ConfigurableJSONFormatter].
+type JSONFormatterOpts struct {
+	// NestUserFieldsUnder is the key of an object all the user keys are placed
+	// under, so that they do not collide with the reserved ones (time, level,
+	// source keys and [MessageKey]), which are kept at the top level.
+	// Example of output: {"date":"...","fields":{"userId":123},"lvl":"ERROR","msg":"could not save user"}.
+	// By default, is set to an empty string, meaning user keys are not nested.
+	NestUserFieldsUnder string
+
+	// UseJSONNumber flag, if true, user keys' string values which are valid
+	// JSON numbers (like "12345678901234567890") are emitted unquoted, as
+	// [json.Number] values, without any precision loss.
+	// Note: numeric values (like int64 IDs) are always emitted with all their
+	// digits, as they are encoded directly, not through float64; and so are
+	// [json.Number] values.
+	// By default, is false, meaning string values are quoted.
+	UseJSONNumber bool
+
+	// EnvelopeKey is the key of an object the whole record is placed under,
+	// for ingestion APIs requiring an envelope.
+	// Example of output: {"log":{"date":"...","lvl":"ERROR","msg":"could not save user","userId":123}}.
+	// By default, is set to an empty string, meaning the record is not enveloped.
+	EnvelopeKey string
+
+	// EnvelopeFields are static key-values added next to the [JSONFormatterOpts.EnvelopeKey]
+	// (example: {"type": "log"}). They are ignored if the record is not enveloped.
+	// The record wins on a key collision with [JSONFormatterOpts.EnvelopeKey].
+	EnvelopeFields map[string]any
+}
+
+// ConfigurableJSONFormatter serializes key-values in JSON format, like
+// [JSONFormatter], customized with given configurations, and writes the
+// resulted JSON to the writer.
+// Reserved keys are taken from given options.
+// It returns error if a serialization/writing problem is encountered.
+var ConfigurableJSONFormatter = func(opts *CommonOpts, jsonOpts JSONFormatterOpts) Formatter {
+	return func(w io.Writer, keyValues []any) error {
+		keyValues = AppendNoValue(keyValues)
+
+		// convert log slice into a map.
+		var (
+			keyValueMap = make(map[string]any, len(keyValues)/2)
+			userFields  = keyValueMap
+		)
+		if jsonOpts.NestUserFieldsUnder != "" {
+			userFields = make(map[string]any, len(keyValues)/2)
+		}
+		for idx := 0; idx < len(keyValues); idx += 2 {
+			key := stringify(keyValues[idx])
+			value := valueForJSON(keyValues[idx+1])
+			if opts.isReservedKey(key) {
+				keyValueMap[key] = value
+			} else {
+				if jsonOpts.UseJSONNumber {
+					value = jsonNumber(value)
+				}
+				userFields[key] = value
+			}
+		}
+		if jsonOpts.NestUserFieldsUnder != "" && len(userFields) > 0 {
+			keyValueMap[jsonOpts.NestUserFieldsUnder] = userFields
+		}
+
+		var record any = keyValueMap
+		if jsonOpts.EnvelopeKey != "" {
+			envelope := make(map[string]any, len(jsonOpts.EnvelopeFields)+1)
+			for key, value := range jsonOpts.EnvelopeFields {
+				envelope[key] = value
+			}
+			envelope[jsonOpts.EnvelopeKey] = keyValueMap
+			record = envelope
+		}
+
+		// encode record into JSON.
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+
+		return encoder.Encode(record)
+	}
+}
+
+// isReservedKey returns true if given key is one of the time, level,
+// source keys, or the [MessageKey].
+func (opts *CommonOpts) isReservedKey(key string) bool {
+	switch key {
+	case opts.TimeKey, opts.LevelKey, opts.SourceKey, MessageKey:
+		return key != ""
+	}
+
+	return false
+}
+
+// jsonNumber returns given value as a [json.Number],
+// if it is a string representing a valid JSON number,
+// or the value itself, otherwise.
+func jsonNumber(value any) any {
+	str, isString := value.(string)
+	if !isString || len(str) == 0 {
+		return value
+	}
+	// a JSON number starts with a minus or a digit and ends with a digit,
+	// json.Valid checks the rest (and rejects other JSON values).
+	first, last := str[0], str[len(str)-1]
+	if (first != '-' && !isDigit(first)) || !isDigit(last) || !json.Valid([]byte(str)) {
+		return value
+	}
+
+	return json.Number(str)
+}
+
+// isDigit returns true if given byte is an ASCII digit.
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
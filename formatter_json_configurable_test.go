@@ -0,0 +1,246 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestConfigurableJSONFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default is like JSONFormatter", testConfigurableJSONFormatterDefault)
+	t.Run("user fields are nested", testConfigurableJSONFormatterNestUserFields)
+	t.Run("no user fields, no nested object", testConfigurableJSONFormatterNestNoUserFields)
+	t.Run("write error", testConfigurableJSONFormatterReturnsWriteErr)
+	t.Run("numeric strings as JSON numbers", testConfigurableJSONFormatterUseJSONNumber)
+	t.Run("record is enveloped", testConfigurableJSONFormatterEnvelope)
+}
+
+func testConfigurableJSONFormatterDefault(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf, expectedBuf bytes.Buffer
+		commOpts         = xlog.NewCommonOpts()
+		subject          = xlog.ConfigurableJSONFormatter(commOpts, xlog.JSONFormatterOpts{})
+		keyValues        = commOpts.WithDefaultKeyValues(
+			xlog.LevelError,
+			xlog.MessageKey, "could not save user",
+			"userId", 123,
+			"odd",
+		)
+	)
+	_ = xlog.JSONFormatter(&expectedBuf, keyValues)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, expectedBuf.String(), buf.String())
+}
+
+func testConfigurableJSONFormatterNestUserFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.ConfigurableJSONFormatter(
+			commOpts,
+			xlog.JSONFormatterOpts{NestUserFieldsUnder: "fields"},
+		)
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.Source = func() any { return "/app/user.go:42" }
+	keyValues := commOpts.WithDefaultKeyValues(
+		xlog.LevelError,
+		xlog.MessageKey, "could not save user",
+		xlog.ErrorKey, errors.New("connection refused"),
+		"userId", 123,
+		"fields", "user's own fields key",
+	)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err.Error())
+	}
+	assertEqual(
+		t,
+		map[string]any{
+			"date": staticTime,
+			"lvl":  "ERROR",
+			"src":  "/app/user.go:42",
+			"msg":  "could not save user",
+			"fields": map[string]any{
+				"err":    "connection refused",
+				"userId": float64(123),
+				"fields": "user's own fields key",
+			},
+		},
+		doc,
+	)
+}
+
+func testConfigurableJSONFormatterNestNoUserFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.ConfigurableJSONFormatter(
+			commOpts,
+			xlog.JSONFormatterOpts{NestUserFieldsUnder: "fields"},
+		)
+	)
+
+	// act
+	err := subject(&buf, []any{"date", staticTime, xlog.MessageKey, "foo"})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, `{"date":"`+staticTime+`","msg":"foo"}`+"\n", buf.String())
+}
+
+func testConfigurableJSONFormatterReturnsWriteErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.ConfigurableJSONFormatter(
+			xlog.NewCommonOpts(),
+			xlog.JSONFormatterOpts{NestUserFieldsUnder: "fields"},
+		)
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	err := subject(writer, []any{xlog.MessageKey, "foo", "bar", "baz"})
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func BenchmarkConfigurableJSONFormatter_nestUserFields(b *testing.B) {
+	var (
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.ConfigurableJSONFormatter(
+			commOpts,
+			xlog.JSONFormatterOpts{NestUserFieldsUnder: "fields"},
+		)
+		kv = commOpts.WithDefaultKeyValues(xlog.LevelError, getBenchmarkKeyVals()...)
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_ = subject(io.Discard, kv)
+	}
+}
+
+func testConfigurableJSONFormatterUseJSONNumber(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.ConfigurableJSONFormatter(
+			commOpts,
+			xlog.JSONFormatterOpts{UseJSONNumber: true},
+		)
+		keyValues = []any{
+			xlog.MessageKey, "123",
+			"id", int64(1234567890123456789),
+			"strId", "9223372036854775807",
+			"negative", "-1.5e3",
+			"leadingZero", "0123",
+			"text", "12 apples",
+			"bool", "true",
+			"empty", "",
+		}
+	)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		`{"bool":"true","empty":"","id":1234567890123456789,"leadingZero":"0123",`+
+			`"msg":"123","negative":-1.5e3,"strId":9223372036854775807,"text":"12 apples"}`+"\n",
+		buf.String(),
+	)
+}
+
+func testConfigurableJSONFormatterEnvelope(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.ConfigurableJSONFormatter(
+			commOpts,
+			xlog.JSONFormatterOpts{
+				EnvelopeKey:         "log",
+				EnvelopeFields:      map[string]any{"type": "log", "log": "overwritten by record"},
+				NestUserFieldsUnder: "fields",
+			},
+		)
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	keyValues := commOpts.WithDefaultKeyValues(
+		xlog.LevelError,
+		xlog.MessageKey, "could not save user",
+		"userId", 123,
+	)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err.Error())
+	}
+	assertEqual(
+		t,
+		map[string]any{
+			"type": "log",
+			"log": map[string]any{
+				"date": staticTime,
+				"lvl":  "ERROR",
+				"msg":  "could not save user",
+				"fields": map[string]any{
+					"userId": float64(123),
+				},
+			},
+		},
+		doc,
+	)
+}
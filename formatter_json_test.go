@@ -11,6 +11,7 @@ import (
 	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/actforgood/xlog"
 )
@@ -60,6 +61,87 @@ func TestJSONFormatter_successfullyWritesJSON(t *testing.T) {
 	assertEqual(t, someErr.Error(), kvMap["err"])
 }
 
+func TestJSONFormatter_embedsJSONMarshalers(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject   = xlog.JSONFormatter
+		keyValues = []any{
+			"raw", json.RawMessage(`{"a":1}`),
+			"marshaler", dummyJSONMarshalerErr{},
+			"err", errors.New("plain error"),
+		}
+		writer bytes.Buffer
+	)
+
+	// act
+	resultErr := subject(&writer, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(
+		t,
+		`{"err":"plain error","marshaler":{"code":500},"raw":{"a":1}}`+"\n",
+		writer.String(),
+	)
+}
+
+// dummyJSONMarshalerErr is an error which has its own JSON representation.
+type dummyJSONMarshalerErr struct{}
+
+func (dummyJSONMarshalerErr) Error() string {
+	return "dummy error"
+}
+
+func (dummyJSONMarshalerErr) MarshalJSON() ([]byte, error) {
+	return []byte(`{"code":500}`), nil
+}
+
+func TestJSONFormatter_writesDurationAsString(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject   = xlog.JSONFormatter
+		keyValues = []any{"took", 1500 * time.Millisecond}
+		writer    bytes.Buffer
+	)
+
+	// act
+	resultErr := subject(&writer, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, `{"took":"1.5s"}`+"\n", writer.String())
+}
+
+func TestJSONFormatter_preservesLargeNumbers(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject   = xlog.JSONFormatter
+		keyValues = []any{
+			"id", int64(9223372036854775807),
+			"anyId", any(uint64(18446744073709551615)),
+			"number", json.Number("12345678901234567890"),
+		}
+		writer bytes.Buffer
+	)
+
+	// act
+	resultErr := subject(&writer, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(
+		t,
+		`{"anyId":18446744073709551615,"id":9223372036854775807,"number":12345678901234567890}`+"\n",
+		writer.String(),
+	)
+}
+
 func TestJSONFormatter_returnsWriteErr(t *testing.T) {
 	t.Parallel()
 
@@ -88,6 +170,64 @@ func TestJSONFormatter_returnsWriteErr(t *testing.T) {
 	assertTrue(t, errors.Is(resultErr, ErrWrite))
 }
 
+func TestNDJSONBatchFormatter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xlog.NDJSONBatchFormatter
+		entries = [][]any{
+			{"foo", "bar", "no", 1},
+			{"foo", "baz", "no", 2},
+			{"foo", "qux", "no", 3},
+		}
+		writer      = new(MockWriter)
+		writtenData []byte
+	)
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		writtenData = append(writtenData, p...)
+
+		return len(p), nil
+	})
+
+	// act
+	resultErr := subject.FormatBatch(writer, entries)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, 1, writer.WriteCallsCount()) // whole batch is written at once.
+	lines := bytes.Split(bytes.TrimSuffix(writtenData, []byte("\n")), []byte("\n"))
+	if assertEqual(t, 3, len(lines)) {
+		for idx, line := range lines {
+			var kvMap map[string]any
+			if err := json.Unmarshal(line, &kvMap); err != nil {
+				t.Fatal(err.Error())
+			}
+			assertEqual(t, idx+1, int(kvMap["no"].(float64)))
+			assertEqual(t, entries[idx][1], kvMap["foo"])
+		}
+	}
+}
+
+func TestNDJSONBatchFormatter_returnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xlog.NDJSONBatchFormatter
+		writer  = new(MockWriter)
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	writeErr := subject.FormatBatch(writer, [][]any{{"foo", "bar"}})
+	encodeErr := subject.FormatBatch(io.Discard, [][]any{{"foo", func() {}}})
+
+	// assert
+	assertTrue(t, errors.Is(writeErr, ErrWrite))
+	assertNotNil(t, encodeErr)
+}
+
 func BenchmarkJSONFormatter(b *testing.B) {
 	var (
 		subject = xlog.JSONFormatter
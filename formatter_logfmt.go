@@ -8,6 +8,7 @@ package xlog
 import (
 	"bytes"
 	"io"
+	"sort"
 	"sync"
 
 	"github.com/go-logfmt/logfmt"
@@ -16,12 +17,16 @@ import (
 type logfmtEncoder struct {
 	*logfmt.Encoder
 	buf bytes.Buffer
+	// keyVals is a reusable slice used to reorder key-values.
+	keyVals []any
 }
 
 // Reset resets the encoder and its buffer.
 func (enc *logfmtEncoder) Reset() {
 	enc.Encoder.Reset()
 	enc.buf.Reset()
+	clear(enc.keyVals) // do not keep references to previous values.
+	enc.keyVals = enc.keyVals[:0]
 }
 
 // Encode encodes given key values in logfmt format.
@@ -42,6 +47,13 @@ var logfmtEncoderPool = sync.Pool{
 	},
 }
 
+// putLogfmtEncoder resets given encoder, so that it does not keep
+// references to the logged values, and puts it back into the pool.
+func putLogfmtEncoder(enc *logfmtEncoder) {
+	enc.Reset()
+	logfmtEncoderPool.Put(enc)
+}
+
 // LogfmtFormatter serializes key-values in logfmt format and writes the
 // resulted bytes to the writer.
 // It returns error if a serialization/writing problem is encountered.
@@ -50,9 +62,68 @@ var LogfmtFormatter Formatter = func(w io.Writer, keyValues []any) error {
 	keyValues = AppendNoValue(keyValues)
 
 	enc := logfmtEncoderPool.Get().(*logfmtEncoder)
-	enc.Reset()
-	defer logfmtEncoderPool.Put(enc)
+	defer putLogfmtEncoder(enc)
+
+	return writeLogfmt(w, enc, keyValues)
+}
+
+// LogfmtOptions holds configurations for a logfmt formatter.
+type LogfmtOptions struct {
+	// SortUserKeys is a flag which, if enabled, keeps the FrontKeys
+	// in front, in their given order, and sorts alphabetically the
+	// remaining keys.
+	// By default, keys are written in the order they were logged.
+	SortUserKeys bool
+
+	// FrontKeys are the keys to be kept in front when SortUserKeys is enabled.
+	// By default, is set to "date", "lvl", "src", "msg" (default keys from [NewCommonOpts]
+	// and [MessageKey]).
+	FrontKeys []string
+
+	// SliceEncoding defines how slices / arrays values are rendered.
+	// By default, is set to [SliceEncodingGoDefault].
+	SliceEncoding SliceEncoding
+}
+
+// NewLogfmtFormatter instantiates a logfmt Formatter, configured
+// with given options.
+// See also [LogfmtFormatter].
+func NewLogfmtFormatter(logfmtOpts LogfmtOptions) Formatter {
+	if logfmtOpts.FrontKeys == nil {
+		logfmtOpts.FrontKeys = []string{
+			defaultOptTimeKey,
+			defaultOptLevelKey,
+			defaultOptSourceKey,
+			MessageKey,
+		}
+	}
+
+	return func(w io.Writer, keyValues []any) error {
+		keyValues = AppendNoValue(keyValues)
+
+		enc := logfmtEncoderPool.Get().(*logfmtEncoder)
+		defer putLogfmtEncoder(enc)
+
+		if logfmtOpts.SortUserKeys {
+			enc.keyVals = sortUserKeys(enc.keyVals, keyValues, logfmtOpts.FrontKeys)
+			keyValues = enc.keyVals
+		}
+		if logfmtOpts.SliceEncoding != SliceEncodingGoDefault {
+			if !logfmtOpts.SortUserKeys { // do not alter caller's key-values.
+				enc.keyVals = append(enc.keyVals, keyValues...)
+				keyValues = enc.keyVals
+			}
+			for idx := 1; idx < len(keyValues); idx += 2 {
+				keyValues[idx] = encodeSlice(keyValues[idx], logfmtOpts.SliceEncoding)
+			}
+		}
+
+		return writeLogfmt(w, enc, keyValues)
+	}
+}
 
+// writeLogfmt encodes key-values and writes them to the writer.
+func writeLogfmt(w io.Writer, enc *logfmtEncoder, keyValues []any) error {
 	if err := enc.Encode(keyValues...); err != nil {
 		return err
 	}
@@ -63,3 +134,53 @@ var LogfmtFormatter Formatter = func(w io.Writer, keyValues []any) error {
 
 	return nil
 }
+
+// sortUserKeys appends to dst the key-values with front keys first (in their
+// given order), followed by the rest of the keys, sorted alphabetically.
+func sortUserKeys(dst, keyValues []any, frontKeys []string) []any {
+	for _, frontKey := range frontKeys {
+		for idx := 0; idx < len(keyValues); idx += 2 {
+			if keyValues[idx] == frontKey {
+				dst = append(dst, keyValues[idx], keyValues[idx+1])
+			}
+		}
+	}
+	userKeysStartIdx := len(dst)
+
+	for idx := 0; idx < len(keyValues); idx += 2 {
+		if !isFrontKey(keyValues[idx], frontKeys) {
+			dst = append(dst, keyValues[idx], keyValues[idx+1])
+		}
+	}
+	sort.Stable(keyValuesByKey(dst[userKeysStartIdx:]))
+
+	return dst
+}
+
+// isFrontKey checks if given key is among front keys.
+func isFrontKey(key any, frontKeys []string) bool {
+	for _, frontKey := range frontKeys {
+		if key == frontKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keyValuesByKey implements [sort.Interface] for sorting
+// key-values pairs by their key.
+type keyValuesByKey []any
+
+func (kv keyValuesByKey) Len() int {
+	return len(kv) / 2
+}
+
+func (kv keyValuesByKey) Less(i, j int) bool {
+	return stringify(kv[2*i]) < stringify(kv[2*j])
+}
+
+func (kv keyValuesByKey) Swap(i, j int) {
+	kv[2*i], kv[2*j] = kv[2*j], kv[2*i]
+	kv[2*i+1], kv[2*j+1] = kv[2*j+1], kv[2*i+1]
+}
@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-logfmt/logfmt"
 
@@ -43,7 +44,7 @@ func ExampleSyncLogger_withLogfmt() {
 	logger.Info(xlog.MessageKey, "Hello World", "year", 2022)
 
 	// Output:
-	// date=2022-04-12T16:01:20Z lvl=INFO src=/formatter_logfmt_test.go:43 appName=demo env=dev msg="Hello World" year=2022
+	// date=2022-04-12T16:01:20Z lvl=INFO src=/formatter_logfmt_test.go:44 appName=demo env=dev msg="Hello World" year=2022
 }
 
 func TestLogfileFormatter_successfullyWritesKeyValues(t *testing.T) {
@@ -63,6 +64,7 @@ func TestLogfileFormatter_successfullyWritesKeyValues(t *testing.T) {
 			[]int{1, 2, 3},
 			dummy, dummy,
 			"err", someErr,
+			"took", 1500 * time.Millisecond,
 		}
 		writer bytes.Buffer
 	)
@@ -84,7 +86,7 @@ func TestLogfileFormatter_successfullyWritesKeyValues(t *testing.T) {
 	if dec.Err() != nil {
 		t.Fatal(dec.Err())
 	}
-	assertEqual(t, 7, len(kvMap))
+	assertEqual(t, 8, len(kvMap))
 	assertEqual(t, "bar", kvMap["foo"])
 	assertEqual(t, "34", kvMap["age"])
 	assertEqual(t, "123.456", kvMap["computation"])
@@ -92,6 +94,7 @@ func TestLogfileFormatter_successfullyWritesKeyValues(t *testing.T) {
 	assertEqual(t, logfmt.ErrUnsupportedValueType.Error(), kvMap["ints-slice"])
 	assertEqual(t, "dummyStringer: John Doe", kvMap["dummyStringer:JohnDoe"])
 	assertEqual(t, someErr.Error(), kvMap["err"])
+	assertEqual(t, "1.5s", kvMap["took"])
 	assertEqual(t, 1, linesCount)
 }
 
@@ -123,6 +126,175 @@ func TestLogfmtFormatter_returnsWriteErr(t *testing.T) {
 	assertTrue(t, errors.Is(resultErr, ErrWrite))
 }
 
+func TestNewLogfmtFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keys are sorted", testNewLogfmtFormatterSortsUserKeys)
+	t.Run("keys are sorted with custom front keys", testNewLogfmtFormatterSortsUserKeysWithCustomFrontKeys)
+	t.Run("keys are not sorted by default", testNewLogfmtFormatterKeepsInsertionOrder)
+	t.Run("write error is returned", testNewLogfmtFormatterReturnsWriteErr)
+	t.Run("slice encoding", testNewLogfmtFormatterSliceEncoding)
+}
+
+func testNewLogfmtFormatterSortsUserKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject   = xlog.NewLogfmtFormatter(xlog.LogfmtOptions{SortUserKeys: true})
+		keyValues = []any{
+			"zeta", 1,
+			"msg", "Hello World",
+			"alpha", 2,
+			"src", "/formatter_logfmt_test.go:30",
+			10, "ten",
+			"date", "2022-04-12T16:01:20Z",
+			"beta", 3,
+			"lvl", "INFO",
+		}
+		expectedResult = "date=2022-04-12T16:01:20Z lvl=INFO src=/formatter_logfmt_test.go:30 " +
+			`msg="Hello World" 10=ten alpha=2 beta=3 zeta=1` + "\n"
+		writer bytes.Buffer
+	)
+
+	for i := 0; i < 3; i++ { // make sure pooled encoder is properly reset.
+		writer.Reset()
+
+		// act
+		resultErr := subject(&writer, keyValues)
+
+		// assert
+		assertNil(t, resultErr)
+		assertEqual(t, expectedResult, writer.String())
+	}
+	// input is not altered.
+	assertEqual(t, "zeta", keyValues[0])
+}
+
+func testNewLogfmtFormatterSortsUserKeysWithCustomFrontKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xlog.NewLogfmtFormatter(xlog.LogfmtOptions{
+			SortUserKeys: true,
+			FrontKeys:    []string{"level", "message"},
+		})
+		keyValues = []any{
+			"message", "Hello World",
+			"b", 2,
+			"level", "INFO",
+			"a", 1,
+			"date", "2022-04-12T16:01:20Z",
+		}
+		expectedResult = `level=INFO message="Hello World" a=1 b=2 date=2022-04-12T16:01:20Z` + "\n"
+		writer         bytes.Buffer
+	)
+
+	// act
+	resultErr := subject(&writer, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, expectedResult, writer.String())
+}
+
+func testNewLogfmtFormatterKeepsInsertionOrder(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject   = xlog.NewLogfmtFormatter(xlog.LogfmtOptions{})
+		keyValues = []any{
+			"zeta", 1,
+			"msg", "Hello World",
+			"alpha", 2,
+			"odd",
+		}
+		expectedResult = `zeta=1 msg="Hello World" alpha=2 odd=*NoValue*` + "\n"
+		writer         bytes.Buffer
+	)
+
+	// act
+	resultErr := subject(&writer, keyValues)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, expectedResult, writer.String())
+}
+
+func testNewLogfmtFormatterReturnsWriteErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject   = xlog.NewLogfmtFormatter(xlog.LogfmtOptions{SortUserKeys: true})
+		keyValues = []any{"foo", "bar"}
+		writer    = new(MockWriter)
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	resultErr := subject(writer, keyValues)
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, ErrWrite))
+}
+
+func testNewLogfmtFormatterSliceEncoding(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name           string
+		logfmtOpts     xlog.LogfmtOptions
+		expectedResult string
+	}{
+		{
+			name:           "go default",
+			logfmtOpts:     xlog.LogfmtOptions{},
+			expectedResult: `tags="unsupported value type" ids="unsupported value type" bytes=raw msg=hi` + "\n",
+		},
+		{
+			name:           "comma joined",
+			logfmtOpts:     xlog.LogfmtOptions{SliceEncoding: xlog.SliceEncodingCommaJoined},
+			expectedResult: `tags=a,b,c ids=1,2 bytes=raw msg=hi` + "\n",
+		},
+		{
+			name:           "json",
+			logfmtOpts:     xlog.LogfmtOptions{SliceEncoding: xlog.SliceEncodingJSON},
+			expectedResult: `tags="[\"a\",\"b\",\"c\"]" ids=[1,2] bytes=raw msg=hi` + "\n",
+		},
+		{
+			name:           "json with sorted keys",
+			logfmtOpts:     xlog.LogfmtOptions{SliceEncoding: xlog.SliceEncodingJSON, SortUserKeys: true},
+			expectedResult: `msg=hi bytes=raw ids=[1,2] tags="[\"a\",\"b\",\"c\"]"` + "\n",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				subject   = xlog.NewLogfmtFormatter(test.logfmtOpts)
+				tags      = []string{"a", "b", "c"}
+				keyValues = []any{"tags", tags, "ids", [2]int{1, 2}, "bytes", []byte("raw"), "msg", "hi"}
+				writer    bytes.Buffer
+			)
+
+			// act
+			resultErr := subject(&writer, keyValues)
+
+			// assert
+			assertNil(t, resultErr)
+			assertEqual(t, test.expectedResult, writer.String())
+			assertEqual(t, tags, keyValues[1]) // input is not altered.
+		})
+	}
+}
+
 func BenchmarkLogfmtFormatter(b *testing.B) {
 	var (
 		subject = xlog.LogfmtFormatter
@@ -146,6 +318,28 @@ func BenchmarkLogfmtFormatter(b *testing.B) {
 	}
 }
 
+func BenchmarkNewLogfmtFormatter_withSortUserKeys(b *testing.B) {
+	var (
+		subject = xlog.NewLogfmtFormatter(xlog.LogfmtOptions{SortUserKeys: true})
+		dummy   = dummyStringer{Name: "John Doe"}
+		input   = []any{
+			"foo", "bar",
+			"age", 34,
+			"computation", 123.456,
+			10, "ten",
+			"date", "2022-04-12T16:01:20Z",
+			dummy, dummy,
+		}
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_ = subject(io.Discard, input)
+	}
+}
+
 func TestLogfmtFormatter_concurrency(t *testing.T) {
 	t.Parallel()
 
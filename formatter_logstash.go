@@ -0,0 +1,128 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// logstashVersion is the Logstash event format version.
+	logstashVersion = "1"
+	// logstashTimeLayout is ISO8601 (UTC) with millisecond precision.
+	logstashTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// LogstashFormatter serializes key-values in JSON format, as a
+// Logstash (json_event) compatible event, and writes it to the writer.
+// Example of output:
+// {"@timestamp":"2022-03-14T16:01:20.123Z","@version":"1","level":"ERROR","message":"could not save user","userId":123}.
+// "@timestamp" is taken from the time key and it is always written as
+// ISO8601 UTC time with millisecond precision, regardless of the time
+// provider's format (the value is re-parsed with [CommonOpts.TimeLayout],
+// RFC3339Nano, or as Unix float seconds / int64 timestamp; if it cannot be
+// parsed, current time is used).
+// Level key is written as "level", [MessageKey] as "message",
+// the rest of the key-values are written as they are, at the top level.
+// It returns error if a serialization/writing problem is encountered.
+var LogstashFormatter = func(opts *CommonOpts) Formatter {
+	return func(w io.Writer, keyValues []any) error {
+		keyValues = AppendNoValue(keyValues)
+
+		var (
+			event          = make(map[string]any, len(keyValues)/2+2)
+			timestamp      time.Time
+			lvl, msg       any
+			hasLvl, hasMsg bool
+		)
+		for idx := 0; idx < len(keyValues); idx += 2 {
+			key := stringify(keyValues[idx])
+			value := keyValues[idx+1]
+			switch key {
+			case opts.TimeKey:
+				timestamp = logstashTime(value, opts.TimeLayout)
+			case opts.LevelKey:
+				lvl, hasLvl = valueForJSON(value), true
+			case MessageKey:
+				msg, hasMsg = stringify(value), true
+			default:
+				event[key] = valueForJSON(value)
+			}
+		}
+		// Logstash fields take precedence over same named user keys.
+		if hasLvl {
+			event["level"] = lvl
+		}
+		if hasMsg {
+			event["message"] = msg
+		}
+		if timestamp.IsZero() {
+			timestamp = now()
+		}
+		event["@timestamp"] = timestamp.UTC().Format(logstashTimeLayout)
+		event["@version"] = logstashVersion
+
+		// encode event into JSON.
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+
+		return encoder.Encode(event)
+	}
+}
+
+// logstashTime returns the time from log's time value,
+// or zero time if it cannot be determined.
+func logstashTime(value any, layout string) time.Time {
+	switch val := value.(type) {
+	case time.Time:
+		return val
+	case string:
+		for _, l := range [...]string{layout, time.RFC3339Nano} {
+			if l == "" {
+				continue
+			}
+			if t, err := time.Parse(l, val); err == nil {
+				return t
+			}
+		}
+	case float64: // Unix seconds, see UnixFloatTimeProvider.
+		sec, frac := math.Modf(val)
+
+		return time.Unix(int64(sec), int64(frac*float64(time.Second)))
+	case int64: // see UnixTimeProvider.
+		return unixTime(val)
+	}
+
+	return time.Time{}
+}
+
+// unixTime returns the time from a Unix timestamp, whose unit
+// (seconds, milliseconds, microseconds or nanoseconds) is inferred
+// from its magnitude, as it is not known (see [UnixTimeProvider]).
+func unixTime(timestamp int64) time.Time {
+	const (
+		maxSeconds      = 1e11 // year 5138 in seconds, year 1973 in milliseconds.
+		maxMilliseconds = maxSeconds * 1e3
+		maxMicroseconds = maxMilliseconds * 1e3
+	)
+	abs := timestamp
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < maxSeconds:
+		return time.Unix(timestamp, 0)
+	case abs < maxMilliseconds:
+		return time.UnixMilli(timestamp)
+	case abs < maxMicroseconds:
+		return time.UnixMicro(timestamp)
+	default:
+		return time.Unix(0, timestamp)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+// logstashTimestampRegexp matches ISO8601 UTC time with millisecond precision.
+var logstashTimestampRegexp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}Z$`)
+
+func TestLogstashFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fields pass-through", testLogstashFormatterFieldsPassThrough)
+	t.Run("timestamp regardless of time format", testLogstashFormatterTimestamp)
+	t.Run("write error", testLogstashFormatterReturnsWriteErr)
+}
+
+func testLogstashFormatterFieldsPassThrough(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+	)
+	commOpts.Time = func() any { return "2022-03-14T16:01:20.123456789+02:00" }
+	commOpts.Source = func() any { return "/app/user.go:42" }
+	subject := xlog.LogstashFormatter(commOpts)
+	keyValues := commOpts.WithDefaultKeyValues(
+		xlog.LevelError,
+		xlog.MessageKey, "could not save user",
+		xlog.ErrorKey, errors.New("connection refused"),
+		"userId", 123,
+		"tags", []string{"a", "b"},
+		"level", "not the logstash level",
+	)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	var event map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &event); err != nil {
+		t.Fatal(err.Error())
+	}
+	assertEqual(
+		t,
+		map[string]any{
+			"@timestamp": "2022-03-14T14:01:20.123Z",
+			"@version":   "1",
+			"level":      "ERROR",
+			"message":    "could not save user",
+			"src":        "/app/user.go:42",
+			"err":        "connection refused",
+			"userId":     float64(123),
+			"tags":       []any{"a", "b"},
+		},
+		event,
+	)
+}
+
+func testLogstashFormatterTimestamp(t *testing.T) {
+	t.Parallel()
+
+	someTime := time.Date(2022, 3, 14, 16, 1, 20, 987654321, time.UTC)
+	tests := [...]struct {
+		name              string
+		timeLayout        string
+		timeValue         any
+		expectedTimestamp string
+	}{
+		{
+			name:              "RFC3339Nano string",
+			timeLayout:        time.RFC3339Nano,
+			timeValue:         someTime.Format(time.RFC3339Nano),
+			expectedTimestamp: "2022-03-14T16:01:20.987Z",
+		},
+		{
+			name:              "no fraction of seconds",
+			timeLayout:        time.RFC3339,
+			timeValue:         someTime.Format(time.RFC3339),
+			expectedTimestamp: "2022-03-14T16:01:20.000Z",
+		},
+		{
+			name:              "custom layout",
+			timeLayout:        time.RFC1123Z,
+			timeValue:         someTime.Format(time.RFC1123Z),
+			expectedTimestamp: "2022-03-14T16:01:20.000Z",
+		},
+		{
+			name:              "time.Time",
+			timeLayout:        "",
+			timeValue:         someTime,
+			expectedTimestamp: "2022-03-14T16:01:20.987Z",
+		},
+		{
+			name:              "unix float seconds",
+			timeLayout:        time.RFC3339Nano,
+			timeValue:         1647273680.5,
+			expectedTimestamp: "2022-03-14T16:01:20.500Z",
+		},
+		{
+			name:              "unix seconds",
+			timeLayout:        time.RFC3339Nano,
+			timeValue:         someTime.Unix(),
+			expectedTimestamp: "2022-03-14T16:01:20.000Z",
+		},
+		{
+			name:              "unix milliseconds",
+			timeLayout:        time.RFC3339Nano,
+			timeValue:         someTime.UnixMilli(),
+			expectedTimestamp: "2022-03-14T16:01:20.987Z",
+		},
+		{
+			name:              "unix microseconds",
+			timeLayout:        time.RFC3339Nano,
+			timeValue:         someTime.UnixMicro(),
+			expectedTimestamp: "2022-03-14T16:01:20.987Z",
+		},
+		{
+			name:              "unix nanoseconds",
+			timeLayout:        time.RFC3339Nano,
+			timeValue:         someTime.UnixNano(),
+			expectedTimestamp: "2022-03-14T16:01:20.987Z",
+		},
+		{
+			name:              "unparsable time",
+			timeLayout:        time.RFC3339Nano,
+			timeValue:         "yesterday",
+			expectedTimestamp: "",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				buf      bytes.Buffer
+				commOpts = xlog.NewCommonOpts()
+			)
+			commOpts.TimeLayout = test.timeLayout
+			subject := xlog.LogstashFormatter(commOpts)
+
+			// act
+			err := subject(&buf, []any{commOpts.TimeKey, test.timeValue, xlog.MessageKey, "foo"})
+
+			// assert
+			assertNil(t, err)
+			var event map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &event); err != nil {
+				t.Fatal(err.Error())
+			}
+			timestamp, _ := event["@timestamp"].(string)
+			assertTrue(t, logstashTimestampRegexp.MatchString(timestamp))
+			if test.expectedTimestamp != "" {
+				assertEqual(t, test.expectedTimestamp, timestamp)
+			}
+			assertEqual(t, "1", event["@version"])
+			assertEqual(t, 3, len(event))
+		})
+	}
+}
+
+func testLogstashFormatterReturnsWriteErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.LogstashFormatter(xlog.NewCommonOpts())
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	err := subject(writer, []any{xlog.MessageKey, "foo"})
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func BenchmarkLogstashFormatter(b *testing.B) {
+	var (
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.LogstashFormatter(commOpts)
+		kv       = commOpts.WithDefaultKeyValues(xlog.LevelError, getBenchmarkKeyVals()...)
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_ = subject(io.Discard, kv)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownFormatter is the error returned by [FormatterByName]
+// for a name no formatter was registered under.
+var ErrUnknownFormatter = errors.New("xlog: unknown formatter")
+
+// formatterRegistry holds the registered formatter factories, by name.
+var formatterRegistry = struct {
+	factories map[string]func(*CommonOpts) Formatter
+	mu        sync.RWMutex
+}{
+	factories: map[string]func(*CommonOpts) Formatter{
+		"json":       func(*CommonOpts) Formatter { return JSONFormatter },
+		"logfmt":     func(*CommonOpts) Formatter { return LogfmtFormatter },
+		"text":       TextFormatter,
+		"color-text": ColorTextFormatter,
+		"ecs":        ECSFormatter,
+		"logstash":   LogstashFormatter,
+	},
+}
+
+// RegisterFormatter registers a formatter factory under given name,
+// so that it can be later retrieved with [FormatterByName].
+// This way formatters can be selected from configuration (for example,
+// a "formatter: logfmt" YAML entry).
+// Registering a factory under an already registered name replaces
+// the previous one.
+// Following formatters are pre-registered: "json", "logfmt", "text",
+// "color-text", "ecs", "logstash".
+// It is concurrent safe.
+func RegisterFormatter(name string, factory func(*CommonOpts) Formatter) {
+	formatterRegistry.mu.Lock()
+	formatterRegistry.factories[name] = factory
+	formatterRegistry.mu.Unlock()
+}
+
+// FormatterByName returns the formatter registered under given name,
+// created with given options.
+// An [ErrUnknownFormatter] error is returned if no formatter
+// was registered under that name.
+// It is concurrent safe.
+func FormatterByName(name string, opts *CommonOpts) (Formatter, error) {
+	formatterRegistry.mu.RLock()
+	factory, found := formatterRegistry.factories[name]
+	formatterRegistry.mu.RUnlock()
+
+	if !found {
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormatter, name)
+	}
+
+	return factory(opts), nil
+}
@@ -0,0 +1,131 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestFormatterByName_builtIns(t *testing.T) {
+	t.Parallel()
+
+	commOpts := xlog.NewCommonOpts()
+	keyValues := []any{commOpts.LevelKey, "ERROR", xlog.MessageKey, "foo"}
+	tests := [...]struct {
+		name              string
+		expectedFormatter xlog.Formatter
+	}{
+		{name: "json", expectedFormatter: xlog.JSONFormatter},
+		{name: "logfmt", expectedFormatter: xlog.LogfmtFormatter},
+		{name: "text", expectedFormatter: xlog.TextFormatter(commOpts)},
+		{name: "ecs", expectedFormatter: xlog.ECSFormatter(commOpts)},
+		{name: "logstash", expectedFormatter: xlog.LogstashFormatter(commOpts)},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var buf, expectedBuf bytes.Buffer
+			_ = test.expectedFormatter(&expectedBuf, keyValues)
+
+			// act
+			subject, err := xlog.FormatterByName(test.name, commOpts)
+
+			// assert
+			assertNil(t, err)
+			if assertNotNil(t, subject) {
+				assertNil(t, subject(&buf, keyValues))
+				if test.name != "logstash" { // @timestamp is current time.
+					assertEqual(t, expectedBuf.String(), buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestFormatterByName_unknownName(t *testing.T) {
+	t.Parallel()
+
+	// act
+	subject, err := xlog.FormatterByName("xml", xlog.NewCommonOpts())
+
+	// assert
+	assertTrue(t, errors.Is(err, xlog.ErrUnknownFormatter))
+	assertTrue(t, bytes.Contains([]byte(err.Error()), []byte(`"xml"`)))
+	assertTrue(t, subject == nil)
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		commOpts      = xlog.NewCommonOpts()
+		passedOpts    *xlog.CommonOpts
+		customFmtName = "test-custom-formatter"
+		customFmt     = func(opts *xlog.CommonOpts) xlog.Formatter {
+			passedOpts = opts
+
+			return func(w io.Writer, keyValues []any) error {
+				_, err := w.Write([]byte("custom:" + strconv.Itoa(len(keyValues)) + "\n"))
+
+				return err
+			}
+		}
+		buf bytes.Buffer
+	)
+
+	// act
+	xlog.RegisterFormatter(customFmtName, customFmt)
+	subject, err := xlog.FormatterByName(customFmtName, commOpts)
+
+	// assert
+	assertNil(t, err)
+	assertTrue(t, passedOpts == commOpts)
+	if assertNotNil(t, subject) {
+		assertNil(t, subject(&buf, []any{"foo", "bar"}))
+		assertEqual(t, "custom:2\n", buf.String())
+	}
+}
+
+func TestRegisterFormatter_concurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		wg          sync.WaitGroup
+		goroutines  = 50
+		commOpts    = xlog.NewCommonOpts()
+		nopFmtMaker = func(*xlog.CommonOpts) xlog.Formatter {
+			return func(io.Writer, []any) error { return nil }
+		}
+	)
+
+	// act
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "test-concurrent-formatter-" + strconv.Itoa(i%5)
+			xlog.RegisterFormatter(name, nopFmtMaker)
+			formatter, err := xlog.FormatterByName(name, commOpts)
+			assertNil(t, err)
+			assertNotNil(t, formatter)
+			_, _ = xlog.FormatterByName("json", commOpts)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,197 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+)
+
+const (
+	// rfc5424NilValue is the value used for a missing header field.
+	rfc5424NilValue = "-"
+	// rfc5424Version is the version of the syslog protocol.
+	rfc5424Version = "1"
+	// rfc5424FacilityUser is the "user-level messages" facility.
+	rfc5424FacilityUser = 1
+	// rfc5424TimeLayout is RFC3339 with max 6 digits for fraction of second.
+	rfc5424TimeLayout = "2006-01-02T15:04:05.999999Z07:00"
+	// rfc5424SDNameMaxLen is the max length of a SD-NAME.
+	rfc5424SDNameMaxLen = 32
+	// rfc5424AppNameMaxLen is the max length of APP-NAME.
+	rfc5424AppNameMaxLen = 48
+)
+
+// RFC5424StructuredDataID is the SD-ID of the structured data element
+// key-values are written into by [RFC5424Formatter].
+// 32473 is the private enterprise number reserved for documentation (RFC 5612).
+const RFC5424StructuredDataID = "xlog@32473"
+
+// RFC5424Formatter is a formatter which produces syslog messages as defined
+// by RFC 5424, unlike the [SyslogFormatter], which relies on stdlib's
+// syslog writer (RFC 3164).
+// Example of output:
+// "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID - [xlog@32473 KEY1="VALUE1" KEY2="VALUE2"] MESSAGE".
+// Priority is computed from log's level, with "user" facility,
+// timestamp is taken from the time key (re-parsed with [CommonOpts.TimeLayout],
+// RFC3339Nano, or as Unix float seconds / int64 timestamp), message from [MessageKey] and
+// the rest of key-values are written as structured data params.
+var RFC5424Formatter = func(opts *CommonOpts, appName string) Formatter {
+	var (
+		hostname, _ = os.Hostname()
+		header      = rfc5424Header(hostname, appName, os.Getpid())
+		levels      = levelsByValue(opts)
+		severities  = map[Level]int{
+			LevelNone:     6,
+			LevelDebug:    7,
+			LevelInfo:     6,
+			LevelWarning:  4,
+			LevelError:    3,
+			LevelCritical: 2,
+		}
+	)
+
+	return func(w io.Writer, keyValues []any) error {
+		keyValues = AppendNoValue(keyValues)
+
+		var (
+			timestamp = rfc5424NilValue
+			lvl       = LevelNone
+			msg       string
+			sd        bytes.Buffer
+		)
+		sd.Grow(128)
+		for idx := 0; idx < len(keyValues); idx += 2 {
+			key := keyValues[idx]
+			value := keyValues[idx+1]
+			switch key {
+			case opts.LevelKey:
+				lvl = levels[stringify(value)]
+			case opts.TimeKey:
+				timestamp = rfc5424Timestamp(value, opts.TimeLayout)
+			case MessageKey:
+				msg = stringify(value)
+			default:
+				_ = sd.WriteByte(' ')
+				_, _ = sd.WriteString(rfc5424SDName(stringify(key)))
+				_, _ = sd.WriteString(`="`)
+				rfc5424WriteSDParamValue(&sd, stringify(value))
+				_ = sd.WriteByte('"')
+			}
+		}
+
+		var out bytes.Buffer
+		out.Grow(len(header) + len(timestamp) + sd.Len() + len(msg) + 32)
+		_ = out.WriteByte('<')
+		_, _ = out.WriteString(strconv.Itoa(rfc5424FacilityUser*8 + severities[lvl]))
+		_ = out.WriteByte('>')
+		_, _ = out.WriteString(rfc5424Version)
+		_ = out.WriteByte(' ')
+		_, _ = out.WriteString(timestamp)
+		_, _ = out.WriteString(header)
+		if sd.Len() > 0 {
+			_ = out.WriteByte('[')
+			_, _ = out.WriteString(RFC5424StructuredDataID)
+			_, _ = out.Write(sd.Bytes())
+			_ = out.WriteByte(']')
+		} else {
+			_, _ = out.WriteString(rfc5424NilValue)
+		}
+		if msg != "" {
+			_ = out.WriteByte(' ')
+			_, _ = out.WriteString(msg)
+		}
+		_ = out.WriteByte('\n')
+
+		_, err := w.Write(out.Bytes())
+
+		return err
+	}
+}
+
+// rfc5424Header returns the static part of the header,
+// " HOSTNAME APP-NAME PROCID MSGID ".
+func rfc5424Header(hostname, appName string, pid int) string {
+	return " " + rfc5424HeaderField(hostname, 255) +
+		" " + rfc5424HeaderField(appName, rfc5424AppNameMaxLen) +
+		" " + strconv.Itoa(pid) +
+		" " + rfc5424NilValue + " "
+}
+
+// rfc5424HeaderField returns a header field, made of printable US-ASCII
+// characters, truncated to given max length, or the NILVALUE if empty.
+func rfc5424HeaderField(field string, maxLen int) string {
+	field = rfc5424PrintASCII(field, nil)
+	if field == "" {
+		return rfc5424NilValue
+	}
+	if len(field) > maxLen {
+		field = field[:maxLen]
+	}
+
+	return field
+}
+
+// rfc5424SDName returns a valid SD-NAME from given key: printable US-ASCII
+// characters, except '=', ' ', ']', '"', truncated to max 32 characters.
+func rfc5424SDName(key string) string {
+	name := rfc5424PrintASCII(key, func(c byte) bool {
+		return c == '=' || c == ']' || c == '"'
+	})
+	if name == "" {
+		return "_"
+	}
+	if len(name) > rfc5424SDNameMaxLen {
+		name = name[:rfc5424SDNameMaxLen]
+	}
+
+	return name
+}
+
+// rfc5424PrintASCII replaces characters which are not printable US-ASCII,
+// or which are additionally forbidden, with '_'.
+func rfc5424PrintASCII(s string, forbidden func(c byte) bool) string {
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 33 || c > 126 || (forbidden != nil && forbidden(c)) {
+			if out == nil {
+				out = []byte(s)
+			}
+			out[i] = '_'
+		}
+	}
+	if out == nil {
+		return s
+	}
+
+	return string(out)
+}
+
+// rfc5424WriteSDParamValue writes given value escaping '"', '\' and ']'.
+func rfc5424WriteSDParamValue(buf *bytes.Buffer, value string) {
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"', '\\', ']':
+			_ = buf.WriteByte('\\')
+			_ = buf.WriteByte(c)
+		default:
+			_ = buf.WriteByte(c)
+		}
+	}
+}
+
+// rfc5424Timestamp returns the timestamp from log's time value (parsed
+// as [LogstashFormatter] does), or the NILVALUE if it cannot be determined.
+func rfc5424Timestamp(value any, layout string) string {
+	if t := logstashTime(value, layout); !t.IsZero() {
+		return t.Format(rfc5424TimeLayout)
+	}
+
+	return rfc5424NilValue
+}
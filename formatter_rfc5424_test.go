@@ -0,0 +1,279 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+// rfc5424Regexp matches a RFC 5424 message, capturing:
+// PRI, TIMESTAMP, HOSTNAME, APP-NAME, PROCID, MSGID, STRUCTURED-DATA, MSG.
+var rfc5424Regexp = regexp.MustCompile(
+	`^<(\d{1,3})>1 (\S+) (\S+) (\S+) (\S+) (\S+) (-|\[.*\])(?: (.*))?\n$`,
+)
+
+// rfc5424SDParamRegexp matches a SD-PARAM, capturing the name and the (escaped) value.
+var rfc5424SDParamRegexp = regexp.MustCompile(` ([^= \]"]+)="((?:[^"\\\]]|\\["\\\]])*)"`)
+
+func TestRFC5424Formatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("header and structured data", testRFC5424FormatterHeaderAndStructuredData)
+	t.Run("priority by level", testRFC5424FormatterPriorityByLevel)
+	t.Run("priority by numeric level", testRFC5424FormatterPriorityByNumericLevel)
+	t.Run("no structured data and no message", testRFC5424FormatterNilValues)
+	t.Run("timestamp regardless of time format", testRFC5424FormatterTimestamp)
+	t.Run("write error", testRFC5424FormatterReturnsWriteErr)
+}
+
+func testRFC5424FormatterHeaderAndStructuredData(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf       bytes.Buffer
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.RFC5424Formatter(commOpts, "demo app")
+		keyValues = []any{
+			commOpts.TimeKey, "2022-03-14T16:01:20.123456789Z",
+			commOpts.LevelKey, "ERROR",
+			xlog.MessageKey, "could not save user",
+			"userId", 123,
+			"quote", `say "hi"`,
+			"path", `C:\tmp`,
+			"bracket", "a]b",
+			"invalid key=", "x",
+		}
+		hostname, _ = os.Hostname()
+	)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	matches := rfc5424Regexp.FindStringSubmatch(buf.String())
+	if !assertEqual(t, 9, len(matches)) {
+		t.Logf("output: %q", buf.String())
+
+		return
+	}
+	assertEqual(t, "11", matches[1]) // user facility (1) * 8 + error severity (3).
+	assertEqual(t, "2022-03-14T16:01:20.123456Z", matches[2])
+	assertEqual(t, hostname, matches[3])
+	assertEqual(t, "demo_app", matches[4])
+	assertEqual(t, strconv.Itoa(os.Getpid()), matches[5])
+	assertEqual(t, "-", matches[6])
+	assertEqual(t, "could not save user", matches[8])
+
+	sd := matches[7]
+	assertEqual(t, "["+xlog.RFC5424StructuredDataID, sd[:len(xlog.RFC5424StructuredDataID)+1])
+	params := rfc5424SDParamRegexp.FindAllStringSubmatch(sd, -1)
+	if assertEqual(t, 5, len(params)) {
+		assertEqual(t, []string{"userId", "123"}, params[0][1:])
+		assertEqual(t, []string{"quote", `say \"hi\"`}, params[1][1:])
+		assertEqual(t, []string{"path", `C:\\tmp`}, params[2][1:])
+		assertEqual(t, []string{"bracket", `a\]b`}, params[3][1:])
+		assertEqual(t, []string{"invalid_key_", "x"}, params[4][1:])
+	}
+}
+
+func testRFC5424FormatterPriorityByLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.RFC5424Formatter(commOpts, "app")
+		tests    = map[xlog.Level]string{
+			xlog.LevelDebug:    "15",
+			xlog.LevelInfo:     "14",
+			xlog.LevelWarning:  "12",
+			xlog.LevelError:    "11",
+			xlog.LevelCritical: "10",
+		}
+	)
+
+	for lvl, expectedPri := range tests {
+		var buf bytes.Buffer
+
+		// act
+		err := subject(&buf, []any{commOpts.LevelKey, commOpts.LevelLabels[lvl], xlog.MessageKey, "foo"})
+
+		// assert
+		assertNil(t, err)
+		matches := rfc5424Regexp.FindStringSubmatch(buf.String())
+		if assertEqual(t, 9, len(matches)) {
+			assertEqual(t, expectedPri, matches[1])
+		}
+	}
+}
+
+func testRFC5424FormatterPriorityByNumericLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+	)
+	commOpts.LevelFormatter = xlog.NumericLevelFormatter
+	subject := xlog.RFC5424Formatter(commOpts, "app")
+
+	// act
+	err := subject(&buf, commOpts.WithDefaultKeyValues(xlog.LevelWarning, xlog.MessageKey, "foo"))
+
+	// assert
+	assertNil(t, err)
+	matches := rfc5424Regexp.FindStringSubmatch(buf.String())
+	if assertEqual(t, 9, len(matches)) {
+		assertEqual(t, "12", matches[1])
+	}
+}
+
+func testRFC5424FormatterNilValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.RFC5424Formatter(commOpts, "")
+		someTime = time.Date(2022, 3, 14, 16, 1, 20, 0, time.UTC)
+	)
+
+	// act
+	err := subject(&buf, []any{commOpts.TimeKey, someTime})
+
+	// assert
+	assertNil(t, err)
+	matches := rfc5424Regexp.FindStringSubmatch(buf.String())
+	if assertEqual(t, 9, len(matches)) {
+		assertEqual(t, "14", matches[1]) // no level, user facility + info severity.
+		assertEqual(t, "2022-03-14T16:01:20Z", matches[2])
+		assertEqual(t, "-", matches[4])
+		assertEqual(t, "-", matches[7])
+		assertEqual(t, "", matches[8])
+	}
+
+	// act - unparsable time.
+	buf.Reset()
+	err = subject(&buf, []any{commOpts.TimeKey, "yesterday", xlog.MessageKey, "foo"})
+
+	// assert
+	assertNil(t, err)
+	matches = rfc5424Regexp.FindStringSubmatch(buf.String())
+	if assertEqual(t, 9, len(matches)) {
+		assertEqual(t, "-", matches[2])
+		assertEqual(t, "foo", matches[8])
+	}
+}
+
+func testRFC5424FormatterTimestamp(t *testing.T) {
+	t.Parallel()
+
+	someTime := time.Date(2022, 3, 14, 16, 1, 20, 123456000, time.UTC)
+	tests := [...]struct {
+		name       string
+		timeLayout string
+		timeValue  any
+		expected   time.Time
+	}{
+		{
+			name:       "custom layout",
+			timeLayout: time.RFC1123Z,
+			timeValue:  someTime.Format(time.RFC1123Z),
+			expected:   someTime.Truncate(time.Second),
+		},
+		{
+			name:       "unix float seconds",
+			timeLayout: time.RFC3339Nano,
+			timeValue:  1647273680.5,
+			expected:   time.Date(2022, 3, 14, 16, 1, 20, 500000000, time.UTC),
+		},
+		{
+			name:       "unix milliseconds",
+			timeLayout: time.RFC3339Nano,
+			timeValue:  someTime.UnixMilli(),
+			expected:   someTime.Truncate(time.Millisecond),
+		},
+		{
+			name:       "unix nanoseconds",
+			timeLayout: time.RFC3339Nano,
+			timeValue:  someTime.UnixNano(),
+			expected:   someTime,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				buf      bytes.Buffer
+				commOpts = xlog.NewCommonOpts()
+			)
+			commOpts.TimeLayout = test.timeLayout
+			subject := xlog.RFC5424Formatter(commOpts, "app")
+
+			// act
+			err := subject(&buf, []any{commOpts.TimeKey, test.timeValue})
+
+			// assert
+			assertNil(t, err)
+			matches := rfc5424Regexp.FindStringSubmatch(buf.String())
+			if assertEqual(t, 9, len(matches)) {
+				timestamp, err := time.Parse(time.RFC3339Nano, matches[2])
+				assertNil(t, err)
+				assertTrue(t, test.expected.Equal(timestamp))
+			}
+		})
+	}
+}
+
+func testRFC5424FormatterReturnsWriteErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.RFC5424Formatter(xlog.NewCommonOpts(), "app")
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	err := subject(writer, []any{xlog.MessageKey, "foo"})
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func BenchmarkRFC5424Formatter(b *testing.B) {
+	var (
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.RFC5424Formatter(commOpts, "app")
+		kv       = commOpts.WithDefaultKeyValues(xlog.LevelError, getBenchmarkKeyVals()...)
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_ = subject(io.Discard, kv)
+	}
+}
@@ -15,53 +15,85 @@ import (
 // It can be used for example for local dev environment.
 // Example of output: "TIME SOURCE LEVEL MESSAGE KEY1=VALUE1 KEY2=VALUE2 ...".
 var TextFormatter = func(opts *CommonOpts) Formatter {
+	return NewTextFormatter(opts, TextOptions{})
+}
+
+// TextOptions holds configurations for a text formatter.
+type TextOptions struct {
+	// SliceEncoding defines how slices / arrays values are rendered.
+	// By default, is set to [SliceEncodingGoDefault].
+	SliceEncoding SliceEncoding
+}
+
+// NewTextFormatter instantiates a [TextFormatter], configured
+// with given options.
+func NewTextFormatter(opts *CommonOpts, textOpts TextOptions) Formatter {
 	return func(w io.Writer, keyValues []any) error {
-		keyValues = AppendNoValue(keyValues)
+		return writeText(w, opts, keyValues, nil, textOpts.SliceEncoding)
+	}
+}
 
-		var (
-			time, level, source, msg  string
-			finalOutBuf, extraInfoBuf bytes.Buffer
-			key, value                any
-		)
-		finalOutBuf.Grow(64)
-		extraInfoBuf.Grow(64)
+// writeText writes key-values in [TextFormatter]'s format, applying
+// given style, if not nil, and rendering slices with given encoding.
+func writeText(w io.Writer, opts *CommonOpts, keyValues []any, style *textStyle, sliceEnc SliceEncoding) error {
+	keyValues = AppendNoValue(keyValues)
 
-		for idx := 0; idx < len(keyValues); idx += 2 {
-			key = keyValues[idx]
-			value = keyValues[idx+1]
-			switch key {
-			case opts.LevelKey:
-				level = stringify(value)
-			case opts.TimeKey:
-				time = stringify(value)
-			case opts.SourceKey:
-				source = stringify(value)
-			case MessageKey:
-				msg = stringify(value)
-			default:
+	var (
+		time, level, source, msg  string
+		finalOutBuf, extraInfoBuf bytes.Buffer
+		key, value                any
+	)
+	finalOutBuf.Grow(64)
+	extraInfoBuf.Grow(64)
+
+	for idx := 0; idx < len(keyValues); idx += 2 {
+		key = keyValues[idx]
+		value = keyValues[idx+1]
+		switch key {
+		case opts.LevelKey:
+			level = stringify(value)
+			if style != nil {
+				level = ansiStyled(style.levels[level], level)
+			}
+		case opts.TimeKey:
+			time = stringify(value)
+		case opts.SourceKey:
+			source = stringify(value)
+		case MessageKey:
+			msg = stringify(value)
+			if style != nil {
+				msg = ansiStyled(style.message, msg)
+			}
+		default:
+			value = encodeSlice(value, sliceEnc)
+			if style != nil {
+				_, _ = extraInfoBuf.WriteString(ansiStyled(style.key, stringify(key)))
+				_ = extraInfoBuf.WriteByte('=')
+				_, _ = extraInfoBuf.WriteString(ansiStyled(style.value, stringify(value)))
+			} else {
 				_, _ = extraInfoBuf.WriteString(stringify(key))
 				_ = extraInfoBuf.WriteByte('=')
 				_, _ = extraInfoBuf.WriteString(stringify(value))
-				_ = extraInfoBuf.WriteByte(' ')
 			}
+			_ = extraInfoBuf.WriteByte(' ')
 		}
+	}
 
-		appendTextFinalOutput(&finalOutBuf, []byte(time))
-		appendTextFinalOutput(&finalOutBuf, []byte(source))
-		appendTextFinalOutput(&finalOutBuf, []byte(level))
-		appendTextFinalOutput(&finalOutBuf, []byte(msg))
-		finalOut := append(finalOutBuf.Bytes(), extraInfoBuf.Bytes()...)
-		finalOut[len(finalOut)-1] = '\n' // replace last space with new line
+	appendTextFinalOutput(&finalOutBuf, time)
+	appendTextFinalOutput(&finalOutBuf, source)
+	appendTextFinalOutput(&finalOutBuf, level)
+	appendTextFinalOutput(&finalOutBuf, msg)
+	finalOut := append(finalOutBuf.Bytes(), extraInfoBuf.Bytes()...)
+	finalOut[len(finalOut)-1] = '\n' // replace last space with new line
 
-		_, err := w.Write(finalOut)
+	_, err := w.Write(finalOut)
 
-		return err
-	}
+	return err
 }
 
-func appendTextFinalOutput(buf *bytes.Buffer, info []byte) {
+func appendTextFinalOutput(buf *bytes.Buffer, info string) {
 	if len(info) > 0 {
-		_, _ = buf.Write(info)
+		_, _ = buf.WriteString(info)
 		_ = buf.WriteByte(' ')
 	}
 }
@@ -0,0 +1,163 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// ANSI escape codes used to colorize the output.
+const (
+	ansiReset  = "\033[0m"
+	ansiRed    = "\033[0;31m"
+	ansiYellow = "\033[0;33m"
+	ansiBlue   = "\033[0;34m"
+	ansiCyan   = "\033[0;36m"
+)
+
+// textStyle holds the ANSI codes applied by [writeText].
+type textStyle struct {
+	// levels maps level labels (and stringified level values,
+	// see [CommonOpts.LevelFormatter]) to their ANSI codes.
+	levels map[string]string
+	// key is the ANSI code applied to keys.
+	key string
+	// value is the ANSI code applied to values.
+	value string
+	// message is the ANSI code applied to the message.
+	message string
+}
+
+// ColorTextOptions holds configurations for a color text formatter.
+// Styles are ANSI escape codes (like "\033[2m" - dim, "\033[1m" - bold,
+// "\033[0;32m" - green), applied around the respective segments of the log,
+// only if colors are enabled.
+// By default, no style is applied.
+type ColorTextOptions struct {
+	// KeyStyle is the style applied to the keys of the extra key-values.
+	KeyStyle string
+
+	// ValueStyle is the style applied to the values of the extra key-values.
+	ValueStyle string
+
+	// MessageStyle is the style applied to the message ([MessageKey]'s value).
+	MessageStyle string
+
+	// SliceEncoding defines how slices / arrays values are rendered.
+	// By default, is set to [SliceEncodingGoDefault].
+	SliceEncoding SliceEncoding
+}
+
+// ColorTextFormatter is a [TextFormatter] which colorizes levels
+// (debug - blue, info - cyan, warning - yellow, error and critical - red),
+// if the output supports colors (see below).
+// It can be used for example for local dev environment.
+//
+// Colorization is decided in the following order (environment variables are
+// read once, when the formatter is created, the terminal detection is done once per writer):
+//   - NO_COLOR environment variable is set (to any value) - colors are disabled;
+//   - FORCE_COLOR environment variable is set (to a non empty value) - colors
+//     are enabled, unless its value is "0" or "false", case in which colors
+//     are disabled;
+//   - the writer is a terminal (an [os.File] character device) - colors are enabled.
+//
+// When colors are disabled, the output is the same as [TextFormatter]'s.
+var ColorTextFormatter = func(opts *CommonOpts) Formatter {
+	return NewColorTextFormatter(opts, ColorTextOptions{})
+}
+
+// NewColorTextFormatter instantiates a [ColorTextFormatter],
+// which additionally styles keys, values and message,
+// as configured by given options.
+// Example, for dimmed keys and bold message:
+//
+//	xlog.NewColorTextFormatter(opts, xlog.ColorTextOptions{
+//		KeyStyle:     "\033[2m",
+//		MessageStyle: "\033[1m",
+//	})
+func NewColorTextFormatter(opts *CommonOpts, colorOpts ColorTextOptions) Formatter {
+	style := &textStyle{
+		levels:  make(map[string]string, 2*len(opts.LevelLabels)),
+		key:     colorOpts.KeyStyle,
+		value:   colorOpts.ValueStyle,
+		message: colorOpts.MessageStyle,
+	}
+	for label, lvl := range levelsByValue(opts) {
+		switch lvl {
+		case LevelDebug:
+			style.levels[label] = ansiBlue
+		case LevelInfo:
+			style.levels[label] = ansiCyan
+		case LevelWarning:
+			style.levels[label] = ansiYellow
+		case LevelError, LevelCritical:
+			style.levels[label] = ansiRed
+		}
+	}
+
+	shouldColorize := newColorizeChecker()
+
+	return func(w io.Writer, keyValues []any) error {
+		if !shouldColorize(w) {
+			return writeText(w, opts, keyValues, nil, colorOpts.SliceEncoding)
+		}
+
+		return writeText(w, opts, keyValues, style, colorOpts.SliceEncoding)
+	}
+}
+
+// newColorizeChecker returns a function which tells if output written to
+// a writer should be colorized, honoring NO_COLOR and FORCE_COLOR conventions
+// (read once, here), and falling back to terminal detection (done once per writer).
+// See also https://no-color.org/ and https://force-color.org/ .
+func newColorizeChecker() func(w io.Writer) bool {
+	if _, noColor := os.LookupEnv("NO_COLOR"); noColor {
+		return func(io.Writer) bool { return false }
+	}
+	if forceColor := os.Getenv("FORCE_COLOR"); forceColor != "" {
+		colorize := forceColor != "0" && forceColor != "false"
+
+		return func(io.Writer) bool { return colorize }
+	}
+
+	var terminals sync.Map // *os.File => bool (is terminal)
+
+	return func(w io.Writer) bool {
+		f, isFile := w.(*os.File)
+		if !isFile {
+			return false
+		}
+		if isTerm, found := terminals.Load(f); found {
+			return isTerm.(bool)
+		}
+		isTerm := isTerminal(f)
+		terminals.Store(f, isTerm)
+
+		return isTerm
+	}
+}
+
+// isTerminal returns true if given file is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// ansiStyled returns given text wrapped in given ANSI code and reset code.
+// If code is empty, text is returned as it is.
+func ansiStyled(code, text string) string {
+	if code == "" || text == "" {
+		return text
+	}
+
+	return code + text + ansiReset
+}
@@ -0,0 +1,304 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+// Note: tests in this file are not run in parallel, as they alter environment.
+
+func TestColorTextFormatter_colorizesLevels(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "1")
+
+	tests := [...]struct {
+		lvl           xlog.Level
+		expectedLevel string
+	}{
+		{lvl: xlog.LevelDebug, expectedLevel: "\033[0;34mDEBUG\033[0m"},
+		{lvl: xlog.LevelInfo, expectedLevel: "\033[0;36mINFO\033[0m"},
+		{lvl: xlog.LevelWarning, expectedLevel: "\033[0;33mWARN\033[0m"},
+		{lvl: xlog.LevelError, expectedLevel: "\033[0;31mERROR\033[0m"},
+		{lvl: xlog.LevelCritical, expectedLevel: "\033[0;31mCRITICAL\033[0m"},
+	}
+	for _, test := range tests {
+		// arrange
+		var (
+			buf      bytes.Buffer
+			commOpts = xlog.NewCommonOpts()
+			subject  = xlog.ColorTextFormatter(commOpts)
+		)
+		commOpts.Time = staticTimeProvider
+		commOpts.SourceKey = ""
+		keyValues := commOpts.WithDefaultKeyValues(test.lvl, xlog.MessageKey, "foo", "year", 2022)
+
+		// act
+		err := subject(&buf, keyValues)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, staticTime+" "+test.expectedLevel+" foo year=2022\n", buf.String())
+	}
+}
+
+func TestColorTextFormatter_colorizesNumericLevels(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "1")
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	commOpts.LevelFormatter = xlog.NumericLevelFormatter
+	subject := xlog.ColorTextFormatter(commOpts)
+	keyValues := commOpts.WithDefaultKeyValues(xlog.LevelError, xlog.MessageKey, "foo")
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, staticTime+" \033[0;31m40\033[0m foo\n", buf.String())
+}
+
+func TestColorTextFormatter_honorsEnv(t *testing.T) {
+	tests := [...]struct {
+		name             string
+		env              map[string]string
+		expectedColorize bool
+	}{
+		{
+			name:             "NO_COLOR suppresses colors",
+			env:              map[string]string{"NO_COLOR": "1"},
+			expectedColorize: false,
+		},
+		{
+			name:             "NO_COLOR suppresses colors, even if empty",
+			env:              map[string]string{"NO_COLOR": ""},
+			expectedColorize: false,
+		},
+		{
+			name:             "NO_COLOR takes precedence over FORCE_COLOR",
+			env:              map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"},
+			expectedColorize: false,
+		},
+		{
+			name:             "FORCE_COLOR forces colors",
+			env:              map[string]string{"FORCE_COLOR": "1"},
+			expectedColorize: true,
+		},
+		{
+			name:             "FORCE_COLOR forces colors, any value",
+			env:              map[string]string{"FORCE_COLOR": "true"},
+			expectedColorize: true,
+		},
+		{
+			name:             "FORCE_COLOR=0 suppresses colors",
+			env:              map[string]string{"FORCE_COLOR": "0"},
+			expectedColorize: false,
+		},
+		{
+			name:             "FORCE_COLOR=false suppresses colors",
+			env:              map[string]string{"FORCE_COLOR": "false"},
+			expectedColorize: false,
+		},
+		{
+			name:             "no env, falls back to terminal detection",
+			env:              map[string]string{"FORCE_COLOR": ""},
+			expectedColorize: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			unsetEnv(t, "NO_COLOR")
+			unsetEnv(t, "FORCE_COLOR")
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			// arrange
+			var (
+				commOpts = xlog.NewCommonOpts()
+				subject  = xlog.ColorTextFormatter(commOpts)
+				buf      bytes.Buffer
+				file, _  = os.Create(filepath.Join(t.TempDir(), "log.txt"))
+			)
+			defer file.Close()
+			commOpts.SourceKey = ""
+			keyValues := commOpts.WithDefaultKeyValues(xlog.LevelError, xlog.MessageKey, "foo")
+			expectedLevel := "ERROR"
+			if test.expectedColorize {
+				expectedLevel = "\033[0;31mERROR\033[0m"
+			}
+
+			for _, writer := range [...]io.Writer{&buf, file} {
+				// act
+				err := subject(writer, keyValues)
+
+				// assert
+				assertNil(t, err)
+			}
+			fileContent, _ := os.ReadFile(file.Name())
+			assertTrue(t, bytes.Contains(buf.Bytes(), []byte(" "+expectedLevel+" foo\n")))
+			assertEqual(t, buf.String(), string(fileContent))
+		})
+	}
+}
+
+func TestColorTextFormatter_noColorIsTextFormatterOutput(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	// arrange
+	var (
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.ColorTextFormatter(commOpts)
+		keyValues = commOpts.WithDefaultKeyValues(
+			xlog.LevelWarning,
+			xlog.MessageKey, "foo",
+			"bar", "baz",
+			"no", 10,
+		)
+		buf, textBuf bytes.Buffer
+	)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	_ = xlog.TextFormatter(commOpts)(&textBuf, keyValues)
+	assertEqual(t, textBuf.String(), buf.String())
+}
+
+func TestColorTextFormatter_returnsWriteErr(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "1")
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.ColorTextFormatter(xlog.NewCommonOpts())
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	err := subject(writer, []any{xlog.MessageKey, "foo"})
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func TestNewColorTextFormatter_stylesSegments(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "1")
+
+	// arrange
+	const (
+		dim   = "\033[2m"
+		green = "\033[0;32m"
+		bold  = "\033[1m"
+		reset = "\033[0m"
+	)
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.NewColorTextFormatter(commOpts, xlog.ColorTextOptions{
+			KeyStyle:     dim,
+			ValueStyle:   green,
+			MessageStyle: bold,
+		})
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.Source = func() any { return "/app/user.go:42" }
+	keyValues := commOpts.WithDefaultKeyValues(
+		xlog.LevelInfo,
+		xlog.MessageKey, "Hello World",
+		"year", 2022,
+		"empty", "",
+	)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		staticTime+" /app/user.go:42 \033[0;36mINFO"+reset+" "+
+			bold+"Hello World"+reset+" "+
+			dim+"year"+reset+"="+green+"2022"+reset+" "+
+			dim+"empty"+reset+"=\n",
+		buf.String(),
+	)
+}
+
+func TestNewColorTextFormatter_noColorIsTextFormatterOutput(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	// arrange
+	var (
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.NewColorTextFormatter(commOpts, xlog.ColorTextOptions{
+			KeyStyle:     "\033[2m",
+			ValueStyle:   "\033[0;32m",
+			MessageStyle: "\033[1m",
+		})
+		keyValues = commOpts.WithDefaultKeyValues(
+			xlog.LevelError,
+			xlog.MessageKey, "could not save user",
+			"userId", 123,
+			"odd",
+		)
+		buf, textBuf bytes.Buffer
+	)
+
+	// act
+	err := subject(&buf, keyValues)
+
+	// assert
+	assertNil(t, err)
+	_ = xlog.TextFormatter(commOpts)(&textBuf, keyValues)
+	assertTrue(t, bytes.Equal(textBuf.Bytes(), buf.Bytes()))
+}
+
+// unsetEnv unsets an environment variable for the duration of the test,
+// restoring its value at cleanup.
+func unsetEnv(t *testing.T, name string) {
+	t.Helper()
+
+	t.Setenv(name, "") // registers value restoring at cleanup.
+	_ = os.Unsetenv(name)
+}
+
+func TestColorTextFormatter_readsEnvOnce(t *testing.T) {
+	unsetEnv(t, "NO_COLOR")
+	t.Setenv("FORCE_COLOR", "1")
+
+	// arrange
+	var (
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.ColorTextFormatter(commOpts)
+		buf      bytes.Buffer
+	)
+	t.Setenv("NO_COLOR", "1") // after formatter's creation, not taken into account.
+
+	// act
+	err := subject(&buf, []any{commOpts.LevelKey, "ERROR", xlog.MessageKey, "foo"})
+
+	// assert
+	assertNil(t, err)
+	assertTrue(t, strings.Contains(buf.String(), "\033[0;31mERROR\033[0m"))
+}
@@ -124,3 +124,50 @@ func BenchmarkTextFormatter(b *testing.B) {
 		_ = subject(io.Discard, input)
 	}
 }
+
+func TestNewTextFormatter_sliceEncoding(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name           string
+		sliceEnc       xlog.SliceEncoding
+		expectedResult string
+	}{
+		{
+			name:           "go default",
+			sliceEnc:       xlog.SliceEncodingGoDefault,
+			expectedResult: "Hello World tags=[a b] ids=[1 2]\n",
+		},
+		{
+			name:           "comma joined",
+			sliceEnc:       xlog.SliceEncodingCommaJoined,
+			expectedResult: "Hello World tags=a,b ids=1,2\n",
+		},
+		{
+			name:           "json",
+			sliceEnc:       xlog.SliceEncodingJSON,
+			expectedResult: `Hello World tags=["a","b"] ids=[1,2]` + "\n",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				subject   = xlog.NewTextFormatter(xlog.NewCommonOpts(), xlog.TextOptions{SliceEncoding: test.sliceEnc})
+				keyValues = []any{xlog.MessageKey, "Hello World", "tags", []string{"a", "b"}, "ids", []int{1, 2}}
+				writer    bytes.Buffer
+			)
+
+			// act
+			resultErr := subject(&writer, keyValues)
+
+			// assert
+			assertNil(t, resultErr)
+			assertEqual(t, test.expectedResult, writer.String())
+		})
+	}
+}
@@ -6,9 +6,8 @@ require (
 	github.com/actforgood/xerr v1.4.0
 	github.com/getsentry/sentry-go v0.27.0
 	github.com/go-logfmt/logfmt v0.6.0
+	github.com/go-logr/logr v1.4.3
+	golang.org/x/sys v0.18.0
 )
 
-require (
-	golang.org/x/sys v0.18.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
-)
+require golang.org/x/text v0.14.0 // indirect
@@ -0,0 +1,13 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+// Package grpc provides gRPC server interceptors which log, through
+// an xlog Logger, each call's method, status code and duration.
+// It lives in its own module in order not to add gRPC
+// dependencies to the main xlog module, and it is compiled only with
+// the "grpc" build tag:
+//
+//	go build -tags=grpc
+package grpc
@@ -0,0 +1,22 @@
+module github.com/actforgood/xlog/grpc
+
+go 1.25.0
+
+require (
+	github.com/actforgood/xlog v0.0.0
+	google.golang.org/grpc v1.82.1
+)
+
+require (
+	github.com/actforgood/xerr v1.4.0 // indirect
+	github.com/getsentry/sentry-go v0.27.0 // indirect
+	github.com/go-logfmt/logfmt v0.6.0 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	golang.org/x/net v0.53.0 // indirect
+	golang.org/x/sys v0.43.0 // indirect
+	golang.org/x/text v0.36.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260414002931-afd174a4e478 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+)
+
+replace github.com/actforgood/xlog => ../
@@ -0,0 +1,157 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+//go:build grpc
+
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/actforgood/xlog"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Keys under which interceptors log a call's details.
+const (
+	MethodKey     = "grpc.method"
+	CodeKey       = "grpc.code"
+	DurationMsKey = "grpc.duration_ms"
+)
+
+// Messages calls are logged with.
+const (
+	unaryMsg  = "finished unary call"
+	streamMsg = "finished streaming call"
+)
+
+// InterceptorOptions is a struct holding configurations for
+// [UnaryServerInterceptor] / [StreamServerInterceptor].
+type InterceptorOptions struct {
+	// LevelByCode is a function that returns the level a call
+	// is logged with, based on its status code.
+	// By default (if nil), [DefaultLevelByCode] is used.
+	LevelByCode func(code codes.Code) xlog.Level
+}
+
+// UnaryServerInterceptor returns a gRPC unary server interceptor which logs,
+// through given Logger, each call's method, status code and duration
+// (in milliseconds). The error returned by the handler, if any, is logged
+// under [xlog.ErrorKey].
+//
+// Example of usage:
+//
+//	server := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(xloggrpc.UnaryServerInterceptor(logger, xloggrpc.InterceptorOptions{})),
+//	)
+func UnaryServerInterceptor(logger xlog.Logger, opts InterceptorOptions) grpclib.UnaryServerInterceptor {
+	levelByCode := opts.levelByCode()
+
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpclib.UnaryServerInfo,
+		handler grpclib.UnaryHandler,
+	) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(logger, levelByCode, unaryMsg, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC stream server interceptor which logs,
+// through given Logger, each call's method, status code and duration
+// (in milliseconds). The error returned by the handler, if any, is logged
+// under [xlog.ErrorKey].
+//
+// Example of usage:
+//
+//	server := grpc.NewServer(
+//		grpc.ChainStreamInterceptor(xloggrpc.StreamServerInterceptor(logger, xloggrpc.InterceptorOptions{})),
+//	)
+func StreamServerInterceptor(logger xlog.Logger, opts InterceptorOptions) grpclib.StreamServerInterceptor {
+	levelByCode := opts.levelByCode()
+
+	return func(
+		srv any,
+		ss grpclib.ServerStream,
+		info *grpclib.StreamServerInfo,
+		handler grpclib.StreamHandler,
+	) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(logger, levelByCode, streamMsg, info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+// DefaultLevelByCode maps a gRPC status code to a level:
+// server side errors (Unknown, Unimplemented, Internal, DataLoss) are
+// mapped to error level, conditions which may need attention
+// (DeadlineExceeded, PermissionDenied, ResourceExhausted, FailedPrecondition,
+// Aborted, OutOfRange, Unavailable) to warning level, and the rest
+// (OK and client side errors) to info level.
+func DefaultLevelByCode(code codes.Code) xlog.Level {
+	switch code {
+	case codes.Unknown, codes.Unimplemented, codes.Internal, codes.DataLoss:
+		return xlog.LevelError
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return xlog.LevelWarning
+	default:
+		return xlog.LevelInfo
+	}
+}
+
+// levelByCode returns the configured level mapping, or the default one.
+func (opts InterceptorOptions) levelByCode() func(codes.Code) xlog.Level {
+	if opts.LevelByCode != nil {
+		return opts.LevelByCode
+	}
+
+	return DefaultLevelByCode
+}
+
+// logCall logs a finished call.
+func logCall(
+	logger xlog.Logger,
+	levelByCode func(codes.Code) xlog.Level,
+	msg, method string,
+	start time.Time,
+	err error,
+) {
+	duration := float64(time.Since(start)) / float64(time.Millisecond)
+	code := status.Code(err)
+	keyValues := []any{
+		xlog.MessageKey, msg,
+		MethodKey, method,
+		CodeKey, code.String(),
+		DurationMsKey, duration,
+	}
+	if err != nil {
+		keyValues = append(keyValues, xlog.ErrorKey, err)
+	}
+
+	switch levelByCode(code) {
+	case xlog.LevelCritical:
+		logger.Critical(keyValues...)
+	case xlog.LevelError:
+		logger.Error(keyValues...)
+	case xlog.LevelWarning:
+		logger.Warn(keyValues...)
+	case xlog.LevelInfo:
+		logger.Info(keyValues...)
+	case xlog.LevelDebug:
+		logger.Debug(keyValues...)
+	default:
+		logger.Log(keyValues...)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+//go:build grpc
+
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+	xloggrpc "github.com/actforgood/xlog/grpc"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful call", testUnaryServerInterceptorOK)
+	t.Run("levels by status code", testUnaryServerInterceptorLevels)
+	t.Run("custom level by code", testUnaryServerInterceptorCustomLevel)
+}
+
+func testUnaryServerInterceptorOK(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewCaptureLogger()
+		subject = xloggrpc.UnaryServerInterceptor(logger, xloggrpc.InterceptorOptions{})
+		info    = &grpclib.UnaryServerInfo{FullMethod: "/users.v1.Users/Get"}
+		sleep   = 20 * time.Millisecond
+		handler = func(_ context.Context, req any) (any, error) {
+			time.Sleep(sleep)
+
+			return "resp for " + req.(string), nil
+		}
+	)
+
+	// act
+	resp, err := subject(context.Background(), "req", info, handler)
+
+	// assert
+	assertEqual(t, nil, err)
+	assertEqual(t, "resp for req", resp)
+	records := logger.Records()
+	if assertEqual(t, 1, len(records)) {
+		assertEqual(t, xlog.LevelInfo, records[0].Level)
+		keyValues := records[0].KeyValues
+		if assertEqual(t, 8, len(keyValues)) {
+			assertEqual(
+				t,
+				[]any{
+					xlog.MessageKey, "finished unary call",
+					xloggrpc.MethodKey, "/users.v1.Users/Get",
+					xloggrpc.CodeKey, "OK",
+					xloggrpc.DurationMsKey,
+				},
+				keyValues[:7],
+			)
+			duration, _ := keyValues[7].(float64)
+			expected := float64(sleep) / float64(time.Millisecond)
+			if duration < expected || duration > expected+1000 {
+				t.Errorf("unexpected duration %v", duration)
+			}
+		}
+	}
+}
+
+func testUnaryServerInterceptorLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name         string
+		err          error
+		expectedLvl  xlog.Level
+		expectedCode string
+	}{
+		{
+			name:         "Internal is error",
+			err:          status.Error(codes.Internal, "db is down"),
+			expectedLvl:  xlog.LevelError,
+			expectedCode: "Internal",
+		},
+		{
+			name:         "non status error is Unknown, error",
+			err:          errors.New("plain error"),
+			expectedLvl:  xlog.LevelError,
+			expectedCode: "Unknown",
+		},
+		{
+			name:         "Unavailable is warning",
+			err:          status.Error(codes.Unavailable, "try later"),
+			expectedLvl:  xlog.LevelWarning,
+			expectedCode: "Unavailable",
+		},
+		{
+			name:         "NotFound is info",
+			err:          status.Error(codes.NotFound, "no such user"),
+			expectedLvl:  xlog.LevelInfo,
+			expectedCode: "NotFound",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				logger  = xlog.NewCaptureLogger()
+				subject = xloggrpc.UnaryServerInterceptor(logger, xloggrpc.InterceptorOptions{})
+				info    = &grpclib.UnaryServerInfo{FullMethod: "/users.v1.Users/Get"}
+				handler = func(context.Context, any) (any, error) {
+					return nil, test.err
+				}
+			)
+
+			// act
+			_, err := subject(context.Background(), nil, info, handler)
+
+			// assert
+			assertEqual(t, test.err, err)
+			assertEqual(t, true, logger.HasEntry(test.expectedLvl, xloggrpc.CodeKey, test.expectedCode))
+			assertEqual(t, true, logger.HasEntry(test.expectedLvl, xlog.ErrorKey, test.err))
+		})
+	}
+}
+
+func testUnaryServerInterceptorCustomLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewCaptureLogger()
+		subject = xloggrpc.UnaryServerInterceptor(logger, xloggrpc.InterceptorOptions{
+			LevelByCode: func(code codes.Code) xlog.Level {
+				if code == codes.NotFound {
+					return xlog.LevelCritical
+				}
+
+				return xlog.LevelDebug
+			},
+		})
+		info = &grpclib.UnaryServerInfo{FullMethod: "/users.v1.Users/Get"}
+	)
+
+	// act
+	_, _ = subject(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "no such user")
+	})
+	_, _ = subject(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+
+	// assert
+	assertEqual(t, true, logger.HasEntry(xlog.LevelCritical, xloggrpc.CodeKey, "NotFound"))
+	assertEqual(t, true, logger.HasEntry(xlog.LevelDebug, xloggrpc.CodeKey, "OK"))
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewCaptureLogger()
+		subject = xloggrpc.StreamServerInterceptor(logger, xloggrpc.InterceptorOptions{})
+		info    = &grpclib.StreamServerInfo{FullMethod: "/users.v1.Users/List", IsServerStream: true}
+		someErr = status.Error(codes.DataLoss, "corrupted stream")
+	)
+
+	// act
+	err := subject(nil, nil, info, func(any, grpclib.ServerStream) error {
+		return someErr
+	})
+
+	// assert
+	assertEqual(t, someErr, err)
+	records := logger.Records()
+	if assertEqual(t, 1, len(records)) {
+		assertEqual(t, xlog.LevelError, records[0].Level)
+		keyValues := records[0].KeyValues
+		if assertEqual(t, 10, len(keyValues)) {
+			assertEqual(t, []any{xlog.MessageKey, "finished streaming call"}, keyValues[:2])
+			assertEqual(t, []any{xloggrpc.MethodKey, "/users.v1.Users/List"}, keyValues[2:4])
+			assertEqual(t, []any{xloggrpc.CodeKey, "DataLoss"}, keyValues[4:6])
+			assertEqual(t, xloggrpc.DurationMsKey, keyValues[6])
+			assertEqual(t, []any{xlog.ErrorKey, someErr}, keyValues[8:])
+		}
+	}
+}
+
+func TestDefaultLevelByCode(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	expected := map[codes.Code]xlog.Level{
+		codes.OK:                 xlog.LevelInfo,
+		codes.Canceled:           xlog.LevelInfo,
+		codes.Unknown:            xlog.LevelError,
+		codes.InvalidArgument:    xlog.LevelInfo,
+		codes.DeadlineExceeded:   xlog.LevelWarning,
+		codes.NotFound:           xlog.LevelInfo,
+		codes.AlreadyExists:      xlog.LevelInfo,
+		codes.PermissionDenied:   xlog.LevelWarning,
+		codes.ResourceExhausted:  xlog.LevelWarning,
+		codes.FailedPrecondition: xlog.LevelWarning,
+		codes.Aborted:            xlog.LevelWarning,
+		codes.OutOfRange:         xlog.LevelWarning,
+		codes.Unimplemented:      xlog.LevelError,
+		codes.Internal:           xlog.LevelError,
+		codes.Unavailable:        xlog.LevelWarning,
+		codes.DataLoss:           xlog.LevelError,
+		codes.Unauthenticated:    xlog.LevelInfo,
+	}
+
+	for code, lvl := range expected {
+		// act
+		result := xloggrpc.DefaultLevelByCode(code)
+
+		// assert
+		if !assertEqual(t, lvl, result) {
+			t.Logf("for code %s", code)
+		}
+	}
+}
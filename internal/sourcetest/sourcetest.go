@@ -0,0 +1,18 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+// Package sourcetest provides helpers logging from a package nested
+// in the module, for source related tests.
+package sourcetest
+
+import "github.com/actforgood/xlog"
+
+// LogLine is the line [Log] logs from.
+const LogLine = 17
+
+// Log logs an error with given logger.
+func Log(logger xlog.Logger) {
+	logger.Error(xlog.MessageKey, "nested package log")
+}
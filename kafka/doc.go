@@ -0,0 +1,12 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+// Package kafka provides an xlog writer which publishes logs to Kafka.
+// It lives in its own module in order not to add Kafka client
+// dependencies to the main xlog module, and it is compiled only with
+// the "kafka" build tag:
+//
+//	go build -tags=kafka
+package kafka
@@ -0,0 +1,21 @@
+module github.com/actforgood/xlog/kafka
+
+go 1.23.0
+
+require (
+	github.com/actforgood/xlog v0.0.0
+	github.com/segmentio/kafka-go v0.4.50
+)
+
+require (
+	github.com/actforgood/xerr v1.4.0 // indirect
+	github.com/getsentry/sentry-go v0.27.0 // indirect
+	github.com/go-logfmt/logfmt v0.6.0 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/klauspost/compress v1.16.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+)
+
+replace github.com/actforgood/xlog => ../
@@ -0,0 +1,122 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+//go:build kafka
+
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// Producer is the contract for the component which publishes messages to Kafka.
+// [kafkago.Writer] satisfies it.
+type Producer interface {
+	// WriteMessages publishes given messages.
+	WriteMessages(ctx context.Context, msgs ...kafkago.Message) error
+	// Close flushes pending messages and releases resources.
+	Close() error
+}
+
+// KafkaWriterConfig holds the configuration for a [KafkaWriter].
+type KafkaWriterConfig struct {
+	// Brokers is the list of Kafka brokers addresses.
+	// It is used only if Producer is not set.
+	Brokers []string
+
+	// Topic is the topic logs are published to.
+	Topic string
+
+	// KeyField is the log's field whose value is used as message key,
+	// for partitioning. Logs having the same key go to the same partition.
+	// It can be left empty, case when messages are published without a key.
+	// Note: the key can be extracted only from JSON formatted logs.
+	KeyField string
+
+	// Producer is the producer messages are published with.
+	// If not set, an asynchronous [kafkago.Writer] is created from Brokers,
+	// with a hash balancer (so that key partitioning is honored).
+	Producer Producer
+}
+
+// KafkaWriter is an io.Writer which publishes each written log
+// as a message to a Kafka topic.
+// Each Write call results in a message, so formatter should write a log
+// in a single Write call (like [xlog.JSONFormatter] does).
+// Make sure to call Close at your application shutdown, in order for pending
+// messages to be published.
+type KafkaWriter struct {
+	producer Producer
+	topic    string
+	keyField string
+}
+
+// NewKafkaWriter instantiates a new Kafka writer.
+func NewKafkaWriter(cfg KafkaWriterConfig) *KafkaWriter {
+	producer := cfg.Producer
+	if producer == nil {
+		producer = &kafkago.Writer{
+			Addr:     kafkago.TCP(cfg.Brokers...),
+			Balancer: &kafkago.Hash{},
+			Async:    true,
+		}
+	}
+
+	return &KafkaWriter{
+		producer: producer,
+		topic:    cfg.Topic,
+		keyField: cfg.KeyField,
+	}
+}
+
+// Write publishes given bytes as a message.
+// A trailing new line is not included in message's value.
+func (kw *KafkaWriter) Write(p []byte) (int, error) {
+	// make a copy as p may be reused by caller after Write returns,
+	// while producer may publish the message asynchronously.
+	value := bytes.Clone(bytes.TrimSuffix(p, []byte{'\n'}))
+	msg := kafkago.Message{
+		Topic: kw.topic,
+		Key:   kw.extractKey(value),
+		Value: value,
+	}
+	if err := kw.producer.WriteMessages(context.Background(), msg); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close flushes pending messages and closes the producer.
+func (kw *KafkaWriter) Close() error {
+	return kw.producer.Close()
+}
+
+// extractKey returns the value of the key field from given JSON log,
+// or nil if the key field is not configured / not found.
+func (kw *KafkaWriter) extractKey(log []byte) []byte {
+	if kw.keyField == "" {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(log, &fields); err != nil {
+		return nil
+	}
+	value, found := fields[kw.keyField]
+	if !found || value == nil {
+		return nil
+	}
+	if str, ok := value.(string); ok {
+		return []byte(str)
+	}
+
+	return []byte(fmt.Sprint(value))
+}
@@ -0,0 +1,186 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+//go:build kafka
+
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+	"github.com/actforgood/xlog/kafka"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// fakeProducer is a producer which stores published messages in memory.
+type fakeProducer struct {
+	msgs        []kafkago.Message
+	err         error
+	closeCalled int
+	mu          sync.Mutex
+}
+
+func (p *fakeProducer) WriteMessages(_ context.Context, msgs ...kafkago.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.msgs = append(p.msgs, msgs...)
+
+	return p.err
+}
+
+func (p *fakeProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeCalled++
+
+	return nil
+}
+
+func (p *fakeProducer) Messages() []kafkago.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.msgs
+}
+
+func TestKafkaWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("messages are published to topic", testKafkaWriterPublishesMessages)
+	t.Run("key is extracted from configured field", testKafkaWriterExtractsKey)
+	t.Run("producer error is returned", testKafkaWriterReturnsProducerErr)
+	t.Run("close closes producer", testKafkaWriterClosesProducer)
+}
+
+func testKafkaWriterPublishesMessages(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		producer = new(fakeProducer)
+		subject  = kafka.NewKafkaWriter(kafka.KafkaWriterConfig{
+			Topic:    "logs",
+			Producer: producer,
+		})
+		commOpts = xlog.NewCommonOpts()
+	)
+	commOpts.Time = func() any { return "2021-11-30T16:01:20Z" }
+	commOpts.SourceKey = ""
+	logger := xlog.NewSyncLogger(subject, xlog.SyncLoggerWithOptions(commOpts))
+
+	// act
+	logger.Error(xlog.MessageKey, "foo")
+	logger.Critical(xlog.MessageKey, "bar")
+
+	// assert
+	msgs := producer.Messages()
+	if assertEqual(t, 2, len(msgs)) {
+		assertEqual(t, "logs", msgs[0].Topic)
+		assertEqual(t, `{"date":"2021-11-30T16:01:20Z","lvl":"ERROR","msg":"foo"}`, string(msgs[0].Value))
+		assertEqual(t, []byte(nil), msgs[0].Key)
+		assertEqual(t, "logs", msgs[1].Topic)
+		assertEqual(t, `{"date":"2021-11-30T16:01:20Z","lvl":"CRITICAL","msg":"bar"}`, string(msgs[1].Value))
+		assertEqual(t, []byte(nil), msgs[1].Key)
+	}
+}
+
+func testKafkaWriterExtractsKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		producer = new(fakeProducer)
+		subject  = kafka.NewKafkaWriter(kafka.KafkaWriterConfig{
+			Topic:    "logs",
+			KeyField: "userId",
+			Producer: producer,
+		})
+		tests = [...]struct {
+			name        string
+			log         string
+			expectedKey []byte
+		}{
+			{
+				name:        "string value",
+				log:         `{"msg":"foo","userId":"abc"}` + "\n",
+				expectedKey: []byte("abc"),
+			},
+			{
+				name:        "numeric value",
+				log:         `{"msg":"foo","userId":123}` + "\n",
+				expectedKey: []byte("123"),
+			},
+			{
+				name:        "missing field",
+				log:         `{"msg":"foo"}` + "\n",
+				expectedKey: nil,
+			},
+			{
+				name:        "not a JSON log",
+				log:         `msg=foo userId=abc` + "\n",
+				expectedKey: nil,
+			},
+		}
+	)
+
+	for idx, testData := range tests {
+		// act
+		n, err := subject.Write([]byte(testData.log))
+
+		// assert
+		assertEqual(t, nil, err)
+		assertEqual(t, len(testData.log), n)
+		msgs := producer.Messages()
+		if assertEqual(t, idx+1, len(msgs)) {
+			assertEqual(t, testData.expectedKey, msgs[idx].Key)
+			assertEqual(t, testData.log[:len(testData.log)-1], string(msgs[idx].Value))
+		}
+	}
+}
+
+func testKafkaWriterReturnsProducerErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered producer error")
+		producer    = &fakeProducer{err: expectedErr}
+		subject     = kafka.NewKafkaWriter(kafka.KafkaWriterConfig{
+			Topic:    "logs",
+			Producer: producer,
+		})
+	)
+
+	// act
+	n, err := subject.Write([]byte(`{"msg":"foo"}`))
+
+	// assert
+	assertEqual(t, expectedErr, err)
+	assertEqual(t, 0, n)
+}
+
+func testKafkaWriterClosesProducer(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		producer = new(fakeProducer)
+		subject  = kafka.NewKafkaWriter(kafka.KafkaWriterConfig{
+			Topic:    "logs",
+			Producer: producer,
+		})
+	)
+
+	// act
+	err := subject.Close()
+
+	// assert
+	assertEqual(t, nil, err)
+	assertEqual(t, 1, producer.closeCalled)
+}
@@ -5,6 +5,13 @@
 
 package xlog
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
 // Level of logging.
 type Level byte
 
@@ -28,3 +35,111 @@ const (
 	// LevelCritical is the level for critical logs.
 	LevelCritical Level = 50
 )
+
+// ErrUnknownLevel is the error returned by [ParseLevel] for a text
+// which does not represent a level.
+var ErrUnknownLevel = errors.New("xlog: unknown level")
+
+// ParseLevel returns the level represented by given text.
+// Recognized texts (case insensitive, surrounding spaces ignored) are
+// "debug", "info", "warn" / "warning", "error", "critical".
+// An [ErrUnknownLevel] error is returned for other texts.
+func ParseLevel(text string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarning, nil
+	case "error":
+		return LevelError, nil
+	case "critical":
+		return LevelCritical, nil
+	}
+
+	return LevelNone, fmt.Errorf("%w %q", ErrUnknownLevel, text)
+}
+
+// standardLevels holds the standard levels, in ascending order.
+var standardLevels = [...]Level{LevelDebug, LevelInfo, LevelWarning, LevelError, LevelCritical}
+
+// AllLevels returns the standard levels, in ascending order
+// (debug, info, warning, error, critical).
+// [LevelNone] is not included, as it does not represent a severity.
+// A new slice is returned on each call, it can be freely modified.
+func AllLevels() []Level {
+	levels := make([]Level, len(standardLevels))
+	copy(levels, standardLevels[:])
+
+	return levels
+}
+
+// Next returns the first standard level greater than current one.
+// It saturates at [LevelCritical] (its next level is itself).
+// Example: LevelDebug.Next() == LevelInfo, LevelNone.Next() == LevelDebug.
+func (lvl Level) Next() Level {
+	for _, standardLvl := range standardLevels {
+		if standardLvl > lvl {
+			return standardLvl
+		}
+	}
+
+	return LevelCritical
+}
+
+// Prev returns the first standard level lower than current one.
+// It saturates at [LevelDebug] (its previous level is itself, as
+// is for [LevelNone]).
+// Example: LevelCritical.Prev() == LevelError.
+func (lvl Level) Prev() Level {
+	for idx := len(standardLevels) - 1; idx >= 0; idx-- {
+		if standardLevels[idx] < lvl {
+			return standardLevels[idx]
+		}
+	}
+
+	return LevelDebug
+}
+
+// levelOverrides holds min/max levels set at runtime on a logger,
+// overriding the ones from [CommonOpts].
+// It is safe for concurrent use.
+type levelOverrides struct {
+	minLevel atomic.Pointer[Level]
+	maxLevel atomic.Pointer[Level]
+}
+
+// setMin overrides the minimum level.
+func (lo *levelOverrides) setMin(lvl Level) {
+	lo.minLevel.Store(&lvl)
+}
+
+// setMax overrides the maximum level.
+func (lo *levelOverrides) setMax(lvl Level) {
+	lo.maxLevel.Store(&lvl)
+}
+
+// betweenMinMax returns true if passed level is found in [min, max] interval,
+// where min/max are the overridden levels, if set, or the ones from
+// given options, otherwise.
+func (lo *levelOverrides) betweenMinMax(opts *CommonOpts, lvl Level) bool {
+	minLvl, maxLvl := lo.minLevel.Load(), lo.maxLevel.Load()
+	if minLvl == nil && maxLvl == nil {
+		return opts.BetweenMinMax(lvl)
+	}
+
+	if minLvl != nil {
+		if lvl < *minLvl {
+			return false
+		}
+	} else if lvl < opts.MinLevel() {
+		return false
+	}
+
+	if maxLvl != nil {
+		return lvl <= *maxLvl
+	}
+
+	return lvl <= opts.MaxLevel()
+}
@@ -0,0 +1,133 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		text          string
+		expectedLevel xlog.Level
+		expectedErr   error
+	}{
+		{text: "debug", expectedLevel: xlog.LevelDebug},
+		{text: "INFO", expectedLevel: xlog.LevelInfo},
+		{text: "warn", expectedLevel: xlog.LevelWarning},
+		{text: " Warning ", expectedLevel: xlog.LevelWarning},
+		{text: "error", expectedLevel: xlog.LevelError},
+		{text: "CRITICAL", expectedLevel: xlog.LevelCritical},
+		{text: "notice", expectedLevel: xlog.LevelNone, expectedErr: xlog.ErrUnknownLevel},
+		{text: "", expectedLevel: xlog.LevelNone, expectedErr: xlog.ErrUnknownLevel},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.text, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			lvl, err := xlog.ParseLevel(test.text)
+
+			// assert
+			assertEqual(t, test.expectedLevel, lvl)
+			if test.expectedErr != nil {
+				assertTrue(t, errors.Is(err, test.expectedErr))
+			} else {
+				assertNil(t, err)
+			}
+		})
+	}
+}
+
+func TestAllLevels(t *testing.T) {
+	t.Parallel()
+
+	// act
+	levels := xlog.AllLevels()
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.Level{
+			xlog.LevelDebug,
+			xlog.LevelInfo,
+			xlog.LevelWarning,
+			xlog.LevelError,
+			xlog.LevelCritical,
+		},
+		levels,
+	)
+	for _, lvl := range levels {
+		assertTrue(t, lvl != xlog.LevelNone)
+	}
+
+	// returned slice is a copy.
+	levels[0] = xlog.LevelNone
+	assertEqual(t, xlog.LevelDebug, xlog.AllLevels()[0])
+}
+
+func TestLevel_Next(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		lvl      xlog.Level
+		expected xlog.Level
+	}{
+		{lvl: xlog.LevelNone, expected: xlog.LevelDebug},
+		{lvl: xlog.LevelDebug, expected: xlog.LevelInfo},
+		{lvl: xlog.LevelInfo, expected: xlog.LevelWarning},
+		{lvl: xlog.LevelWarning, expected: xlog.LevelError},
+		{lvl: xlog.LevelError, expected: xlog.LevelCritical},
+		{lvl: xlog.LevelCritical, expected: xlog.LevelCritical},
+		{lvl: xlog.Level(25), expected: xlog.LevelWarning},
+		{lvl: xlog.Level(60), expected: xlog.LevelCritical},
+	}
+
+	for _, test := range tests {
+		// act
+		result := test.lvl.Next()
+
+		// assert
+		if !assertEqual(t, test.expected, result) {
+			t.Logf("for level %d", test.lvl)
+		}
+	}
+}
+
+func TestLevel_Prev(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		lvl      xlog.Level
+		expected xlog.Level
+	}{
+		{lvl: xlog.LevelNone, expected: xlog.LevelDebug},
+		{lvl: xlog.LevelDebug, expected: xlog.LevelDebug},
+		{lvl: xlog.LevelInfo, expected: xlog.LevelDebug},
+		{lvl: xlog.LevelWarning, expected: xlog.LevelInfo},
+		{lvl: xlog.LevelError, expected: xlog.LevelWarning},
+		{lvl: xlog.LevelCritical, expected: xlog.LevelError},
+		{lvl: xlog.Level(25), expected: xlog.LevelInfo},
+		{lvl: xlog.Level(60), expected: xlog.LevelCritical},
+	}
+
+	for _, test := range tests {
+		// act
+		result := test.lvl.Prev()
+
+		// assert
+		if !assertEqual(t, test.expected, result) {
+			t.Logf("for level %d", test.lvl)
+		}
+	}
+}
@@ -35,3 +35,106 @@ type Logger interface {
 	// Log logs arbitrary data.
 	Log(keyValues ...any)
 }
+
+// LevelChecker is an optional interface a Logger can implement
+// to report whether a level is enabled.
+// It is useful to skip expensive computations of key-values for a log
+// that would be ignored anyway.
+type LevelChecker interface {
+	// Enabled returns true if a log with given level would be logged.
+	Enabled(lvl Level) bool
+}
+
+// IsLevelEnabled returns true if a log with given level would be logged
+// by the given logger.
+// If the logger does not implement [LevelChecker], true is returned.
+//
+// Example of usage:
+//
+//	if xlog.IsLevelEnabled(logger, xlog.LevelDebug) {
+//		logger.Debug(expensiveKeyValues()...)
+//	}
+func IsLevelEnabled(logger Logger, lvl Level) bool {
+	if lc, ok := logger.(LevelChecker); ok {
+		return lc.Enabled(lvl)
+	}
+
+	return true
+}
+
+// bareMessageChecker is implemented by loggers knowing whether a leading
+// string of odd key-values is treated as the message, see [CommonOpts.BareMessageAsKey].
+// Decorators adding key-values implement it too, forwarding the check.
+type bareMessageChecker interface {
+	bareMessageAsKey() bool
+}
+
+// isBareMessageAsKey returns true if given logger treats a leading string
+// of odd key-values as the message.
+func isBareMessageAsKey(logger Logger) bool {
+	if bmc, ok := logger.(bareMessageChecker); ok {
+		return bmc.bareMessageAsKey()
+	}
+
+	return false
+}
+
+// splitBareMessage returns the bare message (as a one element slice),
+// if given key-values start with one and given logger treats it so,
+// and the rest of the key-values. This way, decorators adding key-values
+// can keep the bare message first.
+func splitBareMessage(logger Logger, keyValues []any) (msg, rest []any) {
+	if len(keyValues)%2 == 1 {
+		if _, isString := keyValues[0].(string); isString && isBareMessageAsKey(logger) {
+			return keyValues[:1], keyValues[1:]
+		}
+	}
+
+	return nil, keyValues
+}
+
+// LevelLogger is an optional interface a Logger can implement
+// to log with a level known only at runtime.
+type LevelLogger interface {
+	// LogAt logs with given level, as the leveled method corresponding
+	// to it would do. A [LevelNone] log is logged as Log method would do.
+	LogAt(lvl Level, keyValues ...any)
+}
+
+// LogAt logs with given level (derived from an HTTP status, for example),
+// without a switch statement on the caller's side.
+// If the logger implements [LevelLogger], its LogAt method is called,
+// otherwise the logger's method corresponding to the level
+// (the Log method, for a non standard level).
+// Note: this function adds frames to the call stack: one, for loggers
+// implementing [LevelLogger], two, otherwise (the fallback goes through
+// an internal switch function), so you may want to increase
+// [CommonOpts.SourceSkipExtra] accordingly (or the skipped frames of your
+// explicit [SourceProvider]), or, better, call directly the LogAt method
+// of the loggers implementing it.
+func LogAt(logger Logger, lvl Level, keyValues ...any) {
+	if ll, ok := logger.(LevelLogger); ok {
+		ll.LogAt(lvl, keyValues...)
+
+		return
+	}
+	logByLevel(logger, lvl, keyValues...)
+}
+
+// logByLevel calls the logger's method corresponding to given level.
+func logByLevel(logger Logger, lvl Level, keyValues ...any) {
+	switch lvl {
+	case LevelCritical:
+		logger.Critical(keyValues...)
+	case LevelError:
+		logger.Error(keyValues...)
+	case LevelWarning:
+		logger.Warn(keyValues...)
+	case LevelInfo:
+		logger.Info(keyValues...)
+	case LevelDebug:
+		logger.Debug(keyValues...)
+	default:
+		logger.Log(keyValues...)
+	}
+}
@@ -6,10 +6,18 @@
 package xlog
 
 import (
+	"context"
+	"errors"
 	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrCloseTimeout is returned by [AsyncLogger.CloseWithTimeout] if logs
+// could not be processed in the given time.
+var ErrCloseTimeout = errors.New("xlog: close timed out")
+
 // AsyncLogger is a Logger which writes logs asynchronously.
 // Note: if used in a concurrent context, log writes are concurrent safe if only
 // one worker is configured to process the logs. Otherwise, log writes are not
@@ -23,9 +31,49 @@ type AsyncLogger struct {
 	// internal channel where logs are pushed for processing.
 	// its buffer size is 256 by default.
 	// can be set with [AsyncLoggerWithChannelSize] functional option.
-	entriesChan chan []any
+	entriesChan chan asyncEntry
 	// no of workers to start for processing entriesChan.
 	workersNo int
+	// max no. of entries in a batch; 0 means batching is disabled.
+	// can be set with [AsyncLoggerWithBatch] functional option.
+	batchMaxEntries int
+	// max duration an entry waits in a batch before batch gets written.
+	// can be set with [AsyncLoggerWithBatch] functional option.
+	batchMaxDelay time.Duration
+	// batchFormatter formats a batch of entries at once.
+	// can be set with [AsyncLoggerWithBatchFormatter] functional option.
+	batchFormatter BatchFormatter
+	// blockObserver gets called with the duration a log submission blocked
+	// on the full logs channel.
+	// can be set with [AsyncLoggerWithBlockObserver] functional option.
+	blockObserver func(time.Duration)
+	// ordered flag, true means logs are written in the order they were
+	// received, even if multiple workers format them.
+	// can be set with [AsyncLoggerWithOrderedWorkers] functional option.
+	ordered bool
+	// order serializes workers' writes, if ordered flag is enabled.
+	order orderedOutput
+	// min/max levels set at runtime, overriding opts' ones.
+	// can be set with SetMinLevel / SetMaxLevel methods.
+	levels levelOverrides
+	// the level at or above which a [BufferedWriter] gets flushed
+	// right after writing the log. nil means disabled.
+	// can be set with [AsyncLoggerWithFlushOnLevel] functional option.
+	flushLevel *Level
+	// the level at or above which the stack trace is captured and logged.
+	// nil means disabled.
+	// can be set with [AsyncLoggerWithStackTrace] functional option.
+	stackLevel *Level
+	// max no. of frames of a captured stack trace.
+	stackMaxDepth int
+	// sequence number of the last log, see [CommonOpts.SequenceKey].
+	seq atomic.Uint64
+	// pooling flag, true means key-values slices are reused across logs.
+	// can be set with [AsyncLoggerWithPooling] functional option.
+	pooling bool
+	// concurrency semaphore to make assigning the sequence number and
+	// sending the log on the channel atomic.
+	seqMu sync.Mutex
 	// common options for this logger.
 	// can be set with [AsyncLoggerWithOptions] functional option.
 	opts *CommonOpts
@@ -35,9 +83,45 @@ type AsyncLogger struct {
 	closed bool
 	// concurrency semaphore to protect closed flag access.
 	closeMu sync.RWMutex
+	// gets closed when closing starts, unblocking log submissions waiting
+	// for room in entriesChan (which hold closeMu's read lock), so that
+	// closing does not wait for a stuck writer to make room.
+	closingCh chan struct{}
+	// ensures closingCh is closed once.
+	closingOnce sync.Once
 	// wait group to synchronize internal started goroutine(s) with Close method,
 	// to wait for entriesChan to be drained, and all logs processed.
 	wg sync.WaitGroup
+	// concurrency semaphore to serialize Flush calls.
+	flushMu sync.Mutex
+	// ensures draining is performed once.
+	drainOnce sync.Once
+	// gets closed when all logs were processed and the writer stopped.
+	drainedCh chan struct{}
+	// the error occurred while stopping the writer, if any.
+	// it's safe to read it after drainedCh is closed.
+	closeErr error
+}
+
+// asyncEntry is a log entry pushed for async processing.
+type asyncEntry struct {
+	// the log's key-values.
+	keyVals []any
+	// the pooled slice keyVals reside in, if pooling is enabled.
+	pooled *[]any
+	// the log's level.
+	lvl Level
+	// if not nil, the entry is a flush marker, not a log;
+	// each worker reaching it signals it and waits for the others
+	// (see [AsyncLogger.Flush]).
+	flushMarker *sync.WaitGroup
+}
+
+// release puts back entry's key-values slice into the pool, if it was taken from it.
+func (entry asyncEntry) release() {
+	if entry.pooled != nil {
+		putKeyVals(entry.pooled)
+	}
 }
 
 // NewAsyncLogger instantiates a new logger object that writes logs
@@ -52,6 +136,8 @@ func NewAsyncLogger(w io.Writer, opts ...AsyncLoggerOption) *AsyncLogger {
 		writer:    w,
 		formatter: JSONFormatter,
 		workersNo: 1,
+		drainedCh: make(chan struct{}),
+		closingCh: make(chan struct{}),
 	}
 
 	// apply options, if any.
@@ -65,7 +151,7 @@ func NewAsyncLogger(w io.Writer, opts ...AsyncLoggerOption) *AsyncLogger {
 	// if no option was provided for entriesChan, use default.
 	if logger.entriesChan == nil {
 		const defaultEntriesChanSize = 256
-		logger.entriesChan = make(chan []any, defaultEntriesChanSize)
+		logger.entriesChan = make(chan asyncEntry, defaultEntriesChanSize)
 	}
 
 	// start internal goroutine(s) that will log entries async.
@@ -78,6 +164,12 @@ func NewAsyncLogger(w io.Writer, opts ...AsyncLoggerOption) *AsyncLogger {
 func (logger *AsyncLogger) startWorkers() {
 	logger.wg.Add(logger.workersNo)
 	worker := logger.logAsync
+	if logger.batchMaxEntries > 0 {
+		worker = logger.logAsyncBatch
+	} else if logger.ordered {
+		logger.order.cond = sync.NewCond(&logger.order.mu)
+		worker = logger.logAsyncOrdered
+	}
 	for i := 0; i < logger.workersNo; i++ {
 		go worker()
 	}
@@ -88,75 +180,236 @@ func (logger *AsyncLogger) startWorkers() {
 func (logger *AsyncLogger) logAsync() {
 	defer logger.wg.Done() // notify waiting thread work is finished.
 
-	for keyVals := range logger.entriesChan {
+	for entry := range logger.entriesChan {
+		if entry.flushMarker != nil {
+			entry.flushMarker.Done()
+			entry.flushMarker.Wait() // wait for the other workers to reach the marker.
+
+			continue
+		}
+
 		// format the log.
-		if err := logger.formatter(logger.writer, keyVals); err != nil {
-			logger.opts.ErrHandler(err, keyVals)
+		if err := safeFormat(logger.formatter, logger.writer, entry.keyVals); err != nil {
+			logger.opts.ErrHandler(err, entry.keyVals)
 		}
+		if err := flushOnLevel(logger.writer, logger.flushLevel, entry.lvl); err != nil {
+			logger.opts.ErrHandler(err, entry.keyVals)
+		}
+		entry.release()
 	}
 }
 
 // Critical logs application component unavailable, fatal events.
 func (logger *AsyncLogger) Critical(keyValues ...any) {
-	logger.pushLog(LevelCritical, keyValues...)
+	logger.pushLog(context.Background(), LevelCritical, keyValues...)
 }
 
 // Error logs runtime errors that
 // should typically be logged and monitored.
 func (logger *AsyncLogger) Error(keyValues ...any) {
-	logger.pushLog(LevelError, keyValues...)
+	logger.pushLog(context.Background(), LevelError, keyValues...)
 }
 
 // Warn logs exceptional occurrences that are not errors.
 // Example: Use of deprecated APIs, poor use of an API, undesirable things
 // that are not necessarily wrong.
 func (logger *AsyncLogger) Warn(keyValues ...any) {
-	logger.pushLog(LevelWarning, keyValues...)
+	logger.pushLog(context.Background(), LevelWarning, keyValues...)
 }
 
 // Info logs interesting events.
 // Example: User logs in, SQL logs.
 func (logger *AsyncLogger) Info(keyValues ...any) {
-	logger.pushLog(LevelInfo, keyValues...)
+	logger.pushLog(context.Background(), LevelInfo, keyValues...)
 }
 
 // Debug logs detailed debug information.
 func (logger *AsyncLogger) Debug(keyValues ...any) {
-	logger.pushLog(LevelDebug, keyValues...)
+	logger.pushLog(context.Background(), LevelDebug, keyValues...)
 }
 
 // Log logs arbitrary data.
 func (logger *AsyncLogger) Log(keyValues ...any) {
-	logger.pushLog(LevelNone, keyValues...)
+	logger.pushLog(context.Background(), LevelNone, keyValues...)
+}
+
+// LogAt logs with given level, known only at runtime
+// (derived from an HTTP status, for example).
+// The level is subject to min / max levels filtering, as for the leveled methods.
+func (logger *AsyncLogger) LogAt(lvl Level, keyValues ...any) {
+	logger.pushLog(context.Background(), lvl, keyValues...)
+}
+
+// CriticalCtx is like [AsyncLogger.Critical], but the log submission
+// does not block past given context's done. See [AsyncLogger.LogCtx].
+func (logger *AsyncLogger) CriticalCtx(ctx context.Context, keyValues ...any) {
+	logger.pushLog(ctx, LevelCritical, keyValues...)
+}
+
+// ErrorCtx is like [AsyncLogger.Error], but the log submission
+// does not block past given context's done. See [AsyncLogger.LogCtx].
+func (logger *AsyncLogger) ErrorCtx(ctx context.Context, keyValues ...any) {
+	logger.pushLog(ctx, LevelError, keyValues...)
+}
+
+// WarnCtx is like [AsyncLogger.Warn], but the log submission
+// does not block past given context's done. See [AsyncLogger.LogCtx].
+func (logger *AsyncLogger) WarnCtx(ctx context.Context, keyValues ...any) {
+	logger.pushLog(ctx, LevelWarning, keyValues...)
+}
+
+// InfoCtx is like [AsyncLogger.Info], but the log submission
+// does not block past given context's done. See [AsyncLogger.LogCtx].
+func (logger *AsyncLogger) InfoCtx(ctx context.Context, keyValues ...any) {
+	logger.pushLog(ctx, LevelInfo, keyValues...)
+}
+
+// DebugCtx is like [AsyncLogger.Debug], but the log submission
+// does not block past given context's done. See [AsyncLogger.LogCtx].
+func (logger *AsyncLogger) DebugCtx(ctx context.Context, keyValues ...any) {
+	logger.pushLog(ctx, LevelDebug, keyValues...)
+}
+
+// LogCtx is like [AsyncLogger.Log], but if the internal logs channel is full,
+// the log submission waits at most until given context is done (deadline exceeded /
+// cancelled). In that case the log is dropped, and context's error is reported
+// to [CommonOpts.ErrHandler]. This way you can bound the time logging blocks
+// a request handler, for example, while the application is shutting down.
+// Note: if the channel is not full, the log is submitted even if context is done.
+func (logger *AsyncLogger) LogCtx(ctx context.Context, keyValues ...any) {
+	logger.pushLog(ctx, LevelNone, keyValues...)
+}
+
+// Enabled returns true if a log with given level would be logged.
+// It can be used to avoid expensive computations of key-values
+// for a log that would be ignored anyway.
+func (logger *AsyncLogger) Enabled(lvl Level) bool {
+	return logger.levels.betweenMinMax(logger.opts, lvl)
+}
+
+// bareMessageAsKey returns the [CommonOpts.BareMessageAsKey] flag.
+func (logger *AsyncLogger) bareMessageAsKey() bool {
+	return logger.opts.BareMessageAsKey
+}
+
+// SetMinLevel sets, at runtime, the minimum level allowed to be logged.
+// It overrides the [CommonOpts.MinLevel] for this logger only.
+// It is safe to call it concurrently with logging.
+func (logger *AsyncLogger) SetMinLevel(lvl Level) {
+	logger.levels.setMin(lvl)
+}
+
+// SetMaxLevel sets, at runtime, the maximum level allowed to be logged.
+// It overrides the [CommonOpts.MaxLevel] for this logger only.
+// It is safe to call it concurrently with logging.
+func (logger *AsyncLogger) SetMaxLevel(lvl Level) {
+	logger.levels.setMax(lvl)
+}
+
+// Flush blocks until all the logs queued so far have been processed
+// by the workers, without closing the logger. If the writer is a [BufferedWriter]
+// (or a [MultiWriter] of such writers), it gets flushed, too, and its error, if any, is returned.
+// It can be used to force all the logs out, before a checkpoint, for example.
+// Calling it on a closed logger is a no-op.
+func (logger *AsyncLogger) Flush() error {
+	// push a marker for each worker; a worker blocks on its marker until
+	// all of them reach theirs, so every worker consumes exactly one marker,
+	// after processing the logs queued before it.
+	// flushes are serialized, so that markers of different flushes do not interleave.
+	logger.flushMu.Lock()
+	defer logger.flushMu.Unlock()
+	var marker sync.WaitGroup
+	marker.Add(logger.workersNo + 1)
+	logger.closeMu.RLock()
+	if logger.closed {
+		logger.closeMu.RUnlock()
+
+		return nil
+	}
+sendMarkers:
+	for sent := 0; sent < logger.workersNo; sent++ {
+		select {
+		case logger.entriesChan <- asyncEntry{flushMarker: &marker}:
+		case <-logger.closingCh:
+			// logger is closing, the markers not sent are not waited for,
+			// so that the workers which got one do not wait for them.
+			marker.Add(sent - logger.workersNo)
+
+			break sendMarkers
+		}
+	}
+	logger.closeMu.RUnlock()
+
+	marker.Done()
+	marker.Wait()
+
+	return flushWriter(logger.writer)
 }
 
 // Close nicely closes logger.
 // You should call it to make sure all logs have been processed
 // (for example at your application shutdown).
-// Once called, any further call to any of the logging methods will be ignored.
+// Once called, any further call to any of the logging methods will be ignored,
+// and the ones blocked waiting for room in the full logs channel give up (their logs are dropped).
+// It is safe to be called multiple times, from multiple goroutines; the logs are
+// drained once, and every call returns the same result: the error occurred
+// while stopping the writer (if it is a [BufferedWriter]), if any.
 func (logger *AsyncLogger) Close() error {
+	logger.markClosed()
+	logger.drain()
+	<-logger.drainedCh
+
+	return logger.closeErr
+}
+
+// CloseWithTimeout closes logger like [AsyncLogger.Close], but waits
+// at most given duration for the logs to be processed.
+// If the timeout elapses, the waiting for the logs to be drained is abandoned and
+// [ErrCloseTimeout] is returned. This way, a stuck writer does not hang
+// your application's shutdown.
+func (logger *AsyncLogger) CloseWithTimeout(timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	go func() {
+		logger.markClosed()
+		logger.drain()
+	}()
+
+	select {
+	case <-logger.drainedCh:
+		return logger.closeErr
+	case <-timer.C:
+		return ErrCloseTimeout
+	}
+}
+
+// markClosed marks the logger as closed and closes the logs channel,
+// if not already done.
+func (logger *AsyncLogger) markClosed() {
+	logger.closingOnce.Do(func() {
+		close(logger.closingCh) // unblock log submissions waiting for room in chan.
+	})
 	logger.closeMu.Lock()
 	defer logger.closeMu.Unlock()
 
 	if !logger.closed {
 		logger.closed = true      // mark logger as closed.
 		close(logger.entriesChan) // close log entries chan.
-		logger.wg.Wait()          // wait for workers to process any entry left in chan.
-
-		if bw, ok := logger.writer.(*BufferedWriter); ok {
-			bw.Stop()
-		}
 	}
-
-	return nil
 }
 
-// isClosed returns true if Close method was called, false otherwise.
-func (logger *AsyncLogger) isClosed() bool {
-	logger.closeMu.RLock()
-	defer logger.closeMu.RUnlock()
-
-	return logger.closed
+// drain starts, once, a goroutine which waits for the workers to process
+// any entry left in chan, and then stops the writer.
+// drainedCh gets closed when draining is done.
+func (logger *AsyncLogger) drain() {
+	logger.drainOnce.Do(func() {
+		go func() {
+			defer close(logger.drainedCh)
+			logger.wg.Wait() // wait for workers to process any entry left in chan.
+			logger.closeErr = stopWriter(logger.writer)
+		}()
+	})
 }
 
 // pushLog sends log entry to internal logs channel.
@@ -165,17 +418,73 @@ func (logger *AsyncLogger) isClosed() bool {
 // Using [AsyncLoggerWithChannelSize] to set a higher value to increase
 // throughput in such case can be helpful. Also setting more workers can
 // be helpful, see [AsyncLoggerWithWorkersNo].
-func (logger *AsyncLogger) pushLog(lvl Level, keyValues ...any) {
+// If given context is done while waiting for room in the channel, the log is dropped.
+func (logger *AsyncLogger) pushLog(ctx context.Context, lvl Level, keyValues ...any) {
 	// ignore log conditions check.
-	if !logger.opts.BetweenMinMax(lvl) {
+	if !logger.levels.betweenMinMax(logger.opts, lvl) {
 		return
 	}
 
 	// enrich passed key values with default ones.
-	keyVals := logger.opts.WithDefaultKeyValues(lvl, keyValues...)
+	var (
+		keyVals []any
+		pooled  *[]any
+	)
+	if logger.pooling {
+		pooled = getKeyVals()
+		keyVals = *pooled
+	}
+	keyVals = logger.opts.appendDefaultKeyValues(keyVals, lvl, keyValues)
+	keyVals = appendStackTrace(keyVals, logger.stackLevel, logger.stackMaxDepth, lvl)
 
 	// send log for async processing.
-	if !logger.isClosed() {
-		logger.entriesChan <- keyVals
+	// the read lock is held while sending, so that the chan does not get closed meanwhile;
+	// a send blocked on a full chan gives up once closing starts, releasing the lock.
+	logger.closeMu.RLock()
+	defer logger.closeMu.RUnlock()
+	if logger.closed {
+		if pooled != nil {
+			putKeyVals(pooled)
+		}
+
+		return
+	}
+	if logger.opts.SequenceKey != "" {
+		// sequence is assigned at enqueue time, so that it reflects submission order.
+		logger.seqMu.Lock()
+		defer logger.seqMu.Unlock()
+		keyVals = appendSequence(keyVals, logger.opts.SequenceKey, &logger.seq)
+	}
+	if pooled != nil {
+		*pooled = keyVals // slice may have grown; it is put back into the pool by the worker.
+	}
+	entry := asyncEntry{keyVals: keyVals, pooled: pooled, lvl: lvl}
+	done := ctx.Done()
+	if logger.blockObserver == nil && done == nil {
+		select {
+		case logger.entriesChan <- entry:
+		case <-logger.closingCh: // logger is closing, the log is dropped.
+			entry.release()
+		}
+
+		return
+	}
+	select {
+	case logger.entriesChan <- entry: // fast path, chan is not full.
+		return
+	default: // chan is full, the send blocks.
+	}
+
+	start := time.Now()
+	select {
+	case logger.entriesChan <- entry:
+		if logger.blockObserver != nil {
+			logger.blockObserver(time.Since(start))
+		}
+	case <-done: // a nil chan (no deadline / cancellation) is never ready.
+		logger.opts.ErrHandler(ctx.Err(), keyVals)
+		entry.release()
+	case <-logger.closingCh: // logger is closing, the log is dropped.
+		entry.release()
 	}
 }
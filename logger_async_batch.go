@@ -0,0 +1,131 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+	"time"
+)
+
+// BatchFormatter writes the provided logs (key-values slices) at once,
+// in a given format.
+// Returns error in case something goes wrong.
+type BatchFormatter interface {
+	FormatBatch(w io.Writer, entries [][]any) error
+}
+
+// BatchFormatterFunc is an adapter to allow the use of an ordinary function
+// as a [BatchFormatter].
+type BatchFormatterFunc func(w io.Writer, entries [][]any) error
+
+// FormatBatch calls f(w, entries).
+func (f BatchFormatterFunc) FormatBatch(w io.Writer, entries [][]any) error {
+	return f(w, entries)
+}
+
+// logAsyncBatch processes logs channel and performs the actual logging,
+// in batches.
+// it is meant to be called in another goroutine.
+func (logger *AsyncLogger) logAsyncBatch() {
+	defer logger.wg.Done() // notify waiting thread work is finished.
+
+	var (
+		batch   = make([]asyncEntry, 0, logger.batchMaxEntries)
+		keyVals = make([][]any, 0, logger.batchMaxEntries)
+		timer   = time.NewTimer(time.Hour)
+		timerC  <-chan time.Time // nil while batch is empty, so that select ignores it.
+	)
+	stopTimer(timer)
+	defer timer.Stop()
+
+	writeBatch := func() {
+		if timerC != nil {
+			stopTimer(timer)
+			timerC = nil
+		}
+		keyVals = logger.writeBatch(batch, keyVals[:0])
+		clear(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-logger.entriesChan:
+			if !ok {
+				writeBatch()
+
+				return
+			}
+			if entry.flushMarker != nil {
+				writeBatch()
+				entry.flushMarker.Done()
+				entry.flushMarker.Wait() // wait for the other workers to reach the marker.
+
+				continue
+			}
+
+			batch = append(batch, entry)
+			if len(batch) >= logger.batchMaxEntries {
+				writeBatch()
+			} else if len(batch) == 1 && logger.batchMaxDelay > 0 {
+				timer.Reset(logger.batchMaxDelay)
+				timerC = timer.C
+			}
+		case <-timerC:
+			timerC = nil
+			writeBatch()
+		}
+	}
+}
+
+// writeBatch writes given batch of entries, if any.
+// keyVals is a reusable slice to collect entries' key-values into;
+// it is returned to be reused further.
+func (logger *AsyncLogger) writeBatch(batch []asyncEntry, keyVals [][]any) [][]any {
+	if len(batch) == 0 {
+		return keyVals
+	}
+
+	maxLvl := LevelNone
+	if logger.batchFormatter != nil {
+		for _, entry := range batch {
+			keyVals = append(keyVals, entry.keyVals)
+			maxLvl = max(maxLvl, entry.lvl)
+		}
+		if err := safeFormatBatch(logger.batchFormatter, logger.writer, keyVals); err != nil {
+			for _, entry := range batch {
+				logger.opts.ErrHandler(err, entry.keyVals)
+			}
+		}
+		clear(keyVals)
+	} else {
+		for _, entry := range batch {
+			if err := safeFormat(logger.formatter, logger.writer, entry.keyVals); err != nil {
+				logger.opts.ErrHandler(err, entry.keyVals)
+			}
+			maxLvl = max(maxLvl, entry.lvl)
+		}
+	}
+	if err := flushOnLevel(logger.writer, logger.flushLevel, maxLvl); err != nil {
+		logger.opts.ErrHandler(err, batch[len(batch)-1].keyVals)
+	}
+	for _, entry := range batch {
+		entry.release()
+	}
+
+	return keyVals
+}
+
+// stopTimer stops the timer, draining its channel, if needed,
+// so that it can be safely reset.
+func stopTimer(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+}
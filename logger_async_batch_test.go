@@ -0,0 +1,233 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestAsyncLogger_withBatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("batch is written when full", testAsyncLoggerWithBatchFull)
+	t.Run("batch is written when delay elapses", testAsyncLoggerWithBatchDelay)
+	t.Run("batch is written at flush", testAsyncLoggerWithBatchFlush)
+	t.Run("without batch formatter, logs are formatted one by one", testAsyncLoggerWithBatchNoBatchFormatter)
+	t.Run("batch formatter error is handled", testAsyncLoggerWithBatchFormatterErr)
+	t.Run("batch formatter panic is recovered", testAsyncLoggerWithBatchFormatterPanic)
+}
+
+// batchRecorder records batches passed to FormatBatch.
+type batchRecorder struct {
+	batches [][][]any
+	err     error
+	mu      sync.Mutex
+}
+
+func (br *batchRecorder) FormatBatch(_ io.Writer, entries [][]any) error {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	br.batches = append(br.batches, append([][]any(nil), entries...))
+
+	return br.err
+}
+
+func (br *batchRecorder) batchesSizes() []int {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	sizes := make([]int, len(br.batches))
+	for idx, batch := range br.batches {
+		sizes[idx] = len(batch)
+	}
+
+	return sizes
+}
+
+func testAsyncLoggerWithBatchFull(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		recorder = new(batchRecorder)
+		subject  = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithBatch(5, time.Hour),
+			xlog.AsyncLoggerWithBatchFormatter(recorder),
+		)
+	)
+
+	// act
+	for i := 0; i < 10; i++ {
+		subject.Error("no", i)
+	}
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, []int{5, 5}, recorder.batchesSizes())
+	assertEqual(t, 9, recorder.batches[1][4][len(recorder.batches[1][4])-1])
+}
+
+func testAsyncLoggerWithBatchDelay(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		recorder = new(batchRecorder)
+		subject  = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithBatch(100, 50*time.Millisecond),
+			xlog.AsyncLoggerWithBatchFormatter(recorder),
+		)
+	)
+	defer subject.Close()
+
+	// act
+	for i := 0; i < 3; i++ {
+		subject.Error("no", i)
+	}
+
+	// assert
+	assertEqual(t, 0, len(recorder.batchesSizes())) // batch is not yet written.
+	deadline := time.Now().Add(2 * time.Second)
+	for len(recorder.batchesSizes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assertEqual(t, []int{3}, recorder.batchesSizes())
+}
+
+func testAsyncLoggerWithBatchFlush(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		recorder = new(batchRecorder)
+		subject  = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithBatch(100, 0),
+			xlog.AsyncLoggerWithBatchFormatter(recorder),
+		)
+	)
+	defer subject.Close()
+	for i := 0; i < 3; i++ {
+		subject.Error("no", i)
+	}
+
+	// act
+	err := subject.Flush()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, []int{3}, recorder.batchesSizes())
+}
+
+func testAsyncLoggerWithBatchNoBatchFormatter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithFormatter(formatter.Format),
+			xlog.AsyncLoggerWithBatch(5, time.Hour),
+		)
+	)
+
+	// act
+	for i := 0; i < 7; i++ {
+		subject.Error("no", i)
+	}
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 7, formatter.FormatCallsCount())
+}
+
+func testAsyncLoggerWithBatchFormatterErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		recorder   = &batchRecorder{err: ErrFormat}
+		errHandler = new(MockErrorHandler)
+		commOpts   = xlog.NewCommonOpts()
+		subject    = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithBatch(2, time.Hour),
+			xlog.AsyncLoggerWithBatchFormatter(recorder),
+		)
+	)
+	commOpts.ErrHandler = errHandler.Handle
+	errHandler.SetHandleCallback(func(err error, _ []any) {
+		assertTrue(t, errors.Is(err, ErrFormat))
+	})
+
+	// act
+	subject.Error("no", 1)
+	subject.Error("no", 2)
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, []int{2}, recorder.batchesSizes())
+	assertEqual(t, 2, errHandler.HandleCallsCount()) // error handler is called for each log.
+}
+
+func testAsyncLoggerWithBatchFormatterPanic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer     bytes.Buffer
+		errHandler = new(MockErrorHandler)
+		commOpts   = xlog.NewCommonOpts()
+		formatter  = xlog.BatchFormatterFunc(func(w io.Writer, entries [][]any) error {
+			for _, keyValues := range entries {
+				if err := FormatCallbackPanicOnKey(w, keyValues); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		subject = xlog.NewAsyncLogger(
+			&writer,
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithBatch(2, time.Hour),
+			xlog.AsyncLoggerWithBatchFormatter(formatter),
+		)
+	)
+	commOpts.ErrHandler = errHandler.Handle
+	commOpts.SourceKey = ""
+	commOpts.Time = staticTimeProvider
+	errHandler.SetHandleCallback(func(err error, _ []any) {
+		assertTrue(t, errors.Is(err, xlog.ErrFormatterPanicked))
+	})
+
+	// act
+	subject.Error("no", 1)
+	subject.Error("panic", true)
+	subject.Error("no", 3)
+	subject.Error("no", 4)
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 2, errHandler.HandleCallsCount()) // error handler is called for each log of the batch.
+	assertEqual(
+		t,
+		"date="+staticTime+" lvl=ERROR no=1\n"+
+			"date="+staticTime+" lvl=ERROR no=3\n"+
+			"date="+staticTime+" lvl=ERROR no=4\n",
+		writer.String(),
+	)
+}
@@ -5,6 +5,11 @@
 
 package xlog
 
+import (
+	"io"
+	"time"
+)
+
 // AsyncLoggerOption defines optional function for configuring
 // an async logger.
 type AsyncLoggerOption func(*AsyncLogger)
@@ -18,7 +23,7 @@ type AsyncLoggerOption func(*AsyncLogger)
 // throughput in such case can be helpful.
 func AsyncLoggerWithChannelSize(logsChanSize uint16) AsyncLoggerOption {
 	return func(logger *AsyncLogger) {
-		logger.entriesChan = make(chan []any, logsChanSize)
+		logger.entriesChan = make(chan asyncEntry, logsChanSize)
 	}
 }
 
@@ -42,6 +47,68 @@ func AsyncLoggerWithWorkersNo(workersNo uint16) AsyncLoggerOption {
 	}
 }
 
+// AsyncLoggerWithPooling makes the logger reuse, from a pool, the slices
+// holding logs' key-values, instead of allocating a new one for each log.
+// It reduces allocations on hot paths.
+// A slice is put back into the pool only after a worker consumed the log.
+// Note: as a slice is reused after the log is written, the formatter
+// (batch formatter) and the [CommonOpts.ErrHandler] must not retain
+// the key-values they receive after they return.
+// By default, this feature is disabled.
+func AsyncLoggerWithPooling() AsyncLoggerOption {
+	return func(logger *AsyncLogger) {
+		logger.pooling = true
+	}
+}
+
+// AsyncLoggerWithBlockObserver sets a callback which gets called with the duration
+// a logging call blocked because the internal logs channel was full
+// (the rate of producing messages is higher than consuming one).
+// It is not called if the log was submitted without blocking.
+// It can be used to feed a metric (like a histogram), in order to tune
+// the channel size / no. of workers, see [AsyncLoggerWithChannelSize]
+// and [AsyncLoggerWithWorkersNo].
+// Note: the callback is called synchronously, on the logging goroutine,
+// so it should be fast.
+// By default, no observer is set.
+func AsyncLoggerWithBlockObserver(observer func(d time.Duration)) AsyncLoggerOption {
+	return func(logger *AsyncLogger) {
+		logger.blockObserver = observer
+	}
+}
+
+// AsyncLoggerWithOrderedWorkers makes the logs to be written in the order they
+// were logged, even if multiple workers are configured (see [AsyncLoggerWithWorkersNo]).
+// Workers format the logs in parallel, into in-memory buffers, but the writes are
+// serialized, in logs' order. This way you get formatting throughput, while keeping
+// the output ordered. As a side effect, the writer does not need to be concurrent safe.
+// Note: as logs are formatted into in-memory buffers, formatters which rely on
+// a specific writer (like [SyslogFormatter]) are not supported in this mode.
+// Note: it has no effect if batching is enabled, see [AsyncLoggerWithBatch].
+// By default, this feature is disabled.
+func AsyncLoggerWithOrderedWorkers() AsyncLoggerOption {
+	return func(logger *AsyncLogger) {
+		logger.ordered = true
+	}
+}
+
+// AsyncLoggerWithWriters sets additional writers logs are written to, besides the one
+// the logger was instantiated with (if not nil).
+// Unlike a [MultiLogger] of loggers, a log is formatted only once,
+// and the resulted bytes are written to each writer, through a [MultiWriter].
+// This means all the writers receive identical bytes, so they must all tolerate
+// the chosen format. A failing writer does not stop the write to the rest of them.
+func AsyncLoggerWithWriters(writers ...io.Writer) AsyncLoggerOption {
+	return func(logger *AsyncLogger) {
+		allWriters := make([]io.Writer, 0, len(writers)+1)
+		if logger.writer != nil {
+			allWriters = append(allWriters, logger.writer)
+		}
+		allWriters = append(allWriters, writers...)
+		logger.writer = NewMultiWriter(allWriters...)
+	}
+}
+
 // AsyncLoggerWithFormatter sets desired formatter for the logs.
 // The JSON formatter is used by default.
 func AsyncLoggerWithFormatter(formatter Formatter) AsyncLoggerOption {
@@ -57,3 +124,49 @@ func AsyncLoggerWithOptions(opts *CommonOpts) AsyncLoggerOption {
 		logger.opts = opts
 	}
 }
+
+// AsyncLoggerWithFlushOnLevel makes the logger flush the writer, if it is
+// a [BufferedWriter], right after writing a log with a level at or above
+// given one. This way, for example, an error preceding a crash does not get
+// lost in the buffer.
+// By default, this feature is disabled.
+func AsyncLoggerWithFlushOnLevel(lvl Level) AsyncLoggerOption {
+	return func(logger *AsyncLogger) {
+		logger.flushLevel = &lvl
+	}
+}
+
+// AsyncLoggerWithStackTrace makes the logger capture the current goroutine's stack
+// trace for logs with a level at or above given one, and log it under [StackKey].
+// Frames belonging to this package are skipped.
+// maxDepth limits the no. of frames of the stack trace. If <=0, 32 is used.
+// By default, this feature is disabled.
+func AsyncLoggerWithStackTrace(minLevel Level, maxDepth int) AsyncLoggerOption {
+	return func(logger *AsyncLogger) {
+		logger.stackLevel = &minLevel
+		logger.stackMaxDepth = maxDepth
+	}
+}
+
+// AsyncLoggerWithBatch enables batching: each worker accumulates logs and
+// writes them when the batch is full (maxEntries) or when maxDelay has elapsed
+// since the first log of the batch was received, whichever comes first.
+// It is useful for sinks which are more efficient when writing in bulk.
+// Logs are written with the [BatchFormatter] set with [AsyncLoggerWithBatchFormatter],
+// if any, or one by one, with the configured formatter, otherwise.
+// A maxDelay <= 0 means a batch is written only when full (or at Flush / Close).
+// By default, batching is disabled.
+func AsyncLoggerWithBatch(maxEntries int, maxDelay time.Duration) AsyncLoggerOption {
+	return func(logger *AsyncLogger) {
+		logger.batchMaxEntries = maxEntries
+		logger.batchMaxDelay = maxDelay
+	}
+}
+
+// AsyncLoggerWithBatchFormatter sets the formatter used to write a batch of logs
+// at once. It is used only if batching is enabled, see [AsyncLoggerWithBatch].
+func AsyncLoggerWithBatchFormatter(formatter BatchFormatter) AsyncLoggerOption {
+	return func(logger *AsyncLogger) {
+		logger.batchFormatter = formatter
+	}
+}
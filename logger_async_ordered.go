@@ -0,0 +1,99 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"bytes"
+	"sync"
+)
+
+// orderedOutput serializes the writes of multiple workers,
+// in the order the logs were received from the logs channel.
+type orderedOutput struct {
+	// concurrency semaphore to make receiving an entry and assigning
+	// its sequence number atomic.
+	recvMu sync.Mutex
+	// sequence number to be assigned to the next received entry.
+	received uint64
+	// sequence number of the next entry to be written.
+	next uint64
+	// concurrency semaphore to protect next access.
+	mu sync.Mutex
+	// cond signals workers waiting for their turn to write.
+	cond *sync.Cond
+}
+
+// receive receives an entry from the logs channel, assigning it a sequence number.
+// ok is false if the channel was closed.
+func (oo *orderedOutput) receive(entries <-chan asyncEntry) (entry asyncEntry, seq uint64, ok bool) {
+	oo.recvMu.Lock()
+	defer oo.recvMu.Unlock()
+
+	entry, ok = <-entries
+	if ok && entry.flushMarker == nil {
+		seq = oo.received
+		oo.received++
+	}
+
+	return entry, seq, ok
+}
+
+// waitTurn blocks until all the entries preceding the one with
+// given sequence number are written.
+// It must be followed by a call to [orderedOutput.done].
+func (oo *orderedOutput) waitTurn(seq uint64) {
+	oo.mu.Lock()
+	for oo.next != seq {
+		oo.cond.Wait()
+	}
+}
+
+// done marks the current entry as written, and wakes up the waiting workers.
+func (oo *orderedOutput) done() {
+	oo.next++
+	oo.cond.Broadcast()
+	oo.mu.Unlock()
+}
+
+// logAsyncOrdered processes logs channel and performs the actual logging,
+// formatting logs in parallel with the other workers, but writing them
+// in the order they were received.
+// it is meant to be called in another goroutine.
+func (logger *AsyncLogger) logAsyncOrdered() {
+	defer logger.wg.Done() // notify waiting thread work is finished.
+
+	var buf bytes.Buffer
+	for {
+		entry, seq, ok := logger.order.receive(logger.entriesChan)
+		if !ok {
+			return
+		}
+		if entry.flushMarker != nil {
+			entry.flushMarker.Done()
+			entry.flushMarker.Wait() // wait for the other workers to reach the marker.
+
+			continue
+		}
+
+		// format the log.
+		buf.Reset()
+		err := safeFormat(logger.formatter, &buf, entry.keyVals)
+
+		// write the log, in order.
+		logger.order.waitTurn(seq)
+		if err == nil {
+			_, err = logger.writer.Write(buf.Bytes())
+		}
+		if err != nil {
+			logger.opts.ErrHandler(err, entry.keyVals)
+		}
+		if err := flushOnLevel(logger.writer, logger.flushLevel, entry.lvl); err != nil {
+			logger.opts.ErrHandler(err, entry.keyVals)
+		}
+		logger.order.done()
+		entry.release()
+	}
+}
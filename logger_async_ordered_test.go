@@ -0,0 +1,184 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestAsyncLogger_withOrderedWorkers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("logs are written in order", testAsyncLoggerWithOrderedWorkersKeepsOrder)
+	t.Run("format error is handled and order is kept", testAsyncLoggerWithOrderedWorkersFormatErr)
+	t.Run("flush waits for ordered writes", testAsyncLoggerWithOrderedWorkersFlush)
+}
+
+// slowSeqFormatter is a logfmt formatter which takes longer for some logs,
+// so that workers finish formatting out of order.
+func slowSeqFormatter(w io.Writer, keyValues []any) error {
+	if seq, ok := keyValues[len(keyValues)-1].(int); ok && seq%3 == 0 {
+		time.Sleep(50 * time.Microsecond)
+	}
+
+	return xlog.LogfmtFormatter(w, keyValues)
+}
+
+func testAsyncLoggerWithOrderedWorkersKeepsOrder(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer   bytes.Buffer // not concurrent safe, writes are serialized.
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.NewAsyncLogger(
+			&writer,
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithFormatter(slowSeqFormatter),
+			xlog.AsyncLoggerWithWorkersNo(8),
+			xlog.AsyncLoggerWithChannelSize(64),
+			xlog.AsyncLoggerWithOrderedWorkers(),
+		)
+		goroutinesNo = 20
+		logsNo       = 50
+		wg           sync.WaitGroup
+		counter      int
+		counterMu    sync.Mutex
+	)
+	commOpts.SourceKey = ""
+	commOpts.Time = staticTimeProvider
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < logsNo; j++ {
+				counterMu.Lock() // make counter increment and log submission atomic.
+				counter++
+				subject.Error("seq", counter)
+				counterMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	_ = subject.Close()
+
+	// assert
+	lines := strings.Split(strings.TrimSuffix(writer.String(), "\n"), "\n")
+	if assertEqual(t, goroutinesNo*logsNo, len(lines)) {
+		for idx, line := range lines {
+			expectedLine := "date=" + staticTime + " lvl=ERROR seq=" + strconv.Itoa(idx+1)
+			if !assertEqual(t, expectedLine, line) {
+				break
+			}
+		}
+	}
+}
+
+func testAsyncLoggerWithOrderedWorkersFormatErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer     bytes.Buffer
+		errHandler = new(MockErrorHandler)
+		commOpts   = xlog.NewCommonOpts()
+		subject    = xlog.NewAsyncLogger(
+			&writer,
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithFormatter(func(w io.Writer, keyValues []any) error {
+				if keyValues[len(keyValues)-1] == 2 {
+					return ErrFormat
+				}
+
+				return slowSeqFormatter(w, keyValues)
+			}),
+			xlog.AsyncLoggerWithWorkersNo(4),
+			xlog.AsyncLoggerWithOrderedWorkers(),
+		)
+	)
+	commOpts.ErrHandler = errHandler.Handle
+	commOpts.SourceKey = ""
+	commOpts.Time = staticTimeProvider
+	errHandler.SetHandleCallback(func(err error, keyVals []any) {
+		assertTrue(t, errors.Is(err, ErrFormat))
+		assertEqual(t, []any{"date", staticTime, "lvl", "ERROR", "seq", 2}, keyVals)
+	})
+
+	// act
+	for i := 1; i <= 4; i++ {
+		subject.Error("seq", i)
+	}
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 1, errHandler.HandleCallsCount())
+	assertEqual(
+		t,
+		"date="+staticTime+" lvl=ERROR seq=1\n"+
+			"date="+staticTime+" lvl=ERROR seq=3\n"+
+			"date="+staticTime+" lvl=ERROR seq=4\n",
+		writer.String(),
+	)
+}
+
+func testAsyncLoggerWithOrderedWorkersFlush(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer   bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.NewAsyncLogger(
+			&writer,
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithFormatter(slowSeqFormatter),
+			xlog.AsyncLoggerWithWorkersNo(4),
+			xlog.AsyncLoggerWithOrderedWorkers(),
+		)
+	)
+	defer subject.Close()
+	commOpts.SourceKey = ""
+	commOpts.Time = staticTimeProvider
+
+	for i := 1; i <= 10; i++ {
+		subject.Error("seq", i)
+	}
+
+	// act
+	err := subject.Flush()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 10, strings.Count(writer.String(), "\n"))
+	assertTrue(t, strings.HasSuffix(writer.String(), " seq=10\n"))
+}
+
+func BenchmarkAsyncLogger_json_withDiscardWriter_with256ChanSize_with4OrderedWorkers(b *testing.B) {
+	subject := xlog.NewAsyncLogger(
+		io.Discard,
+		xlog.AsyncLoggerWithWorkersNo(4),
+		xlog.AsyncLoggerWithOrderedWorkers(),
+	)
+	defer subject.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		subject.Error(getBenchmarkKeyVals()...)
+	}
+}
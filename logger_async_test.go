@@ -7,13 +7,17 @@ package xlog_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/actforgood/xlog"
 )
@@ -48,12 +52,12 @@ func ExampleAsyncLogger() {
 	logger.Critical(xlog.MessageKey, "DB connection is down")
 
 	// Unordered output:
-	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","msg":"Hello World","src":"/logger_async_test.go:43","year":2022}
-	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","lvl":"DEBUG","msg":"Hello World","src":"/logger_async_test.go:44","year":2022}
-	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","lvl":"INFO","msg":"Hello World","src":"/logger_async_test.go:45","year":2022}
-	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","lvl":"WARN","msg":"Hello World","src":"/logger_async_test.go:46","year":2022}
-	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","err":"unexpected EOF","file":"/some/file","lvl":"ERROR","msg":"Could not read file","src":"/logger_async_test.go:47"}
-	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","lvl":"CRITICAL","msg":"DB connection is down","src":"/logger_async_test.go:48"}
+	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","msg":"Hello World","src":"/logger_async_test.go:47","year":2022}
+	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","lvl":"DEBUG","msg":"Hello World","src":"/logger_async_test.go:48","year":2022}
+	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","lvl":"INFO","msg":"Hello World","src":"/logger_async_test.go:49","year":2022}
+	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","lvl":"WARN","msg":"Hello World","src":"/logger_async_test.go:50","year":2022}
+	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","err":"unexpected EOF","file":"/some/file","lvl":"ERROR","msg":"Could not read file","src":"/logger_async_test.go:51"}
+	// {"appName":"demo","date":"2022-03-16T16:01:20Z","env":"dev","lvl":"CRITICAL","msg":"DB connection is down","src":"/logger_async_test.go:52"}
 }
 
 func TestAsyncLogger_Log(t *testing.T) {
@@ -253,6 +257,812 @@ func TestAsyncLogger_Close_withBufferedWriter(t *testing.T) {
 	assertTrue(t, strings.Contains(log, "foo bar"))
 }
 
+func TestAsyncLogger_recoversFromFormatterPanic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer     bytes.Buffer
+		formatter  = new(MockFormatter)
+		errHandler = new(MockErrorHandler)
+		commOpts   = xlog.NewCommonOpts()
+		subject    = xlog.NewAsyncLogger(
+			&writer,
+			xlog.AsyncLoggerWithFormatter(formatter.Format),
+			xlog.AsyncLoggerWithOptions(commOpts),
+		)
+	)
+	commOpts.ErrHandler = errHandler.Handle
+	commOpts.SourceKey = ""
+	commOpts.Time = staticTimeProvider
+	formatter.SetFormatCallback(FormatCallbackPanicOnKey)
+	errHandler.SetHandleCallback(func(err error, keyVals []any) {
+		assertTrue(t, errors.Is(err, xlog.ErrFormatterPanicked))
+		assertEqual(t, []any{"date", staticTime, "lvl", "ERROR", "panic", true}, keyVals)
+	})
+
+	// act
+	subject.Error("panic", true)
+	subject.Error("foo", "bar")
+	subject.Error("panic", true)
+	subject.Error("foo", "baz")
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 4, formatter.FormatCallsCount())
+	assertEqual(t, 2, errHandler.HandleCallsCount())
+	assertEqual(
+		t,
+		"date="+staticTime+" lvl=ERROR foo=bar\n"+
+			"date="+staticTime+" lvl=ERROR foo=baz\n",
+		writer.String(),
+	)
+}
+
+func TestAsyncLogger_withFlushOnLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer    = new(MockWriter)
+		commOpts  = xlog.NewCommonOpts()
+		writtenCh = make(chan []byte, 1)
+		bufWriter = xlog.NewBufferedWriter(
+			writer,
+			xlog.BufferedWriterWithSize(1024*1024),
+			xlog.BufferedWriterWithFlushInterval(0),
+		)
+		subject = xlog.NewAsyncLogger(
+			bufWriter,
+			xlog.AsyncLoggerWithFormatter(xlog.LogfmtFormatter),
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithFlushOnLevel(xlog.LevelError),
+		)
+	)
+	defer subject.Close()
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		writtenCh <- append([]byte(nil), p...)
+
+		return len(p), nil
+	})
+
+	// act
+	subject.Info("msg", "info log")
+	subject.Error("msg", "error log")
+
+	// assert - both logs got flushed, without closing the logger.
+	select {
+	case written := <-writtenCh:
+		assertTrue(t, strings.Contains(string(written), "info log"))
+		assertTrue(t, strings.Contains(string(written), "error log"))
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected error log to be flushed")
+	}
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func TestAsyncLogger_withStackTrace(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		commOpts  = xlog.NewCommonOpts()
+		stacks    = make(map[string]string, 2)
+		mu        sync.Mutex
+		subject   = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithFormatter(formatter.Format),
+			xlog.AsyncLoggerWithStackTrace(xlog.LevelError, 2),
+		)
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelWarning)
+	formatter.SetFormatCallback(func(_ io.Writer, keyValues []any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		lvl := keyValues[3].(string)
+		stacks[lvl] = ""
+		if keyValues[len(keyValues)-2] == xlog.StackKey {
+			stacks[lvl] = keyValues[len(keyValues)-1].(string)
+		}
+
+		return nil
+	})
+
+	// act
+	subject.Warn(xlog.MessageKey, "no stack")
+	subject.Error(xlog.MessageKey, "with stack")
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 2, formatter.FormatCallsCount())
+	assertEqual(t, "", stacks["WARN"])
+	stack := stacks["ERROR"]
+	lines := strings.Split(stack, "\n")
+	assertEqual(t, 2, len(lines))
+	assertTrue(t, strings.HasPrefix(lines[0], "github.com/actforgood/xlog_test.TestAsyncLogger_withStackTrace ("))
+	assertTrue(t, strings.Contains(lines[0], "logger_async_test.go:"))
+}
+
+func TestAsyncLogger_Flush(t *testing.T) {
+	t.Parallel()
+
+	t.Run("1 worker", testAsyncLoggerFlush(1))
+	t.Run("4 workers", testAsyncLoggerFlush(4))
+}
+
+func testAsyncLoggerFlush(workersNo uint16) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			writer    = new(MockWriter)
+			bufWriter = xlog.NewBufferedWriter(writer, xlog.BufferedWriterWithFlushInterval(0))
+			formatter = new(MockFormatter)
+			subject   = xlog.NewAsyncLogger(
+				bufWriter,
+				xlog.AsyncLoggerWithFormatter(formatter.Format),
+				xlog.AsyncLoggerWithWorkersNo(workersNo),
+				xlog.AsyncLoggerWithChannelSize(8),
+			)
+			logsNo = 20
+		)
+		defer subject.Close()
+		formatter.SetFormatCallback(func(w io.Writer, _ []any) error {
+			time.Sleep(time.Millisecond) // simulate some work.
+			_, err := w.Write([]byte("x"))
+
+			return err
+		})
+		for i := 0; i < logsNo; i++ {
+			subject.Error("no", i)
+		}
+
+		// act
+		err := subject.Flush()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, logsNo, formatter.FormatCallsCount())
+		assertEqual(t, 1, writer.WriteCallsCount()) // buffered writer got flushed.
+
+		// act - logger is still usable.
+		subject.Error("no", logsNo)
+		err = subject.Flush()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, logsNo+1, formatter.FormatCallsCount())
+		assertEqual(t, 2, writer.WriteCallsCount())
+	}
+}
+
+func TestAsyncLogger_Flush_concurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithWorkersNo(4),
+		)
+		goroutinesNo = 20
+		wg           sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subject.Error("msg", "foo")
+			assertNil(t, subject.Flush())
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assertNil(t, subject.Close())
+	assertNil(t, subject.Flush()) // no-op after close.
+}
+
+func TestAsyncLogger_Close_concurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer    = new(MockWriter)
+		bufWriter = xlog.NewBufferedWriter(writer, xlog.BufferedWriterWithFlushInterval(0))
+		subject   = xlog.NewAsyncLogger(bufWriter)
+		callersNo = 50
+		errs      = make([]error, callersNo)
+		wg        sync.WaitGroup
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+	subject.Error("msg", "foo bar")
+
+	// act
+	for i := 0; i < callersNo; i++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			errs[idx] = subject.Close()
+		}(i)
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, 1, writer.WriteCallsCount()) // logs were drained and flushed once.
+	for _, err := range errs {
+		assertTrue(t, errors.Is(err, ErrWrite)) // stop error is propagated to every caller.
+	}
+	assertTrue(t, errors.Is(subject.Close(), ErrWrite)) // later calls return the same result.
+}
+
+func TestAsyncLogger_CloseWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("logs are processed in time", testAsyncLoggerCloseWithTimeoutSuccessful)
+	t.Run("timeout elapses", testAsyncLoggerCloseWithTimeoutElapses)
+	t.Run("timeout elapses with blocked log submissions", testAsyncLoggerCloseWithTimeoutBlockedSubmissions)
+}
+
+func testAsyncLoggerCloseWithTimeoutSuccessful(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer    bytes.Buffer
+		bufWriter = xlog.NewBufferedWriter(&writer, xlog.BufferedWriterWithFlushInterval(0))
+		subject   = xlog.NewAsyncLogger(bufWriter)
+	)
+	subject.Error("msg", "foo bar")
+
+	// act
+	err := subject.CloseWithTimeout(time.Second)
+
+	// assert
+	assertNil(t, err)
+	assertTrue(t, strings.Contains(writer.String(), "foo bar"))
+	assertNil(t, subject.CloseWithTimeout(time.Second)) // already closed.
+}
+
+func testAsyncLoggerCloseWithTimeoutElapses(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		releaseCh = make(chan struct{})
+		subject   = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithFormatter(formatter.Format),
+		)
+	)
+	defer close(releaseCh)
+	formatter.SetFormatCallback(func(io.Writer, []any) error {
+		<-releaseCh // formatter is stuck until released.
+
+		return nil
+	})
+	subject.Error("msg", "foo bar")
+
+	// act
+	start := time.Now()
+	err := subject.CloseWithTimeout(50 * time.Millisecond)
+	elapsed := time.Since(start)
+
+	// assert
+	assertTrue(t, errors.Is(err, xlog.ErrCloseTimeout))
+	assertTrue(t, elapsed < time.Second)
+}
+
+func testAsyncLoggerCloseWithTimeoutBlockedSubmissions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer    = new(MockWriter)
+		releaseCh = make(chan struct{})
+		subject   = xlog.NewAsyncLogger(
+			writer,
+			xlog.AsyncLoggerWithChannelSize(1),
+		)
+		wg sync.WaitGroup
+	)
+	defer close(releaseCh)
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		<-releaseCh // writer is stuck until released.
+
+		return len(p), nil
+	})
+	// 1st log blocks the worker, 2nd fills the chan, 3rd blocks on the full chan.
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subject.Error("msg", "foo bar")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond) // let the submissions block.
+
+	// act
+	start := time.Now()
+	err := subject.CloseWithTimeout(100 * time.Millisecond)
+	elapsed := time.Since(start)
+
+	// assert
+	assertTrue(t, errors.Is(err, xlog.ErrCloseTimeout))
+	assertTrue(t, elapsed < time.Second)
+	wg.Wait() // blocked submissions gave up.
+}
+
+func TestAsyncLogger_withBlockObserver(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		releaseCh = make(chan struct{})
+		durations []time.Duration
+		mu        sync.Mutex
+		subject   = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithFormatter(formatter.Format),
+			xlog.AsyncLoggerWithChannelSize(1),
+			xlog.AsyncLoggerWithWorkersNo(1),
+			xlog.AsyncLoggerWithBlockObserver(func(d time.Duration) {
+				mu.Lock()
+				durations = append(durations, d)
+				mu.Unlock()
+			}),
+		)
+		throttle = 30 * time.Millisecond
+	)
+	formatter.SetFormatCallback(func(io.Writer, []any) error {
+		<-releaseCh // consumer is stuck until released.
+
+		return nil
+	})
+	subject.Error("msg", "first")  // taken by the worker, which gets stuck.
+	subject.Error("msg", "second") // fills the channel.
+	go func() {
+		time.Sleep(throttle)
+		close(releaseCh)
+	}()
+
+	// act
+	subject.Error("msg", "third") // blocks until the consumer is released.
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 3, formatter.FormatCallsCount())
+	mu.Lock()
+	defer mu.Unlock()
+	if assertTrue(t, len(durations) > 0) {
+		maxDuration := durations[0]
+		for _, d := range durations {
+			assertTrue(t, d > 0)
+			if d > maxDuration {
+				maxDuration = d
+			}
+		}
+		assertTrue(t, maxDuration >= throttle*2/3)
+	}
+}
+
+func TestAsyncLogger_withBlockObserver_notCalledIfNotBlocked(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		observerCallsCnt int
+		subject          = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithChannelSize(10),
+			xlog.AsyncLoggerWithBlockObserver(func(time.Duration) {
+				observerCallsCnt++
+			}),
+		)
+	)
+
+	// act
+	for i := 0; i < 5; i++ {
+		subject.Error("msg", "foo bar")
+	}
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 0, observerCallsCnt)
+}
+
+func TestAsyncLogger_LogCtx(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cancelled context does not block on full channel", testAsyncLoggerLogCtxCancelled)
+	t.Run("deadline bounds blocking on full channel", testAsyncLoggerLogCtxDeadline)
+	t.Run("logs are submitted if channel is not full", testAsyncLoggerLogCtxNotFull)
+}
+
+// makeStuckAsyncLogger returns an async logger whose single worker is stuck
+// until returned chan is closed, and whose channel (of size 1) is full.
+func makeStuckAsyncLogger(errHandler xlog.ErrorHandler) (*xlog.AsyncLogger, *MockFormatter, chan struct{}) {
+	var (
+		formatter = new(MockFormatter)
+		releaseCh = make(chan struct{})
+		commOpts  = xlog.NewCommonOpts()
+		logger    = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithFormatter(formatter.Format),
+			xlog.AsyncLoggerWithChannelSize(1),
+			xlog.AsyncLoggerWithWorkersNo(1),
+		)
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelNone)
+	commOpts.ErrHandler = errHandler
+	formatter.SetFormatCallback(func(io.Writer, []any) error {
+		<-releaseCh // consumer is stuck until released.
+
+		return nil
+	})
+	logger.Error("msg", "first")  // taken by the worker, which gets stuck.
+	logger.Error("msg", "second") // fills the channel.
+
+	return logger, formatter, releaseCh
+}
+
+func testAsyncLoggerLogCtxCancelled(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		errHandler                    = new(MockErrorHandler)
+		subject, formatter, releaseCh = makeStuckAsyncLogger(errHandler.Handle)
+		ctx, cancel                   = context.WithCancel(context.Background())
+		methods                       = []func(context.Context, ...any){
+			subject.CriticalCtx,
+			subject.ErrorCtx,
+			subject.WarnCtx,
+			subject.InfoCtx,
+			subject.DebugCtx,
+			subject.LogCtx,
+		}
+		doneCh = make(chan struct{})
+	)
+	cancel()
+	errHandler.SetHandleCallback(func(err error, keyValues []any) {
+		assertTrue(t, errors.Is(err, context.Canceled))
+		assertEqual(t, "dropped", keyValues[len(keyValues)-1])
+	})
+
+	// act
+	go func() {
+		defer close(doneCh)
+		for _, method := range methods {
+			method(ctx, "msg", "dropped")
+		}
+	}()
+
+	// assert
+	select {
+	case <-doneCh:
+	case <-time.After(time.Second):
+		t.Fatal("logging with a cancelled context blocked")
+	}
+	close(releaseCh)
+	_ = subject.Close()
+	assertEqual(t, len(methods), errHandler.HandleCallsCount())
+	assertEqual(t, 2, formatter.FormatCallsCount())
+}
+
+func testAsyncLoggerLogCtxDeadline(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		errHandler                    = new(MockErrorHandler)
+		subject, formatter, releaseCh = makeStuckAsyncLogger(errHandler.Handle)
+		timeout                       = 30 * time.Millisecond
+		ctx, cancel                   = context.WithTimeout(context.Background(), timeout)
+	)
+	defer cancel()
+	errHandler.SetHandleCallback(func(err error, _ []any) {
+		assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	})
+	start := time.Now()
+
+	// act
+	subject.ErrorCtx(ctx, "msg", "dropped")
+
+	// assert
+	elapsed := time.Since(start)
+	assertTrue(t, elapsed >= timeout*2/3)
+	assertTrue(t, elapsed < time.Second)
+	close(releaseCh)
+	_ = subject.Close()
+	assertEqual(t, 1, errHandler.HandleCallsCount())
+	assertEqual(t, 2, formatter.FormatCallsCount())
+}
+
+func testAsyncLoggerLogCtxNotFull(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer      bytes.Buffer
+		errHandler  = new(MockErrorHandler)
+		commOpts    = xlog.NewCommonOpts()
+		ctx, cancel = context.WithCancel(context.Background())
+		subject     = xlog.NewAsyncLogger(
+			&writer,
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithFormatter(xlog.LogfmtFormatter),
+			xlog.AsyncLoggerWithWorkersNo(1),
+		)
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelNone)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	commOpts.ErrHandler = errHandler.Handle
+	cancel()
+
+	// act
+	subject.ErrorCtx(ctx, "msg", "foo")
+	subject.LogCtx(context.Background(), "msg", "bar")
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 0, errHandler.HandleCallsCount())
+	assertEqual(
+		t,
+		"date="+staticTime+" lvl=ERROR msg=foo\n"+
+			"date="+staticTime+" msg=bar\n",
+		writer.String(),
+	)
+}
+
+func TestAsyncLogger_withSequence(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer   bytes.Buffer // ordered workers serialize writes.
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.NewAsyncLogger(
+			&writer,
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithFormatter(xlog.LogfmtFormatter),
+			xlog.AsyncLoggerWithWorkersNo(4),
+			xlog.AsyncLoggerWithOrderedWorkers(),
+		)
+		goroutinesNo = 10
+		logsNo       = 100
+		wg           sync.WaitGroup
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	commOpts.SequenceKey = "seq"
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < logsNo; j++ {
+				subject.Error("msg", "foo")
+			}
+		}()
+	}
+	wg.Wait()
+	_ = subject.Close()
+
+	// assert
+	// logs are written in submission order, so sequence is written 1..N, without gaps.
+	lines := strings.Split(strings.TrimSuffix(writer.String(), "\n"), "\n")
+	if assertEqual(t, goroutinesNo*logsNo, len(lines)) {
+		for idx, line := range lines {
+			expectedLine := "date=" + staticTime + " lvl=ERROR msg=foo seq=" + strconv.Itoa(idx+1)
+			if !assertEqual(t, expectedLine, line) {
+				break
+			}
+		}
+	}
+}
+
+func TestAsyncLogger_withPooling(t *testing.T) {
+	t.Parallel()
+
+	t.Run("4 workers", testAsyncLoggerWithPooling())
+	t.Run("4 ordered workers", testAsyncLoggerWithPooling(xlog.AsyncLoggerWithOrderedWorkers()))
+	t.Run("4 workers with batch", testAsyncLoggerWithPooling(xlog.AsyncLoggerWithBatch(16, time.Millisecond)))
+}
+
+func testAsyncLoggerWithPooling(opts ...xlog.AsyncLoggerOption) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			writer       = xlog.NewSyncWriter(new(bytes.Buffer))
+			commOpts     = xlog.NewCommonOpts()
+			goroutinesNo = 20
+			logsNo       = 200
+			logged       = make([]atomic.Int32, goroutinesNo*logsNo)
+			subject      = xlog.NewAsyncLogger(
+				writer,
+				append([]xlog.AsyncLoggerOption{
+					xlog.AsyncLoggerWithOptions(commOpts),
+					xlog.AsyncLoggerWithWorkersNo(4),
+					xlog.AsyncLoggerWithChannelSize(8),
+					xlog.AsyncLoggerWithPooling(),
+					xlog.AsyncLoggerWithFormatter(func(w io.Writer, keyValues []any) error {
+						// a reused slice would make the pair below inconsistent.
+						id, _ := keyValues[len(keyValues)-3].(int)
+						idCopy, _ := keyValues[len(keyValues)-1].(int)
+						if id != idCopy {
+							t.Errorf("corrupted key-values: %v", keyValues)
+						} else {
+							logged[id].Add(1)
+						}
+
+						return xlog.LogfmtFormatter(w, keyValues)
+					}),
+				}, opts...)...,
+			)
+			wg sync.WaitGroup
+		)
+		commOpts.Time = staticTimeProvider
+
+		// act
+		for i := 0; i < goroutinesNo; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < logsNo; j++ {
+					id := i*logsNo + j
+					subject.Error("id", id, "idCopy", id)
+				}
+			}(i)
+		}
+		wg.Wait()
+		_ = subject.Close()
+
+		// assert
+		for id := range logged {
+			if !assertEqual(t, int32(1), logged[id].Load()) {
+				break
+			}
+		}
+	}
+}
+
+func TestAsyncLogger_Enabled(t *testing.T) {
+	t.Parallel()
+
+	levels := []xlog.Level{xlog.LevelNone, xlog.LevelDebug, xlog.LevelInfo, xlog.LevelWarning, xlog.LevelError, xlog.LevelCritical}
+	for _, minLvl := range levels {
+		for _, maxLvl := range levels {
+			// arrange
+			var (
+				formatter = new(MockFormatter)
+				commOpts  = xlog.NewCommonOpts()
+				subject   = xlog.NewAsyncLogger(
+					io.Discard,
+					xlog.AsyncLoggerWithFormatter(formatter.Format),
+					xlog.AsyncLoggerWithOptions(commOpts),
+				)
+				expectedFormatCallsCnt int
+			)
+			commOpts.MinLevel = xlog.FixedLevelProvider(minLvl)
+			commOpts.MaxLevel = xlog.FixedLevelProvider(maxLvl)
+
+			for _, lvl := range levels {
+				// act
+				result := subject.Enabled(lvl)
+				callMethodByLevel(subject, lvl)
+
+				// assert
+				assertEqual(t, commOpts.BetweenMinMax(lvl), result)
+				if result {
+					expectedFormatCallsCnt++
+				}
+			}
+			_ = subject.Close()
+			assertEqual(t, expectedFormatCallsCnt, formatter.FormatCallsCount())
+		}
+	}
+}
+
+func TestAsyncLogger_SetMinMaxLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithFormatter(formatter.Format),
+			xlog.AsyncLoggerWithOptions(commOpts),
+		)
+		otherSubject = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithFormatter(formatter.Format),
+			xlog.AsyncLoggerWithOptions(commOpts),
+		)
+	)
+
+	// act & assert
+	subject.Info()
+	otherSubject.Info()
+	_ = otherSubject.Close()
+	subject.SetMinLevel(xlog.LevelDebug)
+	subject.Debug()
+	subject.Info()
+	subject.Critical()
+	subject.SetMaxLevel(xlog.LevelInfo)
+	subject.Debug()
+	subject.Warn()
+	subject.Error()
+	subject.SetMinLevel(xlog.LevelNone)
+	subject.SetMaxLevel(xlog.LevelNone)
+	subject.Log()
+	subject.Debug()
+	_ = subject.Close()
+
+	assertEqual(t, 5, formatter.FormatCallsCount())
+	assertEqual(t, xlog.LevelWarning, commOpts.MinLevel()) // opts were not changed.
+	assertEqual(t, xlog.LevelCritical, commOpts.MaxLevel())
+}
+
+func TestAsyncLogger_SetMinLevel_concurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer       bytes.Buffer
+		subject      = xlog.NewAsyncLogger(xlog.NewSyncWriter(&writer))
+		goroutinesNo = 50
+		logsNo       = 20
+		wg           sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(2)
+		go func(threadNo int) {
+			defer wg.Done()
+			for j := 0; j < logsNo; j++ {
+				subject.Debug("threadNo", threadNo, "logNo", j)
+			}
+		}(i)
+		go func(threadNo int) {
+			defer wg.Done()
+			if threadNo%2 == 0 {
+				subject.SetMinLevel(xlog.LevelDebug)
+			} else {
+				subject.SetMinLevel(xlog.LevelError)
+			}
+			subject.SetMaxLevel(xlog.LevelCritical)
+		}(i)
+	}
+	wg.Wait()
+	subject.SetMinLevel(xlog.LevelError)
+	subject.Debug("lastDebug", "ignored")
+	subject.Error("lastError", "logged")
+	_ = subject.Close()
+
+	// assert
+	output := writer.String()
+	assertTrue(t, strings.Count(output, "\n") <= goroutinesNo*logsNo+1)
+	assertFalse(t, strings.Contains(output, "lastDebug"))
+	assertTrue(t, strings.Contains(output, "lastError"))
+}
+
 func TestAsyncLogger_concurrency(t *testing.T) {
 	t.Parallel()
 
@@ -363,6 +1173,19 @@ func BenchmarkAsyncLogger_json_withDiscardWriter_with256ChanSize_with1Worker_seq
 	}
 }
 
+func BenchmarkAsyncLogger_json_withDiscardWriter_with256ChanSize_with1Worker_withPooling_sequential(b *testing.B) {
+	subject := makeAsyncLogger(io.Discard, 256, 1, xlog.AsyncLoggerWithPooling())
+	defer subject.Close()
+	kv := getBenchmarkKeyVals()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		subject.Error(kv...)
+	}
+}
+
 func BenchmarkAsyncLogger_json_withDiscardWriter_with256ChanSize_with1Worker_parallel(b *testing.B) {
 	subject := makeAsyncLogger(io.Discard, 256, 1)
 	defer subject.Close()
@@ -544,14 +1367,124 @@ func BenchmarkAsyncLogger_json_withFileWriter_with1024ChanSize_with4Workers_with
 }
 
 // makeAsyncLogger creates a new AsyncLogger object.
-func makeAsyncLogger(w io.Writer, chanSize uint16, workersNo uint16) *xlog.AsyncLogger {
+func makeAsyncLogger(w io.Writer, chanSize uint16, workersNo uint16, opts ...xlog.AsyncLoggerOption) *xlog.AsyncLogger {
 	commonOpts := xlog.NewCommonOpts()
 	commonOpts.Source = xlog.SourceProvider(4, 1)
 
 	return xlog.NewAsyncLogger(
 		w,
-		xlog.AsyncLoggerWithOptions(commonOpts),
-		xlog.AsyncLoggerWithChannelSize(chanSize),
-		xlog.AsyncLoggerWithWorkersNo(workersNo),
+		append([]xlog.AsyncLoggerOption{
+			xlog.AsyncLoggerWithOptions(commonOpts),
+			xlog.AsyncLoggerWithChannelSize(chanSize),
+			xlog.AsyncLoggerWithWorkersNo(workersNo),
+		}, opts...)...,
 	)
 }
+
+func TestAsyncLogger_LogAt(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger   = xlog.NewCaptureLogger()
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.NewAsyncLogger(
+			io.Discard,
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithFormatter(func(_ io.Writer, keyValues []any) error {
+				logger.Log(keyValues...)
+
+				return nil
+			}),
+		)
+		levels = []xlog.Level{xlog.LevelNone, xlog.LevelDebug, xlog.LevelInfo, xlog.LevelWarning, xlog.LevelError, xlog.LevelCritical}
+	)
+	commOpts.SourceKey = ""
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+	commOpts.MaxLevel = xlog.FixedLevelProvider(xlog.LevelError)
+
+	// act
+	for _, lvl := range levels {
+		subject.LogAt(lvl, xlog.MessageKey, "dynamic level")
+	}
+	_ = subject.Close()
+
+	// assert
+	records := logger.Records()
+	if assertEqual(t, 3, len(records)) {
+		for idx, lvl := range []xlog.Level{xlog.LevelInfo, xlog.LevelWarning, xlog.LevelError} {
+			assertTrue(t, logger.HasEntry(xlog.LevelNone, "lvl", commOpts.LevelLabels[lvl]))
+			assertEqual(t, "dynamic level", records[idx].KeyValues[5])
+		}
+	}
+}
+
+func TestAsyncLogger_withWriters(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all writers receive identical bytes, formatted once", testAsyncLoggerWithWritersFormatsOnce)
+	t.Run("buffered writers get flushed", testAsyncLoggerWithWritersFlush)
+}
+
+func testAsyncLoggerWithWritersFormatsOnce(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf1, buf2 bytes.Buffer
+		formatter  = new(MockFormatter)
+		commOpts   = xlog.NewCommonOpts()
+		subject    = xlog.NewAsyncLogger(
+			&buf1,
+			xlog.AsyncLoggerWithWriters(&buf2),
+			xlog.AsyncLoggerWithOptions(commOpts),
+			xlog.AsyncLoggerWithFormatter(formatter.Format),
+		)
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	formatter.SetFormatCallback(xlog.LogfmtFormatter)
+
+	// act
+	subject.Warn(xlog.MessageKey, "first log")
+	subject.Error(xlog.MessageKey, "second log")
+	subject.Critical(xlog.MessageKey, "third log")
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 3, formatter.FormatCallsCount())
+	assertEqual(
+		t,
+		`date=`+staticTime+` lvl=WARN msg="first log"`+"\n"+
+			`date=`+staticTime+` lvl=ERROR msg="second log"`+"\n"+
+			`date=`+staticTime+` lvl=CRITICAL msg="third log"`+"\n",
+		buf1.String(),
+	)
+	assertEqual(t, buf1.String(), buf2.String())
+}
+
+func testAsyncLoggerWithWritersFlush(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf1, buf2 bytes.Buffer
+		bufWriter1 = xlog.NewBufferedWriter(&buf1, xlog.BufferedWriterWithFlushInterval(0))
+		bufWriter2 = xlog.NewBufferedWriter(&buf2, xlog.BufferedWriterWithFlushInterval(0))
+		subject    = xlog.NewAsyncLogger(
+			nil,
+			xlog.AsyncLoggerWithWriters(bufWriter1, bufWriter2),
+			xlog.AsyncLoggerWithFormatter(xlog.LogfmtFormatter),
+		)
+	)
+	defer subject.Close()
+
+	// act
+	subject.Error(xlog.MessageKey, "some log")
+	err := subject.Flush()
+
+	// assert
+	assertNil(t, err)
+	assertTrue(t, strings.Contains(buf1.String(), `msg="some log"`))
+	assertEqual(t, buf1.String(), buf2.String())
+}
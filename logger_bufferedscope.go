@@ -0,0 +1,164 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import "sync"
+
+// DroppedLogsKey is the key under which [BufferedScopeLogger] logs, on commit,
+// the no. of logs dropped as its buffer was full.
+const DroppedLogsKey = "_dropped_logs"
+
+// bufferedScopeDroppedMsg is the message the dropped logs count is logged with.
+const bufferedScopeDroppedMsg = "buffered scope logs dropped"
+
+// bufferedScopeEntry is a log held by a [BufferedScopeLogger].
+type bufferedScopeEntry struct {
+	lvl       Level
+	keyValues []any
+}
+
+// BufferedScopeLogger is a Logger which holds logs in memory until
+// [BufferedScopeLogger.Commit] is called (case in which they are passed,
+// in order, to the decorated logger) or [BufferedScopeLogger.Discard] is called
+// (case in which they are dropped). It supports "log everything, but only
+// on error" patterns, like in a request handler, which commits the logs
+// only if the request ultimately fails.
+// It holds at most a given no. of logs, in a fixed size ring buffer;
+// when the buffer is full, the oldest log is dropped in favor of the new one
+// (the no. of dropped logs is logged on commit, see [DroppedLogsKey]).
+// It is concurrent safe.
+// Note: time and source (if configured) are set by the decorated logger at commit time,
+// you may want to log your own timestamp, if the original time matters.
+type BufferedScopeLogger struct {
+	// decorated logger.
+	logger Logger
+	// ring buffer holding the logs.
+	entries *ring[bufferedScopeEntry]
+	// concurrency semaphore to protect ring buffer access.
+	mu sync.Mutex
+}
+
+// NewBufferedScopeLogger instantiates a new logger which holds, until a commit / discard,
+// at most the last capacity logs. A capacity lower than 1 is treated as 1.
+//
+// Example of usage:
+//
+//	reqLogger := xlog.NewBufferedScopeLogger(logger, 100)
+//	defer reqLogger.Discard()
+//	// ... log with reqLogger ...
+//	if err != nil {
+//		reqLogger.Commit()
+//	}
+func NewBufferedScopeLogger(inner Logger, capacity int) *BufferedScopeLogger {
+	return &BufferedScopeLogger{
+		logger:  inner,
+		entries: newRing[bufferedScopeEntry](capacity),
+	}
+}
+
+// Critical logs application component unavailable, fatal events.
+func (logger *BufferedScopeLogger) Critical(keyValues ...any) {
+	logger.hold(LevelCritical, keyValues)
+}
+
+// Error logs runtime errors that
+// should typically be logged and monitored.
+func (logger *BufferedScopeLogger) Error(keyValues ...any) {
+	logger.hold(LevelError, keyValues)
+}
+
+// Warn logs exceptional occurrences that are not errors.
+// Example: Use of deprecated APIs, poor use of an API, undesirable things
+// that are not necessarily wrong.
+func (logger *BufferedScopeLogger) Warn(keyValues ...any) {
+	logger.hold(LevelWarning, keyValues)
+}
+
+// Info logs interesting events.
+// Example: User logs in, SQL logs.
+func (logger *BufferedScopeLogger) Info(keyValues ...any) {
+	logger.hold(LevelInfo, keyValues)
+}
+
+// Debug logs detailed debug information.
+func (logger *BufferedScopeLogger) Debug(keyValues ...any) {
+	logger.hold(LevelDebug, keyValues)
+}
+
+// Log logs arbitrary data.
+func (logger *BufferedScopeLogger) Log(keyValues ...any) {
+	logger.hold(LevelNone, keyValues)
+}
+
+// Enabled returns true if the decorated logger would log
+// a log with given level. See also [IsLevelEnabled].
+func (logger *BufferedScopeLogger) Enabled(lvl Level) bool {
+	return IsLevelEnabled(logger.logger, lvl)
+}
+
+// bareMessageAsKey forwards the check to the decorated logger.
+func (logger *BufferedScopeLogger) bareMessageAsKey() bool {
+	return isBareMessageAsKey(logger.logger)
+}
+
+// Commit passes the held logs, in the order they were made, to the decorated logger,
+// emptying the buffer. Logs made afterwards are held until the next commit / discard.
+// If logs were dropped as the buffer was full, a warning with their count
+// (under [DroppedLogsKey]) is logged first.
+func (logger *BufferedScopeLogger) Commit() {
+	entries, dropped := logger.drain()
+	if dropped > 0 {
+		logger.logger.Warn(MessageKey, bufferedScopeDroppedMsg, DroppedLogsKey, dropped)
+	}
+	for _, entry := range entries {
+		logByLevel(logger.logger, entry.lvl, entry.keyValues...)
+	}
+}
+
+// Discard drops the held logs, emptying the buffer.
+// Logs made afterwards are held until the next commit / discard.
+func (logger *BufferedScopeLogger) Discard() {
+	_, _ = logger.drain()
+}
+
+// Len returns the no. of logs currently held.
+func (logger *BufferedScopeLogger) Len() int {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	return logger.entries.len()
+}
+
+// Close discards the held logs. The decorated logger is not closed,
+// as it is usually shared (an application logger, for example).
+func (logger *BufferedScopeLogger) Close() error {
+	logger.Discard()
+
+	return nil
+}
+
+// hold stores the log in the ring buffer, if the decorated logger would log it.
+func (logger *BufferedScopeLogger) hold(lvl Level, keyValues []any) {
+	if !IsLevelEnabled(logger.logger, lvl) {
+		return
+	}
+	keyVals := make([]any, len(keyValues)) // do not keep caller's slice, it may be reused.
+	copy(keyVals, keyValues)
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.entries.push(bufferedScopeEntry{lvl: lvl, keyValues: keyVals})
+}
+
+// drain returns the held logs, in the order they were made, and the no. of
+// dropped ones, emptying the buffer.
+func (logger *BufferedScopeLogger) drain() ([]bufferedScopeEntry, uint64) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	return logger.entries.drain()
+}
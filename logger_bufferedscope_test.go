@@ -0,0 +1,197 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestBufferedScopeLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("commit passes logs in order", testBufferedScopeLoggerCommit)
+	t.Run("discard drops logs", testBufferedScopeLoggerDiscard)
+	t.Run("capacity keeps last logs", testBufferedScopeLoggerCapacity)
+	t.Run("disabled levels are not held", testBufferedScopeLoggerDisabledLevels)
+	t.Run("close discards logs", testBufferedScopeLoggerClose)
+	t.Run("concurrency", testBufferedScopeLoggerConcurrency)
+}
+
+func testBufferedScopeLoggerCommit(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewBufferedScopeLogger(inner, 10)
+		levels  = []xlog.Level{
+			xlog.LevelDebug,
+			xlog.LevelInfo,
+			xlog.LevelWarning,
+			xlog.LevelError,
+			xlog.LevelCritical,
+			xlog.LevelNone,
+		}
+	)
+	for _, lvl := range levels {
+		callMethodByLevel(subject, lvl)
+	}
+
+	// act & assert
+	assertEqual(t, len(levels), subject.Len())
+	assertEqual(t, 0, len(inner.Records()))
+
+	subject.Commit()
+
+	records := inner.Records()
+	if assertEqual(t, len(levels), len(records)) {
+		for idx, lvl := range levels {
+			assertEqual(t, lvl, records[idx].Level)
+			assertEqual(t, getInputKeyValues(), records[idx].KeyValues)
+		}
+	}
+	assertEqual(t, 0, subject.Len())
+
+	// logs after commit are held until next commit.
+	subject.Info(xlog.MessageKey, "after commit")
+	assertEqual(t, len(levels), len(inner.Records()))
+	subject.Commit()
+	assertTrue(t, inner.HasEntry(xlog.LevelInfo, xlog.MessageKey, "after commit"))
+}
+
+func testBufferedScopeLoggerDiscard(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewBufferedScopeLogger(inner, 10)
+	)
+	subject.Info(xlog.MessageKey, "request started")
+	subject.Debug(xlog.MessageKey, "cache miss")
+
+	// act
+	subject.Discard()
+	subject.Commit()
+
+	// assert
+	assertEqual(t, 0, len(inner.Records()))
+	assertEqual(t, 0, subject.Len())
+}
+
+func testBufferedScopeLoggerCapacity(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewBufferedScopeLogger(inner, 2)
+	)
+
+	// act
+	subject.Info("no", 1)
+	subject.Info("no", 2)
+	subject.Error("no", 3)
+	subject.Commit()
+
+	// assert - dropped logs count is logged first.
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{
+				Level:     xlog.LevelWarning,
+				KeyValues: []any{xlog.MessageKey, "buffered scope logs dropped", xlog.DroppedLogsKey, uint64(1)},
+			},
+			{Level: xlog.LevelInfo, KeyValues: []any{"no", 2}},
+			{Level: xlog.LevelError, KeyValues: []any{"no", 3}},
+		},
+		inner.Records(),
+	)
+
+	// act - dropped logs count got reset.
+	subject.Info("no", 4)
+	subject.Commit()
+
+	// assert
+	records := inner.Records()
+	if assertEqual(t, 4, len(records)) {
+		assertEqual(t, xlog.CaptureRecord{Level: xlog.LevelInfo, KeyValues: []any{"no", 4}}, records[3])
+	}
+}
+
+func testBufferedScopeLoggerDisabledLevels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		commOpts = xlog.NewCommonOpts()
+		inner    = xlog.NewSyncLogger(io.Discard, xlog.SyncLoggerWithOptions(commOpts))
+		subject  = xlog.NewBufferedScopeLogger(inner, 10)
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelWarning)
+
+	// act
+	subject.Debug(xlog.MessageKey, "not held")
+	subject.Error(xlog.MessageKey, "held")
+
+	// assert
+	assertEqual(t, 1, subject.Len())
+	assertFalse(t, subject.Enabled(xlog.LevelDebug))
+	assertTrue(t, subject.Enabled(xlog.LevelError))
+}
+
+func testBufferedScopeLoggerClose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewMockLogger()
+		subject = xlog.NewBufferedScopeLogger(inner, 0)
+	)
+	subject.Error(xlog.MessageKey, "held")
+
+	// act
+	err := subject.Close()
+	subject.Commit()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 0, inner.CloseCallsCount())
+	assertEqual(t, 0, inner.LogCallsCount(xlog.LevelError))
+}
+
+func testBufferedScopeLoggerConcurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner      = xlog.NewCaptureLogger()
+		subject    = xlog.NewBufferedScopeLogger(inner, 1000)
+		goroutines = 10
+		logsNo     = 50
+		wg         sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < logsNo; j++ {
+				subject.Info(xlog.MessageKey, "foo")
+			}
+		}()
+	}
+	wg.Wait()
+	subject.Commit()
+
+	// assert
+	assertEqual(t, goroutines*logsNo, len(inner.Records()))
+}
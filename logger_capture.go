@@ -0,0 +1,113 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"reflect"
+	"sync"
+)
+
+// CaptureRecord is a log captured by a [CaptureLogger].
+type CaptureRecord struct {
+	// Level is the level the log was made with.
+	Level Level
+	// KeyValues are the key-values the log was made with.
+	KeyValues []any
+}
+
+// CaptureLogger is a Logger which captures logs in memory, to be used
+// in tests for asserting what was logged, without parsing a formatted output.
+// Unlike [MockLogger], which counts calls and lets you set callbacks,
+// it records every log, as passed, at any level.
+// It is concurrent safe.
+type CaptureLogger struct {
+	records []CaptureRecord
+	mu      sync.RWMutex
+}
+
+// NewCaptureLogger instantiates a new capture logger.
+func NewCaptureLogger() *CaptureLogger {
+	return new(CaptureLogger)
+}
+
+// Critical captures a critical log.
+func (logger *CaptureLogger) Critical(keyValues ...any) {
+	logger.capture(LevelCritical, keyValues)
+}
+
+// Error captures an error log.
+func (logger *CaptureLogger) Error(keyValues ...any) {
+	logger.capture(LevelError, keyValues)
+}
+
+// Warn captures a warning log.
+func (logger *CaptureLogger) Warn(keyValues ...any) {
+	logger.capture(LevelWarning, keyValues)
+}
+
+// Info captures an info log.
+func (logger *CaptureLogger) Info(keyValues ...any) {
+	logger.capture(LevelInfo, keyValues)
+}
+
+// Debug captures a debug log.
+func (logger *CaptureLogger) Debug(keyValues ...any) {
+	logger.capture(LevelDebug, keyValues)
+}
+
+// Log captures a log without level.
+func (logger *CaptureLogger) Log(keyValues ...any) {
+	logger.capture(LevelNone, keyValues)
+}
+
+// Close does nothing, captured logs are still accessible after it.
+func (logger *CaptureLogger) Close() error {
+	return nil
+}
+
+// Records returns the captured logs, in the order they were made.
+func (logger *CaptureLogger) Records() []CaptureRecord {
+	logger.mu.RLock()
+	defer logger.mu.RUnlock()
+
+	records := make([]CaptureRecord, len(logger.records))
+	copy(records, logger.records)
+
+	return records
+}
+
+// HasEntry returns true if a log with given level, containing
+// given key with given value was captured.
+// Values are compared with [reflect.DeepEqual].
+func (logger *CaptureLogger) HasEntry(lvl Level, key string, value any) bool {
+	logger.mu.RLock()
+	defer logger.mu.RUnlock()
+
+	for _, record := range logger.records {
+		if record.Level != lvl {
+			continue
+		}
+		for idx := 0; idx < len(record.KeyValues); idx += 2 {
+			if record.KeyValues[idx] == key && reflect.DeepEqual(record.KeyValues[idx+1], value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// capture stores the log.
+func (logger *CaptureLogger) capture(lvl Level, keyValues []any) {
+	keyVals := make([]any, len(keyValues))
+	copy(keyVals, keyValues)
+	keyVals = AppendNoValue(keyVals)
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.records = append(logger.records, CaptureRecord{Level: lvl, KeyValues: keyVals})
+}
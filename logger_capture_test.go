@@ -0,0 +1,168 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestCaptureLogger_Records(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.NewCaptureLogger()
+	levels := []xlog.Level{
+		xlog.LevelCritical,
+		xlog.LevelError,
+		xlog.LevelWarning,
+		xlog.LevelInfo,
+		xlog.LevelDebug,
+		xlog.LevelNone,
+	}
+
+	// act
+	for _, lvl := range levels {
+		callMethodByLevel(subject, lvl)
+	}
+	subject.Info("odd")
+	err := subject.Close()
+
+	// assert
+	assertNil(t, err)
+	records := subject.Records()
+	if assertEqual(t, len(levels)+1, len(records)) {
+		for idx, lvl := range levels {
+			assertEqual(t, xlog.CaptureRecord{Level: lvl, KeyValues: getInputKeyValues()}, records[idx])
+		}
+		assertEqual(
+			t,
+			xlog.CaptureRecord{Level: xlog.LevelInfo, KeyValues: []any{"odd", "*NoValue*"}},
+			records[len(levels)],
+		)
+	}
+}
+
+func TestCaptureLogger_HasEntry(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xlog.NewCaptureLogger()
+		someErr = errors.New("some error")
+		tests   = [...]struct {
+			name           string
+			lvl            xlog.Level
+			key            string
+			value          any
+			expectedResult bool
+		}{
+			{
+				name:           "present key with string value",
+				lvl:            xlog.LevelError,
+				key:            xlog.MessageKey,
+				value:          "could not save user",
+				expectedResult: true,
+			},
+			{
+				name:           "present key with error value",
+				lvl:            xlog.LevelError,
+				key:            xlog.ErrorKey,
+				value:          someErr,
+				expectedResult: true,
+			},
+			{
+				name:           "present key with slice value",
+				lvl:            xlog.LevelInfo,
+				key:            "ids",
+				value:          []int{1, 2},
+				expectedResult: true,
+			},
+			{
+				name:           "present key with different value",
+				lvl:            xlog.LevelError,
+				key:            xlog.MessageKey,
+				value:          "could not delete user",
+				expectedResult: false,
+			},
+			{
+				name:           "present key with value of different type",
+				lvl:            xlog.LevelInfo,
+				key:            "count",
+				value:          int64(2),
+				expectedResult: false,
+			},
+			{
+				name:           "present key and value on different level",
+				lvl:            xlog.LevelWarning,
+				key:            xlog.MessageKey,
+				value:          "could not save user",
+				expectedResult: false,
+			},
+			{
+				name:           "absent key",
+				lvl:            xlog.LevelError,
+				key:            "userId",
+				value:          "123",
+				expectedResult: false,
+			},
+			{
+				name:           "value given as key is not matched",
+				lvl:            xlog.LevelInfo,
+				key:            "users listed",
+				value:          "count",
+				expectedResult: false,
+			},
+		}
+	)
+	subject.Error(xlog.MessageKey, "could not save user", xlog.ErrorKey, someErr)
+	subject.Info(xlog.MessageKey, "users listed", "count", 2, "ids", []int{1, 2})
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			result := subject.HasEntry(test.lvl, test.key, test.value)
+
+			// assert
+			assertEqual(t, test.expectedResult, result)
+		})
+	}
+}
+
+func TestCaptureLogger_concurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject    = xlog.NewCaptureLogger()
+		goroutines = 20
+		wg         sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(no int) {
+			defer wg.Done()
+			subject.Info("no", no)
+			_ = subject.HasEntry(xlog.LevelInfo, "no", no)
+			_ = subject.Records()
+		}(i)
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, goroutines, len(subject.Records()))
+	for i := 0; i < goroutines; i++ {
+		assertTrue(t, subject.HasEntry(xlog.LevelInfo, "no", i))
+	}
+}
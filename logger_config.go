@@ -0,0 +1,291 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidConfig is the error returned by [New] for an invalid [Config].
+var ErrInvalidConfig = errors.New("xlog: invalid config")
+
+// Outputs recognized by [Config.Output], besides a file path.
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+)
+
+// Config holds the configuration of a [Logger] created with [New].
+// It centralizes the wiring of logger, writer and formatter,
+// being suitable to be filled from a configuration file.
+type Config struct {
+	// Level is the minimum level logs are written for.
+	// By default, [CommonOpts] one is used (warning).
+	Level Level
+
+	// Formatter is the name of the formatter logs are formatted with.
+	// See [FormatterByName].
+	// By default, "json" is used.
+	Formatter string
+
+	// Async is the flag which, if enabled, makes an [AsyncLogger]
+	// to be created. By default, a [SyncLogger] is created.
+	Async bool
+
+	// ChannelSize is the size of async logger's logs channel.
+	// It can be set only if Async is enabled.
+	// See [AsyncLoggerWithChannelSize].
+	ChannelSize uint16
+
+	// WorkersNo is the no. of async logger's workers.
+	// It can be set only if Async is enabled.
+	// See [AsyncLoggerWithWorkersNo].
+	WorkersNo uint16
+
+	// BufferSize is the size of the buffer logs are written through.
+	// By default, logs are not buffered.
+	// See [BufferedWriterWithSize].
+	BufferSize int
+
+	// FlushInterval is the interval buffered logs are flushed at.
+	// It can be set only if BufferSize is set.
+	// See [BufferedWriterWithFlushInterval].
+	FlushInterval time.Duration
+
+	// Output is the target logs are written to: "stdout", "stderr"
+	// or a file path (file is created if it does not exist, and logs
+	// are appended to it).
+	// By default, "stdout" is used.
+	Output string
+
+	// Options are the common options used by the logger and formatter.
+	// A copy of them is used (see [CommonOpts.Clone]), so that level
+	// configured above, if set, overrides the min level without altering
+	// given options; later changes to them are not seen by the logger.
+	// By default, [NewCommonOpts] is used.
+	Options *CommonOpts
+}
+
+// New instantiates a new [Logger] from given configuration.
+// An error wrapping [ErrInvalidConfig] is returned if configuration
+// is not valid, or an error is returned if the output file cannot be opened.
+// Closing the logger also closes the output file, if any.
+//
+// Example of usage:
+//
+//	logger, err := xlog.New(xlog.Config{
+//		Level:      xlog.LevelInfo,
+//		Formatter:  "logfmt",
+//		Async:      true,
+//		BufferSize: 8 * 1024,
+//		Output:     "/var/log/app.log",
+//	})
+func New(cfg Config) (Logger, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	var opts *CommonOpts
+	if cfg.Options != nil {
+		opts = cfg.Options.Clone() // do not alter caller's options.
+	} else {
+		opts = NewCommonOpts()
+	}
+	if cfg.Level != LevelNone {
+		opts.MinLevel = FixedLevelProvider(cfg.Level)
+	}
+
+	formatterName := cfg.Formatter
+	if formatterName == "" {
+		formatterName = "json"
+	}
+	formatter, err := FormatterByName(formatterName, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	var (
+		w      io.Writer
+		closer io.Closer
+	)
+	switch cfg.Output {
+	case "", OutputStdout:
+		w = os.Stdout
+	case OutputStderr:
+		w = os.Stderr
+	default:
+		f, err := os.OpenFile(cfg.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		w, closer = f, f
+	}
+
+	if cfg.BufferSize > 0 {
+		bwOpts := []BufferedWriterOption{BufferedWriterWithSize(cfg.BufferSize)}
+		if cfg.FlushInterval > 0 {
+			bwOpts = append(bwOpts, BufferedWriterWithFlushInterval(cfg.FlushInterval))
+		}
+		w = NewBufferedWriter(w, bwOpts...)
+	} else if cfg.Async && cfg.WorkersNo > 1 {
+		w = NewSyncWriter(w)
+	}
+
+	var logger Logger
+	if cfg.Async {
+		asyncOpts := []AsyncLoggerOption{
+			AsyncLoggerWithOptions(opts),
+			AsyncLoggerWithFormatter(formatter),
+		}
+		if cfg.ChannelSize > 0 {
+			asyncOpts = append(asyncOpts, AsyncLoggerWithChannelSize(cfg.ChannelSize))
+		}
+		if cfg.WorkersNo > 0 {
+			asyncOpts = append(asyncOpts, AsyncLoggerWithWorkersNo(cfg.WorkersNo))
+		}
+		logger = NewAsyncLogger(w, asyncOpts...)
+	} else {
+		logger = NewSyncLogger(
+			w,
+			SyncLoggerWithOptions(opts),
+			SyncLoggerWithFormatter(formatter),
+		)
+	}
+
+	if closer != nil {
+		return &closingLogger{Logger: logger, closer: closer}, nil
+	}
+
+	return logger, nil
+}
+
+// NewFromEnv instantiates a new [Logger] configured from environment
+// variables, named with given prefix (variables are looked up as
+// PREFIX_LOG_*, or LOG_* if prefix is empty):
+//
+//	PREFIX_LOG_LEVEL          - min level, see [ParseLevel] (default: warning).
+//	PREFIX_LOG_FORMAT         - formatter name, see [FormatterByName] (default: json).
+//	PREFIX_LOG_ASYNC          - "true" for an async logger, see [strconv.ParseBool] (default: false).
+//	PREFIX_LOG_CHANNEL_SIZE   - async logger's channel size (default: 256).
+//	PREFIX_LOG_WORKERS        - async logger's no. of workers (default: 1).
+//	PREFIX_LOG_BUFFER_SIZE    - buffer size, in bytes (default: 0, no buffering).
+//	PREFIX_LOG_FLUSH_INTERVAL - buffer flush interval, see [time.ParseDuration] (default: 10s).
+//	PREFIX_LOG_OUTPUT         - "stdout", "stderr" or a file path (default: stdout).
+//
+// Unset (or empty) variables fall back to their defaults.
+// An error wrapping [ErrInvalidConfig] is returned if a variable's value
+// cannot be parsed, or configuration is not valid. See also [New].
+func NewFromEnv(prefix string) (Logger, error) {
+	if prefix != "" {
+		prefix += "_"
+	}
+	prefix += "LOG_"
+
+	var (
+		cfg = Config{
+			Formatter: os.Getenv(prefix + "FORMAT"),
+			Output:    os.Getenv(prefix + "OUTPUT"),
+		}
+		err error
+	)
+	if value := os.Getenv(prefix + "LEVEL"); value != "" {
+		if cfg.Level, err = ParseLevel(value); err != nil {
+			return nil, fmt.Errorf("%w: %sLEVEL: %w", ErrInvalidConfig, prefix, err)
+		}
+	}
+	if value := os.Getenv(prefix + "ASYNC"); value != "" {
+		if cfg.Async, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("%w: %sASYNC: %w", ErrInvalidConfig, prefix, err)
+		}
+	}
+	if cfg.ChannelSize, err = envUint16(prefix + "CHANNEL_SIZE"); err != nil {
+		return nil, err
+	}
+	if cfg.WorkersNo, err = envUint16(prefix + "WORKERS"); err != nil {
+		return nil, err
+	}
+	if value := os.Getenv(prefix + "BUFFER_SIZE"); value != "" {
+		if cfg.BufferSize, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("%w: %sBUFFER_SIZE: %w", ErrInvalidConfig, prefix, err)
+		}
+	}
+	if value := os.Getenv(prefix + "FLUSH_INTERVAL"); value != "" {
+		if cfg.FlushInterval, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("%w: %sFLUSH_INTERVAL: %w", ErrInvalidConfig, prefix, err)
+		}
+	}
+
+	return New(cfg)
+}
+
+// envUint16 returns the uint16 value of given environment variable,
+// or 0 if it is not set.
+func envUint16(envKey string) (uint16, error) {
+	value := os.Getenv(envKey)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, envKey, err)
+	}
+
+	return uint16(number), nil
+}
+
+// validate checks the configuration for invalid / mutually exclusive values.
+func (cfg Config) validate() error {
+	switch cfg.Level {
+	case LevelNone, LevelDebug, LevelInfo, LevelWarning, LevelError, LevelCritical:
+	default:
+		return fmt.Errorf("%w: unknown level %d", ErrInvalidConfig, cfg.Level)
+	}
+	if !cfg.Async && cfg.ChannelSize > 0 {
+		return fmt.Errorf("%w: channel size can be set only for an async logger", ErrInvalidConfig)
+	}
+	if !cfg.Async && cfg.WorkersNo > 0 {
+		return fmt.Errorf("%w: workers no. can be set only for an async logger", ErrInvalidConfig)
+	}
+	if cfg.BufferSize < 0 {
+		return fmt.Errorf("%w: buffer size cannot be negative", ErrInvalidConfig)
+	}
+	if cfg.BufferSize == 0 && cfg.FlushInterval != 0 {
+		return fmt.Errorf("%w: flush interval can be set only for a buffered output", ErrInvalidConfig)
+	}
+	if cfg.FlushInterval < 0 {
+		return fmt.Errorf("%w: flush interval cannot be negative", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// closingLogger is a Logger which closes also a resource
+// (like the output file), after the decorated logger is closed.
+type closingLogger struct {
+	Logger
+	closer io.Closer
+}
+
+// Close closes the decorated logger, and then the resource.
+func (logger *closingLogger) Close() error {
+	err := logger.Logger.Close()
+	if closeErr := logger.closer.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// Enabled returns true if a log with given level would be logged
+// by the decorated logger.
+func (logger *closingLogger) Enabled(lvl Level) bool {
+	return IsLevelEnabled(logger.Logger, lvl)
+}
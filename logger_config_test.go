@@ -0,0 +1,418 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestNew_writesToFile(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name        string
+		cfg         xlog.Config
+		expectedLog string
+	}{
+		{
+			name:        "sync, json",
+			cfg:         xlog.Config{},
+			expectedLog: `{"date":"` + staticTime + `","lvl":"ERROR","msg":"foo","src":"/logger_config_test.go:112"}` + "\n",
+		},
+		{
+			name:        "sync, logfmt",
+			cfg:         xlog.Config{Formatter: "logfmt"},
+			expectedLog: "date=" + staticTime + " lvl=ERROR src=/logger_config_test.go:112 msg=foo\n",
+		},
+		{
+			name:        "sync, text, buffered",
+			cfg:         xlog.Config{Formatter: "text", BufferSize: 1024, FlushInterval: time.Hour},
+			expectedLog: staticTime + " /logger_config_test.go:112 ERROR foo\n",
+		},
+		{
+			name:        "async",
+			cfg:         xlog.Config{Formatter: "logfmt", Async: true},
+			expectedLog: "date=" + staticTime + " lvl=ERROR src=/logger_config_test.go:112 msg=foo\n",
+		},
+		{
+			name: "async, with channel size and workers",
+			cfg: xlog.Config{
+				Formatter:   "logfmt",
+				Async:       true,
+				ChannelSize: 16,
+				WorkersNo:   4,
+			},
+			expectedLog: "date=" + staticTime + " lvl=ERROR src=/logger_config_test.go:112 msg=foo\n",
+		},
+		{
+			name: "async, buffered",
+			cfg: xlog.Config{
+				Formatter:  "logfmt",
+				Async:      true,
+				WorkersNo:  2,
+				BufferSize: 1024,
+			},
+			expectedLog: "date=" + staticTime + " lvl=ERROR src=/logger_config_test.go:112 msg=foo\n",
+		},
+		{
+			name:        "level filters logs",
+			cfg:         xlog.Config{Level: xlog.LevelCritical},
+			expectedLog: "",
+		},
+		{
+			name:        "debug level",
+			cfg:         xlog.Config{Level: xlog.LevelDebug, Formatter: "logfmt"},
+			expectedLog: "date=" + staticTime + " lvl=ERROR src=/logger_config_test.go:112 msg=foo\n",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				filePath = filepath.Join(t.TempDir(), "app.log")
+				cfg      = test.cfg
+			)
+			cfg.Output = filePath
+			cfg.Options = newConfigTestOpts()
+
+			// act
+			subject, err := xlog.New(cfg)
+
+			// assert
+			if !assertNil(t, err) {
+				return
+			}
+			logAndClose(t, subject)
+			content, err := os.ReadFile(filePath)
+			assertNil(t, err)
+			assertEqual(t, test.expectedLog, string(content))
+		})
+	}
+}
+
+// logAndClose logs an error and closes the logger.
+func logAndClose(t *testing.T, logger xlog.Logger) {
+	t.Helper()
+
+	logger.Error(xlog.MessageKey, "foo")
+	assertNil(t, logger.Close())
+}
+
+func TestNew_appendsToExistingFile(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(filePath, []byte("previous log\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := xlog.Config{Formatter: "text", Output: filePath, Options: newConfigTestOpts()}
+
+	// act
+	subject, err := xlog.New(cfg)
+
+	// assert
+	if !assertNil(t, err) {
+		return
+	}
+	assertTrue(t, xlog.IsLevelEnabled(subject, xlog.LevelWarning))
+	assertFalse(t, xlog.IsLevelEnabled(subject, xlog.LevelInfo))
+	logAndClose(t, subject)
+	content, _ := os.ReadFile(filePath)
+	assertEqual(t, "previous log\n"+staticTime+" /logger_config_test.go:112 ERROR foo\n", string(content))
+}
+
+func TestNew_doesNotAlterOptions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	opts := xlog.NewCommonOpts()
+	opts.MinLevel = xlog.FixedLevelProvider(xlog.LevelError)
+	cfg := xlog.Config{
+		Level:   xlog.LevelDebug,
+		Output:  filepath.Join(t.TempDir(), "app.log"),
+		Options: opts,
+	}
+
+	// act
+	subject, err := xlog.New(cfg)
+
+	// assert
+	if !assertNil(t, err) {
+		return
+	}
+	defer subject.Close()
+	assertTrue(t, xlog.IsLevelEnabled(subject, xlog.LevelDebug))
+	assertEqual(t, xlog.LevelError, opts.MinLevel())
+}
+
+func TestNew_writesToStdStreams(t *testing.T) {
+	// Note: test is not run in parallel as it replaces os.Stdout / os.Stderr.
+	for _, output := range [...]string{"", xlog.OutputStdout, xlog.OutputStderr} {
+		// arrange
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		origStdout, origStderr := os.Stdout, os.Stderr
+		if output == xlog.OutputStderr {
+			os.Stderr = w
+		} else {
+			os.Stdout = w
+		}
+		cfg := xlog.Config{Formatter: "text", Output: output, Options: newConfigTestOpts()}
+
+		// act
+		subject, err := xlog.New(cfg)
+		os.Stdout, os.Stderr = origStdout, origStderr
+
+		// assert
+		if !assertNil(t, err) {
+			continue
+		}
+		logAndClose(t, subject)
+		_ = w.Close()
+		content, _ := io.ReadAll(r)
+		_ = r.Close()
+		assertEqual(t, staticTime+" /logger_config_test.go:112 ERROR foo\n", string(content))
+	}
+}
+
+func TestNew_returnsErrForInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name          string
+		cfg           xlog.Config
+		expectedErrIs error
+		expectedErrIn string
+	}{
+		{
+			name:          "unknown level",
+			cfg:           xlog.Config{Level: 33},
+			expectedErrIs: xlog.ErrInvalidConfig,
+			expectedErrIn: "unknown level 33",
+		},
+		{
+			name:          "unknown formatter",
+			cfg:           xlog.Config{Formatter: "xml"},
+			expectedErrIs: xlog.ErrUnknownFormatter,
+			expectedErrIn: `"xml"`,
+		},
+		{
+			name:          "channel size for sync logger",
+			cfg:           xlog.Config{ChannelSize: 10},
+			expectedErrIs: xlog.ErrInvalidConfig,
+			expectedErrIn: "channel size",
+		},
+		{
+			name:          "workers no. for sync logger",
+			cfg:           xlog.Config{WorkersNo: 2},
+			expectedErrIs: xlog.ErrInvalidConfig,
+			expectedErrIn: "workers no.",
+		},
+		{
+			name:          "negative buffer size",
+			cfg:           xlog.Config{BufferSize: -1},
+			expectedErrIs: xlog.ErrInvalidConfig,
+			expectedErrIn: "buffer size",
+		},
+		{
+			name:          "flush interval without buffer",
+			cfg:           xlog.Config{FlushInterval: time.Second},
+			expectedErrIs: xlog.ErrInvalidConfig,
+			expectedErrIn: "flush interval",
+		},
+		{
+			name:          "negative flush interval",
+			cfg:           xlog.Config{BufferSize: 1024, FlushInterval: -time.Second},
+			expectedErrIs: xlog.ErrInvalidConfig,
+			expectedErrIn: "flush interval",
+		},
+		{
+			name:          "output file cannot be opened",
+			cfg:           xlog.Config{Output: filepath.Join(os.TempDir(), "xlog-missing-dir", "sub", "app.log")},
+			expectedErrIs: os.ErrNotExist,
+			expectedErrIn: "app.log",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			subject, err := xlog.New(test.cfg)
+
+			// assert
+			assertTrue(t, subject == nil)
+			if assertNotNil(t, err) {
+				assertTrue(t, errors.Is(err, test.expectedErrIs))
+				assertTrue(t, strings.Contains(err.Error(), test.expectedErrIn))
+			}
+		})
+	}
+}
+
+// newConfigTestOpts returns common options with static time
+// and file name only source.
+func newConfigTestOpts() *xlog.CommonOpts {
+	opts := xlog.NewCommonOpts()
+	opts.Time = staticTimeProvider
+	opts.Source = xlog.SourceProvider(4, 1)
+
+	return opts
+}
+
+func TestNewFromEnv(t *testing.T) {
+	// Note: test is not run in parallel as it alters environment.
+
+	t.Run("defaults", testNewFromEnvDefaults)
+	t.Run("level and format", testNewFromEnvLevelAndFormat)
+	t.Run("async and buffered", testNewFromEnvAsyncAndBuffered)
+	t.Run("no prefix", testNewFromEnvNoPrefix)
+	t.Run("invalid values", testNewFromEnvInvalidValues)
+}
+
+func testNewFromEnvDefaults(t *testing.T) {
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("XLOGTEST_LOG_OUTPUT", filePath)
+
+	// act
+	subject, err := xlog.NewFromEnv("XLOGTEST")
+
+	// assert
+	if !assertNil(t, err) {
+		return
+	}
+	subject.Info(xlog.MessageKey, "filtered")
+	subject.Warn(xlog.MessageKey, "foo")
+	assertNil(t, subject.Close())
+	content, _ := os.ReadFile(filePath)
+	assertTrue(t, strings.HasPrefix(string(content), "{"))
+	assertTrue(t, strings.Contains(string(content), `"lvl":"WARN"`))
+	assertTrue(t, strings.Contains(string(content), `"msg":"foo"`))
+	assertFalse(t, strings.Contains(string(content), "filtered"))
+	assertEqual(t, 1, strings.Count(string(content), "\n"))
+}
+
+func testNewFromEnvLevelAndFormat(t *testing.T) {
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("XLOGTEST_LOG_OUTPUT", filePath)
+	t.Setenv("XLOGTEST_LOG_LEVEL", "info")
+	t.Setenv("XLOGTEST_LOG_FORMAT", "logfmt")
+
+	// act
+	subject, err := xlog.NewFromEnv("XLOGTEST")
+
+	// assert
+	if !assertNil(t, err) {
+		return
+	}
+	subject.Debug(xlog.MessageKey, "filtered")
+	subject.Info(xlog.MessageKey, "foo")
+	assertNil(t, subject.Close())
+	content, _ := os.ReadFile(filePath)
+	assertTrue(t, strings.HasPrefix(string(content), "date="))
+	assertTrue(t, strings.Contains(string(content), " lvl=INFO "))
+	assertTrue(t, strings.HasSuffix(string(content), " msg=foo\n"))
+	assertFalse(t, strings.Contains(string(content), "filtered"))
+}
+
+func testNewFromEnvAsyncAndBuffered(t *testing.T) {
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("XLOGTEST_LOG_OUTPUT", filePath)
+	t.Setenv("XLOGTEST_LOG_FORMAT", "text")
+	t.Setenv("XLOGTEST_LOG_ASYNC", "true")
+	t.Setenv("XLOGTEST_LOG_CHANNEL_SIZE", "32")
+	t.Setenv("XLOGTEST_LOG_WORKERS", "2")
+	t.Setenv("XLOGTEST_LOG_BUFFER_SIZE", "2048")
+	t.Setenv("XLOGTEST_LOG_FLUSH_INTERVAL", "1h")
+
+	// act
+	subject, err := xlog.NewFromEnv("XLOGTEST")
+
+	// assert
+	if !assertNil(t, err) {
+		return
+	}
+	subject.Error(xlog.MessageKey, "foo")
+	subject.Error(xlog.MessageKey, "bar")
+	assertNil(t, subject.Close())
+	content, _ := os.ReadFile(filePath)
+	assertEqual(t, 2, strings.Count(string(content), " ERROR "))
+}
+
+func testNewFromEnvNoPrefix(t *testing.T) {
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("LOG_OUTPUT", filePath)
+	t.Setenv("LOG_LEVEL", "critical")
+
+	// act
+	subject, err := xlog.NewFromEnv("")
+
+	// assert
+	if !assertNil(t, err) {
+		return
+	}
+	subject.Error(xlog.MessageKey, "filtered")
+	subject.Critical(xlog.MessageKey, "foo")
+	assertNil(t, subject.Close())
+	content, _ := os.ReadFile(filePath)
+	assertTrue(t, strings.Contains(string(content), `"lvl":"CRITICAL"`))
+	assertFalse(t, strings.Contains(string(content), "filtered"))
+}
+
+func testNewFromEnvInvalidValues(t *testing.T) {
+	tests := [...]struct {
+		envKey        string
+		envValue      string
+		expectedErrIn string
+	}{
+		{envKey: "XLOGTEST_LOG_LEVEL", envValue: "verbose", expectedErrIn: "XLOGTEST_LOG_LEVEL"},
+		{envKey: "XLOGTEST_LOG_FORMAT", envValue: "xml", expectedErrIn: `"xml"`},
+		{envKey: "XLOGTEST_LOG_ASYNC", envValue: "maybe", expectedErrIn: "XLOGTEST_LOG_ASYNC"},
+		{envKey: "XLOGTEST_LOG_CHANNEL_SIZE", envValue: "70000", expectedErrIn: "XLOGTEST_LOG_CHANNEL_SIZE"},
+		{envKey: "XLOGTEST_LOG_WORKERS", envValue: "-1", expectedErrIn: "XLOGTEST_LOG_WORKERS"},
+		{envKey: "XLOGTEST_LOG_BUFFER_SIZE", envValue: "4kb", expectedErrIn: "XLOGTEST_LOG_BUFFER_SIZE"},
+		{envKey: "XLOGTEST_LOG_FLUSH_INTERVAL", envValue: "often", expectedErrIn: "XLOGTEST_LOG_FLUSH_INTERVAL"},
+		{envKey: "XLOGTEST_LOG_WORKERS", envValue: "2", expectedErrIn: "async"}, // sync logger.
+	}
+
+	for _, test := range tests {
+		t.Run(test.envKey+"="+test.envValue, func(t *testing.T) {
+			// arrange
+			t.Setenv(test.envKey, test.envValue)
+
+			// act
+			subject, err := xlog.NewFromEnv("XLOGTEST")
+
+			// assert
+			assertTrue(t, subject == nil)
+			if assertNotNil(t, err) {
+				assertTrue(t, errors.Is(err, xlog.ErrInvalidConfig))
+				assertTrue(t, strings.Contains(err.Error(), test.expectedErrIn))
+			}
+		})
+	}
+}
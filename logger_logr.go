@@ -0,0 +1,115 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import "github.com/go-logr/logr"
+
+// LogrNameKey represents the key under which the name of a logr.Logger
+// (built with WithName) resides.
+const LogrNameKey = "logger"
+
+// logrSink is a [logr.LogSink] which logs through a Logger.
+type logrSink struct {
+	// logger to log through.
+	logger Logger
+	// name of the logger, "/" separated, set with WithName.
+	name string
+	// key-values added with WithValues.
+	keyValues []any
+}
+
+// NewLogrSink instantiates a new [logr.LogSink] which logs through given Logger.
+// It is useful to plug xlog into libraries using go-logr/logr, like Kubernetes'
+// controller-runtime.
+// V-levels are mapped like this: V(0) logs with info level, higher V-levels
+// log with debug level. logr's Error logs with error level, the error being
+// set under [ErrorKey].
+// The message is set under [MessageKey], and the name of the logr.Logger
+// (nested names are joined with "/") under [LogrNameKey].
+// Note: logr.Logger and the sink add 2 frames to the call stack, so you may
+// want to set [CommonOpts.SourceSkipExtra] to 2 for the source to point to
+// logr's caller. The sink does not implement [logr.CallDepthLogSink], as the
+// skipped frames are configured per xlog Logger, through SourceSkipExtra,
+// so call depths added with logr.Logger.WithCallDepth / WithCallStackHelper
+// are not taken into account.
+//
+// Example of usage:
+//
+//	logrLogger := logr.New(xlog.NewLogrSink(xLogger))
+//	logrLogger.WithName("controller").Info("reconciled", "object", "foo")
+func NewLogrSink(logger Logger) logr.LogSink {
+	return &logrSink{logger: logger}
+}
+
+// Init does nothing, runtime info is not used.
+func (sink *logrSink) Init(logr.RuntimeInfo) {}
+
+// Enabled returns true if logs with given V-level would be logged.
+func (sink *logrSink) Enabled(level int) bool {
+	return IsLevelEnabled(sink.logger, logrLevel(level))
+}
+
+// Info logs a non-error message with given V-level and key-values.
+func (sink *logrSink) Info(level int, msg string, keyValues ...any) {
+	// logger is called directly, so that Info and Error add the same no. of frames.
+	if logrLevel(level) == LevelDebug {
+		sink.logger.Debug(sink.buildKeyValues(msg, nil, keyValues)...)
+	} else {
+		sink.logger.Info(sink.buildKeyValues(msg, nil, keyValues)...)
+	}
+}
+
+// Error logs an error, with given message and key-values.
+func (sink *logrSink) Error(err error, msg string, keyValues ...any) {
+	sink.logger.Error(sink.buildKeyValues(msg, err, keyValues)...)
+}
+
+// WithValues returns a new LogSink which adds given key-values to every log.
+func (sink *logrSink) WithValues(keyValues ...any) logr.LogSink {
+	clone := *sink
+	clone.keyValues = make([]any, 0, len(sink.keyValues)+len(keyValues))
+	clone.keyValues = append(clone.keyValues, sink.keyValues...)
+	clone.keyValues = append(clone.keyValues, keyValues...)
+
+	return &clone
+}
+
+// WithName returns a new LogSink with given name appended to the current one.
+func (sink *logrSink) WithName(name string) logr.LogSink {
+	clone := *sink
+	if clone.name == "" {
+		clone.name = name
+	} else {
+		clone.name += "/" + name
+	}
+
+	return &clone
+}
+
+// buildKeyValues returns the key-values to be logged.
+func (sink *logrSink) buildKeyValues(msg string, err error, keyValues []any) []any {
+	kv := make([]any, 0, 6+len(sink.keyValues)+len(keyValues))
+	if sink.name != "" {
+		kv = append(kv, LogrNameKey, sink.name)
+	}
+	kv = append(kv, MessageKey, msg)
+	if err != nil {
+		kv = append(kv, ErrorKey, err)
+	}
+	kv = append(kv, sink.keyValues...)
+	kv = append(kv, keyValues...)
+
+	return kv
+}
+
+// logrLevel maps a logr V-level to a xlog Level.
+func logrLevel(level int) Level {
+	if level > 0 {
+		return LevelDebug
+	}
+
+	return LevelInfo
+}
@@ -0,0 +1,175 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestLogrSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("info levels", testLogrSinkInfoLevels)
+	t.Run("error", testLogrSinkError)
+	t.Run("names and values propagate", testLogrSinkNamesAndValues)
+	t.Run("enabled", testLogrSinkEnabled)
+	t.Run("source with SourceSkipExtra", testLogrSinkSource)
+}
+
+func testLogrSinkInfoLevels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		captureLogger = xlog.NewCaptureLogger()
+		subject       = logr.New(xlog.NewLogrSink(captureLogger))
+	)
+
+	// act
+	subject.Info("v0 message", "foo", "bar")
+	subject.V(1).Info("v1 message")
+	subject.V(4).Info("v4 message", "baz", 123)
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{Level: xlog.LevelInfo, KeyValues: []any{xlog.MessageKey, "v0 message", "foo", "bar"}},
+			{Level: xlog.LevelDebug, KeyValues: []any{xlog.MessageKey, "v1 message"}},
+			{Level: xlog.LevelDebug, KeyValues: []any{xlog.MessageKey, "v4 message", "baz", 123}},
+		},
+		captureLogger.Records(),
+	)
+}
+
+func testLogrSinkError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		captureLogger = xlog.NewCaptureLogger()
+		subject       = logr.New(xlog.NewLogrSink(captureLogger))
+		someErr       = errors.New("some error")
+	)
+
+	// act
+	subject.Error(someErr, "could not reconcile", "object", "foo")
+	subject.V(2).Error(nil, "no error")
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{
+				Level:     xlog.LevelError,
+				KeyValues: []any{xlog.MessageKey, "could not reconcile", xlog.ErrorKey, someErr, "object", "foo"},
+			},
+			{Level: xlog.LevelError, KeyValues: []any{xlog.MessageKey, "no error"}},
+		},
+		captureLogger.Records(),
+	)
+}
+
+func testLogrSinkNamesAndValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		captureLogger = xlog.NewCaptureLogger()
+		root          = logr.New(xlog.NewLogrSink(captureLogger))
+		subject       = root.WithName("manager").WithValues("app", "demo").
+				WithName("controller").WithValues("kind", "Pod")
+	)
+
+	// act
+	subject.Info("reconciled", "object", "foo")
+	root.WithName("other").Info("unrelated")
+	root.Info("root")
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{
+				Level: xlog.LevelInfo,
+				KeyValues: []any{
+					xlog.LogrNameKey, "manager/controller",
+					xlog.MessageKey, "reconciled",
+					"app", "demo",
+					"kind", "Pod",
+					"object", "foo",
+				},
+			},
+			{Level: xlog.LevelInfo, KeyValues: []any{xlog.LogrNameKey, "other", xlog.MessageKey, "unrelated"}},
+			{Level: xlog.LevelInfo, KeyValues: []any{xlog.MessageKey, "root"}},
+		},
+		captureLogger.Records(),
+	)
+}
+
+func testLogrSinkEnabled(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		commOpts = xlog.NewCommonOpts()
+		xLogger  = xlog.NewSyncLogger(
+			new(MockWriter),
+			xlog.SyncLoggerWithOptions(commOpts),
+		)
+		subject = logr.New(xlog.NewLogrSink(xLogger))
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+
+	// act & assert
+	assertTrue(t, subject.Enabled())
+	assertTrue(t, subject.V(0).Enabled())
+	assertFalse(t, subject.V(1).Enabled())
+
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelDebug)
+	assertTrue(t, subject.V(1).Enabled())
+}
+
+func testLogrSinkSource(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf  bytes.Buffer
+		opts = xlog.NewCommonOpts()
+	)
+	opts.MinLevel = xlog.FixedLevelProvider(xlog.LevelDebug)
+	opts.SourceSkipExtra = 2
+	opts.UseRelativeSource = true
+	subject := logr.New(xlog.NewLogrSink(xlog.NewSyncLogger(
+		&buf,
+		xlog.SyncLoggerWithOptions(opts),
+		xlog.SyncLoggerWithFormatter(xlog.LogfmtFormatter),
+	)))
+
+	// act
+	subject.Info("info message")
+	_, _, infoLine, _ := runtime.Caller(0)
+	subject.V(1).Info("debug message")
+	_, _, debugLine, _ := runtime.Caller(0)
+	subject.Error(errors.New("some error"), "error message")
+	_, _, errLine, _ := runtime.Caller(0)
+
+	// assert
+	logs := buf.String()
+	for _, line := range [...]int{infoLine, debugLine, errLine} {
+		assertTrue(t, strings.Contains(logs, "src=logger_logr_test.go:"+strconv.Itoa(line-1)+" "))
+	}
+}
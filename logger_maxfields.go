@@ -0,0 +1,100 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+// DroppedFieldsKey is the key under which a [MaxFieldsLogger]
+// logs the number of dropped key-value pairs.
+const DroppedFieldsKey = "_dropped_fields"
+
+// MaxFieldsLogger decorates a Logger so that a log contains at most
+// a maximum number of key-value pairs, guarding against accidentally logging huge
+// argument lists (a whole slice spread, for example), and protecting downstream
+// parsers with field-count limits.
+// The exceeding pairs are dropped, and their count is logged under [DroppedFieldsKey].
+// It is concurrent safe to use.
+// Note: the logging methods add a frame to the call stack; set the decorated
+// logger's [CommonOpts.SourceSkipExtra] to 1 for the source to point to your code.
+type MaxFieldsLogger struct {
+	// decorated logger.
+	logger Logger
+	// max number of key-value pairs of a log.
+	maxPairs int
+}
+
+// NewMaxFieldsLogger decorates given Logger so that a log contains at most
+// maxPairs key-value pairs (the dropped pairs count, if any, is extra).
+// A maxPairs < 0 is treated as 0.
+func NewMaxFieldsLogger(inner Logger, maxPairs int) *MaxFieldsLogger {
+	return &MaxFieldsLogger{
+		logger:   inner,
+		maxPairs: max(maxPairs, 0),
+	}
+}
+
+// Critical logs application component unavailable, fatal events.
+func (logger *MaxFieldsLogger) Critical(keyValues ...any) {
+	logger.logger.Critical(logger.truncate(keyValues)...)
+}
+
+// Error logs runtime errors that
+// should typically be logged and monitored.
+func (logger *MaxFieldsLogger) Error(keyValues ...any) {
+	logger.logger.Error(logger.truncate(keyValues)...)
+}
+
+// Warn logs exceptional occurrences that are not errors.
+// Example: Use of deprecated APIs, poor use of an API, undesirable things
+// that are not necessarily wrong.
+func (logger *MaxFieldsLogger) Warn(keyValues ...any) {
+	logger.logger.Warn(logger.truncate(keyValues)...)
+}
+
+// Info logs interesting events.
+// Example: User logs in, SQL logs.
+func (logger *MaxFieldsLogger) Info(keyValues ...any) {
+	logger.logger.Info(logger.truncate(keyValues)...)
+}
+
+// Debug logs detailed debug information.
+func (logger *MaxFieldsLogger) Debug(keyValues ...any) {
+	logger.logger.Debug(logger.truncate(keyValues)...)
+}
+
+// Log logs arbitrary data.
+func (logger *MaxFieldsLogger) Log(keyValues ...any) {
+	logger.logger.Log(logger.truncate(keyValues)...)
+}
+
+// Enabled returns true if the decorated logger would log
+// a log with given level. See also [IsLevelEnabled].
+func (logger *MaxFieldsLogger) Enabled(lvl Level) bool {
+	return IsLevelEnabled(logger.logger, lvl)
+}
+
+// bareMessageAsKey forwards the check to the decorated logger.
+func (logger *MaxFieldsLogger) bareMessageAsKey() bool {
+	return isBareMessageAsKey(logger.logger)
+}
+
+// Close closes the decorated logger.
+func (logger *MaxFieldsLogger) Close() error {
+	return logger.logger.Close()
+}
+
+// truncate returns the first maxPairs key-value pairs of given key-values,
+// followed by the dropped pairs count. A trailing key without value counts
+// as a pair. Given key-values are returned as they are, if nothing is dropped.
+func (logger *MaxFieldsLogger) truncate(keyValues []any) []any {
+	pairs := (len(keyValues) + 1) / 2
+	if pairs <= logger.maxPairs {
+		return keyValues
+	}
+
+	keyVals := make([]any, 0, 2*logger.maxPairs+2)
+	keyVals = append(keyVals, keyValues[:2*logger.maxPairs]...)
+
+	return append(keyVals, DroppedFieldsKey, pairs-logger.maxPairs)
+}
@@ -0,0 +1,131 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestMaxFieldsLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("exceeding pairs are dropped", testMaxFieldsLoggerTruncates)
+	t.Run("logs within limit are not altered", testMaxFieldsLoggerWithinLimit)
+	t.Run("all levels", testMaxFieldsLoggerAllLevels)
+	t.Run("close", testMaxFieldsLoggerClose)
+}
+
+func testMaxFieldsLoggerTruncates(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewMaxFieldsLogger(inner, 2)
+	)
+
+	// act
+	subject.Info(xlog.MessageKey, "too many fields", "a", 1, "b", 2, "c", 3, "d")
+	subject.Info("a", 1, "b", 2, "c", 3)
+	xlog.NewMaxFieldsLogger(inner, -1).Info("a", 1)
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{
+				Level:     xlog.LevelInfo,
+				KeyValues: []any{xlog.MessageKey, "too many fields", "a", 1, xlog.DroppedFieldsKey, 3},
+			},
+			{
+				Level:     xlog.LevelInfo,
+				KeyValues: []any{"a", 1, "b", 2, xlog.DroppedFieldsKey, 1},
+			},
+			{
+				Level:     xlog.LevelInfo,
+				KeyValues: []any{xlog.DroppedFieldsKey, 1},
+			},
+		},
+		inner.Records(),
+	)
+}
+
+func testMaxFieldsLoggerWithinLimit(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewMaxFieldsLogger(inner, 2)
+	)
+
+	// act
+	subject.Warn(xlog.MessageKey, "within limit", "a", 1)
+	subject.Warn(xlog.MessageKey, "within limit", "odd")
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{Level: xlog.LevelWarning, KeyValues: []any{xlog.MessageKey, "within limit", "a", 1}},
+			{Level: xlog.LevelWarning, KeyValues: []any{xlog.MessageKey, "within limit", "odd", "*NoValue*"}},
+		},
+		inner.Records(),
+	)
+}
+
+func testMaxFieldsLoggerAllLevels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewMaxFieldsLogger(inner, 100)
+		levels  = []xlog.Level{
+			xlog.LevelCritical,
+			xlog.LevelError,
+			xlog.LevelWarning,
+			xlog.LevelInfo,
+			xlog.LevelDebug,
+			xlog.LevelNone,
+		}
+	)
+
+	// act
+	for _, lvl := range levels {
+		callMethodByLevel(subject, lvl)
+	}
+
+	// assert
+	records := inner.Records()
+	if assertEqual(t, len(levels), len(records)) {
+		for idx, lvl := range levels {
+			assertEqual(t, lvl, records[idx].Level)
+			assertEqual(t, getInputKeyValues(), records[idx].KeyValues)
+		}
+	}
+	assertTrue(t, subject.Enabled(xlog.LevelDebug))
+}
+
+func testMaxFieldsLoggerClose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewMockLogger()
+		subject = xlog.NewMaxFieldsLogger(inner, 1)
+	)
+	inner.SetCloseError(ErrWrite)
+
+	// act
+	err := subject.Close()
+
+	// assert
+	assertEqual(t, ErrWrite, err)
+	assertEqual(t, 1, inner.CloseCallsCount())
+}
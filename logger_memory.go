@@ -0,0 +1,182 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// MemoryLogger is a Logger which retains in memory the last N logs,
+// in a fixed size ring buffer. When the buffer is full, the oldest log
+// is dropped in favor of the new one.
+// It is useful for debugging, as retained logs can be dumped on crash,
+// or in tests, as an assertion target.
+// It is concurrent safe.
+type MemoryLogger struct {
+	// ring buffer holding the logs.
+	entries *ring[[]any]
+	// concurrency semaphore to protect ring buffer access.
+	mu sync.Mutex
+	// formatter used to dump logs.
+	// can be set with [MemoryLoggerWithFormatter] functional option.
+	formatter Formatter
+	// min/max levels set at runtime, overriding opts' ones.
+	// can be set with SetMinLevel / SetMaxLevel methods.
+	levels levelOverrides
+	// sequence number of the last log, see [CommonOpts.SequenceKey].
+	seq atomic.Uint64
+	// common options for this logger.
+	// can be set with [MemoryLoggerWithOptions] functional option.
+	opts *CommonOpts
+}
+
+// NewMemoryLogger instantiates a new logger object that retains in memory
+// the last capacity logs. A capacity lower than 1 is treated as 1.
+// Check for MemoryLoggerWith* options to further customize it.
+func NewMemoryLogger(capacity int, opts ...MemoryLoggerOption) *MemoryLogger {
+	// instantiate object with default properties.
+	logger := &MemoryLogger{
+		entries:   newRing[[]any](capacity),
+		formatter: JSONFormatter,
+	}
+
+	// apply functional options, if any.
+	for _, opt := range opts {
+		opt(logger)
+	}
+	if logger.opts == nil {
+		logger.opts = NewCommonOpts()
+	}
+
+	return logger
+}
+
+// Critical logs application component unavailable, fatal events.
+func (logger *MemoryLogger) Critical(keyValues ...any) {
+	logger.log(LevelCritical, keyValues...)
+}
+
+// Error logs runtime errors that
+// should typically be logged and monitored.
+func (logger *MemoryLogger) Error(keyValues ...any) {
+	logger.log(LevelError, keyValues...)
+}
+
+// Warn logs exceptional occurrences that are not errors.
+// Example: Use of deprecated APIs, poor use of an API, undesirable things
+// that are not necessarily wrong.
+func (logger *MemoryLogger) Warn(keyValues ...any) {
+	logger.log(LevelWarning, keyValues...)
+}
+
+// Info logs interesting events.
+// Example: User logs in, SQL logs.
+func (logger *MemoryLogger) Info(keyValues ...any) {
+	logger.log(LevelInfo, keyValues...)
+}
+
+// Debug logs detailed debug information.
+func (logger *MemoryLogger) Debug(keyValues ...any) {
+	logger.log(LevelDebug, keyValues...)
+}
+
+// Log logs arbitrary data.
+func (logger *MemoryLogger) Log(keyValues ...any) {
+	logger.log(LevelNone, keyValues...)
+}
+
+// Enabled returns true if a log with given level would be logged.
+// It can be used to avoid expensive computations of key-values
+// for a log that would be ignored anyway.
+func (logger *MemoryLogger) Enabled(lvl Level) bool {
+	return logger.levels.betweenMinMax(logger.opts, lvl)
+}
+
+// bareMessageAsKey returns the [CommonOpts.BareMessageAsKey] flag.
+func (logger *MemoryLogger) bareMessageAsKey() bool {
+	return logger.opts.BareMessageAsKey
+}
+
+// SetMinLevel sets, at runtime, the minimum level allowed to be logged.
+// It overrides the [CommonOpts.MinLevel] for this logger only.
+// It is safe to call it concurrently with logging.
+func (logger *MemoryLogger) SetMinLevel(lvl Level) {
+	logger.levels.setMin(lvl)
+}
+
+// SetMaxLevel sets, at runtime, the maximum level allowed to be logged.
+// It overrides the [CommonOpts.MaxLevel] for this logger only.
+// It is safe to call it concurrently with logging.
+func (logger *MemoryLogger) SetMaxLevel(lvl Level) {
+	logger.levels.setMax(lvl)
+}
+
+// Entries returns the retained logs (enriched with default key-values),
+// from the oldest to the newest one.
+func (logger *MemoryLogger) Entries() [][]any {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	return logger.entries.all()
+}
+
+// Dump writes the retained logs to given writer, from the oldest
+// to the newest one, with logger's formatter.
+// It stops at, and returns, the first formatting error.
+func (logger *MemoryLogger) Dump(w io.Writer) error {
+	for _, entry := range logger.Entries() {
+		if err := logger.formatter(w, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close does nothing, retained logs are still accessible after it.
+func (logger *MemoryLogger) Close() error {
+	return nil
+}
+
+// log is used internally to store the log, if eligible.
+// Default key-values are prepended to user passed ones.
+func (logger *MemoryLogger) log(lvl Level, keyValues ...any) {
+	// ignore log conditions check.
+	if !logger.levels.betweenMinMax(logger.opts, lvl) {
+		return
+	}
+
+	// enrich passed key values with default ones.
+	keyVals := logger.opts.appendDefaultKeyValues(nil, lvl, keyValues)
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	keyVals = appendSequence(keyVals, logger.opts.SequenceKey, &logger.seq)
+	logger.entries.push(keyVals)
+}
+
+// MemoryLoggerOption defines optional function for configuring
+// a memory logger.
+type MemoryLoggerOption func(*MemoryLogger)
+
+// MemoryLoggerWithFormatter sets desired formatter logs are dumped with.
+// The JSON formatter is used by default.
+func MemoryLoggerWithFormatter(formatter Formatter) MemoryLoggerOption {
+	return func(logger *MemoryLogger) {
+		logger.formatter = formatter
+	}
+}
+
+// MemoryLoggerWithOptions sets the common options.
+// A [NewCommonOpts] is used by default.
+func MemoryLoggerWithOptions(opts *CommonOpts) MemoryLoggerOption {
+	return func(logger *MemoryLogger) {
+		logger.opts = opts
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func ExampleMemoryLogger() {
+	// In this example we create a logger that retains
+	// the last 2 logs in memory, and dumps them on demand.
+
+	opts := xlog.NewCommonOpts()
+	opts.Time = func() any { // mock time for output check
+		return "2022-03-20T16:01:20Z"
+	}
+	opts.SourceKey = "" // disable source for output check
+	logger := xlog.NewMemoryLogger(2, xlog.MemoryLoggerWithOptions(opts))
+	defer logger.Close()
+
+	logger.Warn(xlog.MessageKey, "first")
+	logger.Error(xlog.MessageKey, "second")
+	logger.Critical(xlog.MessageKey, "third")
+
+	_ = logger.Dump(os.Stdout)
+
+	// Output:
+	// {"date":"2022-03-20T16:01:20Z","lvl":"ERROR","msg":"second"}
+	// {"date":"2022-03-20T16:01:20Z","lvl":"CRITICAL","msg":"third"}
+}
+
+func TestMemoryLogger_retainsLatestEntries(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		capacity = 3
+		commOpts = xlog.NewCommonOpts()
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	subject := xlog.NewMemoryLogger(capacity, xlog.MemoryLoggerWithOptions(commOpts))
+
+	// act & assert
+	assertEqual(t, 0, len(subject.Entries()))
+
+	subject.Error("no", 1)
+	subject.Error("no", 2)
+	assertEqual(
+		t,
+		[][]any{
+			{commOpts.TimeKey, staticTime, commOpts.LevelKey, "ERROR", "no", 1},
+			{commOpts.TimeKey, staticTime, commOpts.LevelKey, "ERROR", "no", 2},
+		},
+		subject.Entries(),
+	)
+
+	for i := 3; i <= 2*capacity+1; i++ {
+		subject.Error("no", i)
+	}
+	assertEqual(
+		t,
+		[][]any{
+			{commOpts.TimeKey, staticTime, commOpts.LevelKey, "ERROR", "no", 5},
+			{commOpts.TimeKey, staticTime, commOpts.LevelKey, "ERROR", "no", 6},
+			{commOpts.TimeKey, staticTime, commOpts.LevelKey, "ERROR", "no", 7},
+		},
+		subject.Entries(),
+	)
+}
+
+func TestMemoryLogger_ignoresLogsOutsideMinMax(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.NewMemoryLogger(5)
+
+	// act
+	subject.Debug(xlog.MessageKey, "debug log")
+	subject.Info(xlog.MessageKey, "info log")
+
+	// assert
+	assertEqual(t, 0, len(subject.Entries()))
+	assertFalse(t, subject.Enabled(xlog.LevelInfo))
+	subject.SetMinLevel(xlog.LevelInfo)
+	assertTrue(t, subject.Enabled(xlog.LevelInfo))
+	subject.Info(xlog.MessageKey, "info log")
+	assertEqual(t, 1, len(subject.Entries()))
+}
+
+func TestMemoryLogger_withSequence(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.NewMemoryLogger(10, xlog.MemoryLoggerWithOptions(commOpts))
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	commOpts.SequenceKey = "seq"
+
+	// act
+	for i := 0; i < 3; i++ {
+		subject.Error("msg", "foo")
+	}
+
+	// assert
+	entries := subject.Entries()
+	if assertEqual(t, 3, len(entries)) {
+		for idx, entry := range entries {
+			assertEqual(t, []any{"date", staticTime, "lvl", "ERROR", "msg", "foo", "seq", uint64(idx + 1)}, entry)
+		}
+	}
+}
+
+func TestMemoryLogger_Dump(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", testMemoryLoggerDumpSuccess)
+	t.Run("formatting error", testMemoryLoggerDumpReturnsFormatErr)
+}
+
+func testMemoryLoggerDumpSuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	subject := xlog.NewMemoryLogger(
+		2,
+		xlog.MemoryLoggerWithOptions(commOpts),
+		xlog.MemoryLoggerWithFormatter(xlog.LogfmtFormatter),
+	)
+	subject.Warn(xlog.MessageKey, "foo")
+	subject.Error(xlog.MessageKey, "bar")
+
+	// act
+	err := subject.Dump(&buf)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		"date="+staticTime+" lvl=WARN msg=foo\n"+
+			"date="+staticTime+" lvl=ERROR msg=bar\n",
+		buf.String(),
+	)
+}
+
+func testMemoryLoggerDumpReturnsFormatErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		subject   = xlog.NewMemoryLogger(
+			2,
+			xlog.MemoryLoggerWithFormatter(formatter.Format),
+		)
+	)
+	formatter.SetFormatCallback(FormatCallbackErr)
+	subject.Warn(xlog.MessageKey, "foo")
+	subject.Error(xlog.MessageKey, "bar")
+
+	// act
+	err := subject.Dump(new(bytes.Buffer))
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrFormat))
+	assertEqual(t, 1, formatter.FormatCallsCount())
+}
+
+func TestMemoryLogger_concurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		capacity   = 10
+		goroutines = 20
+		subject    = xlog.NewMemoryLogger(capacity)
+		wg         sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(no int) {
+			defer wg.Done()
+			subject.Error(xlog.MessageKey, "log "+strconv.Itoa(no))
+			_ = subject.Entries()
+		}(i)
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, capacity, len(subject.Entries()))
+}
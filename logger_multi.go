@@ -68,17 +68,63 @@ func (logger *MultiLogger) Log(keyValues ...any) {
 	}
 }
 
+// LogAt logs with given level, known only at runtime, to all loggers.
+// See also [LogAt].
+func (logger *MultiLogger) LogAt(lvl Level, keyValues ...any) {
+	for _, lgr := range logger.loggers {
+		if ll, ok := lgr.(LevelLogger); ok {
+			ll.LogAt(lvl, keyValues...)
+		} else {
+			logByLevel(lgr, lvl, keyValues...)
+		}
+	}
+}
+
+// Enabled returns true if at least one of the loggers would log
+// a log with given level. See also [IsLevelEnabled].
+func (logger *MultiLogger) Enabled(lvl Level) bool {
+	for _, lgr := range logger.loggers {
+		if IsLevelEnabled(lgr, lvl) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Close performs clean up actions, closes resources,
 // avoids memory leaks, etc.
 // Make sure to call it at your application shutdown
 // for example.
+// Returned error aggregates the loggers' Close errors.
+// See [MultiLogger.CloseDetailed] if you need to know which logger failed.
 func (logger *MultiLogger) Close() error {
+	errs := logger.CloseDetailed()
 	var mErr *xerr.MultiError
-	for _, lgr := range logger.loggers {
-		if err := lgr.Close(); err != nil {
+	for idx := range logger.loggers {
+		if err, found := errs[idx]; found {
 			mErr = mErr.Add(err)
 		}
 	}
 
 	return mErr.ErrOrNil()
 }
+
+// CloseDetailed closes all the loggers, like [MultiLogger.Close] does,
+// but returns their Close errors mapped by the index of the logger
+// (the position it was passed in to [NewMultiLogger]).
+// It is useful to diagnose which sink (file / network) failed to flush.
+// Returned map is nil if all the loggers were closed successfully.
+func (logger *MultiLogger) CloseDetailed() map[int]error {
+	var errs map[int]error
+	for idx, lgr := range logger.loggers {
+		if err := lgr.Close(); err != nil {
+			if errs == nil {
+				errs = make(map[int]error)
+			}
+			errs[idx] = err
+		}
+	}
+
+	return errs
+}
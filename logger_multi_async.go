@@ -0,0 +1,296 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/actforgood/xerr"
+)
+
+// DropPolicy defines what happens with a log when a queue is full.
+type DropPolicy byte
+
+const (
+	// DropPolicyBlock blocks the logging call until there is space in the queue.
+	// No log is dropped.
+	// Note: for an [AsyncMultiLogger], a full queue blocks the caller, and thus
+	// the logs for all the other loggers, too, defeating their isolation.
+	// Use it only for loggers which must not drop logs, and are not slow.
+	DropPolicyBlock DropPolicy = iota
+	// DropPolicyNewest drops the log to be queued, if queue is full.
+	DropPolicyNewest
+	// DropPolicyOldest drops the oldest log from the queue, if queue is full,
+	// making room for the log to be queued.
+	DropPolicyOldest
+)
+
+// default size of each child logger's queue.
+const defaultAsyncMultiLoggerQueueSize = 256
+
+// AsyncMultiLogger is a composite Logger capable of logging to multiple loggers,
+// like [MultiLogger], but each child logger has its own bounded queue
+// and worker goroutine, so that a slow logger (for example one that sends
+// logs over network) does not slow down the rest of them.
+// By default, a log is dropped for a logger whose queue is full ([DropPolicyNewest]).
+// Note: as child loggers are called from their worker goroutine, a source
+// provider configured on them will not report the caller's file and line.
+// Also, the time is set by each child logger when its worker processes the log,
+// so, for a slow logger with a backed up queue, it lags behind the moment of the
+// logging call; you may want to log your own timestamp, if the original time matters.
+type AsyncMultiLogger struct {
+	// child loggers with their queues.
+	children []*asyncChildLogger
+	// size of each child logger's queue.
+	// can be set with [AsyncMultiLoggerWithQueueSize] functional option.
+	queueSize int
+	// drop policy applied for all child loggers' queues.
+	// can be set with [AsyncMultiLoggerWithDropPolicy] functional option.
+	dropPolicy DropPolicy
+	// drop policies applied for specific child loggers' queues.
+	// can be set with [AsyncMultiLoggerWithChildDropPolicy] functional option.
+	childDropPolicies map[int]DropPolicy
+	// closed flag, true means Close() has been called, from this point forward,
+	// no further logs are accepted for processing.
+	closed bool
+	// concurrency semaphore to protect closed flag access and queues' closing.
+	closeMu sync.RWMutex
+	// gets closed when closing starts, unblocking log submissions waiting
+	// for room in a full queue (which hold closeMu's read lock).
+	closingCh chan struct{}
+	// ensures closingCh is closed once.
+	closingOnce sync.Once
+}
+
+// asyncChildLogger is a child logger of an [AsyncMultiLogger],
+// with its own queue.
+type asyncChildLogger struct {
+	logger     Logger
+	queue      chan asyncEntry
+	closingCh  <-chan struct{}
+	dropPolicy DropPolicy
+	dropped    atomic.Uint64
+	wg         sync.WaitGroup
+}
+
+// NewAsyncMultiLogger instantiates a new async multi logger object.
+// Accepts the loggers multi-logger handles.
+// Check for AsyncMultiLoggerWith* options to further customize it.
+func NewAsyncMultiLogger(loggers []Logger, opts ...AsyncMultiLoggerOption) *AsyncMultiLogger {
+	// instantiate object with default properties.
+	logger := &AsyncMultiLogger{
+		queueSize:  defaultAsyncMultiLoggerQueueSize,
+		dropPolicy: DropPolicyNewest,
+		closingCh:  make(chan struct{}),
+	}
+
+	// apply options, if any.
+	for _, opt := range opts {
+		opt(logger)
+	}
+
+	// set up children and start their workers.
+	logger.children = make([]*asyncChildLogger, len(loggers))
+	for idx, lgr := range loggers {
+		dropPolicy, found := logger.childDropPolicies[idx]
+		if !found {
+			dropPolicy = logger.dropPolicy
+		}
+		child := &asyncChildLogger{
+			logger:     lgr,
+			queue:      make(chan asyncEntry, logger.queueSize),
+			closingCh:  logger.closingCh,
+			dropPolicy: dropPolicy,
+		}
+		child.wg.Add(1)
+		go child.work()
+		logger.children[idx] = child
+	}
+
+	return logger
+}
+
+// Critical logs application component unavailable, fatal events.
+func (logger *AsyncMultiLogger) Critical(keyValues ...any) {
+	logger.push(LevelCritical, keyValues)
+}
+
+// Error logs runtime errors that
+// should typically be logged and monitored.
+func (logger *AsyncMultiLogger) Error(keyValues ...any) {
+	logger.push(LevelError, keyValues)
+}
+
+// Warn logs exceptional occurrences that are not errors.
+// Example: Use of deprecated APIs, poor use of an API, undesirable things
+// that are not necessarily wrong.
+func (logger *AsyncMultiLogger) Warn(keyValues ...any) {
+	logger.push(LevelWarning, keyValues)
+}
+
+// Info logs interesting events.
+// Example: User logs in, SQL logs.
+func (logger *AsyncMultiLogger) Info(keyValues ...any) {
+	logger.push(LevelInfo, keyValues)
+}
+
+// Debug logs detailed debug information.
+func (logger *AsyncMultiLogger) Debug(keyValues ...any) {
+	logger.push(LevelDebug, keyValues)
+}
+
+// Log logs arbitrarily data.
+func (logger *AsyncMultiLogger) Log(keyValues ...any) {
+	logger.push(LevelNone, keyValues)
+}
+
+// Enabled returns true if at least one of the loggers would log
+// a log with given level. See also [IsLevelEnabled].
+func (logger *AsyncMultiLogger) Enabled(lvl Level) bool {
+	for _, child := range logger.children {
+		if IsLevelEnabled(child.logger, lvl) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Dropped returns the no. of logs dropped for each child logger,
+// in the order loggers were provided.
+func (logger *AsyncMultiLogger) Dropped() []uint64 {
+	dropped := make([]uint64, len(logger.children))
+	for idx, child := range logger.children {
+		dropped[idx] = child.dropped.Load()
+	}
+
+	return dropped
+}
+
+// Close nicely closes logger.
+// It waits for all queues to be drained, and then closes all child loggers.
+// Once called, any further call to any of the logging methods will be ignored,
+// and the ones blocked waiting for room in a full queue give up (their logs are dropped).
+func (logger *AsyncMultiLogger) Close() error {
+	logger.closingOnce.Do(func() {
+		close(logger.closingCh) // unblock log submissions waiting for room in a queue.
+	})
+	logger.closeMu.Lock()
+	if logger.closed {
+		logger.closeMu.Unlock()
+
+		return nil
+	}
+	logger.closed = true
+	for _, child := range logger.children {
+		close(child.queue)
+	}
+	logger.closeMu.Unlock()
+
+	var mErr *xerr.MultiError
+	for _, child := range logger.children {
+		child.wg.Wait() // wait for worker to process any entry left in queue.
+		if err := child.logger.Close(); err != nil {
+			mErr = mErr.Add(err)
+		}
+	}
+
+	return mErr.ErrOrNil()
+}
+
+// push sends the log to every child logger's queue.
+func (logger *AsyncMultiLogger) push(lvl Level, keyValues []any) {
+	logger.closeMu.RLock()
+	defer logger.closeMu.RUnlock()
+
+	if logger.closed {
+		return
+	}
+	// the key-values are processed later, by the children's workers, so they are copied,
+	// in case the caller reuses its slice. Children share the copy, which has no spare
+	// capacity, so that an append (of a NoValue placeholder, for example) by one of them
+	// does not write into the shared backing array.
+	keyVals := make([]any, len(keyValues))
+	copy(keyVals, keyValues)
+	entry := asyncEntry{keyVals: keyVals, lvl: lvl}
+	for _, child := range logger.children {
+		child.push(entry)
+	}
+}
+
+// push sends the log to child's queue, applying the drop policy.
+func (child *asyncChildLogger) push(entry asyncEntry) {
+	switch child.dropPolicy {
+	case DropPolicyNewest:
+		select {
+		case child.queue <- entry:
+		default:
+			child.dropped.Add(1)
+		}
+	case DropPolicyOldest:
+		for {
+			select {
+			case child.queue <- entry:
+				return
+			default:
+			}
+			select {
+			case <-child.queue:
+				child.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case child.queue <- entry:
+		case <-child.closingCh: // logger is closing, the log is dropped.
+			child.dropped.Add(1)
+		}
+	}
+}
+
+// work processes child's queue and performs the actual logging.
+// it is meant to be called in another goroutine.
+func (child *asyncChildLogger) work() {
+	defer child.wg.Done() // notify waiting thread work is finished.
+
+	for entry := range child.queue {
+		logByLevel(child.logger, entry.lvl, entry.keyVals...)
+	}
+}
+
+// AsyncMultiLoggerOption defines optional function for configuring
+// an async multi logger.
+type AsyncMultiLoggerOption func(*AsyncMultiLogger)
+
+// AsyncMultiLoggerWithQueueSize sets the size of each child logger's queue.
+// If not called, defaults to a 256 size.
+func AsyncMultiLoggerWithQueueSize(queueSize uint16) AsyncMultiLoggerOption {
+	return func(logger *AsyncMultiLogger) {
+		logger.queueSize = int(queueSize)
+	}
+}
+
+// AsyncMultiLoggerWithDropPolicy sets the drop policy for all child loggers' queues.
+// If not called, defaults to [DropPolicyNewest].
+func AsyncMultiLoggerWithDropPolicy(policy DropPolicy) AsyncMultiLoggerOption {
+	return func(logger *AsyncMultiLogger) {
+		logger.dropPolicy = policy
+	}
+}
+
+// AsyncMultiLoggerWithChildDropPolicy sets the drop policy for the queue of the
+// child logger found at given index (in the order loggers were provided).
+// It takes precedence over [AsyncMultiLoggerWithDropPolicy].
+func AsyncMultiLoggerWithChildDropPolicy(childIdx int, policy DropPolicy) AsyncMultiLoggerOption {
+	return func(logger *AsyncMultiLogger) {
+		if logger.childDropPolicies == nil {
+			logger.childDropPolicies = make(map[int]DropPolicy)
+		}
+		logger.childDropPolicies[childIdx] = policy
+	}
+}
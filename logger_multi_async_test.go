@@ -0,0 +1,252 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestAsyncMultiLogger_logsOnEveryLogger(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		levels = []xlog.Level{
+			xlog.LevelNone,
+			xlog.LevelDebug,
+			xlog.LevelInfo,
+			xlog.LevelWarning,
+			xlog.LevelError,
+			xlog.LevelCritical,
+		}
+		logger1 = xlog.NewMockLogger()
+		logger2 = xlog.NewMockLogger()
+		subject = xlog.NewAsyncMultiLogger([]xlog.Logger{logger1, logger2})
+		kv      = getInputKeyValues()
+	)
+	for _, lvl := range levels {
+		for _, lgr := range []*xlog.MockLogger{logger1, logger2} {
+			lgr.SetLogCallback(lvl, func(keyValues ...any) {
+				assertEqual(t, kv, keyValues)
+			})
+		}
+	}
+
+	// act
+	for _, lvl := range levels {
+		callMethodByLevel(subject, lvl)
+	}
+	err := subject.Close() // waits for queues to be drained.
+
+	// assert
+	assertNil(t, err)
+	for _, lgr := range []*xlog.MockLogger{logger1, logger2} {
+		for _, lvl := range levels {
+			assertEqual(t, 1, lgr.LogCallsCount(lvl))
+		}
+		assertEqual(t, 1, lgr.CloseCallsCount())
+	}
+	assertEqual(t, []uint64{0, 0}, subject.Dropped())
+}
+
+func TestAsyncMultiLogger_slowLoggerDoesNotThrottleFastOne(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		fastLogger = xlog.NewMockLogger()
+		slowLogger = xlog.NewMockLogger()
+		releaseCh  = make(chan struct{})
+		subject    = xlog.NewAsyncMultiLogger(
+			[]xlog.Logger{fastLogger, slowLogger},
+			xlog.AsyncMultiLoggerWithQueueSize(4),
+			xlog.AsyncMultiLoggerWithChildDropPolicy(0, xlog.DropPolicyBlock), // fast logger drops nothing.
+		)
+		logsNo = 50
+	)
+	slowLogger.SetLogCallback(xlog.LevelError, func(...any) {
+		<-releaseCh // slow logger is stuck until released.
+	})
+
+	// act - slow logger's queue gets full, its logs are dropped (default policy).
+	for i := 0; i < logsNo; i++ {
+		subject.Error("no", i)
+	}
+
+	// assert - fast logger got all the logs, while slow logger is still stuck.
+	deadline := time.Now().Add(2 * time.Second)
+	for fastLogger.LogCallsCount(xlog.LevelError) < logsNo && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assertEqual(t, logsNo, fastLogger.LogCallsCount(xlog.LevelError))
+	assertTrue(t, slowLogger.LogCallsCount(xlog.LevelError) <= 1)
+	dropped := subject.Dropped()
+	assertEqual(t, uint64(0), dropped[0])
+	assertTrue(t, dropped[1] > 0)
+
+	// act - release slow logger and close.
+	close(releaseCh)
+	err := subject.Close()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, logsNo, slowLogger.LogCallsCount(xlog.LevelError)+int(subject.Dropped()[1]))
+}
+
+func TestAsyncMultiLogger_dropPolicyOldest(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger    = xlog.NewMockLogger()
+		releaseCh = make(chan struct{})
+		startedCh = make(chan struct{})
+		received  []any
+		subject   = xlog.NewAsyncMultiLogger(
+			[]xlog.Logger{logger},
+			xlog.AsyncMultiLoggerWithQueueSize(2),
+			xlog.AsyncMultiLoggerWithDropPolicy(xlog.DropPolicyOldest),
+		)
+	)
+	logger.SetLogCallback(xlog.LevelInfo, func(keyValues ...any) {
+		if keyValues[1] == 0 {
+			close(startedCh)
+			<-releaseCh
+		}
+		received = append(received, keyValues[1])
+	})
+	subject.Info("no", 0)
+	<-startedCh // worker is stuck processing first log.
+
+	// act
+	for i := 1; i <= 5; i++ {
+		subject.Info("no", i)
+	}
+	close(releaseCh)
+	err := subject.Close()
+
+	// assert - only the newest 2 logs were kept in the queue.
+	assertNil(t, err)
+	assertEqual(t, []any{0, 4, 5}, received)
+	assertEqual(t, []uint64{3}, subject.Dropped())
+}
+
+func TestAsyncMultiLogger_Close(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger1           = xlog.NewMockLogger()
+		logger2           = xlog.NewMockLogger()
+		subject           = xlog.NewAsyncMultiLogger([]xlog.Logger{logger1, logger2})
+		expectedLoggerErr = errors.New("intentionally triggered logger Close error")
+	)
+	logger2.SetCloseError(expectedLoggerErr)
+
+	// act
+	err1 := subject.Close()
+	err2 := subject.Close()
+	subject.Error("msg", "ignored after close")
+
+	// assert
+	assertTrue(t, errors.Is(err1, expectedLoggerErr))
+	assertNil(t, err2)
+	for _, lgr := range []*xlog.MockLogger{logger1, logger2} {
+		assertEqual(t, 1, lgr.CloseCallsCount())
+		assertEqual(t, 0, lgr.LogCallsCount(xlog.LevelError))
+	}
+}
+
+func TestAsyncMultiLogger_Close_unblocksBlockedSubmissions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger    = xlog.NewMockLogger()
+		releaseCh = make(chan struct{})
+		subject   = xlog.NewAsyncMultiLogger(
+			[]xlog.Logger{logger},
+			xlog.AsyncMultiLoggerWithQueueSize(1),
+			xlog.AsyncMultiLoggerWithDropPolicy(xlog.DropPolicyBlock),
+		)
+		wg sync.WaitGroup
+	)
+	logger.SetLogCallback(xlog.LevelError, func(...any) {
+		<-releaseCh // logger is stuck until released.
+	})
+	// 1st log blocks the worker, 2nd fills the queue, 3rd blocks on the full queue.
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subject.Error("msg", "foo bar")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond) // let the submissions block.
+
+	// act
+	closeErrCh := make(chan error, 1)
+	go func() {
+		closeErrCh <- subject.Close()
+	}()
+
+	// assert - blocked submissions gave up, while the logger is still stuck.
+	wg.Wait()
+	close(releaseCh)
+	assertNil(t, <-closeErrCh)
+	assertEqual(t, uint64(3), uint64(logger.LogCallsCount(xlog.LevelError))+subject.Dropped()[0])
+}
+
+func TestAsyncMultiLogger_callerReusesKeyValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger1 = xlog.NewCaptureLogger()
+		logger2 = xlog.NewCaptureLogger()
+		subject = xlog.NewAsyncMultiLogger([]xlog.Logger{logger1, logger2})
+		logsNo  = 100
+		keyVals = []any{"no", 0, "odd"}
+	)
+
+	// act
+	for i := 0; i < logsNo; i++ {
+		keyVals[1] = i // caller reuses its slice.
+		subject.Error(keyVals...)
+	}
+	_ = subject.Close()
+
+	// assert
+	for _, logger := range []*xlog.CaptureLogger{logger1, logger2} {
+		records := logger.Records()
+		if assertEqual(t, logsNo, len(records)) {
+			for idx, record := range records {
+				assertEqual(t, []any{"no", idx, "odd", "*NoValue*"}, record.KeyValues)
+			}
+		}
+	}
+}
+
+func TestAsyncMultiLogger_Enabled(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		errLogger = xlog.NewSyncLogger(nil)
+		subject   = xlog.NewAsyncMultiLogger([]xlog.Logger{xlog.NopLogger{}, errLogger})
+	)
+	defer subject.Close()
+	errLogger.SetMinLevel(xlog.LevelError)
+
+	// act & assert
+	assertFalse(t, subject.Enabled(xlog.LevelWarning))
+	assertTrue(t, subject.Enabled(xlog.LevelError))
+}
@@ -6,7 +6,9 @@
 package xlog_test
 
 import (
+	"bytes"
 	"errors"
+	"io"
 	"os"
 	"testing"
 
@@ -48,7 +50,7 @@ func ExampleMultiLogger_splitMessagesByLevel() {
 	logger.Error("msg", "I get written to standard error")
 
 	// Output:
-	// {"date":"2022-03-20T16:01:20Z","lvl":"DEBUG","msg":"I get written to standard output","src":"/logger_multi_test.go:47"}
+	// {"date":"2022-03-20T16:01:20Z","lvl":"DEBUG","msg":"I get written to standard output","src":"/logger_multi_test.go:49"}
 }
 
 func ExampleMultiLogger_logToStdOutAndCustomFile() {
@@ -91,7 +93,7 @@ func ExampleMultiLogger_logToStdOutAndCustomFile() {
 	logger.Debug("msg", "I get written to standard output and to a file")
 
 	// Output:
-	// {"date":"2022-03-15T16:01:20Z","lvl":"DEBUG","msg":"I get written to standard output and to a file","src":"/logger_multi_test.go:91"}
+	// {"date":"2022-03-15T16:01:20Z","lvl":"DEBUG","msg":"I get written to standard output and to a file","src":"/logger_multi_test.go:93"}
 }
 
 func TestMultiLogger_logsOnEveryLogger(t *testing.T) {
@@ -173,3 +175,108 @@ func TestMultiLogger_Close_closesAllLoggers(t *testing.T) {
 		assertEqual(t, 0, lgr.LogCallsCount(xlog.LevelCritical))
 	}
 }
+
+func TestMultiLogger_CloseDetailed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reports failed loggers by index", testMultiLoggerCloseDetailedReportsFailures)
+	t.Run("nil if no failure", testMultiLoggerCloseDetailedNoFailure)
+}
+
+func testMultiLoggerCloseDetailedReportsFailures(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger1            = xlog.NewMockLogger()
+		logger2            = xlog.NewMockLogger()
+		logger3            = xlog.NewMockLogger()
+		subject            = xlog.NewMultiLogger(logger1, logger2, logger3)
+		expectedLogger1Err = errors.New("intentionally triggered logger 1 Close error")
+		expectedLogger3Err = errors.New("intentionally triggered logger 3 Close error")
+	)
+	logger1.SetCloseError(expectedLogger1Err)
+	logger3.SetCloseError(expectedLogger3Err)
+
+	// act
+	errs := subject.CloseDetailed()
+
+	// assert
+	assertEqual(t, map[int]error{0: expectedLogger1Err, 2: expectedLogger3Err}, errs)
+	assertEqual(t, 1, logger1.CloseCallsCount())
+	assertEqual(t, 1, logger2.CloseCallsCount())
+	assertEqual(t, 1, logger3.CloseCallsCount())
+}
+
+func testMultiLoggerCloseDetailedNoFailure(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.NewMultiLogger(xlog.NewMockLogger(), xlog.NewMockLogger())
+
+	// act
+	errs := subject.CloseDetailed()
+
+	// assert
+	assertTrue(t, errs == nil)
+}
+
+func TestMultiLogger_Enabled(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		infoOpts   = xlog.NewCommonOpts()
+		infoLogger = xlog.NewSyncLogger(io.Discard, xlog.SyncLoggerWithOptions(infoOpts))
+		errLogger  = xlog.NewSyncLogger(io.Discard)
+		nopLogger  = xlog.NopLogger{}
+	)
+	infoOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+	infoOpts.MaxLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+	errLogger.SetMinLevel(xlog.LevelError)
+	subject := xlog.NewMultiLogger(nopLogger, infoLogger, errLogger)
+	subjectWithMock := xlog.NewMultiLogger(nopLogger, xlog.NewMockLogger())
+
+	// act & assert
+	assertFalse(t, subject.Enabled(xlog.LevelDebug))
+	assertTrue(t, subject.Enabled(xlog.LevelInfo))
+	assertFalse(t, subject.Enabled(xlog.LevelWarning))
+	assertTrue(t, subject.Enabled(xlog.LevelError))
+	assertTrue(t, subject.Enabled(xlog.LevelCritical))
+	assertTrue(t, xlog.IsLevelEnabled(subject, xlog.LevelCritical))
+	assertTrue(t, subjectWithMock.Enabled(xlog.LevelDebug)) // mock does not implement LevelChecker.
+	assertFalse(t, xlog.NewMultiLogger().Enabled(xlog.LevelDebug))
+}
+
+func TestMultiLogger_LogAt(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf        bytes.Buffer
+		syncOpts   = xlog.NewCommonOpts()
+		syncLogger = xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(syncOpts))
+		mockLogger = xlog.NewMockLogger()
+		subject    = xlog.NewMultiLogger(syncLogger, mockLogger, xlog.NopLogger{})
+	)
+	syncOpts.Time = staticTimeProvider
+	syncOpts.SourceKey = ""
+
+	// act
+	subject.LogAt(xlog.LevelError, xlog.MessageKey, "dynamic level")
+	subject.LogAt(xlog.LevelDebug, xlog.MessageKey, "filtered by sync logger")
+	xlog.LogAt(subject, xlog.LevelWarning, xlog.MessageKey, "through package func")
+	xlog.LogAt(mockLogger, xlog.Level(100), xlog.MessageKey, "non standard level")
+
+	// assert
+	assertEqual(
+		t,
+		`{"date":"`+staticTime+`","lvl":"ERROR","msg":"dynamic level"}`+"\n"+
+			`{"date":"`+staticTime+`","lvl":"WARN","msg":"through package func"}`+"\n",
+		buf.String(),
+	)
+	assertEqual(t, 1, mockLogger.LogCallsCount(xlog.LevelError))
+	assertEqual(t, 1, mockLogger.LogCallsCount(xlog.LevelDebug))
+	assertEqual(t, 1, mockLogger.LogCallsCount(xlog.LevelWarning))
+	assertEqual(t, 1, mockLogger.LogCallsCount(xlog.LevelNone))
+}
@@ -5,6 +5,8 @@
 
 package xlog
 
+import "reflect"
+
 // NopLogger is a no-operation Logger which does nothing.
 // It simply ignores any log.
 type NopLogger struct{}
@@ -31,5 +33,48 @@ func (NopLogger) Debug(...any) {}
 // Log logs arbitrary data.
 func (NopLogger) Log(...any) {}
 
+// LogAt logs with given level.
+func (NopLogger) LogAt(Level, ...any) {}
+
+// Enabled returns always false, as nothing gets logged.
+func (NopLogger) Enabled(Level) bool { return false }
+
 // Close nicely closes logger.
 func (NopLogger) Close() error { return nil }
+
+// Safe returns given logger, or a [NopLogger], if it is nil
+// (including a nil pointer to a logger type), so that code
+// accepting an optional Logger can defensively do:
+//
+//	logger = xlog.Safe(logger)
+//
+// instead of panicking on the first log.
+func Safe(logger Logger) Logger {
+	if isNilLogger(logger) {
+		return NopLogger{}
+	}
+
+	return logger
+}
+
+// MustLogger returns given logger, panicking if it is nil
+// (including a nil pointer to a logger type).
+// It is meant for stricter APIs, which require a logger,
+// to fail fast, at construction time, instead of at the first log.
+func MustLogger(logger Logger) Logger {
+	if isNilLogger(logger) {
+		panic("xlog: nil logger")
+	}
+
+	return logger
+}
+
+// isNilLogger returns true if given logger is nil, or a nil pointer.
+func isNilLogger(logger Logger) bool {
+	if logger == nil {
+		return true
+	}
+	rv := reflect.ValueOf(logger)
+
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
@@ -29,4 +29,76 @@ func TestNopLogger_doesNothing(t *testing.T) {
 	subject.Critical(kv)
 	err := subject.Close()
 	assertNil(t, err)
+	assertFalse(t, xlog.IsLevelEnabled(subject, xlog.LevelCritical))
+}
+
+func TestSafe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil logger", func(t *testing.T) {
+		t.Parallel()
+
+		var nilPtrLogger *xlog.SyncLogger
+		for _, logger := range []xlog.Logger{nil, nilPtrLogger} {
+			// act
+			subject := xlog.Safe(logger)
+
+			// assert
+			assertEqual(t, xlog.NopLogger{}, subject)
+			for _, lvl := range []xlog.Level{xlog.LevelNone, xlog.LevelDebug, xlog.LevelInfo, xlog.LevelWarning, xlog.LevelError, xlog.LevelCritical} {
+				callMethodByLevel(subject, lvl)
+			}
+			assertNil(t, subject.Close())
+		}
+	})
+
+	t.Run("non nil logger", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		logger := xlog.NewMockLogger()
+
+		// act
+		subject := xlog.Safe(logger)
+		subject.Info(xlog.MessageKey, "passed through")
+
+		// assert
+		assertTrue(t, subject == xlog.Logger(logger))
+		assertEqual(t, 1, logger.LogCallsCount(xlog.LevelInfo))
+	})
+}
+
+func TestMustLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil logger panics", func(t *testing.T) {
+		t.Parallel()
+
+		var nilPtrLogger *xlog.SyncLogger
+		for _, logger := range []xlog.Logger{nil, nilPtrLogger} {
+			func() {
+				defer func() {
+					assertEqual(t, "xlog: nil logger", recover())
+				}()
+
+				// act
+				_ = xlog.MustLogger(logger)
+
+				t.Error("expected panic")
+			}()
+		}
+	})
+
+	t.Run("non nil logger", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		logger := xlog.NopLogger{}
+
+		// act
+		subject := xlog.MustLogger(logger)
+
+		// assert
+		assertEqual(t, xlog.Logger(logger), subject)
+	})
 }
@@ -0,0 +1,192 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import "sync"
+
+// defaultOccurrenceMaxKeys is the default max no. of distinct key's values
+// an occurrence logger keeps track of.
+const defaultOccurrenceMaxKeys = 10000
+
+// OccurrenceLogger decorates a Logger so that logs identified by the
+// same key's value (by default, the [MessageKey]'s value) are logged only
+// once ([LogOnce]) or every Nth occurrence ([LogEvery]).
+// It is useful to avoid log spam on hot paths, for example for
+// "log this misconfiguration once" scenarios.
+// Logs not having the key are always logged, and logs the decorated logger
+// would not log (see [IsLevelEnabled]) are not counted.
+// Occurrences are tracked per distinct key's value, up to a max no. of values
+// (see [OccurrenceLoggerWithMaxKeys]); once reached, the tracking starts over,
+// so a value may get logged again. Use a key with a bounded set of values
+// (like a message, or an error code), and not one holding ids, for example.
+// It is concurrent safe to use.
+// Note: being a decorator, it sits one frame between your code and the decorated
+// logger, so increase the latter's [CommonOpts.SourceSkipExtra] by one.
+type OccurrenceLogger struct {
+	// decorated logger.
+	logger Logger
+	// the log is logged every n occurrences. 0 means only once.
+	every uint64
+	// the key whose value identifies a log.
+	// can be set with [OccurrenceLoggerWithKey] functional option.
+	key string
+	// max no. of distinct key's values to track.
+	// can be set with [OccurrenceLoggerWithMaxKeys] functional option.
+	maxKeys int
+	// occurrences counters, per key's value.
+	seen map[string]uint64
+	// concurrency semaphore for seen.
+	mu sync.Mutex
+}
+
+// LogOnce decorates given Logger so that a log is logged only
+// the first time its key's value is seen.
+func LogOnce(logger Logger, opts ...OccurrenceLoggerOption) *OccurrenceLogger {
+	return newOccurrenceLogger(logger, 0, opts...)
+}
+
+// LogEvery decorates given Logger so that a log is logged the first time its
+// key's value is seen, and then every Nth occurrence (1st, n+1th, 2n+1th, ...).
+// A value of n <= 1 means every log is logged.
+func LogEvery(logger Logger, n int, opts ...OccurrenceLoggerOption) *OccurrenceLogger {
+	return newOccurrenceLogger(logger, uint64(max(n, 1)), opts...)
+}
+
+// newOccurrenceLogger instantiates a new occurrence logger.
+func newOccurrenceLogger(logger Logger, every uint64, opts ...OccurrenceLoggerOption) *OccurrenceLogger {
+	occLogger := &OccurrenceLogger{
+		logger:  logger,
+		every:   every,
+		key:     MessageKey,
+		maxKeys: defaultOccurrenceMaxKeys,
+		seen:    make(map[string]uint64),
+	}
+	for _, opt := range opts {
+		opt(occLogger)
+	}
+
+	return occLogger
+}
+
+// Critical logs application component unavailable, fatal events.
+func (logger *OccurrenceLogger) Critical(keyValues ...any) {
+	if logger.allow(LevelCritical, keyValues) {
+		logger.logger.Critical(keyValues...)
+	}
+}
+
+// Error logs runtime errors that
+// should typically be logged and monitored.
+func (logger *OccurrenceLogger) Error(keyValues ...any) {
+	if logger.allow(LevelError, keyValues) {
+		logger.logger.Error(keyValues...)
+	}
+}
+
+// Warn logs exceptional occurrences that are not errors.
+// Example: Use of deprecated APIs, poor use of an API, undesirable things
+// that are not necessarily wrong.
+func (logger *OccurrenceLogger) Warn(keyValues ...any) {
+	if logger.allow(LevelWarning, keyValues) {
+		logger.logger.Warn(keyValues...)
+	}
+}
+
+// Info logs interesting events.
+// Example: User logs in, SQL logs.
+func (logger *OccurrenceLogger) Info(keyValues ...any) {
+	if logger.allow(LevelInfo, keyValues) {
+		logger.logger.Info(keyValues...)
+	}
+}
+
+// Debug logs detailed debug information.
+func (logger *OccurrenceLogger) Debug(keyValues ...any) {
+	if logger.allow(LevelDebug, keyValues) {
+		logger.logger.Debug(keyValues...)
+	}
+}
+
+// Log logs arbitrary data.
+func (logger *OccurrenceLogger) Log(keyValues ...any) {
+	if logger.allow(LevelNone, keyValues) {
+		logger.logger.Log(keyValues...)
+	}
+}
+
+// Enabled returns true if the decorated logger would log
+// a log with given level. See also [IsLevelEnabled].
+func (logger *OccurrenceLogger) Enabled(lvl Level) bool {
+	return IsLevelEnabled(logger.logger, lvl)
+}
+
+// bareMessageAsKey forwards the check to the decorated logger.
+func (logger *OccurrenceLogger) bareMessageAsKey() bool {
+	return isBareMessageAsKey(logger.logger)
+}
+
+// Close closes the decorated logger.
+func (logger *OccurrenceLogger) Close() error {
+	return logger.logger.Close()
+}
+
+// allow returns true if the log should be logged,
+// incrementing the occurrences counter of its key's value.
+// Logs with a level disabled in the decorated logger are not counted,
+// otherwise they would use up the slot of an enabled one.
+func (logger *OccurrenceLogger) allow(lvl Level, keyValues []any) bool {
+	if !IsLevelEnabled(logger.logger, lvl) {
+		return false
+	}
+
+	keyValues = AppendNoValue(keyValues)
+	for idx := 0; idx < len(keyValues); idx += 2 {
+		if keyValues[idx] != logger.key {
+			continue
+		}
+		value := stringify(keyValues[idx+1])
+
+		logger.mu.Lock()
+		occurrence, found := logger.seen[value]
+		if !found && len(logger.seen) >= logger.maxKeys {
+			clear(logger.seen) // start over, instead of growing unbounded.
+		}
+		occurrence++
+		logger.seen[value] = occurrence
+		logger.mu.Unlock()
+
+		if logger.every == 0 {
+			return occurrence == 1
+		}
+
+		return (occurrence-1)%logger.every == 0
+	}
+
+	return true
+}
+
+// OccurrenceLoggerOption defines optional function for configuring
+// an occurrence logger.
+type OccurrenceLoggerOption func(*OccurrenceLogger)
+
+// OccurrenceLoggerWithKey sets the key whose value identifies a log.
+// [MessageKey] is used by default.
+func OccurrenceLoggerWithKey(key string) OccurrenceLoggerOption {
+	return func(logger *OccurrenceLogger) {
+		logger.key = key
+	}
+}
+
+// OccurrenceLoggerWithMaxKeys sets the max no. of distinct key's values
+// to keep track of. Once reached, tracking starts over.
+// 10000 is used by default. A value <= 0 is ignored.
+func OccurrenceLoggerWithMaxKeys(maxKeys int) OccurrenceLoggerOption {
+	return func(logger *OccurrenceLogger) {
+		if maxKeys > 0 {
+			logger.maxKeys = maxKeys
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestLogOnce(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewMockLogger()
+		subject = xlog.LogOnce(logger)
+	)
+
+	// act
+	for i := 0; i < 100; i++ {
+		subject.Warn(xlog.MessageKey, "misconfiguration", "i", i)
+		subject.Warn(xlog.MessageKey, "other misconfiguration")
+		subject.Warn("no", "message") // logs without the key are always logged.
+	}
+
+	// assert
+	assertEqual(t, 2+100, logger.LogCallsCount(xlog.LevelWarning))
+}
+
+func TestLogOnce_withKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewMockLogger()
+		subject = xlog.LogOnce(logger, xlog.OccurrenceLoggerWithKey("code"))
+	)
+
+	// act
+	for i := 0; i < 100; i++ {
+		subject.Error(xlog.MessageKey, "msg", "code", 1001)
+		subject.Error(xlog.MessageKey, "msg", "code", 1002)
+	}
+
+	// assert
+	assertEqual(t, 2, logger.LogCallsCount(xlog.LevelError))
+}
+
+func TestLogEvery(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewMockLogger()
+		subject = xlog.LogEvery(logger, 10)
+		logged  []int
+	)
+	logger.SetLogCallback(xlog.LevelInfo, func(keyValues ...any) {
+		logged = append(logged, keyValues[3].(int))
+	})
+
+	// act
+	for i := 1; i <= 100; i++ {
+		subject.Info(xlog.MessageKey, "hot path", "i", i)
+	}
+
+	// assert
+	assertEqual(t, 10, logger.LogCallsCount(xlog.LevelInfo))
+	assertEqual(t, []int{1, 11, 21, 31, 41, 51, 61, 71, 81, 91}, logged)
+}
+
+func TestOccurrenceLogger_disabledLevelsAreNotCounted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+	subject := xlog.LogOnce(xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(commOpts)))
+
+	// act
+	for i := 0; i < 3; i++ {
+		subject.Debug(xlog.MessageKey, "misconfiguration")
+	}
+	subject.Info(xlog.MessageKey, "misconfiguration")
+	subject.Info(xlog.MessageKey, "misconfiguration")
+
+	// assert
+	assertEqual(t, 1, strings.Count(buf.String(), "misconfiguration"))
+}
+
+func TestOccurrenceLoggerWithMaxKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewMockLogger()
+		subject = xlog.LogOnce(logger, xlog.OccurrenceLoggerWithMaxKeys(2))
+	)
+
+	// act
+	subject.Warn(xlog.MessageKey, "a")
+	subject.Warn(xlog.MessageKey, "b")
+	subject.Warn(xlog.MessageKey, "a")
+	subject.Warn(xlog.MessageKey, "c") // max keys reached, tracking starts over.
+	subject.Warn(xlog.MessageKey, "a")
+
+	// assert
+	assertEqual(t, 4, logger.LogCallsCount(xlog.LevelWarning))
+}
+
+func TestOccurrenceLogger_allLevels(t *testing.T) {
+	t.Parallel()
+
+	levels := []xlog.Level{
+		xlog.LevelNone,
+		xlog.LevelDebug,
+		xlog.LevelInfo,
+		xlog.LevelWarning,
+		xlog.LevelError,
+		xlog.LevelCritical,
+	}
+	for _, lvl := range levels {
+		// arrange
+		var (
+			logger  = xlog.NewMockLogger()
+			subject = xlog.LogOnce(logger, xlog.OccurrenceLoggerWithKey("foo"))
+		)
+
+		// act
+		for i := 0; i < 5; i++ {
+			callMethodByLevel(subject, lvl)
+		}
+
+		// assert
+		assertEqual(t, 1, logger.LogCallsCount(lvl))
+	}
+}
+
+func TestOccurrenceLogger_Close(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger      = xlog.NewMockLogger()
+		subject     = xlog.LogEvery(logger, 2)
+		expectedErr = errors.New("intentionally triggered Close error")
+	)
+	logger.SetCloseError(expectedErr)
+
+	// act
+	err := subject.Close()
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertEqual(t, 1, logger.CloseCallsCount())
+	assertTrue(t, subject.Enabled(xlog.LevelDebug))
+}
+
+func TestOccurrenceLogger_concurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger       = xlog.NewMockLogger()
+		subject      = xlog.LogEvery(logger, 5)
+		goroutinesNo = 20
+		logsNo       = 50
+		wg           sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < logsNo; j++ {
+				subject.Error(xlog.MessageKey, "same message")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, goroutinesNo*logsNo/5, logger.LogCallsCount(xlog.LevelError))
+}
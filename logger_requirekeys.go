@@ -0,0 +1,174 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"errors"
+	"fmt"
+)
+
+// defaultMissingKeyValue is the value a missing required key is logged with, by default.
+const defaultMissingKeyValue = "<missing>"
+
+// ErrMissingKeys is the error reported by a [RequireKeysLogger]
+// for a log lacking required key(s).
+var ErrMissingKeys = errors.New("xlog: missing required keys")
+
+// RequireKeysLogger decorates a Logger so that every log contains a required
+// set of keys (example: "tenant", "request_id", for compliance).
+// The missing ones are added, in front of the log's key-values, with a sentinel value
+// ("<missing>" by default), and optionally reported to an error handler.
+// This way instrumentation gaps are caught.
+// It is concurrent safe to use, once configured.
+// Note: the source of the logs is off by one frame, unless you account for
+// this decorator in the decorated logger's [CommonOpts.SourceSkipExtra].
+type RequireKeysLogger struct {
+	// decorated logger.
+	logger Logger
+	// keys every log must contain.
+	required []string
+	// value a missing key is logged with.
+	// can be set with SetMissingValue method.
+	missingValue string
+	// callback missing keys are reported to; nil means disabled.
+	// can be set with SetErrHandler method.
+	errHandler ErrorHandler
+	// the decorated logger's common options, whose additional key-values
+	// count as present; nil means none.
+	// can be set with SetCommonOpts method.
+	opts *CommonOpts
+}
+
+// NewRequireKeysLogger decorates given Logger so that every log
+// contains the required keys.
+func NewRequireKeysLogger(inner Logger, required ...string) *RequireKeysLogger {
+	return &RequireKeysLogger{
+		logger:       inner,
+		required:     required,
+		missingValue: defaultMissingKeyValue,
+	}
+}
+
+// SetMissingValue sets the sentinel value a missing key is logged with.
+// It should be called before logging starts, it is not concurrent safe.
+func (logger *RequireKeysLogger) SetMissingValue(missingValue string) {
+	logger.missingValue = missingValue
+}
+
+// SetErrHandler sets a callback a log lacking required key(s) gets reported to,
+// with an [ErrMissingKeys] error (and the log's original key-values).
+// It should be called before logging starts, it is not concurrent safe.
+func (logger *RequireKeysLogger) SetErrHandler(errHandler ErrorHandler) {
+	logger.errHandler = errHandler
+}
+
+// SetCommonOpts sets the decorated logger's common options, so that the required keys
+// set through their [CommonOpts.AdditionalKeyValues] (a global "tenant", for example)
+// count as present. Otherwise, such keys are reported as missing, and the sentinel
+// value, logged after the real one, may overwrite it (in JSON, the last key wins).
+// It should be called before logging starts, it is not concurrent safe.
+func (logger *RequireKeysLogger) SetCommonOpts(opts *CommonOpts) {
+	logger.opts = opts
+}
+
+// Critical logs application component unavailable, fatal events.
+func (logger *RequireKeysLogger) Critical(keyValues ...any) {
+	logger.logger.Critical(logger.withRequiredKeys(LevelCritical, keyValues)...)
+}
+
+// Error logs runtime errors that
+// should typically be logged and monitored.
+func (logger *RequireKeysLogger) Error(keyValues ...any) {
+	logger.logger.Error(logger.withRequiredKeys(LevelError, keyValues)...)
+}
+
+// Warn logs exceptional occurrences that are not errors.
+// Example: Use of deprecated APIs, poor use of an API, undesirable things
+// that are not necessarily wrong.
+func (logger *RequireKeysLogger) Warn(keyValues ...any) {
+	logger.logger.Warn(logger.withRequiredKeys(LevelWarning, keyValues)...)
+}
+
+// Info logs interesting events.
+// Example: User logs in, SQL logs.
+func (logger *RequireKeysLogger) Info(keyValues ...any) {
+	logger.logger.Info(logger.withRequiredKeys(LevelInfo, keyValues)...)
+}
+
+// Debug logs detailed debug information.
+func (logger *RequireKeysLogger) Debug(keyValues ...any) {
+	logger.logger.Debug(logger.withRequiredKeys(LevelDebug, keyValues)...)
+}
+
+// Log logs arbitrary data.
+func (logger *RequireKeysLogger) Log(keyValues ...any) {
+	logger.logger.Log(logger.withRequiredKeys(LevelNone, keyValues)...)
+}
+
+// Enabled returns true if the decorated logger would log
+// a log with given level. See also [IsLevelEnabled].
+func (logger *RequireKeysLogger) Enabled(lvl Level) bool {
+	return IsLevelEnabled(logger.logger, lvl)
+}
+
+// bareMessageAsKey forwards the check to the decorated logger.
+func (logger *RequireKeysLogger) bareMessageAsKey() bool {
+	return isBareMessageAsKey(logger.logger)
+}
+
+// Close closes the decorated logger.
+func (logger *RequireKeysLogger) Close() error {
+	return logger.logger.Close()
+}
+
+// withRequiredKeys returns given key-values, with the missing required keys
+// added in front of them (after the bare message, if any, see [CommonOpts.BareMessageAsKey]).
+// Given key-values are returned as they are, if no key is missing,
+// or if the decorated logger would not log them anyway.
+func (logger *RequireKeysLogger) withRequiredKeys(lvl Level, keyValues []any) []any {
+	if !IsLevelEnabled(logger.logger, lvl) {
+		return keyValues
+	}
+
+	msg, rest := splitBareMessage(logger.logger, keyValues)
+	var missing []string
+	for _, key := range logger.required {
+		if !hasKey(rest, key) && !logger.hasAdditionalKey(key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return keyValues
+	}
+
+	if logger.errHandler != nil {
+		logger.errHandler(fmt.Errorf("%w %q", ErrMissingKeys, missing), keyValues)
+	}
+	keyVals := make([]any, 0, len(msg)+2*len(missing)+len(rest))
+	keyVals = append(keyVals, msg...)
+	for _, key := range missing {
+		keyVals = append(keyVals, key, logger.missingValue)
+	}
+
+	return append(keyVals, rest...)
+}
+
+// hasAdditionalKey returns true if given key is found among
+// the common options' additional key-values' keys.
+func (logger *RequireKeysLogger) hasAdditionalKey(key string) bool {
+	return logger.opts != nil && hasKey(logger.opts.AdditionalKeyValues, key)
+}
+
+// hasKey returns true if given key is found among key-values' keys.
+func hasKey(keyValues []any, key string) bool {
+	for idx := 0; idx < len(keyValues); idx += 2 {
+		if keyValues[idx] == key {
+			return true
+		}
+	}
+
+	return false
+}
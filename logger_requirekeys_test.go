@@ -0,0 +1,245 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestRequireKeysLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing keys are injected", testRequireKeysLoggerInjectsMissingKeys)
+	t.Run("missing keys are reported", testRequireKeysLoggerReportsMissingKeys)
+	t.Run("custom missing value", testRequireKeysLoggerWithMissingValue)
+	t.Run("keys from common options count as present", testRequireKeysLoggerWithCommonOpts)
+	t.Run("bare message is kept first", testRequireKeysLoggerBareMessage)
+	t.Run("disabled levels are not checked", testRequireKeysLoggerDisabledLevels)
+	t.Run("all levels", testRequireKeysLoggerAllLevels)
+	t.Run("enabled and close are forwarded", testRequireKeysLoggerEnabledAndClose)
+}
+
+func testRequireKeysLoggerInjectsMissingKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewRequireKeysLogger(inner, "tenant", "request_id")
+	)
+
+	// act
+	subject.Info(xlog.MessageKey, "all keys", "tenant", "acme", "request_id", "r1")
+	subject.Info(xlog.MessageKey, "missing request id", "tenant", "acme")
+	subject.Info(xlog.MessageKey, "missing all")
+	subject.Info(xlog.MessageKey, "odd", "tenant")
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{
+				Level:     xlog.LevelInfo,
+				KeyValues: []any{xlog.MessageKey, "all keys", "tenant", "acme", "request_id", "r1"},
+			},
+			{
+				Level:     xlog.LevelInfo,
+				KeyValues: []any{"request_id", "<missing>", xlog.MessageKey, "missing request id", "tenant", "acme"},
+			},
+			{
+				Level:     xlog.LevelInfo,
+				KeyValues: []any{"tenant", "<missing>", "request_id", "<missing>", xlog.MessageKey, "missing all"},
+			},
+			{
+				Level:     xlog.LevelInfo,
+				KeyValues: []any{"request_id", "<missing>", xlog.MessageKey, "odd", "tenant", "*NoValue*"},
+			},
+		},
+		inner.Records(),
+	)
+}
+
+func testRequireKeysLoggerReportsMissingKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner      = xlog.NewCaptureLogger()
+		errHandler = new(MockErrorHandler)
+		subject    = xlog.NewRequireKeysLogger(inner, "tenant", "request_id")
+	)
+	subject.SetErrHandler(errHandler.Handle)
+	errHandler.SetHandleCallback(func(err error, keyValues []any) {
+		assertTrue(t, errors.Is(err, xlog.ErrMissingKeys))
+		assertEqual(t, `xlog: missing required keys ["request_id"]`, err.Error())
+		assertEqual(t, []any{xlog.MessageKey, "foo", "tenant", "acme"}, keyValues)
+	})
+
+	// act
+	subject.Error(xlog.MessageKey, "foo", "tenant", "acme")
+	subject.Error(xlog.MessageKey, "bar", "tenant", "acme", "request_id", "r1")
+
+	// assert
+	assertEqual(t, 1, errHandler.HandleCallsCount())
+	assertEqual(t, 2, len(inner.Records()))
+}
+
+func testRequireKeysLoggerWithMissingValue(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewRequireKeysLogger(inner, "tenant")
+	)
+	subject.SetMissingValue("N/A")
+
+	// act
+	subject.Warn(xlog.MessageKey, "foo")
+
+	// assert
+	assertTrue(t, inner.HasEntry(xlog.LevelWarning, "tenant", "N/A"))
+}
+
+func testRequireKeysLoggerWithCommonOpts(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf        bytes.Buffer
+		commOpts   = xlog.NewCommonOpts()
+		errHandler = new(MockErrorHandler)
+		inner      = xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(commOpts))
+		subject    = xlog.NewRequireKeysLogger(inner, "tenant", "request_id")
+	)
+	commOpts.AdditionalKeyValues = []any{"tenant", "acme"}
+	subject.SetCommonOpts(commOpts)
+	subject.SetErrHandler(errHandler.Handle)
+	errHandler.SetHandleCallback(func(err error, _ []any) {
+		assertEqual(t, `xlog: missing required keys ["request_id"]`, err.Error())
+	})
+
+	// act
+	subject.Error(xlog.MessageKey, "foo")
+
+	// assert
+	assertEqual(t, 1, errHandler.HandleCallsCount())
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err.Error())
+	}
+	assertEqual(t, "acme", doc["tenant"])
+	assertEqual(t, "<missing>", doc["request_id"])
+}
+
+func testRequireKeysLoggerBareMessage(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf        bytes.Buffer
+		commOpts   = xlog.NewCommonOpts()
+		errHandler = new(MockErrorHandler)
+		inner      = xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(commOpts))
+		subject    = xlog.NewRequireKeysLogger(inner, "tenant", "request_id")
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	commOpts.BareMessageAsKey = true
+	subject.SetErrHandler(errHandler.Handle)
+
+	// act
+	subject.Error("user logged in", "request_id", "r1", "tenant", "t1")
+	subject.Error("user logged out", "request_id", "r2")
+
+	// assert
+	assertEqual(t, 1, errHandler.HandleCallsCount()) // only the 2nd log lacks a key.
+	assertEqual(
+		t,
+		`{"date":"`+staticTime+`","lvl":"ERROR","msg":"user logged in","request_id":"r1","tenant":"t1"}`+"\n"+
+			`{"date":"`+staticTime+`","lvl":"ERROR","msg":"user logged out","request_id":"r2","tenant":"<missing>"}`+"\n",
+		buf.String(),
+	)
+}
+
+func testRequireKeysLoggerDisabledLevels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf        bytes.Buffer
+		commOpts   = xlog.NewCommonOpts()
+		errHandler = new(MockErrorHandler)
+		inner      = xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(commOpts))
+		subject    = xlog.NewRequireKeysLogger(inner, "tenant")
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelWarning)
+	subject.SetErrHandler(errHandler.Handle)
+
+	// act
+	subject.Debug(xlog.MessageKey, "foo")
+	subject.Info(xlog.MessageKey, "foo")
+
+	// assert
+	assertEqual(t, 0, errHandler.HandleCallsCount())
+	assertEqual(t, 0, buf.Len())
+}
+
+func testRequireKeysLoggerAllLevels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewRequireKeysLogger(inner, "tenant")
+		levels  = []xlog.Level{
+			xlog.LevelCritical,
+			xlog.LevelError,
+			xlog.LevelWarning,
+			xlog.LevelInfo,
+			xlog.LevelDebug,
+			xlog.LevelNone,
+		}
+	)
+
+	// act
+	for _, lvl := range levels {
+		callMethodByLevel(subject, lvl)
+	}
+
+	// assert
+	records := inner.Records()
+	if assertEqual(t, len(levels), len(records)) {
+		for idx, lvl := range levels {
+			assertEqual(t, lvl, records[idx].Level)
+			assertEqual(t, append([]any{"tenant", "<missing>"}, getInputKeyValues()...), records[idx].KeyValues)
+		}
+	}
+}
+
+func testRequireKeysLoggerEnabledAndClose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewMockLogger()
+		subject = xlog.NewRequireKeysLogger(inner, "tenant")
+	)
+	inner.SetCloseError(ErrWrite)
+
+	// act
+	err := subject.Close()
+
+	// assert
+	assertEqual(t, ErrWrite, err)
+	assertEqual(t, 1, inner.CloseCallsCount())
+	assertTrue(t, subject.Enabled(xlog.LevelDebug)) // mock logger is not a level checker.
+}
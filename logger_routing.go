@@ -0,0 +1,215 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/actforgood/xerr"
+)
+
+// RoutingLogger is a Logger which writes logs synchronously, like [SyncLogger],
+// but to a different writer, depending on log's level.
+// Example: debug and info logs to standard output,
+// warning, error and critical logs to standard error.
+// Levels without an explicit route are written to a default writer.
+// Note: if used in a concurrent context, log writes are not concurrent safe,
+// unless the writers are concurrent safe. See also [NewSyncWriter] on this matter.
+type RoutingLogger struct {
+	// writers logs will be written to, per level.
+	routes map[Level]io.Writer
+	// writer logs with a level without route will be written to.
+	// can be set with [RoutingLoggerWithDefaultWriter] functional option.
+	defaultWriter io.Writer
+	// formatter can be set with [RoutingLoggerWithFormatter] functional option.
+	formatter Formatter
+	// min/max levels set at runtime, overriding opts' ones.
+	// can be set with SetMinLevel / SetMaxLevel methods.
+	levels levelOverrides
+	// sequence number of the last log, see [CommonOpts.SequenceKey].
+	seq atomic.Uint64
+	// common options for this logger.
+	// can be set with [RoutingLoggerWithOptions] functional option.
+	opts *CommonOpts
+}
+
+// NewRoutingLogger instantiates a new logger object that writes logs
+// synchronously, to a writer chosen by log's level.
+// First param is the level to writer map.
+// Second param is/are function option(s) through which you can customize
+// the logger. Check for RoutingLoggerWith* options.
+func NewRoutingLogger(routes map[Level]io.Writer, opts ...RoutingLoggerOption) *RoutingLogger {
+	// instantiate object with default properties.
+	logger := &RoutingLogger{
+		routes:        make(map[Level]io.Writer, len(routes)),
+		defaultWriter: os.Stdout,
+		formatter:     JSONFormatter,
+	}
+	for lvl, w := range routes {
+		logger.routes[lvl] = w
+	}
+
+	// apply functional options, if any.
+	for _, opt := range opts {
+		opt(logger)
+	}
+	if logger.opts == nil {
+		logger.opts = NewCommonOpts()
+	}
+
+	return logger
+}
+
+// Critical logs application component unavailable, fatal events.
+func (logger *RoutingLogger) Critical(keyValues ...any) {
+	logger.log(LevelCritical, keyValues...)
+}
+
+// Error logs runtime errors that
+// should typically be logged and monitored.
+func (logger *RoutingLogger) Error(keyValues ...any) {
+	logger.log(LevelError, keyValues...)
+}
+
+// Warn logs exceptional occurrences that are not errors.
+// Example: Use of deprecated APIs, poor use of an API, undesirable things
+// that are not necessarily wrong.
+func (logger *RoutingLogger) Warn(keyValues ...any) {
+	logger.log(LevelWarning, keyValues...)
+}
+
+// Info logs interesting events.
+// Example: User logs in, SQL logs.
+func (logger *RoutingLogger) Info(keyValues ...any) {
+	logger.log(LevelInfo, keyValues...)
+}
+
+// Debug logs detailed debug information.
+func (logger *RoutingLogger) Debug(keyValues ...any) {
+	logger.log(LevelDebug, keyValues...)
+}
+
+// Log logs arbitrary data.
+func (logger *RoutingLogger) Log(keyValues ...any) {
+	logger.log(LevelNone, keyValues...)
+}
+
+// Enabled returns true if a log with given level would be logged.
+// It can be used to avoid expensive computations of key-values
+// for a log that would be ignored anyway.
+func (logger *RoutingLogger) Enabled(lvl Level) bool {
+	return logger.levels.betweenMinMax(logger.opts, lvl)
+}
+
+// bareMessageAsKey returns the [CommonOpts.BareMessageAsKey] flag.
+func (logger *RoutingLogger) bareMessageAsKey() bool {
+	return logger.opts.BareMessageAsKey
+}
+
+// SetMinLevel sets, at runtime, the minimum level allowed to be logged.
+// It overrides the [CommonOpts.MinLevel] for this logger only.
+// It is safe to call it concurrently with logging.
+func (logger *RoutingLogger) SetMinLevel(lvl Level) {
+	logger.levels.setMin(lvl)
+}
+
+// SetMaxLevel sets, at runtime, the maximum level allowed to be logged.
+// It overrides the [CommonOpts.MaxLevel] for this logger only.
+// It is safe to call it concurrently with logging.
+func (logger *RoutingLogger) SetMaxLevel(lvl Level) {
+	logger.levels.setMax(lvl)
+}
+
+// Close performs clean up actions, closes resources,
+// avoids memory leaks, etc.
+// Each distinct writer which can be stopped (like a [BufferedWriter])
+// is stopped exactly once.
+// Make sure to call it at your application shutdown
+// for example.
+func (logger *RoutingLogger) Close() error {
+	var (
+		stopped = make(map[io.Writer]struct{}, len(logger.routes)+1)
+		mErr    *xerr.MultiError
+	)
+	for _, w := range logger.routes {
+		mErr = mErr.Add(logger.stopOnce(w, stopped))
+	}
+	mErr = mErr.Add(logger.stopOnce(logger.defaultWriter, stopped))
+
+	return mErr.ErrOrNil()
+}
+
+// stopOnce stops given writer, if it wasn't already stopped.
+func (logger *RoutingLogger) stopOnce(w io.Writer, stopped map[io.Writer]struct{}) error {
+	switch w.(type) {
+	case *BufferedWriter, *MultiWriter:
+		if _, found := stopped[w]; !found {
+			stopped[w] = struct{}{}
+
+			return stopWriter(w)
+		}
+	}
+
+	return nil
+}
+
+// writer returns the writer for given level.
+func (logger *RoutingLogger) writer(lvl Level) io.Writer {
+	if w, found := logger.routes[lvl]; found {
+		return w
+	}
+
+	return logger.defaultWriter
+}
+
+// log is used internally to write the log, if eligible.
+// Default key-values are prepended to user passed ones.
+func (logger *RoutingLogger) log(lvl Level, keyValues ...any) {
+	// ignore log conditions check.
+	if !logger.levels.betweenMinMax(logger.opts, lvl) {
+		return
+	}
+
+	// enrich passed key values with default ones.
+	keyVals := logger.opts.appendDefaultKeyValues(nil, lvl, keyValues)
+	keyVals = appendSequence(keyVals, logger.opts.SequenceKey, &logger.seq)
+
+	// format the log.
+	if err := safeFormat(logger.formatter, logger.writer(lvl), keyVals); err != nil {
+		logger.opts.ErrHandler(err, keyVals)
+	}
+}
+
+// RoutingLoggerOption defines optional function for configuring
+// a routing logger.
+type RoutingLoggerOption func(*RoutingLogger)
+
+// RoutingLoggerWithDefaultWriter sets the writer logs with a level without
+// an explicit route are written to.
+// [os.Stdout] is used by default.
+func RoutingLoggerWithDefaultWriter(w io.Writer) RoutingLoggerOption {
+	return func(logger *RoutingLogger) {
+		logger.defaultWriter = w
+	}
+}
+
+// RoutingLoggerWithFormatter sets desired formatter.
+// The JSON formatter is used by default.
+func RoutingLoggerWithFormatter(formatter Formatter) RoutingLoggerOption {
+	return func(logger *RoutingLogger) {
+		logger.formatter = formatter
+	}
+}
+
+// RoutingLoggerWithOptions sets the common options.
+// A [NewCommonOpts] is used by default.
+func RoutingLoggerWithOptions(opts *CommonOpts) RoutingLoggerOption {
+	return func(logger *RoutingLogger) {
+		logger.opts = opts
+	}
+}
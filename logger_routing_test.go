@@ -0,0 +1,164 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func ExampleRoutingLogger() {
+	// In this example we create a logger that writes
+	// debug and info logs to standard output and
+	// warning, error and critical logs to error output.
+
+	opts := xlog.NewCommonOpts()
+	opts.MinLevel = xlog.FixedLevelProvider(xlog.LevelDebug)
+	opts.Time = func() any { // mock time for output check
+		return "2022-03-20T16:01:20Z"
+	}
+	opts.SourceKey = "" // disable source for output check
+	logger := xlog.NewRoutingLogger(
+		map[xlog.Level]io.Writer{
+			xlog.LevelWarning:  os.Stderr,
+			xlog.LevelError:    os.Stderr,
+			xlog.LevelCritical: os.Stderr,
+		},
+		xlog.RoutingLoggerWithDefaultWriter(os.Stdout),
+		xlog.RoutingLoggerWithOptions(opts),
+	)
+	defer logger.Close()
+
+	logger.Debug("msg", "I get written to standard output")
+	logger.Error("msg", "I get written to standard error")
+
+	// Output:
+	// {"date":"2022-03-20T16:01:20Z","lvl":"DEBUG","msg":"I get written to standard output"}
+}
+
+func TestRoutingLogger_routesLogsByLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		stdout   bytes.Buffer
+		stderr   bytes.Buffer
+		fallback bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.NewRoutingLogger(
+			map[xlog.Level]io.Writer{
+				xlog.LevelDebug: &stdout,
+				xlog.LevelInfo:  &stdout,
+				xlog.LevelError: &stderr,
+			},
+			xlog.RoutingLoggerWithDefaultWriter(&fallback),
+			xlog.RoutingLoggerWithOptions(commOpts),
+			xlog.RoutingLoggerWithFormatter(xlog.LogfmtFormatter),
+		)
+	)
+	defer subject.Close()
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelDebug)
+
+	// act
+	subject.Debug(xlog.MessageKey, "debug log")
+	subject.Error(xlog.MessageKey, "error log")
+	subject.Critical(xlog.MessageKey, "critical log")
+
+	// assert
+	assertTrue(t, strings.Contains(stdout.String(), "debug log"))
+	assertFalse(t, strings.Contains(stdout.String(), "error log"))
+	assertTrue(t, strings.Contains(stderr.String(), "error log"))
+	assertFalse(t, strings.Contains(stderr.String(), "debug log"))
+	assertTrue(t, strings.Contains(fallback.String(), "critical log"))
+	assertEqual(t, 3, strings.Count(stdout.String()+stderr.String()+fallback.String(), "\n"))
+}
+
+func TestRoutingLogger_ignoresLogsOutsideMinMax(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.NewRoutingLogger(
+			map[xlog.Level]io.Writer{xlog.LevelDebug: writer},
+			xlog.RoutingLoggerWithDefaultWriter(writer),
+		)
+	)
+	defer subject.Close()
+
+	// act
+	subject.Debug(xlog.MessageKey, "debug log")
+	subject.Info(xlog.MessageKey, "info log")
+
+	// assert
+	assertEqual(t, 0, writer.WriteCallsCount())
+	assertFalse(t, subject.Enabled(xlog.LevelDebug))
+	subject.SetMinLevel(xlog.LevelDebug)
+	assertTrue(t, subject.Enabled(xlog.LevelDebug))
+}
+
+func TestRoutingLogger_callsErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer     = new(MockWriter)
+		errHandler = new(MockErrorHandler)
+		commOpts   = xlog.NewCommonOpts()
+		subject    = xlog.NewRoutingLogger(
+			map[xlog.Level]io.Writer{xlog.LevelError: writer},
+			xlog.RoutingLoggerWithOptions(commOpts),
+		)
+	)
+	defer subject.Close()
+	commOpts.ErrHandler = errHandler.Handle
+	writer.SetWriteCallback(WriteCallbackErr)
+
+	// act
+	subject.Error(xlog.MessageKey, "error log")
+
+	// assert
+	assertEqual(t, 1, writer.WriteCallsCount())
+	assertEqual(t, 1, errHandler.HandleCallsCount())
+}
+
+func TestRoutingLogger_Close_stopsEachWriterOnce(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer    = new(MockWriter)
+		bufWriter = xlog.NewBufferedWriter(writer, xlog.BufferedWriterWithFlushInterval(0))
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.NewRoutingLogger(
+			map[xlog.Level]io.Writer{
+				xlog.LevelWarning: bufWriter,
+				xlog.LevelError:   bufWriter,
+			},
+			xlog.RoutingLoggerWithDefaultWriter(bufWriter),
+			xlog.RoutingLoggerWithOptions(commOpts),
+		)
+	)
+	subject.Warn(xlog.MessageKey, "warn log")
+	subject.Error(xlog.MessageKey, "error log")
+	subject.Critical(xlog.MessageKey, "critical log")
+	assertEqual(t, 0, writer.WriteCallsCount()) // still buffered.
+
+	// act
+	err := subject.Close()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, writer.WriteCallsCount())
+	_, _ = bufWriter.Write([]byte("dropped"))
+	calls, _ := bufWriter.DroppedAfterStop()
+	assertEqual(t, uint64(1), calls)
+}
@@ -0,0 +1,171 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ScopedLogger decorates a Logger so that logs made within a scope
+// (a background job, for example) carry the scope's key-values
+// (the job ID, for example).
+// As Go has no goroutine-local storage, a scope is explicit: it is a logger
+// returned by [ScopedLogger.WithScope], which can be passed around as it is,
+// or through a context, see [ContextWithScope] / [ScopeFromContext].
+// It is concurrent safe to use.
+// Note: scoped logs go through an extra frame; add it to the underlying logger's
+// [CommonOpts.SourceSkipExtra], so the logged source still points to the caller.
+type ScopedLogger struct {
+	// decorated logger.
+	logger Logger
+	// the scope's key-values, added in front of each log's ones.
+	keyValues []any
+	// root flag, true means this is the logger returned by [NewScopedLogger].
+	root bool
+	// ended flag, true means the scope's key-values are no longer added.
+	ended atomic.Bool
+}
+
+// NewScopedLogger instantiates a new logger which can start scopes, with [ScopedLogger.WithScope].
+// Logs made directly through it carry no scope key-values.
+func NewScopedLogger(inner Logger) *ScopedLogger {
+	return &ScopedLogger{logger: inner, root: true}
+}
+
+// WithScope starts a new scope, nested in the current one, returning the logger to be used
+// within it, and a cleanup function to be called when the scope ends.
+// Logs made through the returned logger carry the current scope's key-values
+// and given ones. Once the cleanup function is called, they are no longer added
+// (so that a goroutine outliving the job does not log with the job's ID, for example).
+//
+// Example of usage:
+//
+//	jobLogger, end := scopedLogger.WithScope("job_id", job.ID)
+//	defer end()
+//	jobLogger.Info(xlog.MessageKey, "job started")
+func (logger *ScopedLogger) WithScope(keyValues ...any) (*ScopedLogger, func()) {
+	scopeKeyValues := logger.scopeKeyValues()
+	scoped := &ScopedLogger{
+		logger:    logger.logger,
+		keyValues: make([]any, 0, len(scopeKeyValues)+len(keyValues)+1),
+	}
+	scoped.keyValues = append(scoped.keyValues, scopeKeyValues...)
+	scoped.keyValues = append(scoped.keyValues, keyValues...)
+	if len(scoped.keyValues)%2 == 1 {
+		scoped.keyValues = AppendNoValue(scoped.keyValues)
+	}
+
+	return scoped, scoped.end
+}
+
+// Critical logs application component unavailable, fatal events.
+func (logger *ScopedLogger) Critical(keyValues ...any) {
+	logger.logger.Critical(logger.withScopeKeyValues(keyValues)...)
+}
+
+// Error logs runtime errors that
+// should typically be logged and monitored.
+func (logger *ScopedLogger) Error(keyValues ...any) {
+	logger.logger.Error(logger.withScopeKeyValues(keyValues)...)
+}
+
+// Warn logs exceptional occurrences that are not errors.
+// Example: Use of deprecated APIs, poor use of an API, undesirable things
+// that are not necessarily wrong.
+func (logger *ScopedLogger) Warn(keyValues ...any) {
+	logger.logger.Warn(logger.withScopeKeyValues(keyValues)...)
+}
+
+// Info logs interesting events.
+// Example: User logs in, SQL logs.
+func (logger *ScopedLogger) Info(keyValues ...any) {
+	logger.logger.Info(logger.withScopeKeyValues(keyValues)...)
+}
+
+// Debug logs detailed debug information.
+func (logger *ScopedLogger) Debug(keyValues ...any) {
+	logger.logger.Debug(logger.withScopeKeyValues(keyValues)...)
+}
+
+// Log logs arbitrary data.
+func (logger *ScopedLogger) Log(keyValues ...any) {
+	logger.logger.Log(logger.withScopeKeyValues(keyValues)...)
+}
+
+// Enabled returns true if the decorated logger would log
+// a log with given level. See also [IsLevelEnabled].
+func (logger *ScopedLogger) Enabled(lvl Level) bool {
+	return IsLevelEnabled(logger.logger, lvl)
+}
+
+// bareMessageAsKey forwards the check to the decorated logger.
+func (logger *ScopedLogger) bareMessageAsKey() bool {
+	return isBareMessageAsKey(logger.logger)
+}
+
+// Close closes the decorated logger, if called on the logger returned
+// by [NewScopedLogger]. For a scope, it only ends the scope
+// (the decorated logger is shared with other scopes).
+func (logger *ScopedLogger) Close() error {
+	if logger.root {
+		return logger.logger.Close()
+	}
+	logger.end()
+
+	return nil
+}
+
+// end ends the scope.
+func (logger *ScopedLogger) end() {
+	logger.ended.Store(true)
+}
+
+// scopeKeyValues returns the scope's key-values, if the scope did not end.
+func (logger *ScopedLogger) scopeKeyValues() []any {
+	if logger.ended.Load() {
+		return nil
+	}
+
+	return logger.keyValues
+}
+
+// withScopeKeyValues returns given key-values, with the scope's ones
+// added in front of them (after the bare message, if any, see [CommonOpts.BareMessageAsKey]).
+func (logger *ScopedLogger) withScopeKeyValues(keyValues []any) []any {
+	scopeKeyValues := logger.scopeKeyValues()
+	if len(scopeKeyValues) == 0 {
+		return keyValues
+	}
+	msg, keyValues := splitBareMessage(logger.logger, keyValues)
+	keyVals := make([]any, 0, len(msg)+len(scopeKeyValues)+len(keyValues))
+	keyVals = append(keyVals, msg...)
+	keyVals = append(keyVals, scopeKeyValues...)
+
+	return append(keyVals, keyValues...)
+}
+
+// scopeCtxKey is the key under which a Logger is stored in a context.
+type scopeCtxKey struct{}
+
+// ContextWithScope returns a copy of given context, carrying given Logger
+// (usually a scope, see [ScopedLogger.WithScope]), so that code down the call
+// chain can log within the scope, without a logger being threaded through,
+// with [ScopeFromContext].
+func ContextWithScope(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, scopeCtxKey{}, logger)
+}
+
+// ScopeFromContext returns the Logger carried by given context
+// (see [ContextWithScope]), or given fallback Logger, if the context
+// does not carry one.
+func ScopeFromContext(ctx context.Context, fallback Logger) Logger {
+	if logger, ok := ctx.Value(scopeCtxKey{}).(Logger); ok {
+		return logger
+	}
+
+	return fallback
+}
@@ -0,0 +1,244 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestScopedLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("scopes are isolated", testScopedLoggerIsolation)
+	t.Run("scopes can be nested", testScopedLoggerNested)
+	t.Run("ended scope no longer adds key-values", testScopedLoggerEnded)
+	t.Run("all levels", testScopedLoggerAllLevels)
+	t.Run("close", testScopedLoggerClose)
+	t.Run("scope from context", testScopedLoggerFromContext)
+	t.Run("bare message is kept first", testScopedLoggerBareMessage)
+}
+
+func testScopedLoggerIsolation(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewScopedLogger(inner)
+		wg      sync.WaitGroup
+	)
+	job1Logger, end1 := subject.WithScope("job_id", 1)
+	defer end1()
+	job2Logger, end2 := subject.WithScope("job_id", 2, "queue", "emails")
+	defer end2()
+
+	// act
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		job1Logger.Info(xlog.MessageKey, "job started")
+	}()
+	go func() {
+		defer wg.Done()
+		job2Logger.Info(xlog.MessageKey, "job started")
+	}()
+	wg.Wait()
+	subject.Info(xlog.MessageKey, "no scope")
+
+	// assert
+	records := inner.Records()
+	if assertEqual(t, 3, len(records)) {
+		var job1Found, job2Found bool
+		for _, record := range records[:2] {
+			switch record.KeyValues[1] {
+			case 1:
+				job1Found = true
+				assertEqual(t, []any{"job_id", 1, xlog.MessageKey, "job started"}, record.KeyValues)
+			case 2:
+				job2Found = true
+				assertEqual(t, []any{"job_id", 2, "queue", "emails", xlog.MessageKey, "job started"}, record.KeyValues)
+			}
+		}
+		assertTrue(t, job1Found)
+		assertTrue(t, job2Found)
+		assertEqual(t, []any{xlog.MessageKey, "no scope"}, records[2].KeyValues)
+	}
+}
+
+func testScopedLoggerNested(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewScopedLogger(inner)
+	)
+	jobLogger, endJob := subject.WithScope("job_id", 1)
+	defer endJob()
+	stepLogger, endStep := jobLogger.WithScope("step", "download", "odd")
+	defer endStep()
+
+	// act
+	stepLogger.Warn(xlog.MessageKey, "slow download")
+	jobLogger.Warn(xlog.MessageKey, "job slow")
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{
+				Level:     xlog.LevelWarning,
+				KeyValues: []any{"job_id", 1, "step", "download", "odd", "*NoValue*", xlog.MessageKey, "slow download"},
+			},
+			{
+				Level:     xlog.LevelWarning,
+				KeyValues: []any{"job_id", 1, xlog.MessageKey, "job slow"},
+			},
+		},
+		inner.Records(),
+	)
+}
+
+func testScopedLoggerEnded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewCaptureLogger()
+		subject = xlog.NewScopedLogger(inner)
+	)
+	jobLogger, end := subject.WithScope("job_id", 1)
+
+	// act
+	end()
+	jobLogger.Error(xlog.MessageKey, "after job ended")
+	afterEndLogger, _ := jobLogger.WithScope("step", "x")
+	afterEndLogger.Error(xlog.MessageKey, "nested after job ended")
+
+	// assert
+	assertEqual(
+		t,
+		[]xlog.CaptureRecord{
+			{Level: xlog.LevelError, KeyValues: []any{xlog.MessageKey, "after job ended"}},
+			{Level: xlog.LevelError, KeyValues: []any{"step", "x", xlog.MessageKey, "nested after job ended"}},
+		},
+		inner.Records(),
+	)
+}
+
+func testScopedLoggerAllLevels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner      = xlog.NewCaptureLogger()
+		subject, _ = xlog.NewScopedLogger(inner).WithScope("job_id", 1)
+		levels     = []xlog.Level{
+			xlog.LevelCritical,
+			xlog.LevelError,
+			xlog.LevelWarning,
+			xlog.LevelInfo,
+			xlog.LevelDebug,
+			xlog.LevelNone,
+		}
+	)
+
+	// act
+	for _, lvl := range levels {
+		callMethodByLevel(subject, lvl)
+	}
+
+	// assert
+	records := inner.Records()
+	if assertEqual(t, len(levels), len(records)) {
+		for idx, lvl := range levels {
+			assertEqual(t, lvl, records[idx].Level)
+			assertEqual(t, append([]any{"job_id", 1}, getInputKeyValues()...), records[idx].KeyValues)
+		}
+	}
+}
+
+func testScopedLoggerClose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner   = xlog.NewMockLogger()
+		subject = xlog.NewScopedLogger(inner)
+	)
+	inner.SetCloseError(ErrWrite)
+	jobLogger, _ := subject.WithScope("job_id", 1)
+
+	// act & assert
+	assertNil(t, jobLogger.Close())
+	assertEqual(t, 0, inner.CloseCallsCount())
+
+	assertEqual(t, ErrWrite, subject.Close())
+	assertEqual(t, 1, inner.CloseCallsCount())
+	assertTrue(t, jobLogger.Enabled(xlog.LevelDebug)) // mock logger is not a level checker.
+}
+
+func testScopedLoggerFromContext(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner    = xlog.NewCaptureLogger()
+		fallback = xlog.NewMockLogger()
+		subject  = xlog.NewScopedLogger(inner)
+	)
+	jobLogger, end := subject.WithScope("job_id", 1)
+	defer end()
+	ctx := xlog.ContextWithScope(context.Background(), jobLogger)
+
+	// act
+	xlog.ScopeFromContext(ctx, fallback).Info(xlog.MessageKey, "from context")
+	xlog.ScopeFromContext(context.Background(), fallback).Info(xlog.MessageKey, "fallback")
+
+	// assert
+	assertTrue(t, inner.HasEntry(xlog.LevelInfo, "job_id", 1))
+	assertEqual(t, 1, len(inner.Records()))
+	assertEqual(t, 1, fallback.LogCallsCount(xlog.LevelInfo))
+}
+
+func testScopedLoggerBareMessage(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		inner    = xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(commOpts))
+		subject  = xlog.NewScopedLogger(inner)
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+	commOpts.BareMessageAsKey = true
+	jobLogger, end := subject.WithScope("job", 7)
+	defer end()
+	// decorators stacked on top of each other keep the bare message first, too.
+	requireKeysLogger, endRequired := xlog.NewScopedLogger(
+		xlog.NewRequireKeysLogger(inner, "tenant"),
+	).WithScope("job", 8)
+	defer endRequired()
+
+	// act
+	jobLogger.Warn("job started")
+	requireKeysLogger.Warn("job started", "attempt", 2)
+
+	// assert
+	assertEqual(
+		t,
+		`{"date":"`+staticTime+`","job":7,"lvl":"WARN","msg":"job started"}`+"\n"+
+			`{"attempt":2,"date":"`+staticTime+`","job":8,"lvl":"WARN","msg":"job started","tenant":"<missing>"}`+"\n",
+		buf.String(),
+	)
+}
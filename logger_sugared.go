@@ -0,0 +1,67 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import "fmt"
+
+// SugaredLogger decorates a Logger with Printf-style methods,
+// which log the formatted message under [MessageKey].
+// It is useful when porting code from standard [log.Printf].
+// The structured API of the decorated Logger is still available.
+// The message is formatted only if the level is enabled (see [IsLevelEnabled]).
+// Note: the Printf-style methods add a frame to the call stack,
+// so you may want to increase [CommonOpts.SourceSkipExtra] by one
+// (or the skipped frames of your explicit [SourceProvider]).
+type SugaredLogger struct {
+	Logger
+}
+
+// Sugar decorates given Logger with Printf-style methods.
+func Sugar(logger Logger) *SugaredLogger {
+	return &SugaredLogger{Logger: logger}
+}
+
+// Criticalf logs a formatted critical message.
+func (logger *SugaredLogger) Criticalf(format string, args ...any) {
+	if IsLevelEnabled(logger.Logger, LevelCritical) {
+		logger.Critical(MessageKey, fmt.Sprintf(format, args...))
+	}
+}
+
+// Errorf logs a formatted error message.
+func (logger *SugaredLogger) Errorf(format string, args ...any) {
+	if IsLevelEnabled(logger.Logger, LevelError) {
+		logger.Error(MessageKey, fmt.Sprintf(format, args...))
+	}
+}
+
+// Warnf logs a formatted warning message.
+func (logger *SugaredLogger) Warnf(format string, args ...any) {
+	if IsLevelEnabled(logger.Logger, LevelWarning) {
+		logger.Warn(MessageKey, fmt.Sprintf(format, args...))
+	}
+}
+
+// Infof logs a formatted info message.
+func (logger *SugaredLogger) Infof(format string, args ...any) {
+	if IsLevelEnabled(logger.Logger, LevelInfo) {
+		logger.Info(MessageKey, fmt.Sprintf(format, args...))
+	}
+}
+
+// Debugf logs a formatted debug message.
+func (logger *SugaredLogger) Debugf(format string, args ...any) {
+	if IsLevelEnabled(logger.Logger, LevelDebug) {
+		logger.Debug(MessageKey, fmt.Sprintf(format, args...))
+	}
+}
+
+// Logf logs a formatted arbitrary message.
+func (logger *SugaredLogger) Logf(format string, args ...any) {
+	if IsLevelEnabled(logger.Logger, LevelNone) {
+		logger.Log(MessageKey, fmt.Sprintf(format, args...))
+	}
+}
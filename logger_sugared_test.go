@@ -0,0 +1,108 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func ExampleSugar() {
+	// In this example we create a logger with Printf-style methods.
+
+	opts := xlog.NewCommonOpts()
+	opts.MinLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+	opts.Time = func() any { // mock time for output check
+		return "2022-03-14T16:01:20Z"
+	}
+	opts.Source = xlog.SourceProvider(5, 1) // +1 frame skipped, added by the sugared logger.
+	logger := xlog.Sugar(xlog.NewSyncLogger(
+		os.Stdout,
+		xlog.SyncLoggerWithOptions(opts),
+	))
+	defer logger.Close()
+
+	logger.Infof("Hello %s, it's year %d", "World", 2022)
+
+	// Output:
+	// {"date":"2022-03-14T16:01:20Z","lvl":"INFO","msg":"Hello World, it's year 2022","src":"/logger_sugared_test.go:31"}
+}
+
+func TestSugaredLogger(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		mock     = xlog.NewMockLogger()
+		subject  = xlog.Sugar(mock)
+		levels   = []xlog.Level{xlog.LevelNone, xlog.LevelDebug, xlog.LevelInfo, xlog.LevelWarning, xlog.LevelError, xlog.LevelCritical}
+		expected = []any{xlog.MessageKey, "foo bar 123"}
+	)
+	for _, lvl := range levels {
+		mock.SetLogCallback(lvl, func(keyValues ...any) {
+			assertEqual(t, expected, keyValues)
+		})
+	}
+
+	// act
+	subject.Logf("foo %s %d", "bar", 123)
+	subject.Debugf("foo %s %d", "bar", 123)
+	subject.Infof("foo %s %d", "bar", 123)
+	subject.Warnf("foo %s %d", "bar", 123)
+	subject.Errorf("foo %s %d", "bar", 123)
+	subject.Criticalf("foo %s %d", "bar", 123)
+	mock.SetLogCallback(xlog.LevelInfo, func(keyValues ...any) {
+		assertEqual(t, []any{"structured", "api"}, keyValues)
+	})
+	subject.Info("structured", "api") // structured API is still available.
+	_ = subject.Close()
+
+	// assert
+	for _, lvl := range levels {
+		expectedCallsCnt := 1
+		if lvl == xlog.LevelInfo {
+			expectedCallsCnt = 2
+		}
+		assertEqual(t, expectedCallsCnt, mock.LogCallsCount(lvl))
+	}
+	assertEqual(t, 1, mock.CloseCallsCount())
+}
+
+func TestSugaredLogger_skipsFormattingForDisabledLevels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		opts       = xlog.NewCommonOpts()
+		formatsCnt int
+		arg        = sugaredArg(func() string {
+			formatsCnt++
+
+			return "bar"
+		})
+	)
+	opts.MinLevel = xlog.FixedLevelProvider(xlog.LevelWarning)
+	subject := xlog.Sugar(xlog.NewSyncLogger(io.Discard, xlog.SyncLoggerWithOptions(opts)))
+
+	// act
+	subject.Debugf("foo %s", arg)
+	subject.Infof("foo %s", arg)
+	subject.Warnf("foo %s", arg)
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 1, formatsCnt)
+}
+
+// sugaredArg is a fmt.Stringer counting its calls.
+type sugaredArg func() string
+
+func (arg sugaredArg) String() string {
+	return arg()
+}
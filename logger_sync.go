@@ -7,6 +7,7 @@ package xlog
 
 import (
 	"io"
+	"sync/atomic"
 )
 
 // SyncLogger is a Logger which writes logs synchronously.
@@ -18,6 +19,24 @@ type SyncLogger struct {
 	writer io.Writer
 	// formatter can be set with [SyncLoggerWithFormatter] functional option.
 	formatter Formatter
+	// min/max levels set at runtime, overriding opts' ones.
+	// can be set with SetMinLevel / SetMaxLevel methods.
+	levels levelOverrides
+	// the level at or above which a [BufferedWriter] gets flushed
+	// right after writing the log. nil means disabled.
+	// can be set with [SyncLoggerWithFlushOnLevel] functional option.
+	flushLevel *Level
+	// the level at or above which the stack trace is captured and logged.
+	// nil means disabled.
+	// can be set with [SyncLoggerWithStackTrace] functional option.
+	stackLevel *Level
+	// max no. of frames of a captured stack trace.
+	stackMaxDepth int
+	// sequence number of the last log, see [CommonOpts.SequenceKey].
+	seq atomic.Uint64
+	// pooling flag, true means key-values slices are reused across logs.
+	// can be set with [SyncLoggerWithPooling] functional option.
+	pooling bool
 	// common options for this logger.
 	// can be set with [SyncLoggerWithOptions] functional option.
 	opts *CommonOpts
@@ -81,31 +100,74 @@ func (logger *SyncLogger) Log(keyValues ...any) {
 	logger.log(LevelNone, keyValues...)
 }
 
+// LogAt logs with given level, known only at runtime
+// (derived from an HTTP status, for example).
+// The level is subject to min / max levels filtering, as for the leveled methods.
+func (logger *SyncLogger) LogAt(lvl Level, keyValues ...any) {
+	logger.log(lvl, keyValues...)
+}
+
+// Enabled returns true if a log with given level would be logged.
+// It can be used to avoid expensive computations of key-values
+// for a log that would be ignored anyway.
+func (logger *SyncLogger) Enabled(lvl Level) bool {
+	return logger.levels.betweenMinMax(logger.opts, lvl)
+}
+
+// bareMessageAsKey returns the [CommonOpts.BareMessageAsKey] flag.
+func (logger *SyncLogger) bareMessageAsKey() bool {
+	return logger.opts.BareMessageAsKey
+}
+
+// SetMinLevel sets, at runtime, the minimum level allowed to be logged.
+// It overrides the [CommonOpts.MinLevel] for this logger only.
+// It is safe to call it concurrently with logging.
+func (logger *SyncLogger) SetMinLevel(lvl Level) {
+	logger.levels.setMin(lvl)
+}
+
+// SetMaxLevel sets, at runtime, the maximum level allowed to be logged.
+// It overrides the [CommonOpts.MaxLevel] for this logger only.
+// It is safe to call it concurrently with logging.
+func (logger *SyncLogger) SetMaxLevel(lvl Level) {
+	logger.levels.setMax(lvl)
+}
+
 // Close performs clean up actions, closes resources,
 // avoids memory leaks, etc.
 // Make sure to call it at your application shutdown
 // for example.
 func (logger *SyncLogger) Close() error {
-	if bw, ok := logger.writer.(*BufferedWriter); ok {
-		bw.Stop()
-	}
-
-	return nil
+	return stopWriter(logger.writer)
 }
 
 // log is used internally to write the log, if eligible.
 // Default key-values are prepended to user passed ones.
 func (logger *SyncLogger) log(lvl Level, keyValues ...any) {
 	// ignore log conditions check.
-	if !logger.opts.BetweenMinMax(lvl) {
+	if !logger.levels.betweenMinMax(logger.opts, lvl) {
 		return
 	}
 
 	// enrich passed key values with default ones.
-	keyVals := logger.opts.WithDefaultKeyValues(lvl, keyValues...)
+	var keyVals []any
+	if logger.pooling {
+		pooled := getKeyVals()
+		keyVals = *pooled
+		defer func() {
+			*pooled = keyVals // slice may have grown.
+			putKeyVals(pooled)
+		}()
+	}
+	keyVals = logger.opts.appendDefaultKeyValues(keyVals, lvl, keyValues)
+	keyVals = appendStackTrace(keyVals, logger.stackLevel, logger.stackMaxDepth, lvl)
+	keyVals = appendSequence(keyVals, logger.opts.SequenceKey, &logger.seq)
 
 	// format the log.
-	if err := logger.formatter(logger.writer, keyVals); err != nil {
+	if err := safeFormat(logger.formatter, logger.writer, keyVals); err != nil {
+		logger.opts.ErrHandler(err, keyVals)
+	}
+	if err := flushOnLevel(logger.writer, logger.flushLevel, lvl); err != nil {
 		logger.opts.ErrHandler(err, keyVals)
 	}
 }
@@ -24,3 +24,39 @@ func SyncLoggerWithOptions(opts *CommonOpts) SyncLoggerOption {
 		logger.opts = opts
 	}
 }
+
+// SyncLoggerWithFlushOnLevel makes the logger flush the writer, if it is
+// a [BufferedWriter], right after writing a log with a level at or above
+// given one. This way, for example, an error preceding a crash does not get
+// lost in the buffer.
+// By default, this feature is disabled.
+func SyncLoggerWithFlushOnLevel(lvl Level) SyncLoggerOption {
+	return func(logger *SyncLogger) {
+		logger.flushLevel = &lvl
+	}
+}
+
+// SyncLoggerWithPooling makes the logger reuse, from a pool, the slices
+// holding logs' key-values, instead of allocating a new one for each log.
+// It reduces allocations on hot paths.
+// Note: as a slice is reused after the log is written, the formatter and
+// the [CommonOpts.ErrHandler] must not retain the key-values they receive
+// after they return.
+// By default, this feature is disabled.
+func SyncLoggerWithPooling() SyncLoggerOption {
+	return func(logger *SyncLogger) {
+		logger.pooling = true
+	}
+}
+
+// SyncLoggerWithStackTrace makes the logger capture the current goroutine's stack
+// trace for logs with a level at or above given one, and log it under [StackKey].
+// Frames belonging to this package are skipped.
+// maxDepth limits the no. of frames of the stack trace. If <=0, 32 is used.
+// By default, this feature is disabled.
+func SyncLoggerWithStackTrace(minLevel Level, maxDepth int) SyncLoggerOption {
+	return func(logger *SyncLogger) {
+		logger.stackLevel = &minLevel
+		logger.stackMaxDepth = maxDepth
+	}
+}
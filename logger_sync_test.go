@@ -248,6 +248,475 @@ func TestSyncLogger_Close_withBufferedWriter(t *testing.T) {
 	assertTrue(t, strings.Contains(log, "foo bar"))
 }
 
+func TestSyncLogger_Close_returnsStopErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer    = new(MockWriter)
+		bufWriter = xlog.NewBufferedWriter(writer, xlog.BufferedWriterWithFlushInterval(0))
+		subject   = xlog.NewSyncLogger(bufWriter)
+	)
+	writer.SetWriteCallback(WriteCallbackErr)
+	subject.Error("msg", "foo bar")
+
+	// act
+	err := subject.Close()
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func TestSyncLogger_withFlushOnLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer    = new(MockWriter)
+		commOpts  = xlog.NewCommonOpts()
+		written   []byte
+		bufWriter = xlog.NewBufferedWriter(
+			writer,
+			xlog.BufferedWriterWithSize(1024*1024),
+			xlog.BufferedWriterWithFlushInterval(0),
+		)
+		subject = xlog.NewSyncLogger(
+			bufWriter,
+			xlog.SyncLoggerWithFormatter(xlog.LogfmtFormatter),
+			xlog.SyncLoggerWithOptions(commOpts),
+			xlog.SyncLoggerWithFlushOnLevel(xlog.LevelError),
+		)
+	)
+	defer subject.Close()
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		written = append(written, p...)
+
+		return len(p), nil
+	})
+
+	// act
+	subject.Info("msg", "info log")
+
+	// assert - info log is still buffered.
+	assertEqual(t, 0, writer.WriteCallsCount())
+
+	// act
+	subject.Error("msg", "error log")
+
+	// assert - both logs got flushed.
+	assertEqual(t, 1, writer.WriteCallsCount())
+	assertTrue(t, strings.Contains(string(written), "info log"))
+	assertTrue(t, strings.Contains(string(written), "error log"))
+}
+
+func TestSyncLogger_withStackTrace(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		commOpts  = xlog.NewCommonOpts()
+		stacks    = make(map[string]string, 2)
+		mu        sync.Mutex
+		subject   = xlog.NewSyncLogger(
+			io.Discard,
+			xlog.SyncLoggerWithOptions(commOpts),
+			xlog.SyncLoggerWithFormatter(formatter.Format),
+			xlog.SyncLoggerWithStackTrace(xlog.LevelError, 2),
+		)
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelWarning)
+	formatter.SetFormatCallback(func(_ io.Writer, keyValues []any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		lvl := keyValues[3].(string)
+		stacks[lvl] = ""
+		if keyValues[len(keyValues)-2] == xlog.StackKey {
+			stacks[lvl] = keyValues[len(keyValues)-1].(string)
+		}
+
+		return nil
+	})
+
+	// act
+	subject.Warn(xlog.MessageKey, "no stack")
+	subject.Error(xlog.MessageKey, "with stack")
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, 2, formatter.FormatCallsCount())
+	assertEqual(t, "", stacks["WARN"])
+	stack := stacks["ERROR"]
+	lines := strings.Split(stack, "\n")
+	assertEqual(t, 2, len(lines))
+	assertTrue(t, strings.HasPrefix(lines[0], "github.com/actforgood/xlog_test.TestSyncLogger_withStackTrace ("))
+	assertTrue(t, strings.Contains(lines[0], "logger_sync_test.go:"))
+}
+
+func TestSyncLogger_Enabled(t *testing.T) {
+	t.Parallel()
+
+	levels := []xlog.Level{xlog.LevelNone, xlog.LevelDebug, xlog.LevelInfo, xlog.LevelWarning, xlog.LevelError, xlog.LevelCritical}
+	for _, minLvl := range levels {
+		for _, maxLvl := range levels {
+			// arrange
+			var (
+				formatter = new(MockFormatter)
+				commOpts  = xlog.NewCommonOpts()
+				subject   = xlog.NewSyncLogger(
+					io.Discard,
+					xlog.SyncLoggerWithFormatter(formatter.Format),
+					xlog.SyncLoggerWithOptions(commOpts),
+				)
+				expectedFormatCallsCnt int
+			)
+			commOpts.MinLevel = xlog.FixedLevelProvider(minLvl)
+			commOpts.MaxLevel = xlog.FixedLevelProvider(maxLvl)
+
+			for _, lvl := range levels {
+				// act
+				result := subject.Enabled(lvl)
+				callMethodByLevel(subject, lvl)
+
+				// assert
+				assertEqual(t, commOpts.BetweenMinMax(lvl), result)
+				if result {
+					expectedFormatCallsCnt++
+				}
+			}
+			_ = subject.Close()
+			assertEqual(t, expectedFormatCallsCnt, formatter.FormatCallsCount())
+		}
+	}
+}
+
+func TestSyncLogger_LogAt(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		subject  = xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(commOpts))
+		levels   = []xlog.Level{xlog.LevelNone, xlog.LevelDebug, xlog.LevelInfo, xlog.LevelWarning, xlog.LevelError, xlog.LevelCritical}
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.Source = xlog.SourceProvider(4, 1)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+	commOpts.MaxLevel = xlog.FixedLevelProvider(xlog.LevelError)
+
+	for _, lvl := range levels {
+		buf.Reset()
+
+		// act
+		subject.LogAt(lvl, xlog.MessageKey, "dynamic level")
+
+		// assert
+		if !commOpts.BetweenMinMax(lvl) {
+			assertEqual(t, "", buf.String())
+
+			continue
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err.Error())
+		}
+		assertEqual(t, commOpts.LevelLabels[lvl], doc["lvl"])
+		assertEqual(t, "dynamic level", doc["msg"])
+		assertTrue(t, strings.HasPrefix(doc["src"].(string), "/logger_sync_test.go:")) // caller is reported.
+	}
+}
+
+func TestSyncLogger_recoversFromFormatterPanic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer     bytes.Buffer
+		formatter  = new(MockFormatter)
+		errHandler = new(MockErrorHandler)
+		commOpts   = xlog.NewCommonOpts()
+		subject    = xlog.NewSyncLogger(
+			&writer,
+			xlog.SyncLoggerWithFormatter(formatter.Format),
+			xlog.SyncLoggerWithOptions(commOpts),
+		)
+	)
+	commOpts.ErrHandler = errHandler.Handle
+	commOpts.SourceKey = ""
+	commOpts.Time = staticTimeProvider
+	formatter.SetFormatCallback(FormatCallbackPanicOnKey)
+	errHandler.SetHandleCallback(func(err error, keyVals []any) {
+		assertTrue(t, errors.Is(err, xlog.ErrFormatterPanicked))
+		assertEqual(t, "xlog: formatter panicked: intentionally triggered Formatter panic", err.Error())
+		assertEqual(t, []any{"date", staticTime, "lvl", "ERROR", "panic", true}, keyVals)
+	})
+
+	// act
+	subject.Error("panic", true)
+	subject.Error("foo", "bar")
+
+	// assert
+	assertEqual(t, 2, formatter.FormatCallsCount())
+	assertEqual(t, 1, errHandler.HandleCallsCount())
+	assertEqual(t, "date="+staticTime+" lvl=ERROR foo=bar\n", writer.String())
+}
+
+func TestSyncLogger_withLazyValue(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf       bytes.Buffer
+		commOpts  = xlog.NewCommonOpts()
+		lazyCalls = 0
+		lazyValue = xlog.Lazy(func() any {
+			lazyCalls++
+
+			return "expensive"
+		})
+		subject = xlog.NewSyncLogger(&buf, xlog.SyncLoggerWithOptions(commOpts))
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+
+	// act - log is filtered out.
+	subject.Debug("payload", lazyValue)
+
+	// assert
+	assertEqual(t, 0, lazyCalls)
+	assertEqual(t, 0, buf.Len())
+
+	// act - log is accepted.
+	subject.Error("payload", lazyValue)
+
+	// assert
+	assertEqual(t, 1, lazyCalls)
+	assertEqual(t, `{"date":"`+staticTime+`","lvl":"ERROR","payload":"expensive"}`+"\n", buf.String())
+}
+
+func TestSyncLogger_withSequence(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		commOpts = xlog.NewCommonOpts()
+		captured = make(map[uint64]int)
+		mu       sync.Mutex
+		subject  = xlog.NewSyncLogger(
+			io.Discard,
+			xlog.SyncLoggerWithOptions(commOpts),
+			xlog.SyncLoggerWithFormatter(func(_ io.Writer, keyValues []any) error {
+				mu.Lock()
+				captured[keyValues[len(keyValues)-1].(uint64)]++
+				mu.Unlock()
+
+				return nil
+			}),
+		)
+		goroutinesNo = 10
+		logsNo       = 100
+		wg           sync.WaitGroup
+	)
+	commOpts.SequenceKey = "seq"
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < logsNo; j++ {
+				subject.Error("msg", "foo bar")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, goroutinesNo*logsNo, len(captured))
+	for seq := uint64(1); seq <= uint64(goroutinesNo*logsNo); seq++ {
+		if !assertEqual(t, 1, captured[seq]) {
+			break
+		}
+	}
+}
+
+func TestSyncLogger_withPooling(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer       = xlog.NewSyncWriter(new(bytes.Buffer))
+		commOpts     = xlog.NewCommonOpts()
+		goroutinesNo = 20
+		logsNo       = 200
+		logged       = make([]int, goroutinesNo*logsNo)
+		mu           sync.Mutex
+		subject      = xlog.NewSyncLogger(
+			writer,
+			xlog.SyncLoggerWithOptions(commOpts),
+			xlog.SyncLoggerWithPooling(),
+			xlog.SyncLoggerWithFormatter(func(w io.Writer, keyValues []any) error {
+				// a reused slice would make the pair below inconsistent.
+				id, _ := keyValues[len(keyValues)-3].(int)
+				idCopy, _ := keyValues[len(keyValues)-1].(int)
+				if id != idCopy {
+					t.Errorf("corrupted key-values: %v", keyValues)
+				} else {
+					mu.Lock()
+					logged[id]++
+					mu.Unlock()
+				}
+
+				return xlog.LogfmtFormatter(w, keyValues)
+			}),
+		)
+		wg sync.WaitGroup
+	)
+	commOpts.Time = staticTimeProvider
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < logsNo; j++ {
+				id := i*logsNo + j
+				subject.Error("id", id, "idCopy", id)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// assert
+	for id := range logged {
+		if !assertEqual(t, 1, logged[id]) {
+			break
+		}
+	}
+}
+
+func TestSyncLogger_withPooling_sameOutput(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf, pooledBuf bytes.Buffer
+		commOpts       = xlog.NewCommonOpts()
+		logger         = xlog.NewSyncLogger(
+			&buf,
+			xlog.SyncLoggerWithOptions(commOpts),
+		)
+		subject = xlog.NewSyncLogger(
+			&pooledBuf,
+			xlog.SyncLoggerWithOptions(commOpts),
+			xlog.SyncLoggerWithPooling(),
+		)
+	)
+	commOpts.Time = staticTimeProvider
+	commOpts.AdditionalKeyValues = []any{"app", "demo"}
+	commOpts.Source = xlog.SourceProvider(4, 1)
+
+	// act
+	for i := 0; i < 3; i++ {
+		for _, lgr := range [...]xlog.Logger{logger, subject} {
+			lgr.Error("msg", "foo bar", "i", i) // same line, so that source is the same.
+		}
+	}
+
+	// assert
+	assertEqual(t, buf.String(), pooledBuf.String())
+	assertEqual(t, 3, strings.Count(pooledBuf.String(), `"src":"/logger_sync_test.go:`))
+}
+
+func TestSyncLogger_SetMinMaxLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		formatter = new(MockFormatter)
+		commOpts  = xlog.NewCommonOpts()
+		subject   = xlog.NewSyncLogger(
+			io.Discard,
+			xlog.SyncLoggerWithFormatter(formatter.Format),
+			xlog.SyncLoggerWithOptions(commOpts),
+		)
+		otherSubject = xlog.NewSyncLogger(
+			io.Discard,
+			xlog.SyncLoggerWithFormatter(formatter.Format),
+			xlog.SyncLoggerWithOptions(commOpts),
+		)
+	)
+
+	// act & assert
+	subject.Info()
+	otherSubject.Info()
+	_ = otherSubject.Close()
+	subject.SetMinLevel(xlog.LevelDebug)
+	subject.Debug()
+	subject.Info()
+	subject.Critical()
+	subject.SetMaxLevel(xlog.LevelInfo)
+	subject.Debug()
+	subject.Warn()
+	subject.Error()
+	subject.SetMinLevel(xlog.LevelNone)
+	subject.SetMaxLevel(xlog.LevelNone)
+	subject.Log()
+	subject.Debug()
+	_ = subject.Close()
+
+	assertEqual(t, 5, formatter.FormatCallsCount())
+	assertEqual(t, xlog.LevelWarning, commOpts.MinLevel()) // opts were not changed.
+	assertEqual(t, xlog.LevelCritical, commOpts.MaxLevel())
+}
+
+func TestSyncLogger_SetMinLevel_concurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer       bytes.Buffer
+		subject      = xlog.NewSyncLogger(xlog.NewSyncWriter(&writer))
+		goroutinesNo = 50
+		logsNo       = 20
+		wg           sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(2)
+		go func(threadNo int) {
+			defer wg.Done()
+			for j := 0; j < logsNo; j++ {
+				subject.Debug("threadNo", threadNo, "logNo", j)
+			}
+		}(i)
+		go func(threadNo int) {
+			defer wg.Done()
+			if threadNo%2 == 0 {
+				subject.SetMinLevel(xlog.LevelDebug)
+			} else {
+				subject.SetMinLevel(xlog.LevelError)
+			}
+			subject.SetMaxLevel(xlog.LevelCritical)
+		}(i)
+	}
+	wg.Wait()
+	subject.SetMinLevel(xlog.LevelError)
+	subject.Debug("lastDebug", "ignored")
+	subject.Error("lastError", "logged")
+	_ = subject.Close()
+
+	// assert
+	output := writer.String()
+	assertTrue(t, strings.Count(output, "\n") <= goroutinesNo*logsNo+1)
+	assertFalse(t, strings.Contains(output, "lastDebug"))
+	assertTrue(t, strings.Contains(output, "lastError"))
+}
+
 func TestSyncLogger_concurrency(t *testing.T) {
 	t.Parallel()
 
@@ -348,6 +817,34 @@ func BenchmarkSyncLogger_json_withDiscardWriter_parallel(b *testing.B) {
 	})
 }
 
+func BenchmarkSyncLogger_json_withDiscardWriter_withPooling_sequential(b *testing.B) {
+	subject := makeSyncLogger(io.Discard, xlog.SyncLoggerWithPooling())
+	defer subject.Close()
+	kv := getBenchmarkKeyVals()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		subject.Error(kv...)
+	}
+}
+
+func BenchmarkSyncLogger_json_withDiscardWriter_withPooling_parallel(b *testing.B) {
+	subject := makeSyncLogger(io.Discard, xlog.SyncLoggerWithPooling())
+	defer subject.Close()
+	kv := getBenchmarkKeyVals()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			subject.Error(kv...)
+		}
+	})
+}
+
 func BenchmarkSyncLogger_json_withFileWriter(b *testing.B) {
 	f := setUpFile(b.Name())
 	defer tearDownFile(f)
@@ -379,12 +876,12 @@ func BenchmarkSyncLogger_json_withBufferedFileWriter(b *testing.B) {
 }
 
 // makeSyncLogger creates a new SyncLogger object.
-func makeSyncLogger(w io.Writer) *xlog.SyncLogger {
+func makeSyncLogger(w io.Writer, opts ...xlog.SyncLoggerOption) *xlog.SyncLogger {
 	commonOpts := xlog.NewCommonOpts()
 	commonOpts.Source = xlog.SourceProvider(4, 1)
 
 	return xlog.NewSyncLogger(
 		w,
-		xlog.SyncLoggerWithOptions(commonOpts),
+		append([]xlog.SyncLoggerOption{xlog.SyncLoggerWithOptions(commonOpts)}, opts...)...,
 	)
 }
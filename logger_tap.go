@@ -0,0 +1,140 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultTapChanSize is the capacity of the channel a [TapLogger] sends entries on.
+const defaultTapChanSize = 256
+
+// Entry is a log tapped by a [TapLogger].
+type Entry struct {
+	// Level is the level the log was made with.
+	Level Level
+	// KeyValues are the key-values the log was made with.
+	KeyValues []any
+	// Time is the time the log was made at.
+	Time time.Time
+}
+
+// TapLogger is a Logger which forwards logs to another Logger,
+// and also sends them, as raw key-values, on a channel.
+// It is useful to subscribe to logs programmatically, for example
+// for an admin "live tail" feature.
+// Sending on the channel does not block: if the subscriber is slow
+// and the channel is full, the entry is dropped.
+// All the logs are sent, regardless of the decorated logger's levels.
+// It is concurrent safe.
+type TapLogger struct {
+	// logger to forward logs to.
+	inner Logger
+	// channel entries are sent on.
+	entriesChan chan Entry
+	// unsubscribed flag, true means entries are no longer sent.
+	unsubscribed bool
+	// concurrency semaphore to protect entriesChan closing.
+	mu sync.RWMutex
+}
+
+// NewTapLogger instantiates a new logger which forwards logs to given Logger,
+// and also sends them on the returned channel.
+// The channel gets closed on [TapLogger.Unsubscribe] / [TapLogger.Close].
+func NewTapLogger(inner Logger) (*TapLogger, <-chan Entry) {
+	logger := &TapLogger{
+		inner:       inner,
+		entriesChan: make(chan Entry, defaultTapChanSize),
+	}
+
+	return logger, logger.entriesChan
+}
+
+// Critical logs application component unavailable, fatal events.
+func (logger *TapLogger) Critical(keyValues ...any) {
+	logger.inner.Critical(keyValues...)
+	logger.tap(LevelCritical, keyValues)
+}
+
+// Error logs runtime errors that
+// should typically be logged and monitored.
+func (logger *TapLogger) Error(keyValues ...any) {
+	logger.inner.Error(keyValues...)
+	logger.tap(LevelError, keyValues)
+}
+
+// Warn logs exceptional occurrences that are not errors.
+// Example: Use of deprecated APIs, poor use of an API, undesirable things
+// that are not necessarily wrong.
+func (logger *TapLogger) Warn(keyValues ...any) {
+	logger.inner.Warn(keyValues...)
+	logger.tap(LevelWarning, keyValues)
+}
+
+// Info logs interesting events.
+// Example: User logs in, SQL logs.
+func (logger *TapLogger) Info(keyValues ...any) {
+	logger.inner.Info(keyValues...)
+	logger.tap(LevelInfo, keyValues)
+}
+
+// Debug logs detailed debug information.
+func (logger *TapLogger) Debug(keyValues ...any) {
+	logger.inner.Debug(keyValues...)
+	logger.tap(LevelDebug, keyValues)
+}
+
+// Log logs arbitrary data.
+func (logger *TapLogger) Log(keyValues ...any) {
+	logger.inner.Log(keyValues...)
+	logger.tap(LevelNone, keyValues)
+}
+
+// Enabled returns true if the decorated logger would log
+// a log with given level. See also [IsLevelEnabled].
+func (logger *TapLogger) Enabled(lvl Level) bool {
+	return IsLevelEnabled(logger.inner, lvl)
+}
+
+// Unsubscribe stops sending entries and closes the channel.
+// Logs are still forwarded to the decorated logger.
+// It is safe to call it multiple times.
+func (logger *TapLogger) Unsubscribe() {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if !logger.unsubscribed {
+		logger.unsubscribed = true
+		close(logger.entriesChan)
+	}
+}
+
+// Close unsubscribes (see [TapLogger.Unsubscribe]) and closes the decorated logger.
+func (logger *TapLogger) Close() error {
+	logger.Unsubscribe()
+
+	return logger.inner.Close()
+}
+
+// tap sends the log on the channel, if there is room for it.
+func (logger *TapLogger) tap(lvl Level, keyValues []any) {
+	logger.mu.RLock()
+	defer logger.mu.RUnlock()
+
+	if logger.unsubscribed {
+		return
+	}
+
+	keyVals := make([]any, len(keyValues))
+	copy(keyVals, keyValues)
+	keyVals = AppendNoValue(keyVals)
+
+	select {
+	case logger.entriesChan <- Entry{Level: lvl, KeyValues: keyVals, Time: now()}:
+	default: // subscriber is slow, drop the entry.
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestTapLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("entries are forwarded and tapped", testTapLoggerForwardsAndTaps)
+	t.Run("entries are dropped for slow subscriber", testTapLoggerDropsForSlowSubscriber)
+	t.Run("unsubscribe stops tapping", testTapLoggerUnsubscribe)
+	t.Run("close closes inner logger", testTapLoggerClose)
+	t.Run("enabled", testTapLoggerEnabled)
+}
+
+func testTapLoggerForwardsAndTaps(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner       = xlog.NewCaptureLogger()
+		subject, ch = xlog.NewTapLogger(inner)
+		levels      = []xlog.Level{
+			xlog.LevelCritical,
+			xlog.LevelError,
+			xlog.LevelWarning,
+			xlog.LevelInfo,
+			xlog.LevelDebug,
+			xlog.LevelNone,
+		}
+	)
+	start := time.Now()
+
+	// act
+	for _, lvl := range levels {
+		callMethodByLevel(subject, lvl)
+	}
+	subject.Info("odd")
+
+	// assert
+	end := time.Now()
+	assertEqual(t, len(levels)+1, len(inner.Records()))
+	for _, lvl := range levels {
+		entry := <-ch
+		assertEqual(t, lvl, entry.Level)
+		assertEqual(t, getInputKeyValues(), entry.KeyValues)
+		assertTrue(t, !entry.Time.Before(start) && !entry.Time.After(end))
+	}
+	entry := <-ch
+	assertEqual(t, xlog.LevelInfo, entry.Level)
+	assertEqual(t, []any{"odd", "*NoValue*"}, entry.KeyValues)
+}
+
+func testTapLoggerDropsForSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner       = xlog.NewCaptureLogger()
+		subject, ch = xlog.NewTapLogger(inner)
+		logsNo      = 1000
+		wg          sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < logsNo/4; j++ {
+				subject.Error("msg", "foo bar")
+			}
+		}()
+	}
+	wg.Wait() // no one reads the channel, logging did not block.
+
+	// assert
+	assertEqual(t, logsNo, len(inner.Records()))
+	assertTrue(t, len(ch) > 0)
+	assertTrue(t, len(ch) < logsNo)
+	assertEqual(t, cap(ch), len(ch))
+}
+
+func testTapLoggerUnsubscribe(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner       = xlog.NewMockLogger()
+		subject, ch = xlog.NewTapLogger(inner)
+	)
+	subject.Info("msg", "before unsubscribe")
+
+	// act
+	subject.Unsubscribe()
+	subject.Unsubscribe() // should not panic.
+	subject.Info("msg", "after unsubscribe")
+
+	// assert
+	entry, ok := <-ch
+	assertTrue(t, ok)
+	assertEqual(t, []any{"msg", "before unsubscribe"}, entry.KeyValues)
+	_, ok = <-ch
+	assertFalse(t, ok)
+	assertEqual(t, 2, inner.LogCallsCount(xlog.LevelInfo))
+	assertEqual(t, 0, inner.CloseCallsCount())
+}
+
+func testTapLoggerClose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		inner       = xlog.NewMockLogger()
+		subject, ch = xlog.NewTapLogger(inner)
+	)
+	inner.SetCloseError(ErrWrite)
+
+	// act
+	err := subject.Close()
+
+	// assert
+	assertEqual(t, ErrWrite, err)
+	assertEqual(t, 1, inner.CloseCallsCount())
+	_, ok := <-ch
+	assertFalse(t, ok)
+}
+
+func testTapLoggerEnabled(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		commOpts = xlog.NewCommonOpts()
+		inner    = xlog.NewSyncLogger(
+			new(MockWriter),
+			xlog.SyncLoggerWithOptions(commOpts),
+		)
+		subject, _ = xlog.NewTapLogger(inner)
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelWarning)
+
+	// act & assert
+	assertFalse(t, subject.Enabled(xlog.LevelInfo))
+	assertTrue(t, subject.Enabled(xlog.LevelError))
+}
@@ -0,0 +1,164 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+//go:build xlog_http
+
+package xlog
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Keys under which [NewHTTPMiddleware] logs a request's details.
+const (
+	HTTPMethodKey    = "method"
+	HTTPPathKey      = "path"
+	HTTPStatusKey    = "status"
+	HTTPBytesKey     = "bytes"
+	HTTPLatencyMsKey = "latency_ms"
+	// HTTPHeaderKeyPrefix is the prefix of the keys under which request's
+	// headers are logged (example: "header_user-agent").
+	HTTPHeaderKeyPrefix = "header_"
+)
+
+// httpMiddlewareMsg is the message a request is logged with.
+const httpMiddlewareMsg = "http request"
+
+// MiddlewareOptions is a struct holding configurations for [NewHTTPMiddleware].
+type MiddlewareOptions struct {
+	// LevelByStatus is a function that returns the level a request
+	// is logged with, based on its response status code.
+	// By default (if nil), 5xx statuses are logged with error level,
+	// 4xx statuses with warning level, and the rest with info level.
+	LevelByStatus func(status int) Level
+
+	// Headers are the names of the request's headers to be logged
+	// (if present), under [HTTPHeaderKeyPrefix] + lower cased name keys.
+	// By default, no header is logged.
+	Headers []string
+}
+
+// NewHTTPMiddleware returns an HTTP middleware which logs, through given Logger,
+// each request's method, path, response status, response size (in bytes),
+// and latency (in milliseconds).
+// A request whose handler panics is logged, too (with 500 status, if nothing
+// was written yet), the panic being left to propagate.
+// Note: it is compiled only with the "xlog_http" build tag.
+//
+// Example of usage:
+//
+//	mux := http.NewServeMux()
+//	http.ListenAndServe(":8080", xlog.NewHTTPMiddleware(logger, xlog.MiddlewareOptions{})(mux))
+func NewHTTPMiddleware(logger Logger, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	levelByStatus := opts.LevelByStatus
+	if levelByStatus == nil {
+		levelByStatus = defaultLevelByStatus
+	}
+	headerKeys := make([]string, len(opts.Headers))
+	for idx, header := range opts.Headers {
+		headerKeys[idx] = HTTPHeaderKeyPrefix + strings.ToLower(header)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w}
+			completed := false
+			defer func() { // log from here, so that a panicking handler's request is logged, too.
+				latency := float64(time.Since(start)) / float64(time.Millisecond)
+				status := rw.statusCode()
+				if !completed && rw.status == 0 {
+					status = http.StatusInternalServerError
+				}
+				keyValues := make([]any, 0, 12+2*len(headerKeys))
+				keyValues = append(
+					keyValues,
+					MessageKey, httpMiddlewareMsg,
+					HTTPMethodKey, r.Method,
+					HTTPPathKey, r.URL.Path,
+					HTTPStatusKey, status,
+					HTTPBytesKey, rw.bytes,
+					HTTPLatencyMsKey, latency,
+				)
+				for idx, header := range opts.Headers {
+					if value := r.Header.Get(header); value != "" {
+						keyValues = append(keyValues, headerKeys[idx], value)
+					}
+				}
+				logByLevel(logger, levelByStatus(status), keyValues...)
+			}()
+
+			next.ServeHTTP(rw, r)
+			completed = true
+		})
+	}
+}
+
+// defaultLevelByStatus returns error level for 5xx statuses, warning level
+// for 4xx statuses, and info level for the rest.
+func defaultLevelByStatus(status int) Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return LevelError
+	case status >= http.StatusBadRequest:
+		return LevelWarning
+	default:
+		return LevelInfo
+	}
+}
+
+// responseWriter is a [http.ResponseWriter] which captures
+// the response status code and size.
+type responseWriter struct {
+	http.ResponseWriter
+	// status code set with WriteHeader; 0 means not set.
+	status int
+	// no. of bytes written.
+	bytes int
+}
+
+// WriteHeader captures the status code and sends the response header.
+func (rw *responseWriter) WriteHeader(statusCode int) {
+	if rw.status == 0 {
+		rw.status = statusCode
+	}
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write captures the no. of bytes written and writes them.
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytes += n
+
+	return n, err
+}
+
+// Flush sends any buffered data to the client, if the decorated
+// response writer supports it.
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the decorated response writer (used by [http.ResponseController]).
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// statusCode returns the response status code; if the handler did not
+// write anything, it is 200, as net/http sends it.
+func (rw *responseWriter) statusCode() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+
+	return rw.status
+}
@@ -0,0 +1,204 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+//go:build xlog_http
+
+package xlog_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestNewHTTPMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("request is logged", testHTTPMiddlewareLogsRequest)
+	t.Run("level by status", testHTTPMiddlewareLevelByStatus)
+	t.Run("custom level by status", testHTTPMiddlewareCustomLevelByStatus)
+	t.Run("headers are logged", testHTTPMiddlewareLogsHeaders)
+	t.Run("panicking handler's request is logged", testHTTPMiddlewareLogsPanickingRequest)
+}
+
+func testHTTPMiddlewareLogsRequest(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewCaptureLogger()
+		sleep   = 20 * time.Millisecond
+		subject = xlog.NewHTTPMiddleware(logger, xlog.MiddlewareOptions{})(
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				time.Sleep(sleep)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte("hello"))
+				_, _ = w.Write([]byte(" world"))
+			}),
+		)
+		req = httptest.NewRequest(http.MethodPost, "/users?id=1", nil)
+		rec = httptest.NewRecorder()
+	)
+
+	// act
+	subject.ServeHTTP(rec, req)
+
+	// assert
+	assertEqual(t, http.StatusCreated, rec.Code)
+	assertEqual(t, "hello world", rec.Body.String())
+	records := logger.Records()
+	if assertEqual(t, 1, len(records)) {
+		assertEqual(t, xlog.LevelInfo, records[0].Level)
+		keyValues := records[0].KeyValues
+		if assertEqual(t, 12, len(keyValues)) {
+			assertEqual(
+				t,
+				[]any{
+					xlog.MessageKey, "http request",
+					xlog.HTTPMethodKey, http.MethodPost,
+					xlog.HTTPPathKey, "/users",
+					xlog.HTTPStatusKey, http.StatusCreated,
+					xlog.HTTPBytesKey, 11,
+					xlog.HTTPLatencyMsKey,
+				},
+				keyValues[:11],
+			)
+			latency, _ := keyValues[11].(float64)
+			expected := float64(sleep) / float64(time.Millisecond)
+			assertTrue(t, latency >= expected && latency < expected+1000)
+		}
+	}
+}
+
+func testHTTPMiddlewareLevelByStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name           string
+		handler        http.HandlerFunc
+		expectedLvl    xlog.Level
+		expectedStatus int
+	}{
+		{
+			name:           "no explicit status is 200, info",
+			handler:        func(http.ResponseWriter, *http.Request) {},
+			expectedLvl:    xlog.LevelInfo,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "3xx is info",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/other", http.StatusFound)
+			},
+			expectedLvl:    xlog.LevelInfo,
+			expectedStatus: http.StatusFound,
+		},
+		{
+			name:           "4xx is warning",
+			handler:        http.NotFound,
+			expectedLvl:    xlog.LevelWarning,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "5xx is error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, "oops", http.StatusServiceUnavailable)
+			},
+			expectedLvl:    xlog.LevelError,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				logger  = xlog.NewCaptureLogger()
+				subject = xlog.NewHTTPMiddleware(logger, xlog.MiddlewareOptions{})(test.handler)
+			)
+
+			// act
+			subject.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			// assert
+			assertTrue(t, logger.HasEntry(test.expectedLvl, xlog.HTTPStatusKey, test.expectedStatus))
+		})
+	}
+}
+
+func testHTTPMiddlewareCustomLevelByStatus(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewCaptureLogger()
+		subject = xlog.NewHTTPMiddleware(logger, xlog.MiddlewareOptions{
+			LevelByStatus: func(int) xlog.Level { return xlog.LevelDebug },
+		})(http.NotFoundHandler())
+	)
+
+	// act
+	subject.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// assert
+	assertTrue(t, logger.HasEntry(xlog.LevelDebug, xlog.HTTPStatusKey, http.StatusNotFound))
+}
+
+func testHTTPMiddlewareLogsHeaders(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewCaptureLogger()
+		subject = xlog.NewHTTPMiddleware(logger, xlog.MiddlewareOptions{
+			Headers: []string{"User-Agent", "X-Request-Id"},
+		})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+	)
+	req.Header.Set("User-Agent", "test-agent")
+
+	// act
+	subject.ServeHTTP(httptest.NewRecorder(), req)
+
+	// assert
+	assertTrue(t, logger.HasEntry(xlog.LevelInfo, "header_user-agent", "test-agent"))
+	records := logger.Records()
+	if assertEqual(t, 1, len(records)) {
+		assertEqual(t, 14, len(records[0].KeyValues)) // missing X-Request-Id is not logged.
+	}
+}
+
+func testHTTPMiddlewareLogsPanickingRequest(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewCaptureLogger()
+		subject = xlog.NewHTTPMiddleware(logger, xlog.MiddlewareOptions{})(
+			http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				panic("intentionally triggered panic")
+			}),
+		)
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+	)
+
+	// act
+	func() {
+		defer func() {
+			// assert - panic is propagated.
+			assertEqual(t, "intentionally triggered panic", recover())
+		}()
+		subject.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	// assert
+	assertTrue(t, logger.HasEntry(xlog.LevelError, xlog.HTTPStatusKey, http.StatusInternalServerError))
+}
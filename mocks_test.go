@@ -8,7 +8,9 @@ package xlog_test
 import (
 	"errors"
 	"io"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/actforgood/xlog"
 )
@@ -91,6 +93,19 @@ func FormatCallbackErr(_ io.Writer, _ []any) error {
 	return ErrFormat
 }
 
+// FormatCallbackPanicOnKey is a predefined MockFormatter callback that panics
+// if the key-values contain "panic" key, and writes them in logfmt
+// format otherwise.
+func FormatCallbackPanicOnKey(w io.Writer, keyValues []any) error {
+	for idx := 0; idx < len(keyValues); idx += 2 {
+		if keyValues[idx] == "panic" {
+			panic("intentionally triggered Formatter panic")
+		}
+	}
+
+	return xlog.LogfmtFormatter(w, keyValues)
+}
+
 // MockErrorHandler is a mocked wrapper for an xlog.ErrorHandler.
 type MockErrorHandler struct {
 	handleCallsCnt uint32
@@ -115,3 +130,79 @@ func (mock *MockErrorHandler) SetHandleCallback(callback xlog.ErrorHandler) {
 func (mock *MockErrorHandler) HandleCallsCount() int {
 	return int(atomic.LoadUint32(&mock.handleCallsCnt))
 }
+
+// FakeClock is a manually driven xlog.Clock.
+// Its time passes only through Advance.
+type FakeClock struct {
+	now     time.Time
+	tickers []*fakeTicker
+	mu      sync.Mutex
+}
+
+// NewFakeClock instantiates a new FakeClock, starting at given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the fake current time (not part of xlog.Clock, useful for assertions).
+func (clock *FakeClock) Now() time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	return clock.now
+}
+
+// NewTicker returns a new Ticker, ticking as the fake time passes.
+func (clock *FakeClock) NewTicker(d time.Duration) xlog.Ticker {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	ticker := &fakeTicker{
+		ch:     make(chan time.Time, 1),
+		period: d,
+		next:   clock.now.Add(d),
+	}
+	clock.tickers = append(clock.tickers, ticker)
+
+	return ticker
+}
+
+// Advance moves the fake time forward with given duration,
+// delivering the ticks of the tickers whose period elapsed.
+// As with a [time.Ticker], ticks are dropped for slow receivers.
+func (clock *FakeClock) Advance(d time.Duration) {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	clock.now = clock.now.Add(d)
+	for _, ticker := range clock.tickers {
+		if ticker.stopped.Load() {
+			continue
+		}
+		for !ticker.next.After(clock.now) {
+			select {
+			case ticker.ch <- ticker.next:
+			default:
+			}
+			ticker.next = ticker.next.Add(ticker.period)
+		}
+	}
+}
+
+// fakeTicker is the xlog.Ticker returned by FakeClock.
+type fakeTicker struct {
+	ch      chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped atomic.Bool
+}
+
+// C returns the channel on which the ticks are delivered.
+func (ticker *fakeTicker) C() <-chan time.Time {
+	return ticker.ch
+}
+
+// Stop turns off the ticker.
+func (ticker *fakeTicker) Stop() {
+	ticker.stopped.Store(true)
+}
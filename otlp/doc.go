@@ -0,0 +1,13 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+// Package otlp provides an xlog formatter which exports logs through
+// an OpenTelemetry log exporter (OTLP).
+// It lives in its own module in order not to add OpenTelemetry
+// dependencies to the main xlog module, and it is compiled only with
+// the "otlp" build tag:
+//
+//	go build -tags=otlp
+package otlp
@@ -0,0 +1,195 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+//go:build otlp
+
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/actforgood/xlog"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// severities maps xlog levels to OTLP severities.
+var severities = map[xlog.Level]otellog.Severity{
+	xlog.LevelNone:     otellog.SeverityUndefined,
+	xlog.LevelDebug:    otellog.SeverityDebug,
+	xlog.LevelInfo:     otellog.SeverityInfo,
+	xlog.LevelWarning:  otellog.SeverityWarn,
+	xlog.LevelError:    otellog.SeverityError,
+	xlog.LevelCritical: otellog.SeverityFatal,
+}
+
+// OTLPFormatter is a formatter which maps each log into an OTLP log record
+// and sends it to given exporter.
+// The body is taken from [xlog.MessageKey], the severity from the level,
+// the timestamp from the time key, and all the other key-values become
+// record's attributes.
+// The writer from the Logger should be io.Discard, as nothing is written to it.
+// Note: the exporter is called synchronously, for each log, you may want
+// to use it with an [xlog.AsyncLogger].
+func OTLPFormatter(exporter sdklog.Exporter, opts *xlog.CommonOpts) xlog.Formatter {
+	var (
+		provider = sdklog.NewLoggerProvider(
+			sdklog.WithProcessor(&exportProcessor{exporter: exporter}),
+		)
+		otelLogger    = provider.Logger(instrumentationScope)
+		labeledLevels = make(map[string]xlog.Level, len(opts.LevelLabels))
+	)
+	for lvl, label := range opts.LevelLabels {
+		labeledLevels[label] = lvl
+		if opts.LevelFormatter != nil { // numeric levels, for example.
+			labeledLevels[fmt.Sprint(opts.LevelFormatter(lvl))] = lvl
+		}
+	}
+
+	return func(_ io.Writer, keyValues []any) error {
+		keyValues = xlog.AppendNoValue(keyValues)
+
+		var (
+			record otellog.Record
+			attrs  = make([]otellog.KeyValue, 0, len(keyValues)/2)
+		)
+		record.SetObservedTimestamp(time.Now())
+		for idx := 0; idx < len(keyValues); idx += 2 {
+			key := fmt.Sprint(keyValues[idx])
+			value := keyValues[idx+1]
+			switch key {
+			case xlog.MessageKey:
+				record.SetBody(otelValue(value))
+			case opts.LevelKey:
+				lvl := labeledLevels[fmt.Sprint(value)]
+				record.SetSeverity(severities[lvl])
+				if label, found := opts.LevelLabels[lvl]; found {
+					record.SetSeverityText(label)
+				} else {
+					record.SetSeverityText(fmt.Sprint(value))
+				}
+			case opts.TimeKey:
+				if t, ok := parseTime(value, opts.TimeLayout); ok {
+					record.SetTimestamp(t)
+				}
+			default:
+				attrs = append(attrs, otellog.KeyValue{Key: key, Value: otelValue(value)})
+			}
+		}
+		record.AddAttributes(attrs...)
+
+		var exportErr error
+		otelLogger.Emit(context.WithValue(context.Background(), exportErrKey{}, &exportErr), record)
+
+		return exportErr
+	}
+}
+
+// instrumentationScope is the name of the scope records are emitted with.
+const instrumentationScope = "github.com/actforgood/xlog"
+
+// exportErrKey is the context key under which the export error is stored.
+type exportErrKey struct{}
+
+// exportProcessor is a log processor which synchronously exports
+// each record with the wrapped exporter.
+// Unlike the SDK's simple processor, it reports the export error back
+// to the formatter, so that it reaches logger's ErrHandler.
+type exportProcessor struct {
+	exporter sdklog.Exporter
+	mu       sync.Mutex // exporters are not required to be concurrent safe.
+}
+
+// OnEmit exports the record.
+func (p *exportProcessor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	p.mu.Lock()
+	err := p.exporter.Export(ctx, []sdklog.Record{*record})
+	p.mu.Unlock()
+
+	if errPtr, ok := ctx.Value(exportErrKey{}).(*error); ok {
+		*errPtr = err
+
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown does nothing, the exporter's lifecycle is handled by its owner.
+func (p *exportProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush flushes the exporter.
+func (p *exportProcessor) ForceFlush(ctx context.Context) error {
+	return p.exporter.ForceFlush(ctx)
+}
+
+// parseTime converts log's time value into a [time.Time].
+// Time is usually a string (see [xlog.UTCTimeProvider]), case when
+// it is parsed with given layout, RFC3339Nano being tried as a fallback.
+func parseTime(value any, layout string) (time.Time, bool) {
+	switch val := value.(type) {
+	case time.Time:
+		return val, true
+	case string:
+		for _, l := range []string{layout, time.RFC3339Nano} {
+			if l == "" {
+				continue
+			}
+			if t, err := time.Parse(l, val); err == nil {
+				return t, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// otelValue converts a log value into an OTLP value.
+func otelValue(value any) otellog.Value {
+	switch val := value.(type) {
+	case nil:
+		return otellog.Value{}
+	case string:
+		return otellog.StringValue(val)
+	case bool:
+		return otellog.BoolValue(val)
+	case int:
+		return otellog.IntValue(val)
+	case int8:
+		return otellog.Int64Value(int64(val))
+	case int16:
+		return otellog.Int64Value(int64(val))
+	case int32:
+		return otellog.Int64Value(int64(val))
+	case int64:
+		return otellog.Int64Value(val)
+	case uint8:
+		return otellog.Int64Value(int64(val))
+	case uint16:
+		return otellog.Int64Value(int64(val))
+	case uint32:
+		return otellog.Int64Value(int64(val))
+	case float32:
+		return otellog.Float64Value(float64(val))
+	case float64:
+		return otellog.Float64Value(val)
+	case []byte:
+		return otellog.BytesValue(val)
+	case time.Time:
+		return otellog.StringValue(val.Format(time.RFC3339Nano))
+	case error:
+		return otellog.StringValue(val.Error())
+	case fmt.Stringer:
+		return otellog.StringValue(val.String())
+	default:
+		return otellog.StringValue(fmt.Sprintf("%+v", val))
+	}
+}
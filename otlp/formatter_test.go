@@ -0,0 +1,188 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+//go:build otlp
+
+package otlp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+	"github.com/actforgood/xlog/otlp"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// fakeExporter is an exporter which stores exported records in memory.
+type fakeExporter struct {
+	records []sdklog.Record
+	err     error
+	mu      sync.Mutex
+}
+
+func (exp *fakeExporter) Export(_ context.Context, records []sdklog.Record) error {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	for _, record := range records {
+		exp.records = append(exp.records, record.Clone())
+	}
+
+	return exp.err
+}
+
+func (exp *fakeExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (exp *fakeExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (exp *fakeExporter) Records() []sdklog.Record {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	return exp.records
+}
+
+func TestOTLPFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("record is mapped from key-values", testOTLPFormatterMapsRecord)
+	t.Run("severity is mapped from level", testOTLPFormatterMapsSeverity)
+	t.Run("severity is mapped from numeric level", testOTLPFormatterMapsSeverityFromNumericLevel)
+	t.Run("exporter error is returned", testOTLPFormatterReturnsExporterErr)
+}
+
+func testOTLPFormatterMapsRecord(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		exporter = new(fakeExporter)
+		commOpts = xlog.NewCommonOpts()
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelDebug)
+	commOpts.Time = func() any { return "2021-11-30T16:01:20Z" }
+	commOpts.SourceKey = ""
+	logger := xlog.NewSyncLogger(
+		io.Discard,
+		xlog.SyncLoggerWithOptions(commOpts),
+		xlog.SyncLoggerWithFormatter(otlp.OTLPFormatter(exporter, commOpts)),
+	)
+
+	// act
+	logger.Info(xlog.MessageKey, "Hello World", "year", 2022, "ok", true, "ratio", 0.5, "odd")
+
+	// assert
+	records := exporter.Records()
+	if assertEqual(t, 1, len(records)) {
+		record := records[0]
+		assertEqual(t, "Hello World", record.Body().AsString())
+		assertEqual(t, otellog.SeverityInfo, record.Severity())
+		assertEqual(t, "INFO", record.SeverityText())
+		assertEqual(t, time.Date(2021, 11, 30, 16, 1, 20, 0, time.UTC), record.Timestamp())
+		attrs := make(map[string]otellog.Value, record.AttributesLen())
+		record.WalkAttributes(func(kv otellog.KeyValue) bool {
+			attrs[kv.Key] = kv.Value
+
+			return true
+		})
+		assertEqual(t, 4, len(attrs))
+		assertEqual(t, int64(2022), attrs["year"].AsInt64())
+		assertEqual(t, true, attrs["ok"].AsBool())
+		assertEqual(t, 0.5, attrs["ratio"].AsFloat64())
+		assertEqual(t, "*NoValue*", attrs["odd"].AsString())
+	}
+}
+
+func testOTLPFormatterMapsSeverity(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		exporter = new(fakeExporter)
+		commOpts = xlog.NewCommonOpts()
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelDebug)
+	logger := xlog.NewSyncLogger(
+		io.Discard,
+		xlog.SyncLoggerWithOptions(commOpts),
+		xlog.SyncLoggerWithFormatter(otlp.OTLPFormatter(exporter, commOpts)),
+	)
+	expectedSeverities := []otellog.Severity{
+		otellog.SeverityDebug,
+		otellog.SeverityInfo,
+		otellog.SeverityWarn,
+		otellog.SeverityError,
+		otellog.SeverityFatal,
+	}
+
+	// act
+	logger.Debug(xlog.MessageKey, "debug")
+	logger.Info(xlog.MessageKey, "info")
+	logger.Warn(xlog.MessageKey, "warn")
+	logger.Error(xlog.MessageKey, "error")
+	logger.Critical(xlog.MessageKey, "critical")
+
+	// assert
+	records := exporter.Records()
+	if assertEqual(t, len(expectedSeverities), len(records)) {
+		for idx, record := range records {
+			assertEqual(t, expectedSeverities[idx], record.Severity())
+		}
+	}
+}
+
+func testOTLPFormatterMapsSeverityFromNumericLevel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		exporter = new(fakeExporter)
+		commOpts = xlog.NewCommonOpts()
+	)
+	commOpts.LevelFormatter = xlog.NumericLevelFormatter
+	logger := xlog.NewSyncLogger(
+		io.Discard,
+		xlog.SyncLoggerWithOptions(commOpts),
+		xlog.SyncLoggerWithFormatter(otlp.OTLPFormatter(exporter, commOpts)),
+	)
+
+	// act
+	logger.Error(xlog.MessageKey, "error")
+
+	// assert
+	records := exporter.Records()
+	if assertEqual(t, 1, len(records)) {
+		assertEqual(t, otellog.SeverityError, records[0].Severity())
+		assertEqual(t, "ERROR", records[0].SeverityText())
+	}
+}
+
+func testOTLPFormatterReturnsExporterErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered export error")
+		exporter    = &fakeExporter{err: expectedErr}
+		commOpts    = xlog.NewCommonOpts()
+		subject     = otlp.OTLPFormatter(exporter, commOpts)
+	)
+
+	// act
+	err := subject(io.Discard, []any{xlog.MessageKey, "foo"})
+
+	// assert
+	assertEqual(t, expectedErr, err)
+	assertEqual(t, 1, len(exporter.Records()))
+}
@@ -0,0 +1,57 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+// PanicKey represents the key under which a recovered panic's value resides,
+// see [RecoverAndLog].
+const PanicKey = "panic"
+
+// RecoverAndLog recovers from a panic, if any, and logs it, with critical level,
+// with the recovered value (under [PanicKey]), the stack trace of the panic
+// (under [StackKey]) and given key-values.
+// It is meant to be deferred (it has to be called directly by the deferred call,
+// for the panic to be recovered).
+// See [RecoverLogAndRepanic] if the panic should not be swallowed.
+//
+// Example of usage:
+//
+//	go func() {
+//		defer xlog.RecoverAndLog(logger, "job_id", job.ID)
+//		job.Run()
+//	}()
+func RecoverAndLog(logger Logger, keyValues ...any) {
+	if r := recover(); r != nil {
+		logPanic(logger, r, keyValues)
+	}
+}
+
+// RecoverLogAndRepanic is like [RecoverAndLog], but panics again,
+// with the recovered value, after logging it.
+// This way the panic is logged (in a structured way), but it is still
+// handled up the stack (it crashes the program, for example).
+//
+// Example of usage:
+//
+//	defer xlog.RecoverLogAndRepanic(logger)
+func RecoverLogAndRepanic(logger Logger, keyValues ...any) {
+	if r := recover(); r != nil {
+		logPanic(logger, r, keyValues)
+		panic(r)
+	}
+}
+
+// logPanic logs given recovered value with critical level.
+func logPanic(logger Logger, recovered any, keyValues []any) {
+	keyVals := make([]any, 0, 6+len(keyValues))
+	keyVals = append(
+		keyVals,
+		MessageKey, "recovered from panic",
+		PanicKey, recovered,
+		StackKey, stackTrace(0),
+	)
+	keyVals = append(keyVals, keyValues...)
+	logger.Critical(keyVals...)
+}
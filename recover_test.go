@@ -0,0 +1,101 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestRecoverAndLog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("panic is logged and swallowed", testRecoverAndLogPanic)
+	t.Run("no panic, nothing is logged", testRecoverAndLogNoPanic)
+	t.Run("panic is logged and raised again", testRecoverLogAndRepanic)
+}
+
+// panickingJob is a function which panics, with RecoverAndLog deferred.
+func panickingJob(logger xlog.Logger) {
+	defer xlog.RecoverAndLog(logger, "job_id", 123)
+
+	panic("something went wrong")
+}
+
+func testRecoverAndLogPanic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	logger := xlog.NewCaptureLogger()
+
+	// act
+	panickingJob(logger)
+
+	// assert
+	records := logger.Records()
+	if assertEqual(t, 1, len(records)) {
+		assertEqual(t, xlog.LevelCritical, records[0].Level)
+		keyValues := records[0].KeyValues
+		if assertEqual(t, 8, len(keyValues)) {
+			assertEqual(
+				t,
+				[]any{
+					xlog.MessageKey, "recovered from panic",
+					xlog.PanicKey, "something went wrong",
+					xlog.StackKey,
+				},
+				keyValues[:5],
+			)
+			stack, _ := keyValues[5].(string)
+			assertTrue(t, strings.Contains(stack, "xlog_test.panickingJob"))
+			assertFalse(t, strings.Contains(stack, "xlog.RecoverAndLog"))
+			assertEqual(t, []any{"job_id", 123}, keyValues[6:])
+		}
+	}
+}
+
+func testRecoverAndLogNoPanic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	logger := xlog.NewCaptureLogger()
+
+	// act
+	func() {
+		defer xlog.RecoverAndLog(logger)
+		defer xlog.RecoverLogAndRepanic(logger)
+	}()
+
+	// assert
+	assertEqual(t, 0, len(logger.Records()))
+}
+
+func testRecoverLogAndRepanic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger    = xlog.NewCaptureLogger()
+		recovered any
+	)
+
+	// act
+	func() {
+		defer func() {
+			recovered = recover()
+		}()
+		defer xlog.RecoverLogAndRepanic(logger)
+
+		panic("something went wrong")
+	}()
+
+	// assert
+	assertEqual(t, "something went wrong", recovered)
+	assertTrue(t, logger.HasEntry(xlog.LevelCritical, xlog.PanicKey, "something went wrong"))
+	assertEqual(t, 1, len(logger.Records()))
+}
@@ -0,0 +1,67 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+// ring is a fixed size ring buffer. When full, the oldest item
+// is dropped in favor of the new one.
+// It is not concurrent safe, its owner should protect access to it.
+type ring[T any] struct {
+	// items buffer.
+	items []T
+	// index where the next item will be stored.
+	next int
+	// no. of items currently stored.
+	count int
+	// no. of items dropped, as the buffer was full.
+	dropped uint64
+}
+
+// newRing instantiates a new ring buffer with given capacity.
+// A capacity lower than 1 is treated as 1.
+func newRing[T any](capacity int) *ring[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &ring[T]{items: make([]T, capacity)}
+}
+
+// push stores given item, overwriting the oldest one, if full.
+func (r *ring[T]) push(item T) {
+	r.items[r.next] = item
+	r.next = (r.next + 1) % len(r.items)
+	if r.count < len(r.items) {
+		r.count++
+	} else {
+		r.dropped++
+	}
+}
+
+// len returns the no. of items currently stored.
+func (r *ring[T]) len() int {
+	return r.count
+}
+
+// all returns the stored items, from the oldest to the newest one.
+func (r *ring[T]) all() []T {
+	items := make([]T, 0, r.count)
+	start := (r.next - r.count + len(r.items)) % len(r.items)
+	for i := 0; i < r.count; i++ {
+		items = append(items, r.items[(start+i)%len(r.items)])
+	}
+
+	return items
+}
+
+// drain returns the stored items, from the oldest to the newest one,
+// and the no. of dropped ones, emptying the buffer.
+func (r *ring[T]) drain() ([]T, uint64) {
+	items, dropped := r.all(), r.dropped
+	clear(r.items) // do not keep references to stored values.
+	r.next, r.count, r.dropped = 0, 0, 0
+
+	return items, dropped
+}
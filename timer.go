@@ -0,0 +1,41 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import "time"
+
+// DurationMsKey represents the key under which a [Timer]'s elapsed time,
+// in milliseconds, resides.
+const DurationMsKey = "duration_ms"
+
+// Timer starts timing an operation, returning a function which, when called,
+// logs, with info level, given message, the elapsed time since the timer was started
+// (in milliseconds, under [DurationMsKey]) and given key-values.
+// This way timing logs are standardized.
+// Note: the returned function adds 2 frames to the call stack, so you may
+// want to set [CommonOpts.SourceSkipExtra] to 2, for the source to point to its caller.
+//
+// Example of usage:
+//
+//	stop := xlog.Timer(logger)
+//	rows, err := db.Query(query)
+//	stop("query executed", "rows", len(rows))
+func Timer(logger Logger) func(msg string, keyValues ...any) {
+	return TimerAt(logger, LevelInfo)
+}
+
+// TimerAt is like [Timer], but logs with given level.
+func TimerAt(logger Logger, lvl Level) func(msg string, keyValues ...any) {
+	start := time.Now()
+
+	return func(msg string, keyValues ...any) {
+		elapsed := float64(time.Since(start)) / float64(time.Millisecond)
+		keyVals := make([]any, 0, 4+len(keyValues))
+		keyVals = append(keyVals, MessageKey, msg, DurationMsKey, elapsed)
+		keyVals = append(keyVals, keyValues...)
+		logByLevel(logger, lvl, keyVals...)
+	}
+}
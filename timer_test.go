@@ -0,0 +1,104 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestTimer(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger   = xlog.NewCaptureLogger()
+		sleep    = 30 * time.Millisecond
+		subject  = xlog.Timer(logger)
+		expected = float64(sleep) / float64(time.Millisecond)
+	)
+	time.Sleep(sleep)
+
+	// act
+	subject("operation done", "rows", 10)
+
+	// assert
+	records := logger.Records()
+	if assertEqual(t, 1, len(records)) {
+		assertEqual(t, xlog.LevelInfo, records[0].Level)
+		if assertEqual(t, 6, len(records[0].KeyValues)) {
+			assertEqual(t, xlog.MessageKey, records[0].KeyValues[0])
+			assertEqual(t, "operation done", records[0].KeyValues[1])
+			assertEqual(t, xlog.DurationMsKey, records[0].KeyValues[2])
+			durationMs, _ := records[0].KeyValues[3].(float64)
+			assertTrue(t, durationMs >= expected && durationMs < expected+1000)
+			assertEqual(t, "rows", records[0].KeyValues[4])
+			assertEqual(t, 10, records[0].KeyValues[5])
+		}
+	}
+}
+
+func TestTimerAt(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger = xlog.NewCaptureLogger()
+		levels = []xlog.Level{
+			xlog.LevelCritical,
+			xlog.LevelError,
+			xlog.LevelWarning,
+			xlog.LevelInfo,
+			xlog.LevelDebug,
+			xlog.LevelNone,
+		}
+	)
+
+	// act
+	for _, lvl := range levels {
+		xlog.TimerAt(logger, lvl)("done")
+	}
+
+	// assert
+	records := logger.Records()
+	if assertEqual(t, len(levels), len(records)) {
+		for idx, lvl := range levels {
+			assertEqual(t, lvl, records[idx].Level)
+			assertTrue(t, logger.HasEntry(lvl, xlog.MessageKey, "done"))
+		}
+	}
+}
+
+func TestTimer_sourceSkipExtra(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		commOpts = xlog.NewCommonOpts()
+		logger   = xlog.NewSyncLogger(
+			&buf,
+			xlog.SyncLoggerWithOptions(commOpts),
+			xlog.SyncLoggerWithFormatter(xlog.LogfmtFormatter),
+		)
+	)
+	commOpts.MinLevel = xlog.FixedLevelProvider(xlog.LevelInfo)
+	commOpts.SourceSkipExtra = 2
+	subject := xlog.Timer(logger)
+
+	// act
+	subject("done")
+	_, _, line, _ := runtime.Caller(0)
+
+	// assert
+	assertTrue(t, strings.Contains(buf.String(), "timer_test.go:"+strconv.Itoa(line-1)))
+}
@@ -7,11 +7,17 @@ package xlog
 
 import (
 	"bufio"
+	"errors"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrWriterStopped is returned by [BufferedWriter.Write] after the writer
+// was stopped, if [BufferedWriterWithErrAfterStop] option is enabled.
+var ErrWriterStopped = errors.New("xlog: buffered writer is stopped")
+
 // default buffer size (4 Kb).
 // It complies with the default chosen buffer size by Go from here:
 // https://github.com/golang/go/blob/go1.17.3/src/bufio/bufio.go#L19 .
@@ -22,7 +28,8 @@ const defaultFlushInterval = 10 * time.Second
 
 // BufferedWriter decorates an io.Writer so that written bytes are buffered.
 // It is concurrent safe to use.
-// It has the capability of auto-flushing the buffer, time interval based.
+// It has the capability of auto-flushing the buffer, time interval based,
+// and no. of writes based.
 type BufferedWriter struct {
 	// original writer data is written to.
 	origWriter io.Writer
@@ -35,13 +42,26 @@ type BufferedWriter struct {
 	flushInterval time.Duration
 	// ticker is used to trigger Flush so far collected bytes
 	// regardless if buffer is full or not.
-	ticker *time.Ticker
+	ticker Ticker
+	// clock the ticker is created with.
+	clock Clock
+	// the no. of Write calls after which buffer is flushed,
+	// regardless if buffer is full or not. 0 means disabled.
+	flushEveryN int
+	// no. of Write calls since last flush.
+	writesSinceFlush int
 	// the channel to sync with internal ticking goroutine when
 	// buffer is stopped.
 	stopFlushCh chan struct{}
 	// if flag is true means Stop() has been called, from this point forward,
 	// no further writes are accepted for and flush goroutine stops.
 	stopped bool
+	// if flag is true, Write returns ErrWriterStopped after Stop() has been called.
+	errAfterStop bool
+	// no. of Write calls dropped after Stop() has been called.
+	droppedCalls atomic.Uint64
+	// no. of bytes dropped after Stop() has been called.
+	droppedBytes atomic.Uint64
 	// concurrency semaphore to protect stopped flag access.
 	stopMu sync.RWMutex
 	// wait group to synchronize internal started goroutine(s) with Store method,
@@ -58,6 +78,7 @@ func NewBufferedWriter(w io.Writer, opts ...BufferedWriterOption) *BufferedWrite
 		origWriter:    w,
 		bufSize:       defaultBufSize,
 		flushInterval: defaultFlushInterval,
+		clock:         SystemClock{},
 		stopFlushCh:   make(chan struct{}, 1),
 	}
 
@@ -72,7 +93,7 @@ func NewBufferedWriter(w io.Writer, opts ...BufferedWriterOption) *BufferedWrite
 
 	// start auto-flushing goroutine, if enabled.
 	if bufferedWriter.flushInterval > 0 {
-		bufferedWriter.ticker = time.NewTicker(bufferedWriter.flushInterval)
+		bufferedWriter.ticker = bufferedWriter.clock.NewTicker(bufferedWriter.flushInterval)
 		bufferedWriter.wg.Add(1)
 		go bufferedWriter.flushAsync()
 	}
@@ -82,23 +103,70 @@ func NewBufferedWriter(w io.Writer, opts ...BufferedWriterOption) *BufferedWrite
 
 // Write writes given bytes to the decorated writer (buffered).
 // Returns no. of bytes written, or an error.
+// After Stop has been called, bytes are dropped and, by default, no error is returned.
+// See [BufferedWriterWithErrAfterStop] and [BufferedWriter.DroppedAfterStop].
 func (bw *BufferedWriter) Write(p []byte) (int, error) {
 	bw.mu.Lock()
 	defer bw.mu.Unlock()
 
 	if !bw.isStopped() {
-		n, err := bw.bufWriter.Write(p)
-		if err != nil {
-			// reset to clear the error, otherwise will be returned at any future write.
-			bw.bufWriter.Reset(bw.origWriter)
+		return bw.afterWrite(bw.bufWriter.Write(p))
+	}
+
+	return bw.drop(len(p))
+}
+
+// WriteString writes given string to the decorated writer (buffered),
+// sparing a []byte conversion for callers using [io.WriteString], like
+// custom formatters (built-in ones write []byte through [BufferedWriter.Write]).
+// It behaves like [BufferedWriter.Write].
+func (bw *BufferedWriter) WriteString(s string) (int, error) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if !bw.isStopped() {
+		return bw.afterWrite(bw.bufWriter.WriteString(s))
+	}
+
+	return bw.drop(len(s))
+}
+
+// afterWrite applies the no. of writes based flush, and resets the buffer
+// in case of error, after a write to the buffer with given result.
+// It should be called with mu locked.
+func (bw *BufferedWriter) afterWrite(n int, err error) (int, error) {
+	if err == nil && bw.flushEveryN > 0 {
+		bw.writesSinceFlush++
+		if bw.writesSinceFlush >= bw.flushEveryN {
+			bw.writesSinceFlush = 0
+			err = bw.bufWriter.Flush()
 		}
+	}
+	if err != nil {
+		// reset to clear the error, otherwise will be returned at any future write.
+		bw.bufWriter.Reset(bw.origWriter)
+	}
+
+	return n, err
+}
 
-		return n, err
+// drop accounts a write of given no. of bytes, made after Stop.
+func (bw *BufferedWriter) drop(n int) (int, error) {
+	bw.droppedCalls.Add(1)
+	bw.droppedBytes.Add(uint64(n))
+	if bw.errAfterStop {
+		return 0, ErrWriterStopped
 	}
 
 	return 0, nil
 }
 
+// DroppedAfterStop returns the no. of Write calls and bytes
+// which were dropped because they happened after Stop.
+func (bw *BufferedWriter) DroppedAfterStop() (calls, bytes uint64) {
+	return bw.droppedCalls.Load(), bw.droppedBytes.Load()
+}
+
 // flushAsync periodically flushes the buffer.
 func (bw *BufferedWriter) flushAsync() {
 	defer func() {
@@ -110,7 +178,7 @@ func (bw *BufferedWriter) flushAsync() {
 	// waiting for interval to elapse, or for a stop signal.
 	for {
 		select {
-		case <-bw.ticker.C:
+		case <-bw.ticker.C():
 			bw.flush()
 		case <-bw.stopFlushCh:
 			return
@@ -118,31 +186,93 @@ func (bw *BufferedWriter) flushAsync() {
 	}
 }
 
-// flush simply flushes the buffered writer,
-// writing all (if any) stored bytes.
-func (bw *BufferedWriter) flush() {
+// Flush writes any buffered data to the underlying writer.
+// It can be used to force a flush at certain moments (checkpoints),
+// without waiting for the buffer to get full or for the flush interval to elapse.
+// Returns the underlying writer's error, if any. In case of error, the buffer is
+// reset, so that the error is not returned again at any future write.
+func (bw *BufferedWriter) Flush() error {
 	bw.mu.Lock()
 	defer bw.mu.Unlock()
 
-	if err := bw.bufWriter.Flush(); err != nil {
+	bw.writesSinceFlush = 0
+	err := bw.bufWriter.Flush()
+	if err != nil {
 		// reset to clear the error, otherwise will be returned at any future write.
 		bw.bufWriter.Reset(bw.origWriter)
 	}
+
+	return err
+}
+
+// flush simply flushes the buffered writer,
+// writing all (if any) stored bytes.
+// Error, if any, is ignored.
+func (bw *BufferedWriter) flush() {
+	_ = bw.Flush()
 }
 
 // Stop marks the writer as stopped.
 // You should call it to make sure all data have been processed.
 // Once called any further Write will be ignored.
 func (bw *BufferedWriter) Stop() {
+	_ = bw.stop()
+}
+
+// stop marks the writer as stopped, flushing any buffered data.
+// Returns the flush error, if any.
+func (bw *BufferedWriter) stop() error {
 	bw.stopMu.Lock()
 	defer bw.stopMu.Unlock()
 
-	if !bw.stopped {
-		bw.stopped = true     // mark writer as stopped.
-		close(bw.stopFlushCh) // signal flush goroutine to stop by closing the chan.
-		bw.wg.Wait()          // wait for flush goroutine to finish.
-		bw.flush()            // trigger a flush to store any buffered data.
+	if bw.stopped {
+		return nil
+	}
+	bw.stopped = true     // mark writer as stopped.
+	close(bw.stopFlushCh) // signal flush goroutine to stop by closing the chan.
+	bw.wg.Wait()          // wait for flush goroutine to finish.
+
+	return bw.Flush() // trigger a flush to store any buffered data.
+}
+
+// flushOnLevel flushes given writer (see flushWriter)
+// if given log level is at or above the configured flush level.
+// A nil flush level means the feature is disabled.
+// Returns the flush error, if any.
+func flushOnLevel(w io.Writer, flushLvl *Level, lvl Level) error {
+	if flushLvl == nil || lvl < *flushLvl {
+		return nil
+	}
+
+	return flushWriter(w)
+}
+
+// flushWriter flushes given writer if it is a [*BufferedWriter]
+// or a [*MultiWriter].
+// Returns the flush error, if any.
+func flushWriter(w io.Writer) error {
+	switch fw := w.(type) {
+	case *BufferedWriter:
+		return fw.Flush()
+	case *MultiWriter:
+		return fw.flush()
+	}
+
+	return nil
+}
+
+// stopWriter stops given writer if it is a [*BufferedWriter]
+// or a [*MultiWriter].
+// Returns the stop error, if any.
+func stopWriter(w io.Writer) error {
+	switch sw := w.(type) {
+	case *BufferedWriter:
+		return sw.stop()
+	case *MultiWriter:
+		return sw.stop()
 	}
+
+	return nil
 }
 
 // isStopped returns true if Stop method was called, false otherwise.
@@ -173,3 +303,32 @@ func BufferedWriterWithFlushInterval(flushInterval time.Duration) BufferedWriter
 		bw.flushInterval = flushInterval
 	}
 }
+
+// BufferedWriterWithClock sets the [Clock] the auto-flush interval is measured with.
+// [SystemClock] is used by default. It is useful in tests, to trigger
+// the auto-flush without real waiting.
+func BufferedWriterWithClock(clock Clock) BufferedWriterOption {
+	return func(bw *BufferedWriter) {
+		bw.clock = clock
+	}
+}
+
+// BufferedWriterWithFlushEveryN sets the no. of Write calls (log lines, usually)
+// after which the buffer is flushed, regardless of its size.
+// It combines with size and interval based flushing, whichever triggers first;
+// the count restarts with every interval based / explicit Flush.
+// By default, is disabled (0). Pass a value <=0 to disable it.
+func BufferedWriterWithFlushEveryN(n int) BufferedWriterOption {
+	return func(bw *BufferedWriter) {
+		bw.flushEveryN = n
+	}
+}
+
+// BufferedWriterWithErrAfterStop sets whether Write should return
+// [ErrWriterStopped] once writer was stopped.
+// By default, writes after Stop are silently dropped (nil error is returned).
+func BufferedWriterWithErrAfterStop(enabled bool) BufferedWriterOption {
+	return func(bw *BufferedWriter) {
+		bw.errAfterStop = enabled
+	}
+}
@@ -58,16 +58,20 @@ func TestBufferedWriter_Write_Stop_autoFlushWorks(t *testing.T) {
 	// arrange
 	var (
 		writer  = new(MockWriter)
+		clock   = NewFakeClock(time.Now())
 		subject = xlog.NewBufferedWriter(
 			writer,
 			xlog.BufferedWriterWithSize(2), // we set size to 2 bytes, and we'll write 1 byte
 			xlog.BufferedWriterWithFlushInterval(700*time.Millisecond), // enable auto-flushing at 0.7 sec interval
+			xlog.BufferedWriterWithClock(clock),
 		)
 		dummyByte byte = '\n'
+		flushed        = make(chan struct{}, 1)
 	)
 	defer subject.Stop()
 	writer.SetWriteCallback(func(p []byte) (n int, err error) {
 		assertEqual(t, []byte{dummyByte}, p)
+		flushed <- struct{}{}
 
 		return len(p), nil
 	})
@@ -80,13 +84,219 @@ func TestBufferedWriter_Write_Stop_autoFlushWorks(t *testing.T) {
 	assertNil(t, err)
 	assertEqual(t, 0, writer.WriteCallsCount())
 
-	// wait 1s - within this time auto-flushing should have been called.
-	time.Sleep(1 * time.Second)
+	// act - let less than the flush interval pass.
+	clock.Advance(699 * time.Millisecond)
+
+	// assert - check auto-flushing did not happen.
+	assertEqual(t, 0, writer.WriteCallsCount())
+
+	// act - let the flush interval elapse.
+	clock.Advance(time.Millisecond)
 
 	// assert - check dummy byte was written.
+	waitForFlush(t, flushed)
 	assertEqual(t, 1, writer.WriteCallsCount())
 }
 
+// waitForFlush waits for the auto-flush to be signaled on given channel.
+func waitForFlush(t *testing.T, flushed <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-flushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("auto-flush did not happen")
+	}
+}
+
+func TestBufferedWriter_Flush(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.NewBufferedWriter(
+			writer,
+			xlog.BufferedWriterWithSize(2),
+			xlog.BufferedWriterWithFlushInterval(0),
+		)
+		dummyByte byte = '\n'
+	)
+	defer subject.Stop()
+	_, _ = subject.Write([]byte{dummyByte})
+
+	// act
+	err := subject.Flush()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func TestBufferedWriter_WriteString(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		writer  = new(MockWriter)
+		subject = xlog.NewBufferedWriter(
+			writer,
+			xlog.BufferedWriterWithSize(1024),
+			xlog.BufferedWriterWithFlushInterval(0),
+			xlog.BufferedWriterWithFlushEveryN(2),
+			xlog.BufferedWriterWithErrAfterStop(true),
+		)
+	)
+	writer.SetWriteCallback(buf.Write)
+
+	// act - write a line.
+	n, err := subject.WriteString("line1\n")
+
+	// assert - nothing flushed.
+	assertEqual(t, 6, n)
+	assertNil(t, err)
+	assertEqual(t, 0, writer.WriteCallsCount())
+
+	// act - write 2nd line.
+	n, err = subject.WriteString("line2\n")
+
+	// assert - all lines flushed.
+	assertEqual(t, 6, n)
+	assertNil(t, err)
+	assertEqual(t, 1, writer.WriteCallsCount())
+	assertEqual(t, "line1\nline2\n", buf.String())
+
+	// act - write after stop.
+	subject.Stop()
+	n, err = subject.WriteString("line3\n")
+
+	// assert - line is dropped.
+	assertEqual(t, 0, n)
+	assertTrue(t, errors.Is(err, xlog.ErrWriterStopped))
+	droppedCalls, droppedBytes := subject.DroppedAfterStop()
+	assertEqual(t, uint64(1), droppedCalls)
+	assertEqual(t, uint64(6), droppedBytes)
+	assertEqual(t, "line1\nline2\n", buf.String())
+}
+
+func TestBufferedWriter_Write_flushEveryN(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		writer  = new(MockWriter)
+		n       = 3
+		subject = xlog.NewBufferedWriter(
+			writer,
+			xlog.BufferedWriterWithSize(1024),
+			xlog.BufferedWriterWithFlushInterval(0),
+			xlog.BufferedWriterWithFlushEveryN(n),
+		)
+	)
+	defer subject.Stop()
+	writer.SetWriteCallback(buf.Write)
+
+	// act - write n-1 lines.
+	for i := 1; i < n; i++ {
+		_, err := subject.Write([]byte("line" + strconv.Itoa(i) + "\n"))
+		assertNil(t, err)
+	}
+
+	// assert - nothing flushed.
+	assertEqual(t, 0, writer.WriteCallsCount())
+
+	// act - write nth line.
+	_, err := subject.Write([]byte("line" + strconv.Itoa(n) + "\n"))
+
+	// assert - all lines flushed.
+	assertNil(t, err)
+	assertEqual(t, 1, writer.WriteCallsCount())
+	assertEqual(t, "line1\nline2\nline3\n", buf.String())
+
+	// act - an explicit flush restarts the count.
+	_, _ = subject.Write([]byte("line4\n"))
+	_ = subject.Flush()
+	_, _ = subject.Write([]byte("line5\n"))
+	_, _ = subject.Write([]byte("line6\n"))
+
+	// assert
+	assertEqual(t, 2, writer.WriteCallsCount())
+
+	// act
+	_, _ = subject.Write([]byte("line7\n"))
+
+	// assert
+	assertEqual(t, 3, writer.WriteCallsCount())
+	assertEqual(t, "line1\nline2\nline3\nline4\nline5\nline6\nline7\n", buf.String())
+}
+
+func TestBufferedWriter_Write_flushEveryNAndSize(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.NewBufferedWriter(
+			writer,
+			xlog.BufferedWriterWithSize(8),
+			xlog.BufferedWriterWithFlushInterval(0),
+			xlog.BufferedWriterWithFlushEveryN(100),
+		)
+	)
+	defer subject.Stop()
+
+	// act - size triggers first.
+	_, _ = subject.Write([]byte("12345\n"))
+	_, _ = subject.Write([]byte("12345\n"))
+
+	// assert
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func TestBufferedWriter_Flush_returnsErrAndResets(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.NewBufferedWriter(
+			writer,
+			xlog.BufferedWriterWithSize(16),
+			xlog.BufferedWriterWithFlushInterval(0), // disable auto-flushing.
+		)
+		dummyByte byte = '\n'
+	)
+	defer subject.Stop()
+	writer.SetWriteCallback(WriteCallbackErr)
+	_, _ = subject.Write([]byte{dummyByte})
+
+	// act
+	err := subject.Flush()
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 1, writer.WriteCallsCount())
+
+	// arrange - underlying writer recovers.
+	writer.SetWriteCallback(func(p []byte) (n int, err error) {
+		assertEqual(t, []byte{dummyByte, dummyByte}, p)
+
+		return len(p), nil
+	})
+
+	// act - write and flush again, successfully this time.
+	n, err := subject.Write([]byte{dummyByte, dummyByte})
+	assertEqual(t, 2, n)
+	assertNil(t, err)
+	err = subject.Flush()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 2, writer.WriteCallsCount())
+}
+
 func TestBufferedWriter_Stop_nothingGetsWrittenAfterStop(t *testing.T) {
 	t.Parallel()
 
@@ -125,6 +335,50 @@ func TestBufferedWriter_Stop_nothingGetsWrittenAfterStop(t *testing.T) {
 	assertEqual(t, 1, writer.WriteCallsCount()) // calls count is still 1
 }
 
+func TestBufferedWriter_DroppedAfterStop(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default, nil error", testBufferedWriterDroppedAfterStop(false))
+	t.Run("with ErrWriterStopped", testBufferedWriterDroppedAfterStop(true))
+}
+
+func testBufferedWriterDroppedAfterStop(errAfterStop bool) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			writer  = new(MockWriter)
+			subject = xlog.NewBufferedWriter(
+				writer,
+				xlog.BufferedWriterWithFlushInterval(0), // disable auto-flushing.
+				xlog.BufferedWriterWithErrAfterStop(errAfterStop),
+			)
+		)
+		_, _ = subject.Write([]byte("abc"))
+		subject.Stop()
+
+		// act
+		n1, err1 := subject.Write([]byte("abcd"))
+		n2, err2 := subject.Write([]byte("ef"))
+		calls, bytes := subject.DroppedAfterStop()
+
+		// assert
+		assertEqual(t, 0, n1)
+		assertEqual(t, 0, n2)
+		if errAfterStop {
+			assertTrue(t, errors.Is(err1, xlog.ErrWriterStopped))
+			assertTrue(t, errors.Is(err2, xlog.ErrWriterStopped))
+		} else {
+			assertNil(t, err1)
+			assertNil(t, err2)
+		}
+		assertEqual(t, uint64(2), calls)
+		assertEqual(t, uint64(6), bytes)
+		assertEqual(t, 1, writer.WriteCallsCount())
+	}
+}
+
 func TestBufferedWriter_Write_writeErrorGetsReset(t *testing.T) {
 	t.Parallel()
 
@@ -173,17 +427,21 @@ func TestBufferedWriter_Write_autoFlushErrorGetsReset(t *testing.T) {
 	// arrange
 	var (
 		writer  = new(MockWriter)
+		clock   = NewFakeClock(time.Now())
 		subject = xlog.NewBufferedWriter(
 			writer,
 			xlog.BufferedWriterWithSize(1), // we set size to 1 byte.
 			xlog.BufferedWriterWithFlushInterval(700*time.Millisecond),
+			xlog.BufferedWriterWithClock(clock),
 		)
 		dummyByte byte = '\n'
+		flushed        = make(chan struct{}, 1)
 	)
 	defer subject.Stop()
 	writer.SetWriteCallback(func(p []byte) (n int, err error) {
 		if writer.WriteCallsCount() == 1 { // auto Flush()
 			assertEqual(t, []byte{dummyByte}, p)
+			flushed <- struct{}{}
 
 			return 0, ErrWrite
 		}
@@ -199,8 +457,9 @@ func TestBufferedWriter_Write_autoFlushErrorGetsReset(t *testing.T) {
 	assertEqual(t, 1, n)
 	assertNil(t, err)
 
-	// wait 1s - within this time auto-flushing should have been called.
-	time.Sleep(1 * time.Second)
+	// act - let the flush interval elapse.
+	clock.Advance(700 * time.Millisecond)
+	waitForFlush(t, flushed)
 
 	// act - write 3 dummy bytes, successfully this time.
 	n, err = subject.Write([]byte{dummyByte, dummyByte, dummyByte})
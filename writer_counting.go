@@ -0,0 +1,54 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// CountingWriter decorates an io.Writer so that written bytes
+// and Write calls are counted.
+// It can be useful to measure logs throughput, in order to size
+// buffers / channels.
+// It is concurrent safe to use (counters wise).
+type CountingWriter struct {
+	// original writer data is written to.
+	w io.Writer
+	// no. of bytes written to the original writer.
+	bytesWritten atomic.Uint64
+	// no. of Write calls.
+	writesCount atomic.Uint64
+}
+
+// NewCountingWriter instantiates a new counting writer.
+func NewCountingWriter(w io.Writer) *CountingWriter {
+	return &CountingWriter{w: w}
+}
+
+// Write writes given bytes to the decorated writer.
+// Returns no. of bytes written, or an error, as returned by
+// the decorated writer.
+// Only bytes actually written are counted.
+func (cw *CountingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.writesCount.Add(1)
+	if n > 0 {
+		cw.bytesWritten.Add(uint64(n))
+	}
+
+	return n, err
+}
+
+// BytesWritten returns the no. of bytes written so far.
+func (cw *CountingWriter) BytesWritten() uint64 {
+	return cw.bytesWritten.Load()
+}
+
+// WritesCount returns the no. of Write calls so far.
+func (cw *CountingWriter) WritesCount() uint64 {
+	return cw.writesCount.Load()
+}
@@ -0,0 +1,92 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestCountingWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  bytes.Buffer
+		subject = xlog.NewCountingWriter(&writer)
+	)
+
+	// act
+	n1, err1 := subject.Write([]byte("foo"))
+	n2, err2 := subject.Write([]byte("bar baz"))
+	n3, err3 := subject.Write(nil)
+
+	// assert
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertNil(t, err3)
+	assertEqual(t, 3, n1)
+	assertEqual(t, 7, n2)
+	assertEqual(t, 0, n3)
+	assertEqual(t, uint64(10), subject.BytesWritten())
+	assertEqual(t, uint64(3), subject.WritesCount())
+	assertEqual(t, "foobar baz", writer.String())
+}
+
+func TestCountingWriter_Write_partialWrite(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.NewCountingWriter(writer)
+	)
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		return len(p) / 2, ErrWrite
+	})
+
+	// act
+	n, err := subject.Write([]byte("foobar"))
+
+	// assert
+	assertEqual(t, 3, n)
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, uint64(3), subject.BytesWritten())
+	assertEqual(t, uint64(1), subject.WritesCount())
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func TestCountingWriter_concurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject      = xlog.NewCountingWriter(new(MockWriter))
+		goroutinesNo = 50
+		writesNo     = 20
+		wg           sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < writesNo; j++ {
+				_, _ = subject.Write([]byte("ab"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, uint64(goroutinesNo*writesNo), subject.WritesCount())
+	assertEqual(t, uint64(2*goroutinesNo*writesNo), subject.BytesWritten())
+}
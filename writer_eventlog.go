@@ -0,0 +1,100 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/go-logfmt/logfmt"
+)
+
+// EventLogger is the contract for a Windows Event Log.
+// *eventlog.Log from "golang.org/x/sys/windows/svc/eventlog" satisfies it.
+type EventLogger interface {
+	// Info writes an information event msg with event id eid to the event log.
+	Info(eid uint32, msg string) error
+	// Warning writes a warning event msg with event id eid to the event log.
+	Warning(eid uint32, msg string) error
+	// Error writes an error event msg with event id eid to the event log.
+	Error(eid uint32, msg string) error
+	// Close closes the event log.
+	Close() error
+}
+
+// EventLogWriterEventID is the event id logs are written with
+// by an [EventLogWriter].
+const EventLogWriterEventID uint32 = 1
+
+// EventLogWriter is an io.Writer which writes logs to Windows Event Log,
+// in the Info / Warning / Error category, depending on log's level
+// (read from the "lvl" field of a JSON or logfmt formatted log):
+// debug and info logs are written as Info events, warning logs as Warning
+// events, error and critical logs as Error events.
+// Logs without a (known) level are written as Info events.
+// See [NewEventLogWriter] (Windows only).
+type EventLogWriter struct {
+	eventLog EventLogger
+	levels   map[string]Level
+}
+
+// NewEventLogWriterFromLogger instantiates a new event log writer
+// on top of given event log.
+// It can be used for example with a remote event log
+// (see eventlog.OpenRemote), or with a fake one, in tests.
+func NewEventLogWriterFromLogger(eventLog EventLogger) *EventLogWriter {
+	return &EventLogWriter{
+		eventLog: eventLog,
+		levels:   flipLevelLabels(DefaultLevelLabels()),
+	}
+}
+
+// Write writes given log to the event log.
+func (elw *EventLogWriter) Write(p []byte) (int, error) {
+	msg := string(bytes.TrimSuffix(p, []byte{'\n'}))
+
+	var err error
+	switch elw.levels[stringify(decodeLogFields(p)[defaultOptLevelKey])] {
+	case LevelWarning:
+		err = elw.eventLog.Warning(EventLogWriterEventID, msg)
+	case LevelError, LevelCritical:
+		err = elw.eventLog.Error(EventLogWriterEventID, msg)
+	default:
+		err = elw.eventLog.Info(EventLogWriterEventID, msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the event log.
+func (elw *EventLogWriter) Close() error {
+	return elw.eventLog.Close()
+}
+
+// decodeLogFields decodes a JSON or logfmt formatted log into its fields.
+// On decoding failure, the fields decoded so far are returned.
+func decodeLogFields(p []byte) map[string]any {
+	p = bytes.TrimSpace(p)
+	if len(p) > 0 && p[0] == '{' {
+		var fields map[string]any
+		_ = json.Unmarshal(p, &fields)
+
+		return fields
+	}
+
+	fields := make(map[string]any)
+	dec := logfmt.NewDecoder(bytes.NewReader(p))
+	for dec.ScanRecord() {
+		for dec.ScanKeyval() {
+			fields[string(dec.Key())] = string(dec.Value())
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,177 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestEventLogWriter_mapsLevelToEventCategory(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name             string
+		log              string
+		expectedCategory string
+	}{
+		{
+			name:             "json debug",
+			log:              `{"lvl":"DEBUG","msg":"foo"}` + "\n",
+			expectedCategory: "info",
+		},
+		{
+			name:             "json info",
+			log:              `{"lvl":"INFO","msg":"foo"}` + "\n",
+			expectedCategory: "info",
+		},
+		{
+			name:             "json warning",
+			log:              `{"lvl":"WARN","msg":"foo"}` + "\n",
+			expectedCategory: "warning",
+		},
+		{
+			name:             "json error",
+			log:              `{"lvl":"ERROR","msg":"foo"}` + "\n",
+			expectedCategory: "error",
+		},
+		{
+			name:             "json critical",
+			log:              `{"lvl":"CRITICAL","msg":"foo"}` + "\n",
+			expectedCategory: "error",
+		},
+		{
+			name:             "logfmt warning",
+			log:              `date=2022-03-14T16:01:20Z lvl=WARN msg="foo bar"` + "\n",
+			expectedCategory: "warning",
+		},
+		{
+			name:             "logfmt error",
+			log:              `lvl=ERROR msg=foo` + "\n",
+			expectedCategory: "error",
+		},
+		{
+			name:             "no level",
+			log:              `{"msg":"foo"}` + "\n",
+			expectedCategory: "info",
+		},
+		{
+			name:             "unknown level",
+			log:              `{"lvl":"NOTICE","msg":"foo"}` + "\n",
+			expectedCategory: "info",
+		},
+		{
+			name:             "not a structured log",
+			log:              "some plain text\n",
+			expectedCategory: "info",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				eventLog = new(fakeEventLogger)
+				subject  = xlog.NewEventLogWriterFromLogger(eventLog)
+			)
+
+			// act
+			n, err := subject.Write([]byte(test.log))
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, len(test.log), n)
+			if assertEqual(t, 1, len(eventLog.events)) {
+				assertEqual(t, test.expectedCategory, eventLog.events[0].category)
+				assertEqual(t, xlog.EventLogWriterEventID, eventLog.events[0].eid)
+				assertEqual(t, test.log[:len(test.log)-1], eventLog.events[0].msg)
+			}
+		})
+	}
+}
+
+func TestEventLogWriter_returnsEventLogErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		eventLog = &fakeEventLogger{err: errors.New("intentionally triggered event log error")}
+		subject  = xlog.NewEventLogWriterFromLogger(eventLog)
+	)
+
+	// act
+	n, err := subject.Write([]byte(`{"lvl":"ERROR","msg":"foo"}`))
+
+	// assert
+	assertEqual(t, eventLog.err, err)
+	assertEqual(t, 0, n)
+}
+
+func TestEventLogWriter_Close(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		eventLog = new(fakeEventLogger)
+		subject  = xlog.NewEventLogWriterFromLogger(eventLog)
+	)
+
+	// act
+	err := subject.Close()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, eventLog.closeCallsCnt)
+}
+
+// fakeEvent is an event written to a fakeEventLogger.
+type fakeEvent struct {
+	category string
+	eid      uint32
+	msg      string
+}
+
+// fakeEventLogger is a fake Windows event log, storing events in memory.
+type fakeEventLogger struct {
+	events        []fakeEvent
+	err           error
+	closeCallsCnt int
+	mu            sync.Mutex
+}
+
+func (el *fakeEventLogger) Info(eid uint32, msg string) error {
+	return el.report("info", eid, msg)
+}
+
+func (el *fakeEventLogger) Warning(eid uint32, msg string) error {
+	return el.report("warning", eid, msg)
+}
+
+func (el *fakeEventLogger) Error(eid uint32, msg string) error {
+	return el.report("error", eid, msg)
+}
+
+func (el *fakeEventLogger) Close() error {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	el.closeCallsCnt++
+
+	return nil
+}
+
+func (el *fakeEventLogger) report(category string, eid uint32, msg string) error {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	el.events = append(el.events, fakeEvent{category: category, eid: eid, msg: msg})
+
+	return el.err
+}
@@ -0,0 +1,48 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+//go:build windows
+
+package xlog
+
+import (
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLogSourcesKey is the registry key under which Application event log
+// sources are registered.
+const eventLogSourcesKey = `SYSTEM\CurrentControlSet\Services\EventLog\Application\`
+
+// NewEventLogWriter instantiates a new writer which writes logs to
+// Windows Event Log, under given source (usually your application's name).
+// If the source is not registered, it gets registered.
+// Note: registering a source requires administrative privileges
+// (write access to HKEY_LOCAL_MACHINE registry), so either run your
+// application once as administrator, or register the source
+// at install time, for example with PowerShell:
+//
+//	New-EventLog -LogName Application -Source <source>
+func NewEventLogWriter(source string) (*EventLogWriter, error) {
+	if err := registerEventLogSource(source); err != nil {
+		return nil, err
+	}
+	eventLog, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEventLogWriterFromLogger(eventLog), nil
+}
+
+// registerEventLogSource registers given source, if it's not registered already.
+func registerEventLogSource(source string) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, eventLogSourcesKey+source, registry.QUERY_VALUE)
+	if err == nil {
+		return key.Close() // already registered.
+	}
+
+	return eventlog.InstallAsEventCreate(source, eventlog.Error|eventlog.Warning|eventlog.Info)
+}
@@ -0,0 +1,79 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// headerWriter decorates an io.Writer so that a header
+// is written before the first Write.
+type headerWriter struct {
+	w      io.Writer
+	header []byte
+	// written flag, true means the header was written.
+	written atomic.Bool
+	// mu guards header writing.
+	mu sync.Mutex
+}
+
+// HeaderWriter returns a Writer which writes given header (a CSV header row,
+// an XML prolog, a BOM, etc.) to the decorated writer, exactly once, before the first Write.
+// Concurrent Writes made before the header was written wait for it.
+// If writing the header fails, the error is returned, and the header is
+// attempted again on the next Write.
+// If the decorated writer starts a new file (like a rotating writer does),
+// call [ResetHeader] (from a rotation hook, for example), so that the header
+// is written again, at the top of the new file.
+func HeaderWriter(w io.Writer, header []byte) io.Writer {
+	return &headerWriter{
+		w:      w,
+		header: header,
+	}
+}
+
+// Write writes the header, if not written already, and then given bytes
+// to the decorated writer.
+// Returns no. of bytes of p written, or an error.
+func (hw *headerWriter) Write(p []byte) (int, error) {
+	if !hw.written.Load() {
+		if err := hw.writeHeader(); err != nil {
+			return 0, err
+		}
+	}
+
+	return hw.w.Write(p)
+}
+
+// writeHeader writes the header, if not written already.
+func (hw *headerWriter) writeHeader() error {
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+
+	if hw.written.Load() { // another goroutine has written it meanwhile.
+		return nil
+	}
+	if _, err := hw.w.Write(hw.header); err != nil {
+		return err
+	}
+	hw.written.Store(true)
+
+	return nil
+}
+
+// ResetHeader makes given writer, if it is one returned by [HeaderWriter],
+// write its header again, before the next Write.
+// It returns false if the writer is not a header writer.
+func ResetHeader(w io.Writer) bool {
+	hw, ok := w.(*headerWriter)
+	if ok {
+		hw.written.Store(false)
+	}
+
+	return ok
+}
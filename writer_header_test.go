@@ -0,0 +1,115 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestHeaderWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("header is written once, at the top", testHeaderWriterWritesHeaderOnce)
+	t.Run("concurrent writes", testHeaderWriterConcurrentWrites)
+	t.Run("failed header is retried", testHeaderWriterRetriesFailedHeader)
+	t.Run("reset header", testHeaderWriterReset)
+}
+
+func testHeaderWriterWritesHeaderOnce(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		subject = xlog.HeaderWriter(&buf, []byte("time,level,msg\n"))
+	)
+
+	// act
+	for _, line := range []string{"t1,INFO,foo\n", "t2,WARN,bar\n", "t3,ERROR,baz\n"} {
+		n, err := subject.Write([]byte(line))
+		assertNil(t, err)
+		assertEqual(t, len(line), n)
+	}
+
+	// assert
+	assertEqual(t, "time,level,msg\nt1,INFO,foo\nt2,WARN,bar\nt3,ERROR,baz\n", buf.String())
+}
+
+func testHeaderWriterConcurrentWrites(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf       bytes.Buffer
+		subject   = xlog.HeaderWriter(xlog.NewSyncWriter(&buf), []byte("header\n"))
+		writersNo = 20
+		wg        sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < writersNo; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = subject.Write([]byte("line\n"))
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	output := buf.String()
+	assertTrue(t, strings.HasPrefix(output, "header\n"))
+	assertEqual(t, 1, strings.Count(output, "header\n"))
+	assertEqual(t, writersNo, strings.Count(output, "line\n"))
+}
+
+func testHeaderWriterRetriesFailedHeader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		writer  = failingWriter(&buf, 1)
+		subject = xlog.HeaderWriter(writer, []byte("header\n"))
+	)
+
+	// act
+	n1, err1 := subject.Write([]byte("line1\n"))
+	n2, err2 := subject.Write([]byte("line2\n"))
+
+	// assert
+	assertEqual(t, ErrWrite, err1)
+	assertEqual(t, 0, n1)
+	assertNil(t, err2)
+	assertEqual(t, 6, n2)
+	assertEqual(t, "header\nline2\n", buf.String())
+}
+
+func testHeaderWriterReset(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		subject = xlog.HeaderWriter(&buf, []byte("header\n"))
+	)
+	_, _ = subject.Write([]byte("line1\n"))
+
+	// act
+	ok := xlog.ResetHeader(subject)
+	_, _ = subject.Write([]byte("line2\n"))
+	_, _ = subject.Write([]byte("line3\n"))
+
+	// assert
+	assertTrue(t, ok)
+	assertEqual(t, "header\nline1\nheader\nline2\nline3\n", buf.String())
+	assertFalse(t, xlog.ResetHeader(&buf))
+}
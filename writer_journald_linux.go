@@ -0,0 +1,208 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultJournaldSocketPath is the path of journald's native protocol socket.
+const defaultJournaldSocketPath = "/run/systemd/journal/socket"
+
+// journaldFieldNameMaxLen is the max length of a journald field name.
+const journaldFieldNameMaxLen = 64
+
+// ErrJournaldUnavailable is the error returned in case journald's socket
+// cannot be reached (for example, the host is not running systemd).
+var ErrJournaldUnavailable = errors.New("journald socket is not available")
+
+// JournaldWriter is an io.Writer which sends logs to systemd-journald,
+// through its native protocol, preserving logs' fields.
+// The log (JSON or logfmt formatted) is decoded into its fields:
+// [MessageKey] becomes MESSAGE field, "lvl" becomes PRIORITY field
+// (as a syslog severity), the rest of the keys become uppercased fields.
+// Note: a log exceeding the max datagram size is not sent
+// (an error is returned).
+// It is concurrent safe to use.
+type JournaldWriter struct {
+	// path of journald's socket.
+	// can be set with [JournaldWriterWithSocketPath] functional option.
+	socketPath string
+	// connection to journald's socket, established on first write.
+	conn *net.UnixConn
+	// map of level labels to syslog severities.
+	priorities map[string]string
+	// concurrency semaphore to protect connection access.
+	mu sync.Mutex
+}
+
+// NewJournaldWriter instantiates a new journald writer.
+// The connection to journald is established on first write,
+// an [ErrJournaldUnavailable] error being returned, if it cannot be made.
+// Check for JournaldWriterWith* options to further customize it.
+func NewJournaldWriter(opts ...JournaldWriterOption) *JournaldWriter {
+	// instantiate object with default properties.
+	jw := &JournaldWriter{
+		socketPath: defaultJournaldSocketPath,
+		priorities: make(map[string]string, 5),
+	}
+	for lvl, label := range DefaultLevelLabels() {
+		switch lvl {
+		case LevelDebug:
+			jw.priorities[label] = "7"
+		case LevelInfo:
+			jw.priorities[label] = "6"
+		case LevelWarning:
+			jw.priorities[label] = "4"
+		case LevelError:
+			jw.priorities[label] = "3"
+		case LevelCritical:
+			jw.priorities[label] = "2"
+		}
+	}
+
+	// apply functional options, if any.
+	for _, opt := range opts {
+		opt(jw)
+	}
+
+	return jw
+}
+
+// Write sends given log to journald.
+func (jw *JournaldWriter) Write(p []byte) (int, error) {
+	payload := jw.payload(p)
+
+	jw.mu.Lock()
+	defer jw.mu.Unlock()
+
+	if jw.conn == nil {
+		conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: jw.socketPath, Net: "unixgram"})
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrJournaldUnavailable, err)
+		}
+		jw.conn = conn
+	}
+	if _, err := jw.conn.Write(payload); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the connection to journald.
+func (jw *JournaldWriter) Close() error {
+	jw.mu.Lock()
+	defer jw.mu.Unlock()
+
+	if jw.conn == nil {
+		return nil
+	}
+	err := jw.conn.Close()
+	jw.conn = nil
+
+	return err
+}
+
+// payload builds the native protocol datagram from given log.
+func (jw *JournaldWriter) payload(p []byte) []byte {
+	fields := decodeLogFields(p)
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Grow(len(p) + 16*len(keys))
+	if _, found := fields[MessageKey]; !found {
+		writeJournaldField(&buf, "MESSAGE", string(bytes.TrimSuffix(p, []byte{'\n'})))
+	}
+	for _, key := range keys {
+		value := journaldFieldValue(fields[key])
+		switch key {
+		case MessageKey:
+			writeJournaldField(&buf, "MESSAGE", value)
+		case defaultOptLevelKey:
+			if priority, found := jw.priorities[value]; found {
+				writeJournaldField(&buf, "PRIORITY", priority)
+			}
+		default:
+			writeJournaldField(&buf, journaldFieldName(key), value)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// journaldFieldValue returns the string representation of a decoded value.
+// Non string values are JSON encoded.
+func journaldFieldValue(value any) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	if enc, err := json.Marshal(value); err == nil {
+		return string(enc)
+	}
+
+	return stringify(value)
+}
+
+// journaldFieldName returns a valid journald field name from given key:
+// uppercased, made of A-Z, 0-9, '_', not starting with '_' or a digit,
+// max 64 characters.
+func journaldFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+	name = bytes.TrimLeft(name, "_")
+	if len(name) == 0 || (name[0] >= '0' && name[0] <= '9') {
+		name = append([]byte("X_"), name...)
+	}
+	if len(name) > journaldFieldNameMaxLen {
+		name = name[:journaldFieldNameMaxLen]
+	}
+
+	return string(name)
+}
+
+// writeJournaldField writes a field in native protocol format:
+// "NAME=value\n", or, if value contains new lines,
+// "NAME\n" + value's length as 64 bit little endian + value + "\n".
+func writeJournaldField(buf *bytes.Buffer, name, value string) {
+	_, _ = buf.WriteString(name)
+	if strings.ContainsRune(value, '\n') {
+		_ = buf.WriteByte('\n')
+		_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	} else {
+		_ = buf.WriteByte('=')
+	}
+	_, _ = buf.WriteString(value)
+	_ = buf.WriteByte('\n')
+}
+
+// JournaldWriterOption defines optional function for configuring
+// a journald writer.
+type JournaldWriterOption func(*JournaldWriter)
+
+// JournaldWriterWithSocketPath sets the path of journald's socket.
+// By default, "/run/systemd/journal/socket" is used.
+func JournaldWriterWithSocketPath(socketPath string) JournaldWriterOption {
+	return func(jw *JournaldWriter) {
+		jw.socketPath = socketPath
+	}
+}
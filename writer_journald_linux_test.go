@@ -0,0 +1,210 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestJournaldWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("json log fields are sent", testJournaldWriterSendsJSONLogFields)
+	t.Run("logfmt log fields are sent", testJournaldWriterSendsLogfmtLogFields)
+	t.Run("multiline values are length prefixed", testJournaldWriterSendsMultilineValues)
+	t.Run("unstructured log is sent as message", testJournaldWriterSendsUnstructuredLog)
+	t.Run("absent socket returns error", testJournaldWriterReturnsErrIfSocketIsAbsent)
+}
+
+func testJournaldWriterSendsJSONLogFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		socket, socketPath = setUpFakeJournald(t)
+		subject            = xlog.NewJournaldWriter(xlog.JournaldWriterWithSocketPath(socketPath))
+		log                = `{"date":"2022-03-14T16:01:20Z","lvl":"ERROR","msg":"could not save user",` +
+			`"userId":123,"request-id":"abc","tags":["a","b"]}` + "\n"
+	)
+	defer subject.Close()
+
+	// act
+	n, err := subject.Write([]byte(log))
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, len(log), n)
+	assertEqual(
+		t,
+		map[string]string{
+			"DATE":       "2022-03-14T16:01:20Z",
+			"PRIORITY":   "3",
+			"MESSAGE":    "could not save user",
+			"REQUEST_ID": "abc",
+			"TAGS":       `["a","b"]`,
+			"USERID":     "123",
+		},
+		readJournaldFields(t, socket),
+	)
+}
+
+func testJournaldWriterSendsLogfmtLogFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		socket, socketPath = setUpFakeJournald(t)
+		subject            = xlog.NewJournaldWriter(xlog.JournaldWriterWithSocketPath(socketPath))
+		commOpts           = xlog.NewCommonOpts()
+		logger             = xlog.NewSyncLogger(
+			subject,
+			xlog.SyncLoggerWithOptions(commOpts),
+			xlog.SyncLoggerWithFormatter(xlog.LogfmtFormatter),
+		)
+	)
+	defer subject.Close()
+	commOpts.Time = staticTimeProvider
+	commOpts.SourceKey = ""
+
+	// act
+	logger.Warn(xlog.MessageKey, "disk almost full", "_usage", 0.93, "1st", "x")
+
+	// assert
+	assertEqual(
+		t,
+		map[string]string{
+			"DATE":     staticTime,
+			"PRIORITY": "4",
+			"MESSAGE":  "disk almost full",
+			"USAGE":    "0.93",
+			"X_1ST":    "x",
+		},
+		readJournaldFields(t, socket),
+	)
+}
+
+func testJournaldWriterSendsMultilineValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		socket, socketPath = setUpFakeJournald(t)
+		subject            = xlog.NewJournaldWriter(xlog.JournaldWriterWithSocketPath(socketPath))
+		log                = `{"lvl":"CRITICAL","msg":"panic","stack":"line1\nline2"}` + "\n"
+	)
+	defer subject.Close()
+
+	// act
+	_, err := subject.Write([]byte(log))
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string]string{
+			"PRIORITY": "2",
+			"MESSAGE":  "panic",
+			"STACK":    "line1\nline2",
+		},
+		readJournaldFields(t, socket),
+	)
+}
+
+func testJournaldWriterSendsUnstructuredLog(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		socket, socketPath = setUpFakeJournald(t)
+		subject            = xlog.NewJournaldWriter(xlog.JournaldWriterWithSocketPath(socketPath))
+	)
+	defer subject.Close()
+
+	// act
+	_, err := subject.Write([]byte(`{"not json`))
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, map[string]string{"MESSAGE": `{"not json`}, readJournaldFields(t, socket))
+}
+
+func testJournaldWriterReturnsErrIfSocketIsAbsent(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xlog.NewJournaldWriter(
+		xlog.JournaldWriterWithSocketPath(filepath.Join(t.TempDir(), "absent.sock")),
+	)
+
+	// act
+	n, err := subject.Write([]byte(`{"msg":"foo"}`))
+
+	// assert
+	assertTrue(t, errors.Is(err, xlog.ErrJournaldUnavailable))
+	assertEqual(t, 0, n)
+	assertNil(t, subject.Close())
+}
+
+// setUpFakeJournald listens on a datagram unix socket, in a temporary dir.
+func setUpFakeJournald(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+
+	socketPath := filepath.Join(t.TempDir(), "journal.sock")
+	socket, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("could not set up fake journald: %v", err)
+	}
+	t.Cleanup(func() { _ = socket.Close() })
+
+	return socket, socketPath
+}
+
+// readJournaldFields reads a datagram and parses its native protocol fields.
+func readJournaldFields(t *testing.T, socket *net.UnixConn) map[string]string {
+	t.Helper()
+
+	buf := make([]byte, 64*1024)
+	_ = socket.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := socket.Read(buf)
+	if err != nil {
+		t.Fatalf("could not read datagram: %v", err)
+	}
+
+	fields := make(map[string]string)
+	payload := buf[:n]
+	for len(payload) > 0 {
+		nlIdx := bytes.IndexByte(payload, '\n')
+		if nlIdx < 0 {
+			t.Fatalf("malformed datagram, field not terminated by new line: %q", payload)
+		}
+		line := payload[:nlIdx]
+		if eqIdx := bytes.IndexByte(line, '='); eqIdx >= 0 {
+			fields[string(line[:eqIdx])] = string(line[eqIdx+1:])
+			payload = payload[nlIdx+1:]
+
+			continue
+		}
+		// binary safe field: NAME\n<64 bit LE length><value>\n
+		name := string(line)
+		payload = payload[nlIdx+1:]
+		size := binary.LittleEndian.Uint64(payload[:8])
+		fields[name] = string(payload[8 : 8+size])
+		if payload[8+size] != '\n' {
+			t.Fatalf("malformed datagram, binary field not terminated by new line: %q", payload)
+		}
+		payload = payload[8+size+1:]
+	}
+
+	return fields
+}
@@ -0,0 +1,121 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"bytes"
+	"sync"
+)
+
+// defaultMaxLineLength is the default max length, in bytes, of a buffered partial line.
+const defaultMaxLineLength = 64 * 1024
+
+// LoggerWriter is an io.Writer which logs written text through a Logger,
+// at a fixed level.
+// It is useful to capture output of libraries which write to an io.Writer
+// (like [net/http.Server.ErrorLog], database drivers, etc.) into your
+// structured logs.
+// Written text is split on newlines, each line becoming a log; a partial
+// line is buffered until a newline arrives, or until Close is called.
+// A partial line reaching the max line length (64KiB by default, see
+// [LoggerWriter.SetMaxLineLength]) is logged as it is, the rest of it
+// becoming a new line, so that the buffer does not grow unbounded.
+// Empty lines are skipped.
+// It is concurrent safe to use.
+// Note: as logs are made from inside this writer, a source provider configured
+// on the logger will not report the library's file and line.
+type LoggerWriter struct {
+	// logger to log lines to.
+	logger Logger
+	// level lines are logged with.
+	lvl Level
+	// key under which a line is logged.
+	msgKey string
+	// buffer holding a partial line.
+	buf bytes.Buffer
+	// max length of the partial line.
+	maxLineLen int
+	// concurrency semaphore to protect buf.
+	mu sync.Mutex
+}
+
+// NewLoggerWriter instantiates a new writer which logs each written line
+// through given logger, with given level, under given key (usually [MessageKey]).
+func NewLoggerWriter(logger Logger, lvl Level, msgKey string) *LoggerWriter {
+	return &LoggerWriter{
+		logger:     logger,
+		lvl:        lvl,
+		msgKey:     msgKey,
+		maxLineLen: defaultMaxLineLength,
+	}
+}
+
+// SetMaxLineLength sets the max length, in bytes, of a buffered partial line.
+// A value <= 0 is ignored.
+// It should be called before logging starts, it is not concurrent safe.
+func (lw *LoggerWriter) SetMaxLineLength(maxLineLen int) {
+	if maxLineLen > 0 {
+		lw.maxLineLen = maxLineLen
+	}
+}
+
+// Write logs each complete line found in given bytes
+// (together with the previously buffered partial line, if any),
+// buffering the trailing partial line, if any.
+// It always returns len(p), nil.
+func (lw *LoggerWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	n := len(p)
+	for {
+		idx := bytes.IndexByte(p, '\n')
+		if idx < 0 {
+			for lw.buf.Len()+len(p) > lw.maxLineLen { // log the partial line, do not grow unbounded.
+				chunk := lw.maxLineLen - lw.buf.Len()
+				lw.buf.Write(p[:chunk])
+				lw.logLine(lw.buf.Bytes())
+				lw.buf.Reset()
+				p = p[chunk:]
+			}
+			lw.buf.Write(p)
+
+			break
+		}
+		if lw.buf.Len() > 0 {
+			lw.buf.Write(p[:idx])
+			lw.logLine(lw.buf.Bytes())
+			lw.buf.Reset()
+		} else {
+			lw.logLine(p[:idx])
+		}
+		p = p[idx+1:]
+	}
+
+	return n, nil
+}
+
+// Close logs the buffered partial line, if any.
+func (lw *LoggerWriter) Close() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if lw.buf.Len() > 0 {
+		lw.logLine(lw.buf.Bytes())
+		lw.buf.Reset()
+	}
+
+	return nil
+}
+
+// logLine logs given line, if not empty.
+func (lw *LoggerWriter) logLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(line) == 0 {
+		return
+	}
+	logByLevel(lw.logger, lw.lvl, lw.msgKey, string(line))
+}
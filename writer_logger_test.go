@@ -0,0 +1,135 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestLoggerWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewMockLogger()
+		subject = xlog.NewLoggerWriter(logger, xlog.LevelWarning, xlog.MessageKey)
+		lines   []string
+	)
+	logger.SetLogCallback(xlog.LevelWarning, func(keyValues ...any) {
+		assertEqual(t, 2, len(keyValues))
+		assertEqual(t, xlog.MessageKey, keyValues[0])
+		lines = append(lines, keyValues[1].(string))
+	})
+	chunks := []string{
+		"first li",
+		"ne\nsecond line\r\nthi",
+		"rd",
+		" line\n\nfourth",
+		" line",
+	}
+
+	// act
+	for _, chunk := range chunks {
+		n, err := subject.Write([]byte(chunk))
+		assertNil(t, err)
+		assertEqual(t, len(chunk), n)
+	}
+
+	// assert
+	assertEqual(t, []string{"first line", "second line", "third line"}, lines)
+	assertEqual(t, 3, logger.LogCallsCount(xlog.LevelWarning))
+
+	// act - trailing partial line gets flushed.
+	err := subject.Close()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, []string{"first line", "second line", "third line", "fourth line"}, lines)
+	assertEqual(t, 4, logger.LogCallsCount(xlog.LevelWarning))
+
+	// act - nothing left to flush.
+	err = subject.Close()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 4, logger.LogCallsCount(xlog.LevelWarning))
+}
+
+func TestLoggerWriter_SetMaxLineLength(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewMockLogger()
+		subject = xlog.NewLoggerWriter(logger, xlog.LevelError, xlog.MessageKey)
+		lines   []string
+	)
+	subject.SetMaxLineLength(4)
+	logger.SetLogCallback(xlog.LevelError, func(keyValues ...any) {
+		lines = append(lines, keyValues[1].(string))
+	})
+
+	// act
+	_, _ = subject.Write([]byte("ab"))
+	_, _ = subject.Write([]byte("cdefghijk"))
+	_, _ = subject.Write([]byte("l\nmn"))
+	_ = subject.Close()
+
+	// assert
+	assertEqual(t, []string{"abcd", "efgh", "ijkl", "mn"}, lines)
+}
+
+func TestLoggerWriter_withStdLogger(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger    = xlog.NewMockLogger()
+		subject   = xlog.NewLoggerWriter(logger, xlog.LevelError, "error")
+		stdLogger = log.New(subject, "", 0)
+	)
+	logger.SetLogCallback(xlog.LevelError, func(keyValues ...any) {
+		assertEqual(t, []any{"error", "http: TLS handshake error"}, keyValues)
+	})
+
+	// act
+	stdLogger.Print("http: TLS handshake error")
+
+	// assert
+	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelError))
+}
+
+func TestLoggerWriter_concurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger       = xlog.NewMockLogger()
+		subject      = xlog.NewLoggerWriter(logger, xlog.LevelInfo, xlog.MessageKey)
+		goroutinesNo = 20
+		writesNo     = 10
+		wg           sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < writesNo; j++ {
+				_, _ = subject.Write([]byte("some line\n"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, goroutinesNo*writesNo, logger.LogCallsCount(xlog.LevelInfo))
+}
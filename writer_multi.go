@@ -0,0 +1,92 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+
+	"github.com/actforgood/xerr"
+)
+
+// MultiWriter duplicates its writes to all the provided writers,
+// similar to [io.MultiWriter], with the difference that a failing writer
+// does not stop the write process for the rest of the writers.
+// It is useful for sending the same formatted log to multiple destinations
+// (like stdout and a file) without formatting it multiple times, as
+// a [MultiLogger] does.
+type MultiWriter struct {
+	writers []io.Writer
+}
+
+// NewMultiWriter instantiates a new writer that duplicates its writes
+// to all the provided writers.
+func NewMultiWriter(writers ...io.Writer) *MultiWriter {
+	allWriters := make([]io.Writer, 0, len(writers))
+	for _, w := range writers {
+		if mw, ok := w.(*MultiWriter); ok {
+			allWriters = append(allWriters, mw.writers...)
+		} else {
+			allWriters = append(allWriters, w)
+		}
+	}
+
+	return &MultiWriter{writers: allWriters}
+}
+
+// Write writes given bytes to all the writers.
+// Writing continues even if a writer fails.
+// Returns len(p) and nil if all writes succeeded, otherwise, the no. of bytes
+// written by the first failing writer and its error.
+func (mw *MultiWriter) Write(p []byte) (int, error) {
+	var (
+		firstN   = len(p)
+		firstErr error
+	)
+	for _, w := range mw.writers {
+		n, err := w.Write(p)
+		if err == nil && n != len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil && firstErr == nil {
+			firstN, firstErr = n, err
+		}
+	}
+
+	return firstN, firstErr
+}
+
+// Stop stops any writer that can be stopped, like a [BufferedWriter].
+// You should call it to make sure all data have been processed.
+func (mw *MultiWriter) Stop() {
+	_ = mw.stop()
+}
+
+// stop stops any writer that can be stopped.
+// Returns the stop errors, if any.
+func (mw *MultiWriter) stop() error {
+	var mErr *xerr.MultiError
+	for _, w := range mw.writers {
+		switch sw := w.(type) {
+		case *BufferedWriter, *MultiWriter:
+			mErr = mErr.Add(stopWriter(sw))
+		case interface{ Stop() }:
+			sw.Stop()
+		}
+	}
+
+	return mErr.ErrOrNil()
+}
+
+// flush flushes any writer that can be flushed, like a [BufferedWriter].
+// Returns the flush errors, if any.
+func (mw *MultiWriter) flush() error {
+	var mErr *xerr.MultiError
+	for _, w := range mw.writers {
+		mErr = mErr.Add(flushWriter(w))
+	}
+
+	return mErr.ErrOrNil()
+}
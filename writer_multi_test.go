@@ -0,0 +1,150 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func ExampleNewMultiWriter() {
+	// In this example we write the same formatted log
+	// to standard output and to an in memory buffer,
+	// formatting the log only once.
+
+	buf := new(bytes.Buffer)
+	opts := xlog.NewCommonOpts()
+	opts.Time = func() any { // mock time for output check
+		return "2022-04-12T16:01:20Z"
+	}
+	opts.SourceKey = "" // disable source for output check
+	logger := xlog.NewSyncLogger(
+		xlog.NewMultiWriter(os.Stdout, buf),
+		xlog.SyncLoggerWithOptions(opts),
+		xlog.SyncLoggerWithFormatter(xlog.LogfmtFormatter),
+	)
+	defer logger.Close()
+
+	logger.Error(xlog.MessageKey, "Hello World")
+	_, _ = io.Copy(os.Stdout, buf)
+
+	// Output:
+	// date=2022-04-12T16:01:20Z lvl=ERROR msg="Hello World"
+	// date=2022-04-12T16:01:20Z lvl=ERROR msg="Hello World"
+}
+
+func TestMultiWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all writers succeed", testMultiWriterWriteSuccessfully)
+	t.Run("a writer fails", testMultiWriterWriteWithErr)
+	t.Run("a writer does a short write", testMultiWriterWriteShortWrite)
+}
+
+func testMultiWriterWriteSuccessfully(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf1    bytes.Buffer
+		buf2    bytes.Buffer
+		subject = xlog.NewMultiWriter(&buf1, &buf2)
+		input   = []byte("foo=bar\n")
+	)
+
+	// act
+	n, err := subject.Write(input)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, len(input), n)
+	assertEqual(t, string(input), buf1.String())
+	assertEqual(t, string(input), buf2.String())
+}
+
+func testMultiWriterWriteWithErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf           bytes.Buffer
+		failingWriter = new(MockWriter)
+		otherWriter   = new(MockWriter)
+		subject       = xlog.NewMultiWriter(failingWriter, &buf, otherWriter)
+		input         = []byte("foo=bar\n")
+	)
+	failingWriter.SetWriteCallback(WriteCallbackErr)
+	otherWriter.SetWriteCallback(func(_ []byte) (int, error) {
+		return 0, errors.New("other error")
+	})
+
+	// act
+	n, err := subject.Write(input)
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite)) // first error is returned.
+	assertEqual(t, 0, n)
+	assertEqual(t, 1, failingWriter.WriteCallsCount())
+	assertEqual(t, 1, otherWriter.WriteCallsCount())
+	assertEqual(t, string(input), buf.String())
+}
+
+func testMultiWriterWriteShortWrite(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.NewMultiWriter(io.Discard, writer)
+		input   = []byte("foo=bar\n")
+	)
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		return len(p) - 1, nil
+	})
+
+	// act
+	n, err := subject.Write(input)
+
+	// assert
+	assertTrue(t, errors.Is(err, io.ErrShortWrite))
+	assertEqual(t, len(input)-1, n)
+}
+
+func TestMultiWriter_Stop(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf1      bytes.Buffer
+		buf2      bytes.Buffer
+		bufWriter = xlog.NewBufferedWriter(
+			&buf1,
+			xlog.BufferedWriterWithFlushInterval(0),
+		)
+		subject = xlog.NewMultiWriter(bufWriter, &buf2)
+		logger  = xlog.NewSyncLogger(subject)
+	)
+	logger.Error(xlog.MessageKey, "foo bar")
+	assertEqual(t, 0, buf1.Len())                             // still buffered.
+	assertTrue(t, strings.Contains(buf2.String(), "foo bar")) // written directly.
+
+	// act
+	_ = logger.Close() // will call Stop on subject, which will stop bufWriter.
+
+	// assert
+	assertTrue(t, strings.Contains(buf1.String(), "foo bar"))
+	calls, _ := bufWriter.DroppedAfterStop()
+	assertEqual(t, uint64(0), calls)
+	_, _ = subject.Write([]byte("x"))
+	calls, _ = bufWriter.DroppedAfterStop()
+	assertEqual(t, uint64(1), calls)
+}
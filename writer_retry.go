@@ -0,0 +1,88 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	// default no. of attempts a retry writer makes.
+	defaultRetryMaxAttempts = 3
+	// default delay before the first retry of a retry writer.
+	defaultRetryBaseDelay = 50 * time.Millisecond
+)
+
+// RetryOptions holds configurations for a retry writer.
+type RetryOptions struct {
+	// MaxAttempts is the max no. of Write attempts (including the first one).
+	// By default, is set to 3.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry.
+	// Delay doubles with each subsequent retry (exponential backoff).
+	// By default, is set to 50ms.
+	BaseDelay time.Duration
+
+	// Retryable is a predicate which reports whether a Write error
+	// should be retried.
+	// By default, all errors are retried.
+	Retryable func(err error) bool
+}
+
+// retryWriter decorates an io.Writer so that a failed Write is retried.
+type retryWriter struct {
+	w    io.Writer
+	opts RetryOptions
+}
+
+// NewRetryWriter instantiates a new Writer which retries, with exponential
+// backoff, a failed Write of the decorated writer, returning the last error
+// if all attempts fail.
+// If the decorated writer writes only part of the bytes before failing,
+// only the remaining bytes are retried, so that nothing gets duplicated.
+// Note: the decorated writer should be "all-or-nothing" (a Write reporting
+// 0 bytes written on error should have written nothing), otherwise
+// duplicated bytes cannot be avoided.
+// Note: a Write blocks during the retries; consider an [AsyncLogger] if
+// this is not acceptable.
+func NewRetryWriter(w io.Writer, opts RetryOptions) io.Writer {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = defaultRetryBaseDelay
+	}
+
+	return &retryWriter{
+		w:    w,
+		opts: opts,
+	}
+}
+
+// Write writes given bytes to the decorated writer, retrying on error.
+// Returns no. of bytes written, or the last error.
+func (rw *retryWriter) Write(p []byte) (int, error) {
+	var (
+		written int
+		delay   = rw.opts.BaseDelay
+	)
+	for attempt := 1; ; attempt++ {
+		n, err := rw.w.Write(p[written:])
+		written += n
+		if err == nil {
+			return written, nil
+		}
+		if attempt >= rw.opts.MaxAttempts ||
+			(rw.opts.Retryable != nil && !rw.opts.Retryable(err)) {
+			return written, err
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
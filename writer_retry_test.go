@@ -0,0 +1,188 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestRetryWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success after transient failures", testRetryWriterSucceedsAfterFailures)
+	t.Run("last error after all attempts fail", testRetryWriterReturnsLastErr)
+	t.Run("non retryable error is not retried", testRetryWriterDoesNotRetryNonRetryableErr)
+	t.Run("partial write is not duplicated", testRetryWriterDoesNotDuplicatePartialWrite)
+	t.Run("backoff between attempts", testRetryWriterBacksOff)
+}
+
+// failingWriter returns a MockWriter which fails first failuresNo calls,
+// and writes into given buffer afterwards.
+func failingWriter(buf *bytes.Buffer, failuresNo int) *MockWriter {
+	writer := new(MockWriter)
+	calls := 0
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		calls++
+		if calls <= failuresNo {
+			return 0, ErrWrite
+		}
+
+		return buf.Write(p)
+	})
+
+	return writer
+}
+
+func testRetryWriterSucceedsAfterFailures(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		writer  = failingWriter(&buf, 2)
+		subject = xlog.NewRetryWriter(writer, xlog.RetryOptions{
+			MaxAttempts: 3,
+			BaseDelay:   time.Microsecond,
+		})
+		log = []byte("some log\n")
+	)
+
+	// act
+	n, err := subject.Write(log)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, len(log), n)
+	assertEqual(t, 3, writer.WriteCallsCount())
+	assertEqual(t, "some log\n", buf.String())
+}
+
+func testRetryWriterReturnsLastErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.NewRetryWriter(writer, xlog.RetryOptions{
+			MaxAttempts: 4,
+			BaseDelay:   time.Microsecond,
+		})
+		lastErr = errors.New("last error")
+		calls   = 0
+	)
+	writer.SetWriteCallback(func([]byte) (int, error) {
+		calls++
+		if calls == 4 {
+			return 0, lastErr
+		}
+
+		return 0, ErrWrite
+	})
+
+	// act
+	n, err := subject.Write([]byte("some log\n"))
+
+	// assert
+	assertTrue(t, errors.Is(err, lastErr))
+	assertEqual(t, 0, n)
+	assertEqual(t, 4, writer.WriteCallsCount())
+}
+
+func testRetryWriterDoesNotRetryNonRetryableErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer       = new(MockWriter)
+		retryableErr = errors.New("retryable error")
+		subject      = xlog.NewRetryWriter(writer, xlog.RetryOptions{
+			MaxAttempts: 5,
+			BaseDelay:   time.Microsecond,
+			Retryable: func(err error) bool {
+				return errors.Is(err, retryableErr)
+			},
+		})
+		calls = 0
+	)
+	writer.SetWriteCallback(func([]byte) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, retryableErr
+		}
+
+		return 0, ErrWrite
+	})
+
+	// act
+	n, err := subject.Write([]byte("some log\n"))
+
+	// assert
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 0, n)
+	assertEqual(t, 2, writer.WriteCallsCount())
+}
+
+func testRetryWriterDoesNotDuplicatePartialWrite(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		writer  = new(MockWriter)
+		subject = xlog.NewRetryWriter(writer, xlog.RetryOptions{BaseDelay: time.Microsecond})
+		log     = []byte("some log\n")
+		calls   = 0
+	)
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		calls++
+		if calls == 1 {
+			n, _ := buf.Write(p[:4]) // write "some", then fail.
+
+			return n, ErrWrite
+		}
+
+		return buf.Write(p)
+	})
+
+	// act
+	n, err := subject.Write(log)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, len(log), n)
+	assertEqual(t, 2, writer.WriteCallsCount())
+	assertEqual(t, "some log\n", buf.String())
+}
+
+func testRetryWriterBacksOff(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf       bytes.Buffer
+		writer    = failingWriter(&buf, 3)
+		baseDelay = 5 * time.Millisecond
+		subject   = xlog.NewRetryWriter(writer, xlog.RetryOptions{
+			MaxAttempts: 4,
+			BaseDelay:   baseDelay,
+		})
+	)
+
+	// act
+	start := time.Now()
+	_, err := subject.Write([]byte("some log\n"))
+	elapsed := time.Since(start)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 4, writer.WriteCallsCount())
+	assertTrue(t, elapsed >= 7*baseDelay) // 5ms + 10ms + 20ms.
+}
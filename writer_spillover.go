@@ -0,0 +1,265 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrSpillFull is returned by a spillover writer's Write, along with
+// the primary writer's error, if the bytes could not be spooled as
+// the spool would exceed its max size.
+var ErrSpillFull = errors.New("xlog: spillover spool is full")
+
+const (
+	// prefix of the spool files' names.
+	spillFilePrefix = "xlog-spill-"
+	// extension of the spool files' names.
+	spillFileExt = ".log"
+	// size after which a new spool file is started.
+	spillFileMaxSize = 1024 * 1024
+	// size of a spooled record's header (holding the record's length).
+	spillRecordHeaderSize = 4
+)
+
+// spillFile holds info about a spool file.
+type spillFile struct {
+	seq  uint64
+	size int64
+	// sealed flag, true means no more records are appended to the file.
+	sealed bool
+}
+
+// spilloverWriter decorates an io.Writer so that bytes failed to be written
+// are spooled on disk, and replayed later.
+type spilloverWriter struct {
+	primary  io.Writer
+	dir      string
+	maxBytes int64
+	// loaded flag, true means the spool files left in dir (by a previous run) were loaded.
+	loaded bool
+	// spool files, oldest first.
+	files []spillFile
+	// offset in the oldest spool file of the first not yet replayed record.
+	offset int64
+	// total size of the spool files.
+	spooled int64
+	// sequence of the next spool file.
+	nextSeq uint64
+	// mu guards all the above.
+	mu sync.Mutex
+}
+
+// SpilloverWriter returns a Writer which writes to the primary writer (a network sink, for example),
+// and, when that fails, appends the bytes to spool files in given dir (created, if needed),
+// instead of losing them. The spooled bytes are replayed, in order, before the next Writes,
+// once the primary writer recovers, and the spool files are removed once replayed.
+// Each Write is spooled and replayed as a whole, so that log lines are not merged.
+// A Write reports success if the bytes were spooled.
+// The total size of the spool files is capped to maxBytes, bytes which do not fit are
+// dropped and an error wrapping [ErrSpillFull] and the primary writer's error is returned.
+// The spool files left by a previous run (in the same dir) are replayed, too.
+// Note: the primary writer should be "all-or-nothing" (a failed Write should have written nothing),
+// otherwise a replayed Write may be duplicated. Also, a spool file partially replayed
+// before a restart is replayed again from its beginning.
+// It is concurrent safe to use.
+func SpilloverWriter(primary io.Writer, dir string, maxBytes int64) io.Writer {
+	return &spilloverWriter{
+		primary:  primary,
+		dir:      dir,
+		maxBytes: maxBytes,
+		nextSeq:  1,
+	}
+}
+
+// Write replays the spooled bytes, if any, and then writes given bytes to the
+// primary writer. If the replay or the write fails, given bytes are spooled.
+// Returns no. of bytes written (or spooled), or an error.
+func (sw *spilloverWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if !sw.loaded {
+		if err := sw.load(); err != nil {
+			return 0, err
+		}
+	}
+
+	var (
+		written int
+		err     error
+	)
+	if err = sw.replay(); err == nil {
+		written, err = sw.primary.Write(p)
+		if err == nil {
+			return written, nil
+		}
+	}
+
+	if spoolErr := sw.spool(p[written:]); spoolErr != nil {
+		return written, fmt.Errorf("%w: %w", spoolErr, err)
+	}
+
+	return len(p), nil
+}
+
+// load loads the spool files left in dir by a previous run.
+func (sw *spilloverWriter) load() error {
+	entries, err := os.ReadDir(sw.dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, entry := range entries {
+		seq, ok := spillFileSeq(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		sw.files = append(sw.files, spillFile{seq: seq, size: info.Size(), sealed: true})
+		sw.spooled += info.Size()
+		sw.nextSeq = max(sw.nextSeq, seq+1)
+	}
+	sort.Slice(sw.files, func(i, j int) bool {
+		return sw.files[i].seq < sw.files[j].seq
+	})
+	sw.loaded = true
+
+	return nil
+}
+
+// replay writes the spooled records to the primary writer, oldest first,
+// removing the spool files once replayed.
+// It stops at first primary writer's error, which is returned.
+func (sw *spilloverWriter) replay() error {
+	for len(sw.files) > 0 {
+		if err := sw.replayOldestFile(); err != nil {
+			return err
+		}
+		if err := os.Remove(sw.filePath(sw.files[0].seq)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		sw.spooled -= sw.files[0].size
+		sw.files = sw.files[1:]
+		sw.offset = 0
+	}
+
+	return nil
+}
+
+// replayOldestFile writes the oldest spool file's records to the primary writer,
+// starting with the first not yet replayed one.
+// A truncated record (an incomplete spool, due to a crash, for example) ends the file.
+func (sw *spilloverWriter) replayOldestFile() error {
+	f, err := os.Open(sw.filePath(sw.files[0].seq))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(sw.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	var (
+		reader = bufio.NewReader(f)
+		header [spillRecordHeaderSize]byte
+		record []byte
+	)
+	for {
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			return ignoreSpillEOF(err)
+		}
+		recordSize := int(binary.BigEndian.Uint32(header[:]))
+		if cap(record) < recordSize {
+			record = make([]byte, recordSize)
+		}
+		record = record[:recordSize]
+		if _, err := io.ReadFull(reader, record); err != nil {
+			return ignoreSpillEOF(err)
+		}
+		if _, err := sw.primary.Write(record); err != nil {
+			return err
+		}
+		sw.offset += int64(spillRecordHeaderSize + len(record))
+	}
+}
+
+// spool appends given bytes, as a record, to the newest spool file,
+// starting a new one, if needed.
+func (sw *spilloverWriter) spool(p []byte) error {
+	recordSize := int64(spillRecordHeaderSize + len(p))
+	if sw.spooled+recordSize > sw.maxBytes {
+		return ErrSpillFull
+	}
+	if len(sw.files) == 0 || sw.files[len(sw.files)-1].sealed {
+		if err := os.MkdirAll(sw.dir, 0o755); err != nil {
+			return err
+		}
+		sw.files = append(sw.files, spillFile{seq: sw.nextSeq})
+		sw.nextSeq++
+	}
+	last := &sw.files[len(sw.files)-1]
+
+	f, err := os.OpenFile(sw.filePath(last.seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	record := make([]byte, recordSize)
+	binary.BigEndian.PutUint32(record, uint32(len(p)))
+	copy(record[spillRecordHeaderSize:], p)
+	n, err := f.Write(record)
+	last.size += int64(n)
+	sw.spooled += int64(n)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	// a failed append may leave an incomplete record, which ends the file when replayed,
+	// so next records go to a new file.
+	last.sealed = err != nil || last.size >= spillFileMaxSize
+
+	return err
+}
+
+// filePath returns the path of the spool file with given sequence.
+func (sw *spilloverWriter) filePath(seq uint64) string {
+	return filepath.Join(sw.dir, fmt.Sprintf("%s%020d%s", spillFilePrefix, seq, spillFileExt))
+}
+
+// spillFileSeq returns the sequence of the spool file with given name.
+// Returns false if the name is not a spool file's one.
+func spillFileSeq(name string) (uint64, bool) {
+	if !strings.HasPrefix(name, spillFilePrefix) || !strings.HasSuffix(name, spillFileExt) {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, spillFilePrefix), spillFileExt), 10, 64)
+
+	return seq, err == nil
+}
+
+// ignoreSpillEOF returns nil for the errors marking the end of a spool file.
+func ignoreSpillEOF(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+
+	return err
+}
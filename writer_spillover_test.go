@@ -0,0 +1,264 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xlog/blob/main/LICENSE.
+
+package xlog_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/actforgood/xlog"
+)
+
+func TestSpilloverWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("spooled bytes are replayed in order on recovery", testSpilloverWriterSpoolsAndReplays)
+	t.Run("replay resumes after another failure", testSpilloverWriterReplayResumes)
+	t.Run("spool size is capped", testSpilloverWriterCapsSpool)
+	t.Run("spool files of a previous run are replayed", testSpilloverWriterReplaysPreviousRun)
+	t.Run("no failure, nothing is spooled", testSpilloverWriterNoFailure)
+	t.Run("concurrency", testSpilloverWriterConcurrency)
+}
+
+// toggleWriter is a writer which fails while it is down.
+type toggleWriter struct {
+	writes [][]byte
+	down   atomic.Bool
+	mu     sync.Mutex
+}
+
+func (w *toggleWriter) Write(p []byte) (int, error) {
+	if w.down.Load() {
+		return 0, ErrWrite
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, append([]byte(nil), p...))
+
+	return len(p), nil
+}
+
+func (w *toggleWriter) Writes() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	writes := make([]string, len(w.writes))
+	for idx, p := range w.writes {
+		writes[idx] = string(p)
+	}
+
+	return writes
+}
+
+// spoolSize returns the total size of the files found in given dir.
+func spoolSize(t *testing.T, dir string) (filesCount int, size int64) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, 0
+		}
+		t.Fatal(err.Error())
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		size += info.Size()
+	}
+
+	return len(entries), size
+}
+
+func testSpilloverWriterSpoolsAndReplays(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		primary = new(toggleWriter)
+		dir     = filepath.Join(t.TempDir(), "spool")
+		subject = xlog.SpilloverWriter(primary, dir, 1024)
+	)
+	_, _ = subject.Write([]byte("line1\n"))
+	primary.down.Store(true)
+
+	// act - write while primary is down.
+	for _, line := range []string{"line2\n", "line3\n", "line4\n"} {
+		n, err := subject.Write([]byte(line))
+
+		// assert
+		assertEqual(t, len(line), n)
+		assertNil(t, err)
+	}
+
+	// assert - bytes are spooled to disk.
+	assertEqual(t, []string{"line1\n"}, primary.Writes())
+	filesCount, size := spoolSize(t, dir)
+	assertEqual(t, 1, filesCount)
+	assertEqual(t, int64(3*(4+6)), size)
+	entries, _ := os.ReadDir(dir)
+	content, _ := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	assertTrue(t, bytes.Contains(content, []byte("line2\n")))
+	assertTrue(t, bytes.Contains(content, []byte("line4\n")))
+
+	// act - recover primary.
+	primary.down.Store(false)
+	n, err := subject.Write([]byte("line5\n"))
+
+	// assert - spooled bytes are replayed in order and pruned.
+	assertEqual(t, 6, n)
+	assertNil(t, err)
+	assertEqual(t, []string{"line1\n", "line2\n", "line3\n", "line4\n", "line5\n"}, primary.Writes())
+	filesCount, _ = spoolSize(t, dir)
+	assertEqual(t, 0, filesCount)
+}
+
+func testSpilloverWriterReplayResumes(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		primary = new(toggleWriter)
+		writer  = new(MockWriter)
+		subject = xlog.SpilloverWriter(writer, t.TempDir(), 1024)
+	)
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		// fails the 1st, 2nd writes, and the 4th one (line2's replay).
+		switch writer.WriteCallsCount() {
+		case 1, 2, 4:
+			return 0, ErrWrite
+		}
+
+		return primary.Write(p)
+	})
+
+	// act
+	_, _ = subject.Write([]byte("line1\n")) // spooled.
+	_, _ = subject.Write([]byte("line2\n")) // line1 replay fails, line2 spooled.
+	_, _ = subject.Write([]byte("line3\n")) // line1 replayed, line2 replay fails, line3 spooled.
+	_, _ = subject.Write([]byte("line4\n")) // line2, line3 replayed, line4 written.
+
+	// assert
+	assertEqual(t, []string{"line1\n", "line2\n", "line3\n", "line4\n"}, primary.Writes())
+}
+
+func testSpilloverWriterCapsSpool(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		primary = new(toggleWriter)
+		dir     = t.TempDir()
+		subject = xlog.SpilloverWriter(primary, dir, 25)
+	)
+	primary.down.Store(true)
+
+	// act
+	n1, err1 := subject.Write([]byte("line1\n"))
+	n2, err2 := subject.Write([]byte("line2\n"))
+	n3, err3 := subject.Write([]byte("line3\n"))
+
+	// assert
+	assertEqual(t, 6, n1)
+	assertNil(t, err1)
+	assertEqual(t, 6, n2)
+	assertNil(t, err2)
+	assertEqual(t, 0, n3)
+	assertTrue(t, errors.Is(err3, xlog.ErrSpillFull))
+	assertTrue(t, errors.Is(err3, ErrWrite))
+	_, size := spoolSize(t, dir)
+	assertEqual(t, int64(20), size)
+
+	// act - recover primary.
+	primary.down.Store(false)
+	_, _ = subject.Write([]byte("line4\n"))
+
+	// assert
+	assertEqual(t, []string{"line1\n", "line2\n", "line4\n"}, primary.Writes())
+}
+
+func testSpilloverWriterReplaysPreviousRun(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		primary = new(toggleWriter)
+		dir     = t.TempDir()
+	)
+	primary.down.Store(true)
+	previousRun := xlog.SpilloverWriter(primary, dir, 1024)
+	_, _ = previousRun.Write([]byte("line1\n"))
+	_, _ = previousRun.Write([]byte("line2\n"))
+	primary.down.Store(false)
+	subject := xlog.SpilloverWriter(primary, dir, 1024)
+
+	// act
+	_, _ = subject.Write([]byte("line3\n"))
+
+	// assert
+	assertEqual(t, []string{"line1\n", "line2\n", "line3\n"}, primary.Writes())
+	filesCount, _ := spoolSize(t, dir)
+	assertEqual(t, 0, filesCount)
+}
+
+func testSpilloverWriterNoFailure(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		primary = new(toggleWriter)
+		dir     = filepath.Join(t.TempDir(), "spool")
+		subject = xlog.SpilloverWriter(primary, dir, 1024)
+	)
+
+	// act
+	n, err := subject.Write([]byte("line1\n"))
+
+	// assert
+	assertEqual(t, 6, n)
+	assertNil(t, err)
+	assertEqual(t, []string{"line1\n"}, primary.Writes())
+	_, err = os.Stat(dir)
+	assertTrue(t, errors.Is(err, os.ErrNotExist))
+}
+
+func testSpilloverWriterConcurrency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		primary      = new(toggleWriter)
+		subject      = xlog.SpilloverWriter(primary, t.TempDir(), 1024*1024)
+		goroutinesNo = 50
+		wg           sync.WaitGroup
+	)
+
+	// act
+	for i := 0; i < goroutinesNo; i++ {
+		wg.Add(1)
+		go func(threadNo int) {
+			defer wg.Done()
+			primary.down.Store(threadNo%2 == 0)
+			_, err := subject.Write([]byte(strconv.Itoa(threadNo)))
+			assertNil(t, err)
+		}(i)
+	}
+	wg.Wait()
+	primary.down.Store(false)
+	_, _ = subject.Write([]byte("last"))
+
+	// assert
+	writes := primary.Writes()
+	assertEqual(t, goroutinesNo+1, len(writes))
+	assertEqual(t, "last", writes[len(writes)-1])
+}
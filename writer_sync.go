@@ -22,6 +22,8 @@ type syncWriter struct {
 
 // NewSyncWriter instantiates a new Writer decorated
 // with a mutex, making is safe for concurrent use by multiple goroutines.
+// The returned writer implements [io.StringWriter], too, which is of use
+// for callers writing strings through [io.WriteString], like custom formatters.
 func NewSyncWriter(w io.Writer) io.Writer {
 	return &syncWriter{
 		w:  w,
@@ -37,3 +39,13 @@ func (sw syncWriter) Write(p []byte) (int, error) {
 
 	return sw.w.Write(p)
 }
+
+// WriteString writes given string to the decorated writer, through its WriteString
+// method, if it implements [io.StringWriter], sparing a []byte conversion.
+// Returns no. of bytes written, or an error.
+func (sw syncWriter) WriteString(s string) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	return io.WriteString(sw.w, s)
+}
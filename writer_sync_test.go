@@ -12,6 +12,7 @@ import (
 	"io"
 	"log"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 
@@ -108,3 +109,76 @@ func TestSyncWriter_concurrency(t *testing.T) {
 	assertEqual(t, goroutinesNo, linesCount)
 	assertEqual(t, expectedSum, sum)
 }
+
+func TestSyncWriter_WriteString(t *testing.T) {
+	t.Parallel()
+
+	t.Run("string writer", testSyncWriterWriteStringToStringWriter)
+	t.Run("non string writer", testSyncWriterWriteStringToWriter)
+}
+
+func testSyncWriterWriteStringToStringWriter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  bytes.Buffer
+		subject = xlog.NewSyncWriter(&writer)
+	)
+
+	// act
+	n, err := subject.(io.StringWriter).WriteString("some log\n")
+
+	// assert
+	assertEqual(t, 9, n)
+	assertNil(t, err)
+	assertEqual(t, "some log\n", writer.String())
+}
+
+func testSyncWriterWriteStringToWriter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		writer  = new(MockWriter)
+		subject = xlog.NewSyncWriter(writer)
+	)
+	writer.SetWriteCallback(func(p []byte) (int, error) {
+		assertEqual(t, []byte("some log\n"), p)
+
+		return len(p), ErrWrite
+	})
+
+	// act
+	n, err := subject.(io.StringWriter).WriteString("some log\n")
+
+	// assert
+	assertEqual(t, 9, n)
+	assertTrue(t, errors.Is(err, ErrWrite))
+	assertEqual(t, 1, writer.WriteCallsCount())
+}
+
+func BenchmarkSyncWriter(b *testing.B) {
+	var (
+		subject = xlog.NewSyncWriter(io.Discard) // io.Discard implements io.StringWriter.
+		text    = strings.Repeat("Lorem ipsum dolor sit amet. ", 4) + strconv.Itoa(b.N)
+	)
+
+	b.Run("Write", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			_, _ = subject.Write([]byte(text))
+		}
+	})
+
+	b.Run("WriteString", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			_, _ = io.WriteString(subject, text)
+		}
+	})
+}